@@ -0,0 +1,28 @@
+package mariadb
+
+import (
+	"context"
+
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/crudquery"
+)
+
+// Count implements crud.Counter with a SELECT COUNT(*) query.
+func (r Repository[ENT, ID]) Count(ctx context.Context) (int, error) {
+	return r.repository().Count(ctx)
+}
+
+// CountQuery implements crud.QueryCounter by counting the rows that match the query's Where expression.
+func (r Repository[ENT, ID]) CountQuery(ctx context.Context, q crudquery.Query) (int, error) {
+	return r.repository().CountQuery(ctx, q)
+}
+
+// CountGroups implements crud.GroupCounter with a GROUP BY query over the column that the field refers to.
+func (r Repository[ENT, ID]) CountGroups(ctx context.Context, field string, q crudquery.Query) ([]crud.GroupCount, error) {
+	return r.repository().CountGroups(ctx, field, q)
+}
+
+// ExistsByID implements crud.Exister with a SELECT EXISTS query.
+func (r Repository[ENT, ID]) ExistsByID(ctx context.Context, id ID) (bool, error) {
+	return r.repository().ExistsByID(ctx, id)
+}
@@ -0,0 +1,25 @@
+package mariadb
+
+import (
+	"context"
+)
+
+// CreateMany implements crud.ManyCreator with multi-row INSERT statements within a single transaction.
+// The entities that already exist are reported in a crud.BulkError, and they are left out from the INSERT.
+func (r Repository[ENT, ID]) CreateMany(ctx context.Context, ptrs ...*ENT) error {
+	return r.repository().CreateMany(ctx, ptrs...)
+}
+
+// UpdateMany implements crud.ManyUpdater within a single transaction.
+// Unless the entities are versioned or soft deletable,
+// the present entities are updated with a multi-row INSERT ... ON DUPLICATE KEY UPDATE statement.
+// The absent and the concurrently modified entities are reported in a crud.BulkError.
+func (r Repository[ENT, ID]) UpdateMany(ctx context.Context, ptrs ...*ENT) error {
+	return r.repository().UpdateMany(ctx, ptrs...)
+}
+
+// DeleteByIDs implements crud.ByIDsDeleter with multi-row DELETE statements within a single transaction.
+// The IDs of the absent entities are reported in a crud.BulkError.
+func (r Repository[ENT, ID]) DeleteByIDs(ctx context.Context, ids ...ID) error {
+	return r.repository().DeleteByIDs(ctx, ids...)
+}
@@ -5,7 +5,9 @@ go 1.25
 require (
 	github.com/go-sql-driver/mysql v1.9.3
 	go.llib.dev/frameless v0.318.0
-	go.llib.dev/testcase v0.189.0
+	go.llib.dev/testcase v0.193.0
 )
 
 require filippo.io/edwards25519 v1.2.0 // indirect
+
+replace go.llib.dev/frameless => ../..
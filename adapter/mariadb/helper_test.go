@@ -103,6 +103,63 @@ var FooMapping = flsql.Mapping[testent.Foo, testent.FooID]{
 	ID: func(f *testent.Foo) *testent.FooID { return &f.ID },
 }
 
+func MigrateComposite(tb testing.TB, c flsql.Connection) {
+	ctx := context.Background()
+	_, err := c.ExecContext(ctx, CompositeMigrateDOWN)
+	assert.Nil(tb, err)
+	_, err = c.ExecContext(ctx, CompositeMigrateUP)
+	assert.Nil(tb, err)
+	tb.Cleanup(func() {
+		_, err := c.ExecContext(ctx, CompositeMigrateDOWN)
+		assert.Nil(tb, err)
+	})
+}
+
+const CompositeMigrateUP = `
+CREATE TABLE IF NOT EXISTS composites (
+    tenant_id VARCHAR(255) NOT NULL,
+    local_id  INT          NOT NULL,
+    value     LONGTEXT     NOT NULL,
+    PRIMARY KEY (tenant_id, local_id)
+);
+`
+
+const CompositeMigrateDOWN = `
+DROP TABLE IF EXISTS composites;
+`
+
+var CompositeMapping = flsql.Mapping[testent.Composite, testent.CompositeID]{
+	TableName: "composites",
+
+	ToQuery: func(ctx context.Context) ([]flsql.ColumnName, flsql.MapScan[testent.Composite]) {
+		return []flsql.ColumnName{"tenant_id", "local_id", "value"},
+			func(v *testent.Composite, sf flsql.Scanner) error {
+				return sf.Scan(&v.TenantID, &v.LocalID, &v.Value)
+			}
+	},
+
+	QueryID: func(id testent.CompositeID) (flsql.QueryArgs, error) {
+		return flsql.QueryArgs{"tenant_id": id.TenantID, "local_id": id.LocalID}, nil
+	},
+
+	ToArgs: func(v testent.Composite) (flsql.QueryArgs, error) {
+		return flsql.QueryArgs{
+			"tenant_id": v.TenantID,
+			"local_id":  v.LocalID,
+			"value":     v.Value,
+		}, nil
+	},
+
+	Prepare: func(ctx context.Context, v *testent.Composite) error {
+		if zerokit.IsZero(v.TenantID) && zerokit.IsZero(v.LocalID) {
+			rnd := random.New(random.CryptoSeed{})
+			v.TenantID = rnd.UUID()
+			v.LocalID = rnd.IntBetween(1, 1<<30)
+		}
+		return nil
+	},
+}
+
 func MakeContext(testing.TB) context.Context { return context.Background() }
 
 func MakeString(tb testing.TB) string {
@@ -203,3 +260,61 @@ CREATE TABLE test_entities (
 const testMigrateDOWN = `
 DROP TABLE IF EXISTS test_entities;
 `
+
+var DocMapping = flsql.Mapping[testent.Doc, testent.DocID]{
+	TableName:     "test_docs",
+	VersionColumn: "version",
+
+	QueryID: func(id testent.DocID) (flsql.QueryArgs, error) {
+		return flsql.QueryArgs{"id": id}, nil
+	},
+
+	ToArgs: func(d testent.Doc) (flsql.QueryArgs, error) {
+		return flsql.QueryArgs{
+			`id`:      d.ID,
+			`title`:   d.Title,
+			`body`:    d.Body,
+			`version`: d.Version,
+		}, nil
+	},
+
+	ToQuery: func(ctx context.Context) ([]flsql.ColumnName, flsql.MapScan[testent.Doc]) {
+		return []flsql.ColumnName{`id`, `title`, `body`, `version`},
+			func(v *testent.Doc, s flsql.Scanner) error {
+				return s.Scan(&v.ID, &v.Title, &v.Body, &v.Version)
+			}
+	},
+
+	Prepare: func(ctx context.Context, d *testent.Doc) error {
+		if zerokit.IsZero(d.ID) {
+			d.ID = testent.DocID(random.New(random.CryptoSeed{}).UUID())
+		}
+		return nil
+	},
+}
+
+func MigrateDoc(tb testing.TB, cm flsql.Connection) {
+	ctx := context.Background()
+	_, err := cm.ExecContext(ctx, testMigrateDocDOWN)
+	assert.Nil(tb, err)
+	_, err = cm.ExecContext(ctx, testMigrateDocUP)
+	assert.Nil(tb, err)
+
+	tb.Cleanup(func() {
+		_, err := cm.ExecContext(ctx, testMigrateDocDOWN)
+		assert.Nil(tb, err)
+	})
+}
+
+const testMigrateDocUP = `
+CREATE TABLE test_docs (
+    id      VARCHAR(255) NOT NULL PRIMARY KEY,
+    title   LONGTEXT     NOT NULL,
+    body    LONGTEXT     NOT NULL,
+    version BIGINT       NOT NULL
+);
+`
+
+const testMigrateDocDOWN = `
+DROP TABLE IF EXISTS test_docs;
+`
@@ -19,25 +19,57 @@ import (
 	"go.llib.dev/frameless/pkg/errorkit"
 	"go.llib.dev/frameless/pkg/flsql"
 	"go.llib.dev/frameless/pkg/iterkit"
-	"go.llib.dev/frameless/pkg/logger"
-	"go.llib.dev/frameless/pkg/logging"
-	"go.llib.dev/frameless/pkg/slicekit"
 	"go.llib.dev/frameless/pkg/tasker"
 	"go.llib.dev/frameless/pkg/zerokit"
-	"go.llib.dev/frameless/port/comproto"
 	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/crudquery"
 	"go.llib.dev/frameless/port/crud/extid"
 	"go.llib.dev/frameless/port/guard"
 	"go.llib.dev/frameless/port/migration"
 )
 
 func Connect(dsn string) (Connection, error) {
-	db, err := sql.Open("mysql", dsn)
+	db, err := open(dsn)
 	if err != nil {
 		return Connection{}, err
 	}
-	ca := flsql.SQLConnectionAdapter(db)
-	conn := Connection{ConnectionAdapter: ca}
+	return Connection{ConnectionAdapter: flsql.SQLConnectionAdapter(db)}, nil
+}
+
+// ConnectWithReplicas will connect to a primary MariaDB and to its read-only replicas.
+//
+// Outside of a transaction, the read-only queries, such as FindByID and FindAll, are routed to a healthy replica,
+// while the writes and everything within a transaction goes to the primary.
+// Closing the Connection closes every replica as well.
+func ConnectWithReplicas(primaryDSN string, replicaDSNs ...string) (Connection, error) {
+	primary, err := open(primaryDSN)
+	if err != nil {
+		return Connection{}, err
+	}
+	var replicas []*sql.DB
+	for _, dsn := range replicaDSNs {
+		replica, err := open(dsn)
+		if err != nil {
+			errs := []error{err, primary.Close()}
+			for _, replica := range replicas {
+				errs = append(errs, replica.Close())
+			}
+			return Connection{}, errorkit.Merge(errs...)
+		}
+		replicas = append(replicas, replica)
+	}
+	ca := flsql.SQLConnectionAdapter(primary)
+	if 0 < len(replicas) {
+		ca.Replicas = &flsql.ReplicaSet[sql.DB]{Replicas: replicas}
+	}
+	return Connection{ConnectionAdapter: ca}, nil
+}
+
+func open(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, err
+	}
 	// SetConnMaxLifetime is required to ensure connections are closed by the driver safely before connection is closed by MySQL server,
 	// OS, or other middlewares. Since some middlewares close idle connections by 5 minutes,
 	// we recommend timeout shorter than 5 minutes.
@@ -51,273 +83,75 @@ func Connect(dsn string) (Connection, error) {
 	// Idle connections can be closed by the db.SetConnMaxLifetime().
 	// If you want to close idle connections more rapidly, you can use db.SetConnMaxIdleTime() since Go 1.15.
 	db.SetMaxIdleConns(10)
-	return conn, nil
+	return db, nil
 }
 
 type Connection struct {
 	flsql.ConnectionAdapter[sql.DB, sql.Tx]
 }
 
+// Dialect is the flsql.Dialect of MariaDB.
+// MariaDB supports RETURNING in INSERT statements,
+// thus the inserted rows are read back without an extra query.
+var Dialect = func() flsql.Dialect {
+	d := flsql.DialectMySQL
+	d.Returning = true
+	return d
+}()
+
 // Repository implements CRUD operations for a specific entity type in mariadb.
+//
+// The CRUD operations are handled by flsql.Repository with the MariaDB Dialect.
 type Repository[ENT, ID any] struct {
 	Connection Connection
 	Mapping    flsql.Mapping[ENT, ID]
 }
 
-func (r Repository[ENT, ID]) Create(ctx context.Context, ptr *ENT) (rErr error) {
-	if ptr == nil {
-		return fmt.Errorf("nil entity pointer given to Create")
-	}
-	ctx, err := r.BeginTx(ctx)
-	if err != nil {
-		return err
-	}
-	// this will ensure that either commit or rollback is called on the transaction,
-	// depending on the value of "rErr".
-	defer comproto.FinishOnePhaseCommit(&rErr, r, ctx)
-
-	if err := r.Mapping.OnPrepare(ctx, ptr); err != nil {
-		return err
-	}
-
-	id, ok := r.Mapping.ID.Lookup(*ptr)
-	if ok {
-		_, found, err := r.FindByID(ctx, id)
-		if err != nil {
-			return err
-		}
-		if found {
-			err := crud.ErrAlreadyExists.F(`%T already exists with id: %v`, *new(ENT), id)
-			err = errorkit.WithContext(err, ctx)
-			return err
-		}
-	}
-
-	args, err := r.Mapping.ToArgs(*ptr)
-	if err != nil {
-		return err
-	}
-
-	cols, valuesArgs := flsql.SplitArgs(args)
-	valueClause := make([]string, len(cols))
-	for i := range cols {
-		valueClause[i] = "?"
-	}
-
-	rcolumns, mapscan := r.Mapping.ToQuery(contextkit.WithoutValues(ctx))
-
-	query := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES (%s) RETURNING %s",
-		r.Mapping.TableName,
-		flsql.JoinColumnName(cols, "`%s`", ", "),
-		strings.Join(valueClause, ", "),
-		flsql.JoinColumnName(rcolumns, "`%s`", ", "),
-	)
-
-	logger.Debug(ctx, "executing create SQL", logging.Field("query", query))
-
-	row := r.Connection.QueryRowContext(ctx, query, valuesArgs...)
-
-	var got ENT
-	if err := mapscan(&got, row); err != nil {
-		return err
+func (r Repository[ENT, ID]) repository() flsql.Repository[ENT, ID] {
+	return flsql.Repository[ENT, ID]{
+		Connection: r.Connection,
+		Mapping:    r.Mapping,
+		Dialect:    Dialect,
 	}
-	*ptr = got
+}
 
-	return nil
+func (r Repository[ENT, ID]) Create(ctx context.Context, ptr *ENT) error {
+	return r.repository().Create(ctx, ptr)
 }
 
 func (r Repository[ENT, ID]) FindByID(ctx context.Context, id ID) (ENT, bool, error) {
-	var queryArgs []any
-
-	idArgs, err := r.Mapping.QueryID(id)
-	if err != nil {
-		return *new(ENT), false, err
-	}
-
-	cols, scan := r.Mapping.ToQuery(ctx)
-
-	idWhereClauseCols, idWhereClauseArgs := flsql.SplitArgs(idArgs)
-	queryArgs = append(queryArgs, idWhereClauseArgs...)
-
-	query := fmt.Sprintf("SELECT %s FROM `%s` WHERE %s LIMIT 1",
-		flsql.JoinColumnName(cols, "`%s`", ", "),
-		r.Mapping.TableName,
-		flsql.JoinColumnName(idWhereClauseCols, "`%s` = ?", " AND "),
-	)
-
-	row := r.Connection.QueryRowContext(ctx, query, queryArgs...)
-
-	var v ENT
-	err = scan(&v, row)
-	if errors.Is(err, sql.ErrNoRows) {
-		return *new(ENT), false, nil
-	}
-
-	if err != nil {
-		return *new(ENT), false, err
-	}
-
-	return v, true, nil
+	return r.repository().FindByID(ctx, id)
 }
 
-func (r Repository[ENT, ID]) DeleteAll(ctx context.Context) (rErr error) {
-	ctx, err := r.BeginTx(ctx)
-	if err != nil {
-		return err
-	}
-	defer comproto.FinishOnePhaseCommit(&rErr, r, ctx)
-
-	query := fmt.Sprintf("DELETE FROM `%s`", r.Mapping.TableName)
-
-	if _, err := r.Connection.ExecContext(ctx, query); err != nil {
-		return err
-	}
-
-	return nil
+func (r Repository[ENT, ID]) DeleteAll(ctx context.Context) error {
+	return r.repository().DeleteAll(ctx)
 }
 
-func (r Repository[ENT, ID]) DeleteByID(ctx context.Context, id ID) (rErr error) {
-	idArgs, err := r.Mapping.QueryID(id)
-	if err != nil {
-		return err
-	}
-
-	whereClauseQuery, whereClauseArgs := r.buildWhereClause(idArgs)
-
-	query := fmt.Sprintf("DELETE FROM `%s` WHERE %s",
-		r.Mapping.TableName,
-		whereClauseQuery,
-	)
-
-	ctx, err = r.BeginTx(ctx)
-	if err != nil {
-		return err
-	}
-	defer comproto.FinishOnePhaseCommit(&rErr, r, ctx)
-
-	result, err := r.Connection.ExecContext(ctx, query, whereClauseArgs...)
-	if err != nil {
-		return err
-	}
-
-	if n, err := result.RowsAffected(); err != nil {
-		return err
-	} else if n == 0 {
-		return crud.ErrNotFound
-	}
-
-	return nil
+func (r Repository[ENT, ID]) DeleteByID(ctx context.Context, id ID) error {
+	return r.repository().DeleteByID(ctx, id)
 }
 
-func (r Repository[ENT, ID]) Update(ctx context.Context, ptr *ENT) (rErr error) {
-	if ptr == nil {
-		return fmt.Errorf("nil entity pointer received in Update")
-	}
-
-	id, ok := r.Mapping.ID.Lookup(*ptr)
-	if !ok {
-		return fmt.Errorf("missing entity ID for Update")
-	}
-
-	idArgs, err := r.Mapping.QueryID(id)
-	if err != nil {
-		return err
-	}
-
-	setArgs, err := r.Mapping.ToArgs(*ptr)
-	if err != nil {
-		return err
-	}
-
-	cols, values := flsql.SplitArgs(setArgs)
-	whereClauseQuery, whereClauseArgs := r.buildWhereClause(idArgs)
-	args := append(values, whereClauseArgs...)
-
-	// Corrected part: Removed the `setClause` variable and directly inserted the mapped columns into the query.
-	query := fmt.Sprintf("UPDATE `%s` SET %s WHERE %s",
-		r.Mapping.TableName,
-		flsql.JoinColumnName(cols, "`%s` = ?", ", "),
-		whereClauseQuery,
-	)
-
-	ctx, err = r.BeginTx(ctx)
-	if err != nil {
-		return err
-	}
-	defer comproto.FinishOnePhaseCommit(&rErr, r, ctx)
-
-	res, err := r.Connection.ExecContext(ctx, query, args...)
-	if err != nil {
-		return err
-	}
-
-	if n, err := res.RowsAffected(); err != nil {
-		return err
-	} else if n == 0 {
-		return crud.ErrNotFound
-	}
-
-	got, found, err := r.FindByID(ctx, id)
-	if err != nil {
-		return fmt.Errorf("error while looking up the entity: %w", err)
-	}
-	if !found {
-		return fmt.Errorf("expected that updated entity is findable")
-	}
-	*ptr = got
-
-	return nil
+func (r Repository[ENT, ID]) Update(ctx context.Context, ptr *ENT) error {
+	return r.repository().Update(ctx, ptr)
 }
 
 func (r Repository[ENT, ID]) FindAll(ctx context.Context) iterkit.SeqE[ENT] {
-	cols, scan := r.Mapping.ToQuery(ctx)
-	query := fmt.Sprintf("SELECT %s FROM `%s`",
-		flsql.JoinColumnName(cols, "`%s`", ", "),
-		r.Mapping.TableName,
-	)
-	return flsql.QueryMany(r.Connection, ctx, scan.Map, query)
+	return r.repository().FindAll(ctx)
 }
 
 func (r Repository[ENT, ID]) FindByIDs(ctx context.Context, ids ...ID) iterkit.SeqE[ENT] {
-	if len(ids) == 0 {
-		return iterkit.Empty2[ENT, error]()
-	}
+	return r.repository().FindByIDs(ctx, ids...)
+}
 
-	var (
-		whereClauses []string
-		queryArgs    []any
-	)
-	for _, id := range ids {
-		idArgs, err := r.Mapping.QueryID(id)
-		if err != nil {
-			return iterkit.Error[ENT](err)
-		}
-		whereClauseQuery, whereClauseArgs := r.buildWhereClause(idArgs)
-		whereClauses = append(whereClauses, fmt.Sprintf("(%s)", whereClauseQuery))
-		queryArgs = append(queryArgs, whereClauseArgs...)
-	}
+// Query implements crud.Querier by compiling the query into SQL.
+// Query fields are resolved against the Mapping's ToQuery columns, see crudquery.MatchField.
+func (r Repository[ENT, ID]) Query(ctx context.Context, q crudquery.Query) iterkit.SeqE[ENT] {
+	return r.repository().Query(ctx, q)
+}
 
-	cols, scan := r.Mapping.ToQuery(ctx)
-	query := fmt.Sprintf("SELECT `%s` FROM `%s` WHERE %s",
-		strings.Join(slicekit.Map(cols, func(c flsql.ColumnName) string { return string(c) }), "`, `"),
-		r.Mapping.TableName,
-		strings.Join(whereClauses, " OR "),
-	)
-
-	{
-		var (
-			count      int
-			countQuery = fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS `src`", query)
-		)
-		err := r.Connection.QueryRowContext(ctx, countQuery, queryArgs...).Scan(&count)
-		if err != nil {
-			return iterkit.Error[ENT](err)
-		}
-		if count != len(ids) {
-			return iterkit.Error[ENT](crud.ErrNotFound)
-		}
-	}
-	return flsql.QueryMany(r.Connection, ctx, scan.Map, query, queryArgs...)
+// FindPage implements crud.PageFinder with keyset pagination over the ID columns of the table.
+func (r Repository[ENT, ID]) FindPage(ctx context.Context, cursor crud.Cursor, size int) (crud.Page[ENT], error) {
+	return r.repository().FindPage(ctx, cursor, size)
 }
 
 // BeginTx implements the comproto.OnePhaseCommitter interface.
@@ -335,67 +169,23 @@ func (r Repository[ENT, ID]) RollbackTx(ctx context.Context) error {
 	return r.Connection.RollbackTx(ctx)
 }
 
-func (r Repository[ENT, ID]) buildWhereClause(qargs flsql.QueryArgs) (string, []any) {
-	cols, args := flsql.SplitArgs(qargs)
-	return flsql.JoinColumnName(cols, "`%s` = ?", " AND "), args
+// SoftDeleteByID implements crud.SoftDeleter by setting the Mapping.DeletedAtColumn of the row.
+func (r Repository[ENT, ID]) SoftDeleteByID(ctx context.Context, id ID) error {
+	return r.repository().SoftDeleteByID(ctx, id)
 }
 
-func (r Repository[ENT, ID]) Save(ctx context.Context, ptr *ENT) (rErr error) {
-	ctx, err := r.BeginTx(ctx)
-	if err != nil {
-		return err
-	}
-	defer comproto.FinishOnePhaseCommit(&rErr, r, ctx)
-
-	if ptr == nil {
-		return fmt.Errorf("nil entity pointer given to Upsert")
-	}
-
-	if _, ok := r.Mapping.ID.Lookup(*ptr); !ok {
-		// if ID is not found, we assume it was never created before, so preparation is required.
-		if err := r.Mapping.OnPrepare(ctx, ptr); err != nil {
-			return err
-		}
-	}
-
-	// Prepare the entity's arguments
-	args, err := r.Mapping.ToArgs(*ptr)
-	if err != nil {
-		return err
-	}
-
-	cols, values := flsql.SplitArgs(args)
-	valuesClause := make([]string, len(cols))
-	for i := range cols {
-		valuesClause[i] = "?"
-	}
-
-	// Prepare update clause for ON DUPLICATE KEY UPDATE
-	updateClause := strings.Join(slicekit.Map(cols, func(c flsql.ColumnName) string { return fmt.Sprintf("`%s` = VALUES(`%s`)", c, c) }), ", ")
-
-	rcolumns, mapscan := r.Mapping.ToQuery(contextkit.WithoutValues(ctx))
-
-	// Construct the UPSERT query
-	query := fmt.Sprintf(
-		"INSERT INTO `%s` (`%s`) VALUES (%s) ON DUPLICATE KEY UPDATE %s RETURNING %s",
-		r.Mapping.TableName,
-		strings.Join(slicekit.Map(cols, func(c flsql.ColumnName) string { return string(c) }), "`, `"),
-		strings.Join(valuesClause, ", "),
-		updateClause,
-		flsql.JoinColumnName(rcolumns, "`%s`", ", "),
-	)
-
-	logger.Debug(ctx, "mysql Repository Upsert", logging.Fields{
-		"query": query,
-		"args":  values,
-	})
+// Restore implements crud.SoftDeleter by clearing the Mapping.DeletedAtColumn of the row.
+func (r Repository[ENT, ID]) Restore(ctx context.Context, id ID) error {
+	return r.repository().Restore(ctx, id)
+}
 
-	// Execute the query
-	if err := mapscan(ptr, r.Connection.QueryRowContext(ctx, query, values...)); err != nil {
-		return err
-	}
+// FindDeleted implements crud.SoftDeleter by listing the rows that has their Mapping.DeletedAtColumn set.
+func (r Repository[ENT, ID]) FindDeleted(ctx context.Context) iterkit.SeqE[ENT] {
+	return r.repository().FindDeleted(ctx)
+}
 
-	return nil
+func (r Repository[ENT, ID]) Save(ctx context.Context, ptr *ENT) error {
+	return r.repository().Save(ctx, ptr)
 }
 
 // Timestamp is a MySQL DTO Model for the timestamp type mapping.
@@ -53,6 +53,157 @@ func TestRepository(t *testing.T) {
 	)
 }
 
+func TestRepository_Querier(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+
+	subject := &mariadb.Repository[Entity, EntityID]{
+		Connection: cm,
+		Mapping:    EntityMapping(),
+	}
+
+	crudcontract.Querier[Entity, EntityID](subject, crudcontract.QuerierField[Entity]{
+		Name: "foo",
+		Set:  func(ptr *Entity, v string) { ptr.Foo = v },
+	}, crudcontract.Config[Entity, EntityID]{
+		MakeContext:    MakeContext,
+		OnePhaseCommit: cm,
+	}).Test(t)
+}
+
+func TestRepository_aggregation(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+
+	subject := &mariadb.Repository[Entity, EntityID]{
+		Connection: cm,
+		Mapping:    EntityMapping(),
+	}
+
+	field := crudcontract.QuerierField[Entity]{
+		Name: "foo",
+		Set:  func(ptr *Entity, v string) { ptr.Foo = v },
+	}
+	conf := crudcontract.Config[Entity, EntityID]{
+		MakeContext:    MakeContext,
+		OnePhaseCommit: cm,
+	}
+	testcase.RunSuite(t,
+		crudcontract.Counter[Entity, EntityID](subject, conf),
+		crudcontract.QueryCounter[Entity, EntityID](subject, field, conf),
+		crudcontract.GroupCounter[Entity, EntityID](subject, field, conf),
+		crudcontract.Exister[Entity, EntityID](subject, conf),
+	)
+}
+
+func TestRepository_PageFinder(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+
+	subject := &mariadb.Repository[Entity, EntityID]{
+		Connection: cm,
+		Mapping:    EntityMapping(),
+	}
+
+	crudcontract.PageFinder[Entity, EntityID](subject, crudcontract.Config[Entity, EntityID]{
+		MakeContext: MakeContext,
+	}).Test(t)
+}
+
+func TestRepository_VersionedUpdater(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateDoc(t, cm)
+
+	subject := &mariadb.Repository[testent.Doc, testent.DocID]{
+		Connection: cm,
+		Mapping:    DocMapping,
+	}
+
+	conf := crudcontract.Config[testent.Doc, testent.DocID]{
+		MakeContext:    MakeContext,
+		MakeEntity:     testent.MakeDoc,
+		OnePhaseCommit: cm,
+	}
+	testcase.RunSuite(t,
+		crudcontract.Updater[testent.Doc, testent.DocID](subject, conf),
+		crudcontract.VersionedUpdater[testent.Doc, testent.DocID](subject, conf),
+	)
+}
+
+func TestRepository_SoftDeleter(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+
+	_, err := cm.ExecContext(context.Background(), "ALTER TABLE `test_entities` ADD COLUMN `deleted_at` DATETIME(6) NULL")
+	assert.NoError(t, err)
+
+	mapping := EntityMapping()
+	mapping.DeletedAtColumn = "deleted_at"
+
+	subject := &mariadb.Repository[Entity, EntityID]{
+		Connection: cm,
+		Mapping:    mapping,
+	}
+
+	conf := crudcontract.Config[Entity, EntityID]{
+		MakeContext:    MakeContext,
+		OnePhaseCommit: cm,
+	}
+	testcase.RunSuite(t,
+		crudcontract.SoftDeleter[Entity, EntityID](subject, conf),
+		crudcontract.Finder[Entity, EntityID](subject, conf),
+		crudcontract.Updater[Entity, EntityID](subject, conf),
+		crudcontract.PageFinder[Entity, EntityID](subject, conf),
+	)
+}
+
+func TestRepository_bulk(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+
+	subject := &mariadb.Repository[Entity, EntityID]{
+		Connection: cm,
+		Mapping:    EntityMapping(),
+	}
+
+	conf := crudcontract.Config[Entity, EntityID]{
+		MakeContext:    MakeContext,
+		OnePhaseCommit: cm,
+	}
+	testcase.RunSuite(t,
+		crudcontract.ManyCreator[Entity, EntityID](subject, conf),
+		crudcontract.ManyUpdater[Entity, EntityID](subject, conf),
+		crudcontract.ByIDsDeleter[Entity, EntityID](subject, conf),
+	)
+}
+
+func TestRepository_compositeID(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateComposite(t, cm)
+
+	subject := &mariadb.Repository[testent.Composite, testent.CompositeID]{
+		Connection: cm,
+		Mapping:    CompositeMapping,
+	}
+
+	conf := crudcontract.Config[testent.Composite, testent.CompositeID]{
+		MakeContext:     MakeContext,
+		MakeEntity:      testent.MakeComposite,
+		SupportIDReuse:  true,
+		SupportRecreate: true,
+		OnePhaseCommit:  cm,
+	}
+	testcase.RunSuite(t,
+		crudcontract.Creator[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.Finder[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.ByIDsFinder[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.Updater[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.Deleter[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.ByIDsDeleter[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.Saver[testent.Composite, testent.CompositeID](subject, conf),
+	)
+}
+
 func TestCacheRepository(t *testing.T) {
 	logger.Testing(t)
 	ctx := context.Background()
@@ -23,6 +23,9 @@ type EventLog struct {
 	events []Event
 	eMutex sync.RWMutex
 
+	observers map[int]func(Event)
+	observerN int
+
 	// namespace allow multiple memory memory to manage transactions on the same context
 	namespace     string
 	namespaceInit sync.Once
@@ -116,10 +119,31 @@ func (el *EventLog) Append(ctx context.Context, event Event) error {
 	}
 	el.eMutex.Lock()
 	el.events = append(el.events, event)
+	for _, fn := range el.observers {
+		fn(event)
+	}
 	el.eMutex.Unlock()
 	return nil
 }
 
+// observe registers a callback that receives every event that is appended to the committed event log.
+// The returned events are the ones that were already committed at the time of the registration.
+func (el *EventLog) observe(fn func(Event)) (_ []Event, cancel func()) {
+	el.eMutex.Lock()
+	defer el.eMutex.Unlock()
+	if el.observers == nil {
+		el.observers = make(map[int]func(Event))
+	}
+	el.observerN++
+	id := el.observerN
+	el.observers[id] = fn
+	return append([]Event{}, el.events...), func() {
+		el.eMutex.Lock()
+		defer el.eMutex.Unlock()
+		delete(el.observers, id)
+	}
+}
+
 func RewriteEventLog[EventType any](el *EventLog, rewrite func(es []EventType) []EventType) {
 	el.Rewrite(func(es []Event) []Event {
 		var (
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"iter"
 	"sync"
+	"time"
 
 	"go.llib.dev/frameless/pkg/errorkit"
 	"go.llib.dev/frameless/pkg/iterkit"
@@ -12,6 +13,8 @@ import (
 	"go.llib.dev/frameless/pkg/zerokit"
 	"go.llib.dev/frameless/port/comproto"
 	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/pubsub"
+	"go.llib.dev/testcase/clock"
 
 	"go.llib.dev/frameless/pkg/reflectkit"
 	"go.llib.dev/frameless/port/crud/extid"
@@ -60,6 +63,8 @@ type EventLogRepositoryEvent[ENT, ID any] struct {
 	Name      string
 	Value     ENT
 	Trace     []Stack
+	// Time is when the event was made.
+	Time time.Time
 }
 
 func (e EventLogRepositoryEvent[ENT, ID]) GetTrace() []Stack      { return e.Trace }
@@ -351,6 +356,139 @@ func (s *EventLogRepository[ENT, ID]) Events(ctx context.Context) []EventLogRepo
 	return events
 }
 
+// SubscribeChanges implements crud.ChangeSubscriber.
+// The changes are derived from the repository's events once they are committed into the EventLog.
+func (s *EventLogRepository[ENT, ID]) SubscribeChanges(ctx context.Context) pubsub.Subscription[crud.Change[ENT, ID]] {
+	// the subscription is registered before returning the iterator,
+	// so changes made right after SubscribeChanges returns are not missed.
+	sub := &eventLogRepositoryChangeSubscription[ENT, ID]{
+		repo:   s,
+		signal: make(chan struct{}, 1),
+	}
+	committed, cancel := s.EventLog.observe(sub.observe)
+	context.AfterFunc(ctx, cancel)
+	var own []EventLogRepositoryEvent[ENT, ID]
+	for _, event := range committed {
+		if v, ok := event.(EventLogRepositoryEvent[ENT, ID]); ok && s.ownEvent(v) {
+			own = append(own, v)
+		}
+	}
+	sub.view = s.view(own)
+	return func(yield func(pubsub.Message[crud.Change[ENT, ID]], error) bool) {
+		defer cancel()
+		for {
+			change, err := sub.next(ctx)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			var acked bool
+			msg := pubsub.MakeMessage(ctx, change,
+				func(pubsub.Message[crud.Change[ENT, ID]]) error {
+					acked = true
+					return nil
+				},
+				func(pubsub.Message[crud.Change[ENT, ID]]) error {
+					return nil
+				})
+			if !yield(msg, nil) {
+				return
+			}
+			if acked {
+				sub.pending = sub.pending[1:]
+			}
+		}
+	}
+}
+
+type eventLogRepositoryChangeSubscription[ENT, ID any] struct {
+	repo *EventLogRepository[ENT, ID]
+	view EventLogRepositoryView[ENT, ID]
+
+	mutex  sync.Mutex
+	events []EventLogRepositoryEvent[ENT, ID]
+	signal chan struct{}
+
+	pending []crud.Change[ENT, ID]
+}
+
+func (sub *eventLogRepositoryChangeSubscription[ENT, ID]) observe(event Event) {
+	v, ok := event.(EventLogRepositoryEvent[ENT, ID])
+	if !ok || !sub.repo.ownEvent(v) {
+		return
+	}
+	sub.mutex.Lock()
+	sub.events = append(sub.events, v)
+	sub.mutex.Unlock()
+	select {
+	case sub.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (sub *eventLogRepositoryChangeSubscription[ENT, ID]) next(ctx context.Context) (crud.Change[ENT, ID], error) {
+	for len(sub.pending) == 0 {
+		if err := ctx.Err(); err != nil {
+			return crud.Change[ENT, ID]{}, err
+		}
+		if event, ok := sub.pop(); ok {
+			sub.pending = sub.changes(event)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-sub.signal:
+		}
+	}
+	return sub.pending[0], nil
+}
+
+func (sub *eventLogRepositoryChangeSubscription[ENT, ID]) pop() (EventLogRepositoryEvent[ENT, ID], bool) {
+	sub.mutex.Lock()
+	defer sub.mutex.Unlock()
+	if len(sub.events) == 0 {
+		return EventLogRepositoryEvent[ENT, ID]{}, false
+	}
+	event := sub.events[0]
+	sub.events = sub.events[1:]
+	return event, true
+}
+
+// changes translates an event into changes, while it keeps the view of the subscription up to date.
+// The view is required to tell the last known state of the deleted entities.
+func (sub *eventLogRepositoryChangeSubscription[ENT, ID]) changes(event EventLogRepositoryEvent[ENT, ID]) []crud.Change[ENT, ID] {
+	switch event.Name {
+	case CreateEvent, UpdateEvent:
+		id, ok := sub.repo.IDA.Lookup(event.Value)
+		if !ok {
+			panic(fmt.Errorf(`missing id in event value: %#v<%T>`, event.Value, event.Value))
+		}
+		var kind = crud.Updated
+		if event.Name == CreateEvent {
+			kind = crud.Created
+		}
+		sub.view.setByID(id, event.Value)
+		return []crud.Change[ENT, ID]{{Kind: kind, ID: id, Entity: event.Value}}
+	case DeleteByIDEvent:
+		id, ok := sub.repo.IDA.Lookup(event.Value)
+		if !ok {
+			panic(fmt.Errorf(`missing id in event value: %#v<%T>`, event.Value, event.Value))
+		}
+		ent, _ := sub.view.FindByID(id)
+		sub.view.delByID(id)
+		return []crud.Change[ENT, ID]{{Kind: crud.Deleted, ID: id, Entity: ent}}
+	case DeleteAllEvent:
+		var changes []crud.Change[ENT, ID]
+		for _, ent := range sub.view {
+			changes = append(changes, crud.Change[ENT, ID]{Kind: crud.Deleted, ID: sub.repo.IDA.Get(ent), Entity: ent})
+		}
+		sub.view = make(EventLogRepositoryView[ENT, ID])
+		return changes
+	default:
+		return nil
+	}
+}
+
 func (s *EventLogRepository[ENT, ID]) View(ctx context.Context) EventLogRepositoryView[ENT, ID] {
 	return s.view(s.Events(ctx))
 }
@@ -382,6 +520,9 @@ func (s *EventLogRepository[ENT, ID]) view(events []EventLogRepositoryEvent[ENT,
 }
 
 func (s *EventLogRepository[ENT, ID]) append(ctx context.Context, event EventLogRepositoryEvent[ENT, ID]) error {
+	if event.Time.IsZero() {
+		event.Time = clock.Now()
+	}
 	if err := s.EventLog.Append(ctx, event); err != nil {
 		return err
 	}
@@ -411,17 +552,71 @@ func (s *EventLogRepository[ENT, ID]) Compress() {
 		}
 		// append own events from view
 		v := s.view(own)
-		for _, ent := range v {
+		// the compressed events keep the time of the entities' last change
+		times := make(map[string]time.Time)
+		for _, e := range own {
+			if id, ok := s.IDA.Lookup(e.Value); ok {
+				times[v.key(id)] = e.Time
+			}
+		}
+		for key, ent := range v {
 			out = append(out, EventLogRepositoryEvent[ENT, ID]{
 				Namespace: s.GetNamespace(),
 				Name:      CreateEvent,
 				Value:     ent,
+				Time:      times[key],
 			})
 		}
 		return out
 	})
 }
 
+// AsOf implements crud.TemporalFinder, by replaying the events that were made until the given point in time.
+// Compressing the EventLog discards the earlier states of the entities, so only their last state is known afterwards.
+func (s *EventLogRepository[ENT, ID]) AsOf(ctx context.Context, at time.Time) (crud.Finder[ENT, ID], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.isDoneTx(ctx); err != nil {
+		return nil, err
+	}
+	var events []EventLogRepositoryEvent[ENT, ID]
+	for _, event := range s.Events(ctx) {
+		if event.Time.After(at) {
+			continue
+		}
+		events = append(events, event)
+	}
+	return eventLogRepositoryFinder[ENT, ID]{View: s.view(events)}, nil
+}
+
+// eventLogRepositoryFinder is a read-only crud.Finder over an EventLogRepositoryView.
+type eventLogRepositoryFinder[ENT, ID any] struct {
+	View EventLogRepositoryView[ENT, ID]
+}
+
+func (f eventLogRepositoryFinder[ENT, ID]) FindByID(ctx context.Context, id ID) (ENT, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return *new(ENT), false, err
+	}
+	ent, ok := f.View.FindByID(id)
+	return ent, ok, nil
+}
+
+func (f eventLogRepositoryFinder[ENT, ID]) FindAll(ctx context.Context) iter.Seq2[ENT, error] {
+	return iterkit.From(func(yield func(ENT) bool) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for _, ent := range f.View {
+			if !yield(ent) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
 type EventLogRepositoryView[ENT, ID any] map[ /* Namespace */ string] /* entity<T> */ ENT
 
 func (v EventLogRepositoryView[ENT, ID]) FindByID(id ID) (ENT, bool) {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"go.llib.dev/frameless/adapter/memory"
 	"go.llib.dev/frameless/internal/spechelper/resource"
@@ -16,9 +17,14 @@ import (
 	"go.llib.dev/frameless/port/crud/crudtest"
 	"go.llib.dev/frameless/port/crud/extid"
 	"go.llib.dev/frameless/port/meta/metacontract"
+	"go.llib.dev/frameless/port/pubsub"
+	"go.llib.dev/frameless/port/pubsub/pubsubtest"
+	"go.llib.dev/frameless/testing/testent"
 
 	"go.llib.dev/testcase"
 	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/clock"
+	"go.llib.dev/testcase/clock/timecop"
 	"go.llib.dev/testcase/random"
 )
 
@@ -27,6 +33,7 @@ var _ interface {
 	crud.Finder[TestEntity, string]
 	crud.Updater[TestEntity]
 	crud.Deleter[string]
+	crud.ChangeSubscriber[TestEntity, string]
 	comproto.OnePhaseCommitProtocol
 } = &memory.EventLogRepository[TestEntity, string]{}
 
@@ -237,6 +244,65 @@ func TestEventLogRepository_Options_CompressEventLog(t *testing.T) {
 	testcase.RunSuite(t, getRepositorySpecs[TestEntity, string](subject, makeTestEntity)...)
 }
 
+func TestEventLogRepository_ChangeSubscriber(t *testing.T) {
+	m := memory.NewEventLog()
+	subject := memory.NewEventLogRepository[testent.Foo, testent.FooID](m)
+	crudcontract.ChangeSubscriber[testent.Foo, testent.FooID](subject, crudcontract.Config[testent.Foo, testent.FooID]{
+		MakeEntity: testent.MakeFoo,
+	}).Test(t)
+
+	t.Run("DeleteAll is delivered as a Deleted change for each entity", func(t *testing.T) {
+		ctx := context.Background()
+		assert.NoError(t, subject.DeleteAll(ctx))
+		foo1, foo2 := testent.MakeFoo(t), testent.MakeFoo(t)
+		assert.NoError(t, subject.Create(ctx, &foo1))
+		assert.NoError(t, subject.Create(ctx, &foo2))
+
+		sub := pubsubtest.Subscribe[crud.Change[testent.Foo, testent.FooID]](t, changeSubscriber{subject}, ctx)
+		assert.NoError(t, subject.DeleteAll(ctx))
+
+		sub.Eventually(t, func(tb testing.TB, changes []crud.Change[testent.Foo, testent.FooID]) {
+			assert.ContainsExactly(tb, []crud.Change[testent.Foo, testent.FooID]{
+				{Kind: crud.Deleted, ID: foo1.ID, Entity: foo1},
+				{Kind: crud.Deleted, ID: foo2.ID, Entity: foo2},
+			}, changes)
+		})
+	})
+}
+
+func TestEventLogRepository_TemporalFinder(t *testing.T) {
+	m := memory.NewEventLog()
+	subject := memory.NewEventLogRepository[testent.Foo, testent.FooID](m)
+	crudcontract.TemporalFinder[testent.Foo, testent.FooID](subject, crudcontract.Config[testent.Foo, testent.FooID]{
+		MakeEntity: testent.MakeFoo,
+	}).Test(t)
+
+	t.Run("compressing keeps the time of the last change", func(t *testing.T) {
+		ctx := context.Background()
+		foo := testent.MakeFoo(t)
+		assert.NoError(t, subject.Create(ctx, &foo))
+		timecop.Travel(t, time.Minute)
+		at := clock.Now()
+		timecop.Travel(t, time.Minute)
+		subject.Compress()
+
+		finder, err := subject.AsOf(ctx, at)
+		assert.NoError(t, err)
+		got, found, err := finder.FindByID(ctx, foo.ID)
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, foo, got)
+	})
+}
+
+type changeSubscriber struct {
+	crud.ChangeSubscriber[testent.Foo, testent.FooID]
+}
+
+func (s changeSubscriber) Subscribe(ctx context.Context) pubsub.Subscription[crud.Change[testent.Foo, testent.FooID]] {
+	return s.SubscribeChanges(ctx)
+}
+
 func TestEventLogRepository_NewIDFunc(t *testing.T) {
 	t.Run(`when NewID is absent`, func(t *testing.T) {
 		repository := memory.NewEventLogRepository[TestEntity, string](memory.NewEventLog())
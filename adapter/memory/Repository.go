@@ -2,9 +2,11 @@ package memory
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"iter"
 	"reflect"
+	"slices"
 	"sync"
 
 	"go.llib.dev/frameless/port/comproto"
@@ -14,9 +16,11 @@ import (
 	"go.llib.dev/frameless/pkg/mapkit"
 	"go.llib.dev/frameless/pkg/zerokit"
 	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/crudquery"
 
 	"go.llib.dev/frameless/pkg/reflectkit"
 	"go.llib.dev/frameless/port/crud/extid"
+	"go.llib.dev/frameless/port/crud/extver"
 )
 
 func NewRepository[ENT, ID any](m *Memory) *Repository[ENT, ID] {
@@ -36,6 +40,11 @@ type Repository[ENT, ID any] struct {
 	Namespace string
 	// IDA [optional] is the ID Accessor that maps the ID to the ENT field.
 	IDA extid.Accessor[ENT, ID]
+	// VersionA [optional] is the version Accessor that maps the version to the ENT field.
+	// When ENT is versioned, Update uses optimistic concurrency control.
+	//
+	// default: the field tagged with `ext:"version"`, if any.
+	VersionA extver.Accessor[ENT]
 	// MakeID [optional] is an optional field if you need a specific way of generating new IDs during creation.
 	MakeID func(context.Context) (ID, error)
 	// ExpectID [optional] is a test simulator flag that allows you to enforce the user of this Repository,
@@ -43,6 +52,8 @@ type Repository[ENT, ID any] struct {
 	ExpectID bool
 
 	m sync.Mutex
+	// um ensures that the version check and the version increment of Update happen atomically.
+	um sync.Mutex
 
 	namespaceOnce sync.Once
 }
@@ -83,6 +94,15 @@ func (r *Repository[ENT, ID]) Create(ctx context.Context, ptr *ENT) error {
 	} else if found {
 		return errorkit.WithContext(crud.ErrAlreadyExists.F(`%T already exists with id: %v`, *new(ENT), id), ctx)
 	}
+	if _, found := r.memory().Get(ctx, r.tombstoneNamespace(), r.IDToMemoryKey(id)); found {
+		return errorkit.WithContext(crud.ErrAlreadyExists.F(`%T already exists with id as soft deleted: %v`, *new(ENT), id), ctx)
+	}
+
+	if r.VersionA.IsVersioned() && r.VersionA.Get(*ptr) == 0 {
+		if err := r.VersionA.Set(ptr, extver.Initial); err != nil {
+			return err
+		}
+	}
 
 	r.memory().Set(ctx, getNamespaceFor[ENT](typeNameRepository, &r.Namespace, &r.namespaceOnce), r.IDToMemoryKey(id), *ptr)
 	return nil
@@ -154,7 +174,12 @@ func (r *Repository[ENT, ID]) DeleteByID(ctx context.Context, id ID) error {
 	if err := r.isDoneTx(ctx); err != nil {
 		return err
 	}
-	if r.memory().Del(ctx, getNamespaceFor[ENT](typeNameRepository, &r.Namespace, &r.namespaceOnce), r.IDToMemoryKey(id)) {
+	var (
+		key  = r.IDToMemoryKey(id)
+		live = r.memory().Del(ctx, getNamespaceFor[ENT](typeNameRepository, &r.Namespace, &r.namespaceOnce), key)
+		dead = r.memory().Del(ctx, r.tombstoneNamespace(), key)
+	)
+	if live || dead {
 		return nil
 	}
 	return errNotFound(*new(ENT), id)
@@ -172,16 +197,101 @@ func (r *Repository[ENT, ID]) DeleteAll(ctx context.Context) error {
 		}
 		_ = r.memory().Del(ctx, getNamespaceFor[ENT](typeNameRepository, &r.Namespace, &r.namespaceOnce), r.IDToMemoryKey(id))
 	}
+	for key := range r.memory().allByKey(ctx, r.tombstoneNamespace()) {
+		_ = r.memory().Del(ctx, r.tombstoneNamespace(), key)
+	}
+	return nil
+}
+
+// SoftDeleteByID implements crud.SoftDeleter.
+// The soft deleted entity is kept as a tombstone, which is hidden from the finder methods.
+func (r *Repository[ENT, ID]) SoftDeleteByID(ctx context.Context, id ID) (rErr error) {
+	ctx, err := r.memory().BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer comproto.FinishOnePhaseCommit(&rErr, r.memory(), ctx)
+
+	ent, found, err := r.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errNotFound(*new(ENT), id)
+	}
+	key := r.IDToMemoryKey(id)
+	r.memory().Set(ctx, r.tombstoneNamespace(), key, ent)
+	r.memory().Del(ctx, getNamespaceFor[ENT](typeNameRepository, &r.Namespace, &r.namespaceOnce), key)
 	return nil
 }
 
-func (r *Repository[ENT, ID]) Update(ctx context.Context, ptr *ENT) error {
+// Restore implements crud.SoftDeleter by bringing back the tombstone of a soft deleted entity.
+func (r *Repository[ENT, ID]) Restore(ctx context.Context, id ID) (rErr error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ctx, err := r.memory().BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer comproto.FinishOnePhaseCommit(&rErr, r.memory(), ctx)
+
+	key := r.IDToMemoryKey(id)
+	ent, found := r.memory().Get(ctx, r.tombstoneNamespace(), key)
+	if !found {
+		return errNotFound(*new(ENT), id)
+	}
+	r.memory().Set(ctx, getNamespaceFor[ENT](typeNameRepository, &r.Namespace, &r.namespaceOnce), key, ent)
+	r.memory().Del(ctx, r.tombstoneNamespace(), key)
+	return nil
+}
+
+// FindDeleted implements crud.SoftDeleter by listing the tombstones.
+func (r *Repository[ENT, ID]) FindDeleted(ctx context.Context) iter.Seq2[ENT, error] {
+	return iterkit.From(func(yield func(ENT) bool) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.isDoneTx(ctx); err != nil {
+			return err
+		}
+		for v := range memoryAll[ENT](r.memory(), ctx, r.tombstoneNamespace()) {
+			if !yield(reflectkit.CloneT(v)) {
+				return nil
+			}
+		}
+		return ctx.Err()
+	})
+}
+
+func (r *Repository[ENT, ID]) tombstoneNamespace() string {
+	return getNamespaceFor[ENT](typeNameRepository, &r.Namespace, &r.namespaceOnce) + "#tombstone"
+}
+
+// Update updates a stored entity.
+// A versioned entity is only updated when its version matches the stored one,
+// and the version is checked again when the transaction of the update commits,
+// so concurrent transactions can't overwrite each other's update.
+func (r *Repository[ENT, ID]) Update(ctx context.Context, ptr *ENT) (rErr error) {
 	id, ok := r.IDA.Lookup(*ptr)
 	if !ok {
 		return fmt.Errorf(`entity doesn't have id field`)
 	}
 
-	_, found, err := r.FindByID(ctx, id)
+	if _, ok := r.memory().LookupTx(ctx); !ok {
+		// the update runs in its own transaction, to have its version checked at commit
+		tx, err := r.memory().BeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer comproto.FinishOnePhaseCommit(&rErr, r.memory(), tx)
+		ctx = tx
+	}
+
+	r.um.Lock()
+	defer r.um.Unlock()
+
+	stored, found, err := r.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -189,6 +299,28 @@ func (r *Repository[ENT, ID]) Update(ctx context.Context, ptr *ENT) error {
 		return errNotFound(*new(ENT), id)
 	}
 
+	if r.VersionA.IsVersioned() {
+		version := r.VersionA.Get(*ptr)
+		if storedVersion := r.VersionA.Get(stored); version != storedVersion {
+			return errorkit.WithContext(crud.ErrConcurrentModification.F(
+				`%T with id %v was modified concurrently (expected version %d, got %d)`,
+				*new(ENT), id, storedVersion, version), ctx)
+		}
+		if err := r.VersionA.Set(ptr, version+1); err != nil {
+			return err
+		}
+		if tx, ok := r.memory().LookupTx(ctx); ok {
+			tx.expect(getNamespaceFor[ENT](typeNameRepository, &r.Namespace, &r.namespaceOnce), r.IDToMemoryKey(id), func(v any) error {
+				if got := r.VersionA.Get(v.(ENT)); got != version {
+					return crud.ErrConcurrentModification.F(
+						`%T with id %v was modified concurrently (expected version %d, got %d)`,
+						*new(ENT), id, version, got)
+				}
+				return nil
+			})
+		}
+	}
+
 	r.memory().Set(ctx, getNamespaceFor[ENT](typeNameRepository, &r.Namespace, &r.namespaceOnce), r.IDToMemoryKey(id), *ptr)
 	return nil
 }
@@ -218,6 +350,59 @@ func (r *Repository[ENT, ID]) FindByIDs(ctx context.Context, ids ...ID) iter.Seq
 	})
 }
 
+// CreateMany implements crud.ManyCreator by creating the entities within a single transaction.
+func (r *Repository[ENT, ID]) CreateMany(ctx context.Context, ptrs ...*ENT) error {
+	return r.bulk(ctx, len(ptrs), func(ctx context.Context, i int) error {
+		return r.Create(ctx, ptrs[i])
+	})
+}
+
+// UpdateMany implements crud.ManyUpdater by updating the entities within a single transaction.
+func (r *Repository[ENT, ID]) UpdateMany(ctx context.Context, ptrs ...*ENT) error {
+	return r.bulk(ctx, len(ptrs), func(ctx context.Context, i int) error {
+		return r.Update(ctx, ptrs[i])
+	})
+}
+
+// DeleteByIDs implements crud.ByIDsDeleter by deleting the entities within a single transaction.
+func (r *Repository[ENT, ID]) DeleteByIDs(ctx context.Context, ids ...ID) error {
+	return r.bulk(ctx, len(ids), func(ctx context.Context, i int) error {
+		return r.DeleteByID(ctx, ids[i])
+	})
+}
+
+// bulk runs the operation for each item within a single transaction.
+// The failed items are collected into a crud.BulkError, and they don't prevent the commit of the rest.
+func (r *Repository[ENT, ID]) bulk(ctx context.Context, n int, op func(ctx context.Context, i int) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := r.isDoneTx(ctx); err != nil {
+		return err
+	}
+	var bulkErr crud.BulkError
+	err := func() (rErr error) {
+		ctx, err := r.memory().BeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer comproto.FinishOnePhaseCommit(&rErr, r.memory(), ctx)
+		for i := 0; i < n; i++ {
+			if err := op(ctx, i); err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return ctxErr
+				}
+				bulkErr.Add(i, err)
+			}
+		}
+		return nil
+	}()
+	if err != nil {
+		return err
+	}
+	return bulkErr.ErrOrNil()
+}
+
 func (r *Repository[ENT, ID]) QueryOne(ctx context.Context, filter func(v ENT) bool) (ENT, bool, error) {
 	itr := r.FindAll(ctx)
 	var zero ENT
@@ -238,6 +423,113 @@ func (r *Repository[ENT, ID]) QueryMany(ctx context.Context, filter func(v ENT)
 	}), nil
 }
 
+// Query implements crud.Querier by evaluating the query in memory.
+// Query fields are resolved against the ENT struct fields, see crudquery.MatchField.
+func (r *Repository[ENT, ID]) Query(ctx context.Context, q crudquery.Query) iter.Seq2[ENT, error] {
+	return crudquery.Apply(q, r.FindAll(ctx))
+}
+
+func (r *Repository[ENT, ID]) Count(ctx context.Context) (int, error) {
+	return r.CountQuery(ctx, crudquery.Query{})
+}
+
+// CountQuery implements crud.QueryCounter by evaluating the query's Where expression in memory.
+func (r *Repository[ENT, ID]) CountQuery(ctx context.Context, q crudquery.Query) (int, error) {
+	var n int
+	for _, err := range crudquery.Apply(crudquery.Query{Where: q.Where}, r.FindAll(ctx)) {
+		if err != nil {
+			return 0, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// CountGroups implements crud.GroupCounter.
+// The field is resolved against the ENT struct fields, and its values must be comparable.
+func (r *Repository[ENT, ID]) CountGroups(ctx context.Context, field string, q crudquery.Query) ([]crud.GroupCount, error) {
+	var (
+		groups []crud.GroupCount
+		index  = make(map[any]int)
+	)
+	for v, err := range crudquery.Apply(crudquery.Query{Where: q.Where}, r.FindAll(ctx)) {
+		if err != nil {
+			return nil, err
+		}
+		value, err := crudquery.FieldValue(v, field)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil && !reflect.TypeOf(value).Comparable() {
+			return nil, crudquery.ErrInvalidQuery.F("%T values can't be grouped: %q", value, field)
+		}
+		i, ok := index[value]
+		if !ok {
+			i = len(groups)
+			index[value] = i
+			groups = append(groups, crud.GroupCount{Value: value})
+		}
+		groups[i].Count++
+	}
+	if groups == nil { // an unknown field should be reported even when nothing matches
+		if _, err := crudquery.FieldValue(*new(ENT), field); err != nil {
+			return nil, err
+		}
+	}
+	return groups, nil
+}
+
+func (r *Repository[ENT, ID]) ExistsByID(ctx context.Context, id ID) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if err := r.isDoneTx(ctx); err != nil {
+		return false, err
+	}
+	_, ok := r.memory().Get(ctx, getNamespaceFor[ENT](typeNameRepository, &r.Namespace, &r.namespaceOnce), r.IDToMemoryKey(id))
+	return ok, nil
+}
+
+// FindPage implements crud.PageFinder with keyset pagination.
+// Entities are paged in the order of their memory keys, and the cursor holds the key of the last entity on the page.
+func (r *Repository[ENT, ID]) FindPage(ctx context.Context, cursor crud.Cursor, size int) (crud.Page[ENT], error) {
+	if err := ctx.Err(); err != nil {
+		return crud.Page[ENT]{}, err
+	}
+	if err := r.isDoneTx(ctx); err != nil {
+		return crud.Page[ENT]{}, err
+	}
+	if size < 1 {
+		return crud.Page[ENT]{}, fmt.Errorf("invalid page size: %d", size)
+	}
+	var after string
+	if cursor != "" {
+		key, err := base64.RawURLEncoding.DecodeString(string(cursor))
+		if err != nil || len(key) == 0 {
+			return crud.Page[ENT]{}, crud.ErrInvalidCursor.F("malformed cursor: %q", cursor)
+		}
+		after = string(key)
+	}
+	var (
+		vs   = r.memory().allByKey(ctx, getNamespaceFor[ENT](typeNameRepository, &r.Namespace, &r.namespaceOnce))
+		keys = mapkit.Keys(vs, slices.Sort)
+		page crud.Page[ENT]
+		last string
+	)
+	for _, key := range keys {
+		if key <= after {
+			continue
+		}
+		if len(page.Entries) == size {
+			page.Next = crud.Cursor(base64.RawURLEncoding.EncodeToString([]byte(last)))
+			break
+		}
+		page.Entries = append(page.Entries, reflectkit.CloneT(vs[key].(ENT)))
+		last = key
+	}
+	return page, nil
+}
+
 func (r *Repository[ENT, ID]) mkID(ctx context.Context) (ID, error) {
 	if r.MakeID != nil {
 		return r.MakeID(ctx)
@@ -289,6 +581,9 @@ func NewMemory() *Memory {
 type Memory struct {
 	m      sync.Mutex
 	tables map[string]MemoryNamespace
+	// cm serialises the commits into the Memory,
+	// so the expectations of a transaction are checked together with applying its changes.
+	cm sync.Mutex
 
 	ns struct {
 		init  sync.Once
@@ -389,6 +684,13 @@ func (m *Memory) All(T any, ctx context.Context, namespace string) (sliceOfT int
 	return m.toTSlice(T, m.all(namespace))
 }
 
+func (m *Memory) allByKey(ctx context.Context, namespace string) map[string]interface{} {
+	if tx, ok := m.LookupTx(ctx); ok && !tx.done {
+		return tx.all(namespace)
+	}
+	return m.all(namespace)
+}
+
 func (m *Memory) toTSlice(T any, vs map[string]interface{}) interface{} {
 	rSlice := reflect.MakeSlice(reflect.SliceOf(reflectkit.ToType(T)), 0, len(vs))
 	for _, v := range vs {
@@ -485,6 +787,8 @@ type MemoryTx struct {
 	done    bool
 	super   memoryActions
 	changes map[string]memoryTxChanges
+	// expectations are checked against the state of the super at commit.
+	expectations []memoryTxExpectation
 
 	cancelContext func()
 }
@@ -494,6 +798,22 @@ type memoryTxChanges struct {
 	Deleted map[string]struct{}
 }
 
+// memoryTxExpectation is a precondition of a transaction's commit about a value that the transaction read.
+type memoryTxExpectation struct {
+	Namespace string
+	Key       string
+	// Check receives the value that is stored outside the transaction.
+	Check func(stored any) error
+}
+
+// expect registers an expectation, that the commit checks against the value stored outside the transaction.
+// A value that is absent outside the transaction, for example because it was created within it, is not checked.
+func (tx *MemoryTx) expect(namespace, key string, check func(stored any) error) {
+	tx.m.Lock()
+	defer tx.m.Unlock()
+	tx.expectations = append(tx.expectations, memoryTxExpectation{Namespace: namespace, Key: key, Check: check})
+}
+
 func (tx *MemoryTx) all(namespace string) map[string]interface{} {
 	tx.m.RLock()
 	defer tx.m.RUnlock()
@@ -548,10 +868,21 @@ func (tx *MemoryTx) commit() error {
 	if tx.done {
 		return errTxDone
 	}
+	if m, ok := tx.super.(*Memory); ok {
+		m.cm.Lock()
+		defer m.cm.Unlock()
+	}
+	if err := tx.checkExpectations(); err != nil {
+		_ = tx.rollback()
+		return err
+	}
 	tx.m.Lock()
 	defer tx.m.Unlock()
 	tx.done = true
 	tx.cancelContext()
+	if super, ok := tx.super.(*MemoryTx); ok {
+		super.inheritExpectations(tx.expectations)
+	}
 	for namespace, values := range tx.changes {
 		for key := range values.Deleted {
 			tx.super.del(namespace, key)
@@ -563,6 +894,39 @@ func (tx *MemoryTx) commit() error {
 	return nil
 }
 
+func (tx *MemoryTx) checkExpectations() error {
+	tx.m.RLock()
+	defer tx.m.RUnlock()
+	for _, exp := range tx.expectations {
+		stored, ok := tx.super.lookup(exp.Namespace, exp.Key)
+		if !ok {
+			continue
+		}
+		if err := exp.Check(stored); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inheritExpectations takes over the expectations of a committed sub transaction,
+// so they are checked again when the transaction itself commits.
+// A value that the transaction changed on its own is left out, as its own changes are based on it.
+func (tx *MemoryTx) inheritExpectations(exps []memoryTxExpectation) {
+	tx.m.Lock()
+	defer tx.m.Unlock()
+	for _, exp := range exps {
+		changes := tx.getChanges(exp.Namespace)
+		if _, ok := changes.Values[exp.Key]; ok {
+			continue
+		}
+		if _, ok := changes.Deleted[exp.Key]; ok {
+			continue
+		}
+		tx.expectations = append(tx.expectations, exp)
+	}
+}
+
 func (tx *MemoryTx) rollback() error {
 	if tx.done {
 		return errTxDone
@@ -98,6 +98,45 @@ func TestRepository_query(t *testing.T) {
 	assert.ContainsExactly(t, vs, []testent.Foo{ent1, ent3})
 }
 
+func TestRepository_Querier(t *testing.T) {
+	m := memory.NewMemory()
+	r := memory.NewRepository[testent.Foo, testent.FooID](m)
+	crudcontract.Querier[testent.Foo, testent.FooID](r, crudcontract.QuerierField[testent.Foo]{
+		Name: "foo",
+		Set:  func(ptr *testent.Foo, v string) { ptr.Foo = v },
+	}, crudcontract.Config[testent.Foo, testent.FooID]{
+		MakeEntity:     testent.MakeFoo,
+		OnePhaseCommit: m,
+	}).Test(t)
+}
+
+func TestRepository_aggregation(t *testing.T) {
+	m := memory.NewMemory()
+	r := memory.NewRepository[testent.Foo, testent.FooID](m)
+	field := crudcontract.QuerierField[testent.Foo]{
+		Name: "foo",
+		Set:  func(ptr *testent.Foo, v string) { ptr.Foo = v },
+	}
+	conf := crudcontract.Config[testent.Foo, testent.FooID]{
+		MakeEntity:     testent.MakeFoo,
+		OnePhaseCommit: m,
+	}
+	testcase.RunSuite(t,
+		crudcontract.Counter[testent.Foo, testent.FooID](r, conf),
+		crudcontract.QueryCounter[testent.Foo, testent.FooID](r, field, conf),
+		crudcontract.GroupCounter[testent.Foo, testent.FooID](r, field, conf),
+		crudcontract.Exister[testent.Foo, testent.FooID](r, conf),
+	)
+}
+
+func TestRepository_PageFinder(t *testing.T) {
+	m := memory.NewMemory()
+	r := memory.NewRepository[testent.Foo, testent.FooID](m)
+	crudcontract.PageFinder[testent.Foo, testent.FooID](r, crudcontract.Config[testent.Foo, testent.FooID]{
+		MakeEntity: testent.MakeFoo,
+	}).Test(t)
+}
+
 func TestRepository_Batcher_crudBatch(t *testing.T) {
 	m := memory.NewMemory()
 	r := memory.NewRepository[testent.Foo, testent.FooID](m)
@@ -181,3 +220,55 @@ func TestRepository_concurrentUse_lazyInitStaysConsistent(t *testing.T) {
 		assert.Equal(t, *ent, got)
 	}
 }
+
+func TestRepository_VersionedUpdater(t *testing.T) {
+	m := memory.NewMemory()
+	r := memory.NewRepository[testent.Doc, testent.DocID](m)
+	conf := crudcontract.Config[testent.Doc, testent.DocID]{
+		MakeEntity:     testent.MakeDoc,
+		OnePhaseCommit: m,
+	}
+	testcase.RunSuite(t,
+		crudcontract.Updater[testent.Doc, testent.DocID](r, conf),
+		crudcontract.VersionedUpdater[testent.Doc, testent.DocID](r, conf),
+	)
+}
+
+func TestRepository_SoftDeleter(t *testing.T) {
+	m := memory.NewMemory()
+	r := memory.NewRepository[testent.Foo, testent.FooID](m)
+	crudcontract.SoftDeleter[testent.Foo, testent.FooID](r, crudcontract.Config[testent.Foo, testent.FooID]{
+		MakeEntity: testent.MakeFoo,
+	}).Test(t)
+}
+
+func TestRepository_bulk(t *testing.T) {
+	m := memory.NewMemory()
+	r := memory.NewRepository[testent.Foo, testent.FooID](m)
+	conf := crudcontract.Config[testent.Foo, testent.FooID]{
+		MakeEntity:     testent.MakeFoo,
+		OnePhaseCommit: m,
+	}
+	testcase.RunSuite(t,
+		crudcontract.ManyCreator[testent.Foo, testent.FooID](r, conf),
+		crudcontract.ManyUpdater[testent.Foo, testent.FooID](r, conf),
+		crudcontract.ByIDsDeleter[testent.Foo, testent.FooID](r, conf),
+	)
+}
+
+func TestRepository_compositeID(t *testing.T) {
+	m := memory.NewMemory()
+	subject := memory.NewRepository[testent.Composite, testent.CompositeID](m)
+
+	conf := crudcontract.Config[testent.Composite, testent.CompositeID]{
+		MakeEntity:     testent.MakeComposite,
+		OnePhaseCommit: m,
+	}
+	testcase.RunSuite(t,
+		crudcontract.Creator[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.Finder[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.Updater[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.Deleter[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.ByIDsFinder[testent.Composite, testent.CompositeID](subject, conf),
+	)
+}
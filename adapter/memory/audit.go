@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+
+	"go.llib.dev/frameless/pkg/audit"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/reflectkit"
+)
+
+// AuditEventRepository is an audit.EventRepository that stores its events in a Memory.
+// Recording events through it takes part in the transactions of the Memory.
+type AuditEventRepository[ID any] struct {
+	// Name separates the events of different audit trails in the Memory.
+	Name string
+	Repository[audit.Event[ID], audit.EventID]
+}
+
+// NewAuditEventRepository returns an audit.EventRepository that stores the events of the named audit trail in the given Memory.
+func NewAuditEventRepository[ID any](m *Memory, name string) *AuditEventRepository[ID] {
+	return &AuditEventRepository[ID]{
+		Name: name,
+		Repository: Repository[audit.Event[ID], audit.EventID]{
+			Memory:    m,
+			Namespace: fmt.Sprintf("audit.EventRepository[%T]/%s", *new(ID), name),
+		},
+	}
+}
+
+func (r *AuditEventRepository[ID]) Create(ctx context.Context, ptr *audit.Event[ID]) error {
+	if r.Name == "" {
+		return fmt.Errorf("missing audit name")
+	}
+	return r.Repository.Create(ctx, ptr)
+}
+
+func (r *AuditEventRepository[ID]) FindByEntityID(ctx context.Context, entityID ID) iter.Seq2[audit.Event[ID], error] {
+	events, err := iterkit.CollectE(iterkit.OnSeqEValue(r.Repository.FindAll(ctx), func(i iter.Seq[audit.Event[ID]]) iter.Seq[audit.Event[ID]] {
+		return iterkit.Filter(i, func(e audit.Event[ID]) bool {
+			return reflectkit.Equal(e.EntityID, entityID)
+		})
+	}))
+	if err != nil {
+		return iterkit.Error[audit.Event[ID]](err)
+	}
+	slices.SortStableFunc(events, func(a, b audit.Event[ID]) int {
+		if c := a.Timestamp.Compare(b.Timestamp); c != 0 {
+			return c
+		}
+		if a.ID < b.ID {
+			return -1
+		}
+		if a.ID > b.ID {
+			return 1
+		}
+		return 0
+	})
+	return iterkit.FromSliceE(events)
+}
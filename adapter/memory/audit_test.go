@@ -0,0 +1,48 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"go.llib.dev/frameless/adapter/memory"
+	"go.llib.dev/frameless/pkg/audit"
+	"go.llib.dev/frameless/pkg/audit/auditcontract"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/testing/testent"
+	"go.llib.dev/testcase/assert"
+)
+
+var _ audit.EventRepository[testent.FooID] = memory.NewAuditEventRepository[testent.FooID](nil, "foos")
+
+func TestNewAuditEventRepository(t *testing.T) {
+	m := memory.NewMemory()
+	auditcontract.EventRepository[testent.FooID](memory.NewAuditEventRepository[testent.FooID](m, "foos"), auditcontract.Config[testent.FooID]{
+		OnePhaseCommit: m,
+	}).Test(t)
+}
+
+func TestAuditEventRepository_Name(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = memory.NewMemory()
+	)
+	t.Run("the name is required", func(t *testing.T) {
+		repo := memory.NewAuditEventRepository[testent.FooID](m, "")
+		assert.Error(t, repo.Create(ctx, &audit.Event[testent.FooID]{EntityID: "42"}))
+	})
+	t.Run("the audit trails of different names are separated", func(t *testing.T) {
+		foos := memory.NewAuditEventRepository[testent.FooID](m, "foos")
+		bars := memory.NewAuditEventRepository[testent.FooID](m, "bars")
+
+		event := audit.Event[testent.FooID]{EntityID: "42"}
+		assert.NoError(t, foos.Create(ctx, &event))
+
+		got, err := iterkit.CollectE(foos.FindByEntityID(ctx, "42"))
+		assert.NoError(t, err)
+		assert.Equal(t, []audit.Event[testent.FooID]{event}, got)
+
+		got, err = iterkit.CollectE(bars.FindByEntityID(ctx, "42"))
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
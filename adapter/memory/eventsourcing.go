@@ -0,0 +1,235 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+	"sync"
+
+	"go.llib.dev/frameless/pkg/eventsourcing"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/reflectkit"
+	"go.llib.dev/frameless/port/comproto"
+	"go.llib.dev/testcase/clock"
+)
+
+// EventStore is an eventsourcing.EventStore, which appends its records into an EventLog.
+// It also keeps the checkpoints of the projections, as an eventsourcing.CheckpointStore.
+//
+// The optimistic concurrency check sees the committed records and the records of the context's own transaction.
+// While a transaction has uncommitted appends to a stream, the appends of other transactions to the same stream
+// fail with a concurrency conflict, even if the transaction is rolled back later.
+type EventStore[E any] struct {
+	EventLog  *EventLog
+	Namespace string
+
+	mutex sync.Mutex
+	// pending holds the outermost transactions that appended to a stream, but haven't finished yet.
+	pending map[eventsourcing.StreamID][]*EventLogTx
+}
+
+// NewEventStore returns an eventsourcing.EventStore that appends its records into the given EventLog.
+func NewEventStore[E any](el *EventLog) *EventStore[E] {
+	return &EventStore[E]{
+		EventLog:  el,
+		Namespace: fmt.Sprintf("eventsourcing.EventStore[%T]", *new(E)),
+	}
+}
+
+var (
+	_ eventsourcing.EventStore[any]    = (*EventStore[any])(nil)
+	_ eventsourcing.CheckpointStore    = (*EventStore[any])(nil)
+	_ comproto.OnePhaseCommitProtocol  = (*EventStore[any])(nil)
+	_ eventsourcing.SnapshotStore[any] = (*SnapshotStore[any])(nil)
+)
+
+type eventStoreRecordEvent[E any] struct {
+	Namespace string
+	Record    eventsourcing.Record[E]
+}
+
+type eventStoreCheckpointEvent struct {
+	Namespace  string
+	Projection string
+	Position   eventsourcing.Position
+}
+
+func (s *EventStore[E]) Append(ctx context.Context, streamID eventsourcing.StreamID, expected eventsourcing.Version, events ...E) (rErr error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var current eventsourcing.Version
+	for _, rec := range s.records(ctx) {
+		if rec.StreamID == streamID {
+			current = rec.Version
+		}
+	}
+	if current != expected || s.hasPendingAppend(ctx, streamID) {
+		return eventsourcing.ErrConcurrencyConflict
+	}
+	tx, err := s.EventLog.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer comproto.FinishOnePhaseCommit(&rErr, s.EventLog, tx)
+	now := clock.Now().UTC()
+	for i, event := range events {
+		rec := eventsourcing.Record[E]{
+			StreamID:   streamID,
+			Version:    expected + eventsourcing.Version(i+1),
+			Event:      event,
+			RecordedAt: now,
+		}
+		if err := s.EventLog.Append(tx, eventStoreRecordEvent[E]{Namespace: s.Namespace, Record: rec}); err != nil {
+			return err
+		}
+	}
+	if chain := s.txChain(ctx); 0 < len(chain) {
+		if s.pending == nil {
+			s.pending = make(map[eventsourcing.StreamID][]*EventLogTx)
+		}
+		s.pending[streamID] = append(s.pending[streamID], chain[len(chain)-1])
+	}
+	return nil
+}
+
+// hasPendingAppend tells whether another, still running transaction has uncommitted appends to the stream.
+// The appends of the context's own transaction are already part of its records.
+func (s *EventStore[E]) hasPendingAppend(ctx context.Context, streamID eventsourcing.StreamID) bool {
+	var running []*EventLogTx
+	for _, tx := range s.pending[streamID] {
+		if !tx.isDone() {
+			running = append(running, tx)
+		}
+	}
+	if len(running) == 0 {
+		delete(s.pending, streamID)
+		return false
+	}
+	s.pending[streamID] = running
+	chain := s.txChain(ctx)
+	for _, tx := range running {
+		if !slices.Contains(chain, tx) {
+			return true
+		}
+	}
+	return false
+}
+
+// txChain returns the transaction of the context along with its parent transactions, from the innermost to the outermost.
+func (s *EventStore[E]) txChain(ctx context.Context) []*EventLogTx {
+	var chain []*EventLogTx
+	tx, ok := s.EventLog.LookupTx(ctx)
+	for ok {
+		chain = append(chain, tx)
+		tx, ok = tx.parent.(*EventLogTx)
+	}
+	return chain
+}
+
+func (s *EventStore[E]) Load(ctx context.Context, streamID eventsourcing.StreamID, after eventsourcing.Version) iter.Seq2[eventsourcing.Record[E], error] {
+	if err := ctx.Err(); err != nil {
+		return iterkit.Error[eventsourcing.Record[E]](err)
+	}
+	var out []eventsourcing.Record[E]
+	for _, rec := range s.records(ctx) {
+		if rec.StreamID == streamID && after < rec.Version {
+			out = append(out, rec)
+		}
+	}
+	return iterkit.FromSliceE(out)
+}
+
+func (s *EventStore[E]) LoadAll(ctx context.Context, after eventsourcing.Position) iter.Seq2[eventsourcing.Record[E], error] {
+	if err := ctx.Err(); err != nil {
+		return iterkit.Error[eventsourcing.Record[E]](err)
+	}
+	var out []eventsourcing.Record[E]
+	for _, rec := range s.records(ctx) {
+		if after < rec.Position {
+			out = append(out, rec)
+		}
+	}
+	return iterkit.FromSliceE(out)
+}
+
+// records returns the records of the EventStore, in the order of the EventLog.
+// The position of a record is its place in this order.
+func (s *EventStore[E]) records(ctx context.Context) []eventsourcing.Record[E] {
+	var recs []eventsourcing.Record[E]
+	for _, event := range s.EventLog.EventsInContext(ctx) {
+		v, ok := event.(eventStoreRecordEvent[E])
+		if !ok || v.Namespace != s.Namespace {
+			continue
+		}
+		rec := v.Record
+		rec.Position = eventsourcing.Position(len(recs) + 1)
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func (s *EventStore[E]) LoadCheckpoint(ctx context.Context, projection string) (eventsourcing.Position, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	var position eventsourcing.Position
+	for _, event := range s.EventLog.EventsInContext(ctx) {
+		v, ok := event.(eventStoreCheckpointEvent)
+		if ok && v.Namespace == s.Namespace && v.Projection == projection {
+			position = v.Position
+		}
+	}
+	return position, nil
+}
+
+func (s *EventStore[E]) SaveCheckpoint(ctx context.Context, projection string, position eventsourcing.Position) error {
+	return s.EventLog.Append(ctx, eventStoreCheckpointEvent{
+		Namespace:  s.Namespace,
+		Projection: projection,
+		Position:   position,
+	})
+}
+
+func (s *EventStore[E]) BeginTx(ctx context.Context) (context.Context, error) {
+	return s.EventLog.BeginTx(ctx)
+}
+
+func (s *EventStore[E]) CommitTx(ctx context.Context) error {
+	return s.EventLog.CommitTx(ctx)
+}
+
+func (s *EventStore[E]) RollbackTx(ctx context.Context) error {
+	return s.EventLog.RollbackTx(ctx)
+}
+
+// SnapshotStore is an eventsourcing.SnapshotStore, which keeps the snapshots in an EventLog.
+// The states are deep copied, so an aggregate that is changed after its snapshot is taken, doesn't affect the snapshot.
+type SnapshotStore[S any] struct {
+	Repository *EventLogRepository[eventsourcing.Snapshot[S], eventsourcing.StreamID]
+}
+
+// NewSnapshotStore returns an eventsourcing.SnapshotStore that keeps its snapshots in the given EventLog.
+func NewSnapshotStore[S any](el *EventLog) *SnapshotStore[S] {
+	return &SnapshotStore[S]{
+		Repository: NewEventLogRepositoryWithNamespace[eventsourcing.Snapshot[S], eventsourcing.StreamID](el,
+			fmt.Sprintf("eventsourcing.SnapshotStore[%T]", *new(S))),
+	}
+}
+
+func (s *SnapshotStore[S]) SaveSnapshot(ctx context.Context, snapshot eventsourcing.Snapshot[S]) error {
+	snapshot.State = reflectkit.CloneT(snapshot.State)
+	return s.Repository.Save(ctx, &snapshot)
+}
+
+func (s *SnapshotStore[S]) LoadSnapshot(ctx context.Context, streamID eventsourcing.StreamID) (eventsourcing.Snapshot[S], bool, error) {
+	snapshot, found, err := s.Repository.FindByID(ctx, streamID)
+	if err != nil || !found {
+		return snapshot, found, err
+	}
+	snapshot.State = reflectkit.CloneT(snapshot.State)
+	return snapshot, true, nil
+}
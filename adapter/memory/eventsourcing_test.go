@@ -0,0 +1,22 @@
+package memory_test
+
+import (
+	"testing"
+
+	"go.llib.dev/frameless/adapter/memory"
+	"go.llib.dev/frameless/pkg/eventsourcing/eventsourcingcontract"
+	"go.llib.dev/frameless/testing/testent"
+)
+
+func TestEventStore(t *testing.T) {
+	el := memory.NewEventLog()
+	subject := memory.NewEventStore[testent.Foo](el)
+	eventsourcingcontract.EventStore[testent.Foo](subject, eventsourcingcontract.Config[testent.Foo]{
+		OnePhaseCommit: el,
+	}).Test(t)
+}
+
+func TestSnapshotStore(t *testing.T) {
+	el := memory.NewEventLog()
+	eventsourcingcontract.SnapshotStore[*testent.Foo](memory.NewSnapshotStore[*testent.Foo](el)).Test(t)
+}
@@ -16,29 +16,44 @@ func errNotFound(T, id any) error {
 }
 
 func MakeID[ID any](context.Context) (ID, error) {
-	returnError := func() (ID, error) {
+	id, ok := makeID(reflectkit.TypeOf[ID]())
+	if !ok {
 		var id ID
 		const format = "%T id type is not supported by default, please provide id generator in the .NewID field"
 		return id, fmt.Errorf(format, id)
 	}
+	return id.Interface().(ID), nil
+}
 
-	switch reflect.TypeOf(*new(ID)).Kind() {
+// makeID makes a new unique ID value.
+// A composite ID struct gets a unique value in each of its fields.
+func makeID(typ reflect.Type) (reflect.Value, bool) {
+	switch typ.Kind() {
 	case reflect.String:
-		id, ok := reflectkit.Cast[ID](genStringUID())
-		if !ok {
-			return returnError()
-		}
-		return id, nil
+		return reflect.ValueOf(genStringUID()).Convert(typ), true
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		id, ok := reflectkit.Cast[ID](genIntUID())
-		if !ok {
-			return returnError()
+		return reflect.ValueOf(genIntUID()).Convert(typ), true
+
+	case reflect.Struct:
+		if typ.NumField() == 0 {
+			return reflect.Value{}, false
+		}
+		id := reflect.New(typ).Elem()
+		for i := range typ.NumField() {
+			if !typ.Field(i).IsExported() {
+				return reflect.Value{}, false
+			}
+			v, ok := makeID(typ.Field(i).Type)
+			if !ok {
+				return reflect.Value{}, false
+			}
+			id.Field(i).Set(v)
 		}
-		return id, nil
+		return id, true
 
 	default:
-		return returnError()
+		return reflect.Value{}, false
 
 	}
 }
@@ -0,0 +1,16 @@
+package memory
+
+import (
+	"fmt"
+
+	"go.llib.dev/frameless/pkg/outbox"
+)
+
+// NewOutboxRepository returns an outbox.Repository that stores its records in the given Memory.
+// Publishing into an outbox.Outbox backed by it, takes part in the transactions of the Memory.
+func NewOutboxRepository[Data any](m *Memory) *Repository[outbox.Record[Data], outbox.RecordID] {
+	return &Repository[outbox.Record[Data], outbox.RecordID]{
+		Memory:    m,
+		Namespace: fmt.Sprintf("outbox.Repository[%T]", *new(Data)),
+	}
+}
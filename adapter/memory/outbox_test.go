@@ -0,0 +1,20 @@
+package memory_test
+
+import (
+	"testing"
+
+	"go.llib.dev/frameless/adapter/memory"
+	"go.llib.dev/frameless/pkg/outbox"
+	"go.llib.dev/frameless/pkg/outbox/outboxcontract"
+	"go.llib.dev/frameless/testing/testent"
+)
+
+var _ outbox.Repository[testent.Foo] = memory.NewOutboxRepository[testent.Foo](nil)
+
+func TestNewOutboxRepository(t *testing.T) {
+	m := memory.NewMemory()
+	outboxcontract.Repository[testent.Foo](memory.NewOutboxRepository[testent.Foo](m), outboxcontract.Config[testent.Foo]{
+		MakeData:       testent.MakeFoo,
+		OnePhaseCommit: m,
+	}).Test(t)
+}
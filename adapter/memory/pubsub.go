@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"iter"
+	"maps"
 	"math"
 	"runtime"
 	"sort"
@@ -32,12 +33,29 @@ type Queue[Data any] struct {
 	// SortLessFunc will define how to sort data, when we look for what message to handle next.
 	// if not supplied FIFO is the default ordering.
 	SortLessFunc func(i Data, j Data) bool
+	// MaxDeliveryAttempts [optional] is how many times a message is delivered, before it is considered a poison message.
+	// A poison message is NACK-ed on its last delivery attempt, and instead of being redelivered,
+	// it is moved to the DeadLetterQueue.
+	//
+	// default: unlimited
+	MaxDeliveryAttempts int
+	// DeadLetterQueue [optional] is the Publisher that receives the poison messages.
+	// Without a DeadLetterQueue, the poison messages are dropped.
+	DeadLetterQueue pubsub.Publisher[Data]
+	// VisibilityTimeout [optional] is how long a received message is leased to its subscriber.
+	// A message that is neither ACK-ed nor NACK-ed within its lease is delivered again, as if its subscriber had crashed.
+	// The lease can be extended through the pubsub.LeaseExtender of the message.
+	//
+	// default: unlimited, a message is held until its subscriber ACK-s or NACK-s it.
+	VisibilityTimeout time.Duration
 
 	m    sync.RWMutex
 	msgs []*queueMessage[Data]
 	subs map[subscriptionID]*QueueSubscription[Data]
 }
 
+// Publish will publish the data to the queue.
+// A message published with pubsub.ContextWithNotBefore is delivered only after its not-before time.
 func (q *Queue[Data]) Publish(ctx context.Context, data Data) (rErr error) {
 	if err := ctx.Err(); err != nil {
 		return err
@@ -54,16 +72,37 @@ func (q *Queue[Data]) Publish(ctx context.Context, data Data) (rErr error) {
 var msgIDIndex uint64
 
 func (q *Queue[Data]) publish(ctx context.Context, data Data) *queueMessage[Data] {
+	var msg = q.newMessage(ctx, data)
 	q.m.Lock()
 	defer q.m.Unlock()
-	var msg = &queueMessage[Data]{
+	q.msgs = append(q.msgs, msg)
+	return msg
+}
+
+type ctxKeyMessageID struct{}
+
+// ctxMessageID is the message ID of a publish that an exchange makes to multiple queues,
+// so the copies of the message share the same ID.
+var ctxMessageID contextkit.ValueHandler[ctxKeyMessageID, string]
+
+func newMessageID() string {
+	return fmt.Sprintf("%s-%d", rnd.UUID(), atomic.AddUint64(&msgIDIndex, 1))
+}
+
+func (q *Queue[Data]) newMessage(ctx context.Context, data Data) *queueMessage[Data] {
+	notBefore, _ := pubsub.NotBefore(ctx)
+	id, ok := ctxMessageID.Lookup(ctx)
+	if !ok {
+		id = newMessageID()
+	}
+	return &queueMessage[Data]{
 		q:         q,
 		v:         data,
-		id:        fmt.Sprintf("%s-%d", rnd.UUID(), atomic.AddUint64(&msgIDIndex, 1)),
+		id:        id,
 		timestamp: clock.Now(),
+		notBefore: notBefore,
+		headers:   pubsub.MakeHeaders(ctx),
 	}
-	q.msgs = append(q.msgs, msg)
-	return msg
 }
 func (q *Queue[Data]) blockingWait(ctx context.Context, data Data) error {
 	// wait until the published message is acknowledged,
@@ -101,10 +140,10 @@ func (q *Queue[Data]) rIter() iter.Seq[*queueMessage[Data]] {
 	}
 }
 
-func (q *Queue[Data]) take(ctx context.Context, s *QueueSubscription[Data]) (_ *queueMessage[Data], _ context.Context, ack, nack func() error, _ error) {
+func (q *Queue[Data]) take(ctx context.Context, s *QueueSubscription[Data]) (*pubsubMessage[Data], error) {
 do:
 	if err := ctx.Err(); err != nil {
-		return nil, nil, nil, nil, err
+		return nil, err
 	}
 
 	msgs := q.rIter()
@@ -124,7 +163,7 @@ do:
 		var err error
 		tx, err = q.BeginTx(tx)
 		if err != nil {
-			return nil, nil, nil, nil, err
+			return nil, err
 		}
 		// Use a detached context for commit/rollback so that subscription
 		// cancellation does not interfere with the transaction lifecycle.
@@ -140,36 +179,56 @@ do:
 	}
 
 	for msg := range msgs {
-		if msg.take(s.id) {
-			var ack, nack func() error
+		if !msg.isVisible() {
+			continue
+		}
+		if lease, ok := msg.take(q.VisibilityTimeout); ok {
+			deliveryCount := int(atomic.AddInt32(&msg.deliveries, 1))
 
-			ack = func() error {
-				if err := txCommit(); err != nil {
-					// Transaction commit failed, so the message should be released
-					// so it can be picked up again. We cannot call nack() because
-					// the transaction is already marked as done by the failed commit.
-					q.m.Lock()
-					msg.release(s.id)
+			ack := func() error {
+				// The message is removed before the commit,
+				// so it can't be delivered again while the transaction is being committed.
+				q.m.Lock()
+				if !msg.holds(lease) {
 					q.m.Unlock()
-					return err
+					return errorkit.Merge(pubsub.ErrLeaseExpired, txRollback())
 				}
-				q.m.Lock()
-				defer q.m.Unlock()
 				q.msgs = slicekit.Filter(q.msgs, func(m *queueMessage[Data]) bool {
 					return m.id != msg.id
 				})
-				msg.release(s.id)
+				q.m.Unlock()
+				if err := txCommit(); err != nil {
+					// Transaction commit failed, so the message should be put back
+					// so it can be picked up again. We cannot call nack() because
+					// the transaction is already marked as done by the failed commit.
+					msg.release(lease)
+					return errorkit.Merge(err, q.dbPublishMessages(msg))
+				}
+				msg.release(lease)
 				return nil
 			}
 
-			nack = func() error {
-				q.m.Lock()
-				defer q.m.Unlock()
-				msg.release(s.id)
+			nack := func() error {
+				if !msg.holds(lease) {
+					return errorkit.Merge(pubsub.ErrLeaseExpired, txRollback())
+				}
+				if q.MaxDeliveryAttempts > 0 && q.MaxDeliveryAttempts <= deliveryCount {
+					return errorkit.Merge(txRollback(), q.deadLetter(contextkit.WithoutCancel(ctx), msg, lease))
+				}
+				msg.release(lease)
 				return txRollback()
 			}
 
-			return msg, tx, ack, nack, nil
+			return &pubsubMessage[Data]{
+				ctx:           pubsub.ExtractContext(tx, msg.headers),
+				q:             q,
+				sub:           s,
+				msg:           msg,
+				lease:         lease,
+				ack:           ack,
+				nack:          nack,
+				deliveryCount: deliveryCount,
+			}, nil
 		}
 
 		runtime.Gosched()
@@ -179,6 +238,75 @@ do:
 	goto do
 }
 
+// deadLetter moves a poison message to the DeadLetterQueue.
+// When the DeadLetterQueue fails to receive the message, the message is released for a redelivery.
+func (q *Queue[Data]) deadLetter(ctx context.Context, msg *queueMessage[Data], lease uint64) error {
+	if q.DeadLetterQueue != nil {
+		if err := q.DeadLetterQueue.Publish(pubsub.ContextWithHeaders(ctx, msg.headers), msg.v); err != nil {
+			msg.release(lease)
+			return err
+		}
+	}
+	q.m.Lock()
+	defer q.m.Unlock()
+	q.msgs = slicekit.Filter(q.msgs, func(m *queueMessage[Data]) bool {
+		return m.id != msg.id
+	})
+	msg.release(lease)
+	return nil
+}
+
+// Inspect lists the messages of the queue without consuming them, in the order of their delivery.
+// Messages that are being processed by a subscriber are also listed.
+func (q *Queue[Data]) Inspect(ctx context.Context) iterkit.SeqE[Data] {
+	return func(yield func(Data, error) bool) {
+		if err := ctx.Err(); err != nil {
+			var zero Data
+			yield(zero, err)
+			return
+		}
+		var vs []Data
+		for msg := range q.rIter() {
+			vs = append(vs, msg.v)
+		}
+		for _, v := range vs {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Requeue moves every message of the queue, that is not being processed by a subscriber, to the Publisher.
+// For example, a dead-letter queue can be requeued to its source queue.
+func (q *Queue[Data]) Requeue(ctx context.Context, to pubsub.Publisher[Data]) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	q.m.Lock()
+	q.sort(q.msgs)
+	var msgs []*queueMessage[Data]
+	q.msgs = slicekit.Filter(q.msgs, func(m *queueMessage[Data]) bool {
+		if m.isTaken() {
+			return true
+		}
+		msgs = append(msgs, m)
+		return false
+	})
+	q.m.Unlock()
+	for i, msg := range msgs {
+		pubCtx := pubsub.ContextWithHeaders(ctx, msg.headers)
+		if !msg.notBefore.IsZero() {
+			pubCtx = pubsub.ContextWithNotBefore(pubCtx, msg.notBefore)
+		}
+		if err := to.Publish(pubCtx, msg.v); err != nil {
+			// the messages that are not yet requeued are put back.
+			return errorkit.Merge(err, q.dbPublishMessages(msgs[i:]...))
+		}
+	}
+	return nil
+}
+
 func (q *Queue[Data]) sort(recs []*queueMessage[Data]) {
 	sort.Slice(recs, func(i, j int) bool {
 		if q.SortLessFunc != nil {
@@ -196,8 +324,9 @@ type queueTx[Data any] struct {
 	m sync.Mutex
 	q *Queue[Data]
 
-	ds   []Data
-	done bool
+	msgs     []*queueMessage[Data]
+	done     bool
+	prepared bool
 }
 
 type qpub[Data any] struct {
@@ -212,17 +341,6 @@ func (fn qPublisher[Data]) Publish(ctx context.Context, data Data) error {
 	return fn(ctx, data)
 }
 
-func (q *Queue[Data]) dbPublish(ds ...Data) error {
-	return q.dbPublishMessages(slicekit.Map(ds, func(v Data) *queueMessage[Data] {
-		return &queueMessage[Data]{
-			q:         q,
-			v:         v,
-			id:        fmt.Sprintf("%s-%d", rnd.UUID(), atomic.AddUint64(&msgIDIndex, 1)),
-			timestamp: clock.Now(),
-		}
-	})...)
-}
-
 func (q *Queue[Data]) dbPublishMessages(msgs ...*queueMessage[Data]) error {
 	q.m.Lock()
 	defer q.m.Unlock()
@@ -237,17 +355,20 @@ func (q *Queue[Data]) txm() txkit.Manager[Queue[Data], queueTx[Data], qPublisher
 			return func(ctx context.Context, data Data) error {
 				tx.m.Lock()
 				defer tx.m.Unlock()
-				tx.ds = append(tx.ds, data)
+				if tx.prepared {
+					return txkit.ErrTxPrepared
+				}
+				tx.msgs = append(tx.msgs, tx.q.newMessage(ctx, data))
 				return nil
 			}
 		},
 		DBAdapter: func(db *Queue[Data]) qPublisher[Data] {
-			return qPublisher[Data](func(ctx context.Context, ds Data) error {
-				return db.dbPublish(ds)
+			return qPublisher[Data](func(ctx context.Context, data Data) error {
+				return db.dbPublishMessages(db.newMessage(ctx, data))
 			})
 		},
 		Begin: func(ctx context.Context, db *Queue[Data]) (*queueTx[Data], error) {
-			return &queueTx[Data]{q: db, ds: []Data{}}, nil
+			return &queueTx[Data]{q: db}, nil
 		},
 		Commit: func(ctx context.Context, tx *queueTx[Data]) error {
 			tx.m.Lock()
@@ -256,12 +377,22 @@ func (q *Queue[Data]) txm() txkit.Manager[Queue[Data], queueTx[Data], qPublisher
 			// We must not go through tx.q.Publish here, because the context
 			// still carries this transaction, which would route the publish
 			// back into the tx buffer and re-acquire tx.m, causing a deadlock.
-			return tx.q.dbPublish(tx.ds...)
+			// The messages are published at the time of the commit.
+			for _, msg := range tx.msgs {
+				msg.timestamp = clock.Now()
+			}
+			return tx.q.dbPublishMessages(tx.msgs...)
 		},
 		Rollback: func(ctx context.Context, tx *queueTx[Data]) error {
 			tx.m.Lock()
 			defer tx.m.Unlock()
-			tx.ds = nil
+			tx.msgs = nil
+			return nil
+		},
+		Prepare: func(ctx context.Context, tx *queueTx[Data], txID string) error {
+			tx.m.Lock()
+			defer tx.m.Unlock()
+			tx.prepared = true
 			return nil
 		},
 	}
@@ -347,6 +478,14 @@ func (q *Queue[Data]) RollbackTx(ctx context.Context) error {
 	return q.txm().RollbackTx(ctx)
 }
 
+// PrepareTx prepares the current transaction for a two-phase commit.
+// A prepared transaction no longer accepts new messages, and it can be finished with CommitTx or RollbackTx.
+//
+// The prepared transaction only lives in memory, so it doesn't outlive the process.
+func (q *Queue[Data]) PrepareTx(ctx context.Context) (context.Context, error) {
+	return q.txm().PrepareTx(ctx)
+}
+
 func (q *Queue[Data]) Purge(ctx context.Context) error {
 	q.m.Lock()
 	defer q.m.Unlock()
@@ -403,21 +542,13 @@ func (s *QueueSubscription[Data]) Next() bool {
 		return false
 	}
 
-	msg, ctx, ack, nack, err := s.q.take(s.ctx, s)
+	msg, err := s.q.take(s.ctx, s)
 	if err != nil {
 		s.err = err
 		return false
 	}
 
-	s.value = &pubsubMessage[Data]{
-		ctx:  ctx,
-		q:    s.q,
-		sub:  s,
-		msg:  msg,
-		ack:  ack,
-		nack: nack,
-	}
-
+	s.value = msg
 	return true
 }
 
@@ -431,14 +562,23 @@ func (s *QueueSubscription[Data]) Value() pubsub.Message[Data] {
 type pubsubMessage[Data any] struct {
 	ctx context.Context
 
-	q   *Queue[Data]
-	sub *QueueSubscription[Data]
-	msg *queueMessage[Data]
+	q     *Queue[Data]
+	sub   *QueueSubscription[Data]
+	msg   *queueMessage[Data]
+	lease uint64
 
 	ack  func() error
 	nack func() error
+
+	deliveryCount int
+
+	fin sync.Once
+	err error
 }
 
+var _ pubsub.LeaseExtender = (*pubsubMessage[int])(nil)
+var _ pubsub.MetadataProvider = (*pubsubMessage[int])(nil)
+
 func (pm *pubsubMessage[Data]) Context() context.Context {
 	return pm.ctx
 }
@@ -447,14 +587,44 @@ func (pm *pubsubMessage[Data]) ACK() error {
 	if pm.msg == nil {
 		return fmt.Errorf(".Value accessed before iter.Next, nothing to ACK")
 	}
-	return pm.ack()
+	pm.fin.Do(func() { pm.err = pm.ack() })
+	return pm.err
 }
 
 func (pm *pubsubMessage[Data]) NACK() error {
 	if pm.msg == nil {
 		return fmt.Errorf(".Value accessed before iter.Next, nothing to NACK")
 	}
-	return pm.nack()
+	pm.fin.Do(func() { pm.err = pm.nack() })
+	return pm.err
+}
+
+// Metadata implements the pubsub.MetadataProvider.
+func (pm *pubsubMessage[Data]) Metadata() pubsub.Metadata {
+	if pm.msg == nil {
+		return pubsub.Metadata{}
+	}
+	return pubsub.Metadata{
+		ID:            pm.msg.id,
+		Headers:       maps.Clone(pm.msg.headers),
+		PublishedAt:   pm.msg.timestamp,
+		DeliveryCount: pm.deliveryCount,
+	}
+}
+
+// ExtendLease implements the pubsub.LeaseExtender.
+// Without a Queue.VisibilityTimeout, the lease is unlimited, and extending it has no effect.
+func (pm *pubsubMessage[Data]) ExtendLease(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if pm.msg == nil {
+		return fmt.Errorf(".Value accessed before iter.Next, nothing to extend")
+	}
+	if !pm.msg.extend(pm.lease, d) {
+		return pubsub.ErrLeaseExpired
+	}
+	return nil
 }
 
 func (pm *pubsubMessage[Data]) Data() Data {
@@ -470,41 +640,84 @@ type queueMessage[Data any] struct {
 
 	id string
 
-	timestamp time.Time
-	takenBy   subscriptionID
+	timestamp  time.Time
+	deliveries int32
+	// notBefore is the time before which the message is not delivered.
+	notBefore time.Time
+	headers   map[string]string
+
+	m sync.Mutex
+	// lease is the ID of the current delivery, zero when the message is not taken by a subscriber.
+	lease uint64
+	// leasedUntil is the end of the current lease, zero when the lease is unlimited.
+	leasedUntil time.Time
+}
+
+func (msg *queueMessage[Data]) isVisible() bool {
+	return msg.notBefore.IsZero() || !clock.Now().Before(msg.notBefore)
 }
 
 type subscriptionID int32
 
-func (msg *queueMessage[Data]) take(subID subscriptionID) bool {
-	for {
-		curTakenBy := atomic.LoadInt32((*int32)(&msg.takenBy))
-		if curTakenBy != 0 {
-			return false
-		}
-		if curTakenBy == (int32)(subID) {
-			return true
-		}
-		if atomic.CompareAndSwapInt32((*int32)(&msg.takenBy), 0, (int32)(subID)) {
-			return true
-		}
+var leaseIDIndex uint64
+
+// take leases the message for a delivery, when it is not taken already, or when its last lease is expired.
+func (msg *queueMessage[Data]) take(timeout time.Duration) (uint64, bool) {
+	msg.m.Lock()
+	defer msg.m.Unlock()
+	if msg.isLeased() {
+		return 0, false
+	}
+	msg.lease = atomic.AddUint64(&leaseIDIndex, 1)
+	msg.leasedUntil = time.Time{}
+	if 0 < timeout {
+		msg.leasedUntil = clock.Now().Add(timeout)
+	}
+	return msg.lease, true
+}
+
+func (msg *queueMessage[Data]) isTaken() bool {
+	msg.m.Lock()
+	defer msg.m.Unlock()
+	return msg.isLeased()
+}
+
+func (msg *queueMessage[Data]) isLeased() bool {
+	if msg.lease == 0 {
+		return false
 	}
+	return msg.leasedUntil.IsZero() || clock.Now().Before(msg.leasedUntil)
+}
+
+// holds tells if the lease is still the current lease of the message.
+// An expired lease is still held, until the message is delivered again.
+func (msg *queueMessage[Data]) holds(lease uint64) bool {
+	msg.m.Lock()
+	defer msg.m.Unlock()
+	return msg.lease == lease
+}
+
+func (msg *queueMessage[Data]) extend(lease uint64, d time.Duration) bool {
+	msg.m.Lock()
+	defer msg.m.Unlock()
+	if msg.lease != lease {
+		return false
+	}
+	if !msg.leasedUntil.IsZero() {
+		msg.leasedUntil = clock.Now().Add(d)
+	}
+	return true
 }
 
 // Release means the message is NACK -ed and should be picked up again.
-func (rec *queueMessage[Data]) release(subID subscriptionID) {
-	for {
-		curTakenBy := atomic.LoadInt32((*int32)(&rec.takenBy))
-		if curTakenBy == 0 { // already released
-			return
-		}
-		if curTakenBy != (int32)(subID) {
-			return // impossible to release
-		}
-		if atomic.CompareAndSwapInt32((*int32)(&rec.takenBy), (int32)(subID), 0) {
-			break
-		}
+func (msg *queueMessage[Data]) release(lease uint64) {
+	msg.m.Lock()
+	defer msg.m.Unlock()
+	if msg.lease != lease {
+		return // impossible to release, the message is delivered again already
 	}
+	msg.lease = 0
+	msg.leasedUntil = time.Time{}
 }
 
 //--------------------------------------------------------------------------------------------------------------------//
@@ -518,7 +731,9 @@ type FanOutExchange[Data any] struct {
 
 // Publish will publish all data to all FanOutExchange.Queues in an atomic fashion.
 // It will either all succeed or all fail together.
+// The copies of the message share the same message ID in their pubsub.Metadata.
 func (e *FanOutExchange[Data]) Publish(ctx context.Context, data Data) (rErr error) {
+	ctx = ctxMessageID.ContextWith(ctx, newMessageID())
 	return e.eachQueue(ctx, func(ctx context.Context, q *Queue[Data]) error {
 		return q.Publish(ctx, data)
 	})
@@ -532,7 +747,12 @@ func (e *FanOutExchange[Data]) Purge(ctx context.Context) (rErr error) {
 }
 
 func (e *FanOutExchange[Data]) eachQueue(ctx context.Context, blk func(ctx context.Context, q *Queue[Data]) error) (rErr error) {
-	for _, q := range e.Queues {
+	return eachQueue(ctx, e.Queues, blk)
+}
+
+// eachQueue calls blk with every queue in a transaction, and commits them only if all of them succeeded.
+func eachQueue[Data any](ctx context.Context, queues []*Queue[Data], blk func(ctx context.Context, q *Queue[Data]) error) (rErr error) {
+	for _, q := range queues {
 		tx, err := q.BeginTx(ctx)
 		if err != nil {
 			return err
@@ -553,3 +773,105 @@ func (e *FanOutExchange[Data]) MakeQueue() *Queue[Data] {
 }
 
 //--------------------------------------------------------------------------------------------------------------------//
+
+// TopicExchange delivers messages to the queues whose binding pattern matches the routing key of the message.
+// The routing key is a string of dot-separated words,
+// and the binding pattern can use "*" to match a single word, or "#" to match zero or more words.
+// For the details, see pubsub.MatchTopic.
+type TopicExchange[Data any] struct {
+	// Bindings contain every Queue that is bound to the Topic Exchange with their routing key pattern.
+	Bindings []TopicBinding[Data]
+}
+
+type TopicBinding[Data any] struct {
+	Pattern string
+	Queue   *Queue[Data]
+}
+
+var _ pubsub.Publisher[pubsub.Envelope[int]] = &TopicExchange[int]{}
+
+// Publish will publish the data to all queues with a matching binding in an atomic fashion.
+// A queue receives the message only once, even if multiple of its bindings match.
+// A message without a matching binding is dropped.
+// The headers of the envelope are published as the headers of the message.
+func (e *TopicExchange[Data]) Publish(ctx context.Context, env pubsub.Envelope[Data]) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var queues []*Queue[Data]
+	for _, b := range e.Bindings {
+		if pubsub.MatchTopic(b.Pattern, env.RoutingKey) && !slicekit.Contains(queues, b.Queue) {
+			queues = append(queues, b.Queue)
+		}
+	}
+	ctx = ctxMessageID.ContextWith(ctx, newMessageID())
+	ctx = pubsub.ContextWithHeaders(ctx, env.Headers)
+	return eachQueue(ctx, queues, func(ctx context.Context, q *Queue[Data]) error {
+		return q.Publish(ctx, env.Data)
+	})
+}
+
+// Purge will flush all data from the exchange's queues
+func (e *TopicExchange[Data]) Purge(ctx context.Context) error {
+	return eachQueue(ctx, slicekit.Map(e.Bindings, func(b TopicBinding[Data]) *Queue[Data] { return b.Queue }),
+		func(ctx context.Context, q *Queue[Data]) error { return q.Purge(ctx) })
+}
+
+// MakeQueue creates a unique queue which is bound to the Topic exchange with the routing key pattern.
+func (e *TopicExchange[Data]) MakeQueue(pattern string) *Queue[Data] {
+	q := &Queue[Data]{}
+	e.Bindings = append(e.Bindings, TopicBinding[Data]{Pattern: pattern, Queue: q})
+	return q
+}
+
+//--------------------------------------------------------------------------------------------------------------------//
+
+// HeadersExchange delivers messages to the queues whose headers binding matches the headers of the message.
+// For the details, see pubsub.HeadersBinding.
+type HeadersExchange[Data any] struct {
+	// Bindings contain every Queue that is bound to the Headers Exchange with their headers binding.
+	Bindings []HeadersBinding[Data]
+}
+
+type HeadersBinding[Data any] struct {
+	pubsub.HeadersBinding
+	Queue *Queue[Data]
+}
+
+var _ pubsub.Publisher[pubsub.Envelope[int]] = &HeadersExchange[int]{}
+
+// Publish will publish the data to all queues with a matching binding in an atomic fashion.
+// A queue receives the message only once, even if multiple of its bindings match.
+// A message without a matching binding is dropped.
+// The headers of the envelope are published as the headers of the message.
+func (e *HeadersExchange[Data]) Publish(ctx context.Context, env pubsub.Envelope[Data]) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var queues []*Queue[Data]
+	for _, b := range e.Bindings {
+		if b.Matches(env.Headers) && !slicekit.Contains(queues, b.Queue) {
+			queues = append(queues, b.Queue)
+		}
+	}
+	ctx = ctxMessageID.ContextWith(ctx, newMessageID())
+	ctx = pubsub.ContextWithHeaders(ctx, env.Headers)
+	return eachQueue(ctx, queues, func(ctx context.Context, q *Queue[Data]) error {
+		return q.Publish(ctx, env.Data)
+	})
+}
+
+// Purge will flush all data from the exchange's queues
+func (e *HeadersExchange[Data]) Purge(ctx context.Context) error {
+	return eachQueue(ctx, slicekit.Map(e.Bindings, func(b HeadersBinding[Data]) *Queue[Data] { return b.Queue }),
+		func(ctx context.Context, q *Queue[Data]) error { return q.Purge(ctx) })
+}
+
+// MakeQueue creates a unique queue which is bound to the Headers exchange with the headers binding.
+func (e *HeadersExchange[Data]) MakeQueue(binding pubsub.HeadersBinding) *Queue[Data] {
+	q := &Queue[Data]{}
+	e.Bindings = append(e.Bindings, HeadersBinding[Data]{HeadersBinding: binding, Queue: q})
+	return q
+}
+
+//--------------------------------------------------------------------------------------------------------------------//
@@ -8,10 +8,14 @@ import (
 	"time"
 
 	"go.llib.dev/frameless/adapter/memory"
+	"go.llib.dev/frameless/port/comproto"
+	"go.llib.dev/frameless/port/comproto/comprotocontract"
 	"go.llib.dev/frameless/port/pubsub"
 	"go.llib.dev/frameless/port/pubsub/pubsubcontract"
+	"go.llib.dev/frameless/port/pubsub/pubsubtest"
 	"go.llib.dev/testcase"
 	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/clock/timecop"
 
 	"go.llib.dev/frameless/testing/testent"
 )
@@ -140,6 +144,177 @@ func TestQueue_implementsFanOutExchange(t *testing.T) {
 	)
 }
 
+var _ pubsub.Publisher[pubsub.Envelope[testent.Foo]] = &memory.TopicExchange[testent.Foo]{}
+
+func TestTopicExchange(t *testing.T) {
+	exchange := &memory.TopicExchange[testent.Foo]{}
+
+	var Bind = func(tb testing.TB, pattern string) pubsub.Subscriber[testent.Foo] {
+		return exchange.MakeQueue(pattern)
+	}
+
+	pubsubcontract.TopicExchange[testent.Foo](exchange, Bind).Test(t)
+}
+
+var _ pubsub.Publisher[pubsub.Envelope[testent.Foo]] = &memory.HeadersExchange[testent.Foo]{}
+
+func TestHeadersExchange(t *testing.T) {
+	exchange := &memory.HeadersExchange[testent.Foo]{}
+
+	var Bind = func(tb testing.TB, binding pubsub.HeadersBinding) pubsub.Subscriber[testent.Foo] {
+		return exchange.MakeQueue(binding)
+	}
+
+	pubsubcontract.HeadersExchange[testent.Foo](exchange, Bind).Test(t)
+}
+
+func TestTopicExchange_publishIsAtomic(t *testing.T) {
+	ctx := context.Background()
+	exchange := &memory.TopicExchange[testent.Foo]{}
+	q1 := exchange.MakeQueue("order.*")
+	q2 := exchange.MakeQueue("order.#")
+	q2.Blocking = true // a blocking queue fails to publish in a transaction
+
+	assert.Error(t, exchange.Publish(ctx, pubsub.Envelope[testent.Foo]{RoutingKey: "order.created", Data: testent.MakeFoo(t)}))
+
+	res := pubsubtest.Subscribe[testent.Foo](t, q1, ctx)
+	res.AssertEmpty(t, "the message was published to none of the queues")
+}
+
+var _ interface {
+	pubsub.Inspector[testent.Foo]
+	pubsub.Requeuer[testent.Foo]
+} = &memory.Queue[testent.Foo]{}
+
+func TestQueue_implementsDeadLetter(t *testing.T) {
+	dlq := &memory.Queue[testent.Foo]{}
+	q := &memory.Queue[testent.Foo]{
+		MaxDeliveryAttempts: 3,
+		DeadLetterQueue:     dlq,
+	}
+
+	pubsubcontract.DeadLetter[testent.Foo](q, q, dlq, q.MaxDeliveryAttempts).Test(t)
+}
+
+func TestQueue_deadLetterWithoutDeadLetterQueue(t *testing.T) {
+	ctx := context.Background()
+	q := &memory.Queue[testent.Foo]{MaxDeliveryAttempts: 1}
+
+	poison := testent.MakeFoo(t)
+	assert.NoError(t, q.Publish(ctx, poison))
+	for msg, err := range q.Subscribe(ctx) {
+		assert.NoError(t, err)
+		assert.NoError(t, msg.NACK())
+		break
+	}
+
+	res := pubsubtest.Subscribe[testent.Foo](t, q, ctx)
+	res.AssertEmpty(t, "the poison message was expected to be dropped")
+}
+
+func TestQueue_implementsDelayed(t *testing.T) {
+	q := &memory.Queue[testent.Foo]{}
+
+	pubsubcontract.Delayed[testent.Foo](q, q).Test(t)
+}
+
+func TestQueue_delayedPublishInTransaction(t *testing.T) {
+	ctx := context.Background()
+	q := &memory.Queue[testent.Foo]{}
+
+	tx, err := q.BeginTx(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, q.Publish(pubsub.ContextWithDelay(tx, time.Hour), testent.MakeFoo(t)))
+	assert.NoError(t, q.CommitTx(tx))
+
+	res := pubsubtest.Subscribe[testent.Foo](t, q, ctx)
+	res.AssertEmpty(t, "the delayed message was not expected to be delivered before its delay")
+}
+
+func TestQueue_implementsVisibilityTimeout(t *testing.T) {
+	q := &memory.Queue[testent.Foo]{VisibilityTimeout: time.Minute}
+
+	pubsubcontract.VisibilityTimeout[testent.Foo](q, q, q.VisibilityTimeout).Test(t)
+}
+
+func TestQueue_implementsMetadata(t *testing.T) {
+	q := &memory.Queue[testent.Foo]{}
+	leased := &memory.Queue[testent.Foo]{VisibilityTimeout: time.Minute}
+
+	testcase.RunSuite(t,
+		pubsubcontract.Metadata[testent.Foo](q, q),
+		pubsubcontract.Metadata[testent.Foo](leased, leased),
+	)
+}
+
+func TestQueue_deadLetterKeepsTheMessageMetadata(t *testing.T) {
+	ctx := context.Background()
+	dlq := &memory.Queue[testent.Foo]{}
+	q := &memory.Queue[testent.Foo]{MaxDeliveryAttempts: 1, DeadLetterQueue: dlq}
+
+	headers := map[string]string{"correlation-id": t.Name()}
+	assert.NoError(t, q.Publish(pubsub.ContextWithHeaders(ctx, headers), testent.MakeFoo(t)))
+	for msg, err := range q.Subscribe(ctx) {
+		assert.NoError(t, err)
+		assert.NoError(t, msg.NACK())
+		break
+	}
+
+	for msg, err := range dlq.Subscribe(ctx) {
+		assert.NoError(t, err)
+		meta, ok := pubsub.LookupMetadata(msg)
+		assert.True(t, ok)
+		assert.Equal(t, headers, meta.Headers)
+		assert.NoError(t, msg.ACK())
+		break
+	}
+}
+
+func TestFanOutExchange_theCopiesOfAMessageShareTheMessageID(t *testing.T) {
+	ctx := context.Background()
+	exchange := &memory.FanOutExchange[testent.Foo]{}
+	q1 := exchange.MakeQueue()
+	q2 := exchange.MakeQueue()
+
+	headers := map[string]string{"correlation-id": t.Name()}
+	assert.NoError(t, exchange.Publish(pubsub.ContextWithHeaders(ctx, headers), testent.MakeFoo(t)))
+
+	receive := func(q *memory.Queue[testent.Foo]) pubsub.Metadata {
+		for msg, err := range q.Subscribe(ctx) {
+			assert.NoError(t, err)
+			meta, ok := pubsub.LookupMetadata(msg)
+			assert.True(t, ok)
+			assert.NoError(t, msg.ACK())
+			return meta
+		}
+		t.Fatal("expected to receive a message")
+		return pubsub.Metadata{}
+	}
+	meta1, meta2 := receive(q1), receive(q2)
+	assert.NotEmpty(t, meta1.ID)
+	assert.Equal(t, meta1.ID, meta2.ID)
+	assert.Equal(t, headers, meta1.Headers)
+	assert.Equal(t, headers, meta2.Headers)
+}
+
+func TestQueue_withoutVisibilityTimeoutTheMessageIsHeldUntilACK(t *testing.T) {
+	ctx := context.Background()
+	q := &memory.Queue[testent.Foo]{}
+
+	assert.NoError(t, q.Publish(ctx, testent.MakeFoo(t)))
+	next, stop := iter.Pull2(iter.Seq2[pubsub.Message[testent.Foo], error](q.Subscribe(ctx)))
+	defer stop()
+	msg, err, ok := next()
+	assert.True(t, ok)
+	assert.NoError(t, err)
+
+	timecop.Travel(t, 24*time.Hour)
+
+	res := pubsubtest.Subscribe[testent.Foo](t, q, ctx)
+	res.AssertEmpty(t, "without a visibility timeout, the message is not expected to be delivered again")
+	assert.NoError(t, msg.ACK())
+}
+
 func TestQueue_implementsTransactionalMessageContext(t *testing.T) {
 	pubsubConfig := pubsubcontract.Config[TestEntity]{
 		MakeContext: func(t testing.TB) context.Context {
@@ -176,6 +351,31 @@ func TestQueue_implementsTransactionalPublisher(t *testing.T) {
 
 var _ pubsub.Publisher[testent.Foo] = &memory.FanOutExchange[testent.Foo]{}
 
+var _ comproto.TwoPhaseCommitProtocol = &memory.Queue[testent.Foo]{}
+
+func TestQueue_implementsTwoPhaseCommitProtocol(t *testing.T) {
+	comprotocontract.TwoPhaseCommitProtocol(&memory.Queue[testent.Foo]{}).Test(t)
+}
+
+func TestQueue_preparedTransactionDoesNotAcceptNewMessages(t *testing.T) {
+	ctx := context.Background()
+	q := &memory.Queue[testent.Foo]{}
+	sub := pubsubtest.Subscribe[testent.Foo](t, q, ctx)
+
+	tx, err := q.BeginTx(ctx)
+	assert.NoError(t, err)
+	foo := testent.MakeFoo(t)
+	assert.NoError(t, q.Publish(tx, foo))
+	tx, err = q.PrepareTx(tx)
+	assert.NoError(t, err)
+	assert.Error(t, q.Publish(tx, testent.MakeFoo(t)))
+
+	assert.NoError(t, q.CommitTx(tx))
+	sub.Eventually(t, func(tb testing.TB, got []testent.Foo) {
+		assert.Equal(tb, []testent.Foo{foo}, got)
+	})
+}
+
 // TestQueue_combined
 //
 // @flaky
@@ -0,0 +1,123 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.llib.dev/frameless/pkg/audit"
+	"go.llib.dev/frameless/pkg/errorkit"
+	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/logger"
+	"go.llib.dev/frameless/pkg/logging"
+	"go.llib.dev/frameless/pkg/uuid"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/migration"
+)
+
+// AuditEventRepository is an audit.EventRepository that stores the events in a shared audit table.
+// Recording events through it takes part in the transaction of the Connection.
+//
+// The entity ID and the values of the changes are stored as JSON,
+// thus they must be serialisable with the encoding/json package.
+// After a round trip, the Change values hold their JSON decoded form, such as a float64 for a number.
+type AuditEventRepository[ID any] struct {
+	// Name separates the events of different audit trails in the audit table.
+	Name       string
+	Connection Connection
+}
+
+var _ audit.EventRepository[any] = AuditEventRepository[any]{}
+
+const auditTableName = "frameless_audit_events"
+
+const queryCreateAuditTable = `
+CREATE TABLE IF NOT EXISTS ` + auditTableName + ` (
+	audit       TEXT                     NOT NULL,
+	id          TEXT                     NOT NULL,
+	entity_id   JSONB                    NOT NULL,
+	action      TEXT                     NOT NULL,
+	actor       TEXT                     NOT NULL,
+	occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	changes     JSON                     NOT NULL,
+	PRIMARY KEY (audit, id)
+);
+
+CREATE INDEX IF NOT EXISTS ` + auditTableName + `_entity_id_idx ON ` + auditTableName + ` (audit, entity_id, occurred_at);
+`
+
+func (r AuditEventRepository[ID]) Migrate(ctx context.Context) error {
+	return MakeMigrator(r.Connection, auditTableName, migration.Steps[Connection]{
+		"0": flsql.MigrationStep[Connection]{UpQuery: queryCreateAuditTable},
+	}).Migrate(ctx)
+}
+
+func (r AuditEventRepository[ID]) Create(ctx context.Context, ptr *audit.Event[ID]) error {
+	if ptr == nil {
+		return fmt.Errorf("nil entity pointer given to %T#Create", r)
+	}
+	if r.Name == "" {
+		return fmt.Errorf("missing audit name")
+	}
+	if ptr.ID == "" {
+		id, err := uuid.MakeV7()
+		if err != nil {
+			return err
+		}
+		ptr.ID = audit.EventID(id.String())
+	}
+	entityID, err := json.Marshal(ptr.EntityID)
+	if err != nil {
+		return err
+	}
+	changes, err := json.Marshal(ptr.Changes)
+	if err != nil {
+		return err
+	}
+
+	// ON CONFLICT avoids aborting the caller's transaction on a duplicate event.
+	const query = `INSERT INTO ` + auditTableName + ` (audit, id, entity_id, action, actor, occurred_at, changes) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT DO NOTHING`
+
+	logger.Debug(ctx, "postgresql.AuditEventRepository#Create", logging.Field("query", query))
+
+	result, err := r.Connection.ExecContext(ctx, query, r.Name, string(ptr.ID), string(entityID),
+		string(ptr.Action), ptr.Actor, ptr.Timestamp.UTC(), string(changes))
+	if err != nil {
+		return err
+	}
+	if count, err := result.RowsAffected(); err != nil {
+		return err
+	} else if count == 0 {
+		err := crud.ErrAlreadyExists.F(`audit event already exists with id: %v`, ptr.ID)
+		return errorkit.WithContext(err, ctx)
+	}
+	return nil
+}
+
+func (r AuditEventRepository[ID]) FindByEntityID(ctx context.Context, entityID ID) iterkit.SeqE[audit.Event[ID]] {
+	id, err := json.Marshal(entityID)
+	if err != nil {
+		return iterkit.Error[audit.Event[ID]](err)
+	}
+	const query = `SELECT id, entity_id, action, actor, occurred_at, changes FROM ` + auditTableName +
+		` WHERE audit = $1 AND entity_id = $2::jsonb ORDER BY occurred_at, id`
+	return flsql.QueryMany(r.Connection, ctx, func(s flsql.Scanner) (audit.Event[ID], error) {
+		var (
+			event    audit.Event[ID]
+			entityID []byte
+			changes  []byte
+		)
+		if err := s.Scan(&event.ID, &entityID, &event.Action, &event.Actor, &event.Timestamp, &changes); err != nil {
+			return event, err
+		}
+		if err := json.Unmarshal(entityID, &event.EntityID); err != nil {
+			return event, err
+		}
+		if err := json.Unmarshal(changes, &event.Changes); err != nil {
+			return event, err
+		}
+		event.Timestamp = event.Timestamp.UTC()
+		return event, nil
+	}, query, r.Name, string(id))
+}
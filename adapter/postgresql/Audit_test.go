@@ -0,0 +1,30 @@
+package postgresql_test
+
+import (
+	"testing"
+
+	"go.llib.dev/frameless/adapter/postgresql"
+	"go.llib.dev/frameless/pkg/audit/auditcontract"
+	"go.llib.dev/frameless/port/migration"
+	"go.llib.dev/frameless/testing/testent"
+	"go.llib.dev/testcase/assert"
+)
+
+var _ migration.Migratable = postgresql.AuditEventRepository[testent.FooID]{}
+
+func TestAuditEventRepository(t *testing.T) {
+	cm := GetConnection(t)
+
+	repo := postgresql.AuditEventRepository[testent.FooID]{
+		Name:       "test_audit",
+		Connection: cm,
+	}
+	assert.NoError(t, repo.Migrate(MakeContext(t)))
+
+	auditcontract.EventRepository[testent.FooID](repo,
+		auditcontract.Config[testent.FooID]{
+			MakeContext:    MakeContext,
+			OnePhaseCommit: cm,
+		},
+	).Test(t)
+}
@@ -0,0 +1,224 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/logger"
+	"go.llib.dev/frameless/pkg/logging"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/migration"
+	"go.llib.dev/frameless/port/pubsub"
+	"go.llib.dev/testcase/clock"
+)
+
+// ChangeFeedConfig configures the crud.ChangeSubscriber support of the Repository.
+//
+// When the change feed is enabled, every mutation of the Repository is recorded into an outbox table,
+// within the same transaction as the mutation itself.
+// This ensures that a change is only delivered when its mutation is committed.
+//
+// The changes are stored as JSON, so both ENT and ID must be serialisable with the encoding/json package.
+// Mutations made through Batch are recorded as well, but with BatchConfig.NoTransaction,
+// they are recorded independently of the batch insertion.
+type ChangeFeedConfig struct {
+	// Enabled flag will make the Repository record its mutations into the change feed outbox table.
+	// Use Repository.MigrateChangeFeed to create the outbox table.
+	Enabled bool
+	// EmptyFeedBreakTime is the time.Duration that a subscription waits when there is no new change to deliver.
+	EmptyFeedBreakTime time.Duration
+}
+
+const changeFeedTableName = "frameless_crud_changes"
+
+const queryCreateChangeFeedTable = `
+CREATE TABLE IF NOT EXISTS ` + changeFeedTableName + ` (
+	id         BIGSERIAL PRIMARY KEY,
+	txid       BIGINT NOT NULL DEFAULT txid_current(),
+	resource   TEXT NOT NULL,
+	kind       TEXT NOT NULL,
+	entity_id  JSON NOT NULL,
+	entity     JSON NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+CREATE INDEX IF NOT EXISTS ` + changeFeedTableName + `_position_idx ON ` + changeFeedTableName + ` (resource, txid, id);
+`
+
+// MigrateChangeFeed creates the outbox table of the change feed.
+func (r Repository[ENT, ID]) MigrateChangeFeed(ctx context.Context) error {
+	return MakeMigrator(r.Connection, changeFeedTableName, migration.Steps[Connection]{
+		"0": flsql.MigrationStep[Connection]{UpQuery: queryCreateChangeFeedTable},
+	}).Migrate(ctx)
+}
+
+// recordChange records the changed entities in the change feed and in the history.
+// It is the flsql.Repository's OnChange hook, thus it runs within the transaction of the mutation.
+func (r Repository[ENT, ID]) recordChange(ctx context.Context, kind crud.ChangeKind, ents ...ENT) error {
+	if err := r.recordChanges(ctx, kind, ents...); err != nil {
+		return err
+	}
+	return r.recordHistory(ctx, kind, ents...)
+}
+
+// recordsChanges tells if the mutations need to know the affected rows.
+func (r Repository[ENT, ID]) recordsChanges() bool {
+	return r.ChangeFeed.Enabled || r.History.Enabled
+}
+
+// recordChanges inserts the changes into the outbox table of the change feed.
+// It should be called within the transaction of the mutation.
+func (r Repository[ENT, ID]) recordChanges(ctx context.Context, kind crud.ChangeKind, ents ...ENT) error {
+	if !r.ChangeFeed.Enabled || len(ents) == 0 {
+		return nil
+	}
+	var (
+		nextPH       = makePrepareStatementPlaceholderGenerator()
+		valuesClause []string
+		args         []any
+		now          = clock.Now().UTC()
+	)
+	for _, ent := range ents {
+		id, err := json.Marshal(r.Mapping.ID.Get(ent))
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(ent)
+		if err != nil {
+			return err
+		}
+		valuesClause = append(valuesClause, fmt.Sprintf("(%s, %s, %s, %s, %s)", nextPH(), nextPH(), nextPH(), nextPH(), nextPH()))
+		args = append(args, r.Mapping.TableName, string(kind), id, data, now)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (resource, kind, entity_id, entity, created_at)\nVALUES %s",
+		changeFeedTableName, strings.Join(valuesClause, ",\n"))
+
+	logger.Debug(ctx, "postgresql.Repository#recordChanges", logging.Field("query", query))
+
+	_, err := r.Connection.ExecContext(ctx, query, args...)
+	return err
+}
+
+// changeFeedPosition points to a change in the outbox table.
+//
+// The changes are ordered by the ID of the transaction that made them.
+// Only the changes of transactions older than the oldest still running transaction are delivered,
+// thus a change committed later can't appear behind an already delivered position.
+type changeFeedPosition struct {
+	TxID int64
+	ID   int64
+}
+
+const queryChangeFeedHead = `SELECT txid_snapshot_xmin(txid_current_snapshot())`
+
+const queryChangeFeedNext = `
+SELECT id, txid, kind, entity_id, entity
+FROM ` + changeFeedTableName + `
+WHERE resource = $1
+  AND (txid, id) > ($2, $3)
+  AND txid < txid_snapshot_xmin(txid_current_snapshot())
+ORDER BY txid, id
+LIMIT 1
+`
+
+// SubscribeChanges implements crud.ChangeSubscriber by polling the outbox table of the change feed.
+//
+// The subscription starts from the oldest still running transaction,
+// so it may also deliver changes that were committed shortly before the subscription was made.
+// A long-running transaction delays the delivery of the changes made after it started.
+//
+// The changes are delivered in the order of the transaction IDs, which follows the start of the transactions,
+// and not their commit, thus the changes of overlapping transactions may be delivered in a different order
+// than they were committed in.
+func (r Repository[ENT, ID]) SubscribeChanges(ctx context.Context) pubsub.Subscription[crud.Change[ENT, ID]] {
+	if !r.ChangeFeed.Enabled {
+		return iterkit.Error[pubsub.Message[crud.Change[ENT, ID]]](fmt.Errorf("change feed is not enabled for the %s table", r.Mapping.TableName))
+	}
+	if err := ctx.Err(); err != nil {
+		return iterkit.Error[pubsub.Message[crud.Change[ENT, ID]]](err)
+	}
+	// the position is taken before returning the iterator,
+	// so changes made right after SubscribeChanges returns are not missed.
+	var xmin int64
+	if err := r.Connection.QueryRowContext(ctx, queryChangeFeedHead).Scan(&xmin); err != nil {
+		return iterkit.Error[pubsub.Message[crud.Change[ENT, ID]]](err)
+	}
+	var pos = changeFeedPosition{TxID: xmin - 1, ID: math.MaxInt64}
+	return func(yield func(pubsub.Message[crud.Change[ENT, ID]], error) bool) {
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			change, next, found, err := r.nextChange(ctx, pos)
+			if err != nil {
+				if ctx.Err() != nil {
+					err = ctx.Err()
+				}
+				yield(nil, err)
+				return
+			}
+			if !found {
+				select {
+				case <-ctx.Done():
+				case <-clock.After(r.getEmptyFeedBreakTime()):
+				}
+				continue
+			}
+			var acked bool
+			msg := pubsub.MakeMessage(ctx, change,
+				func(pubsub.Message[crud.Change[ENT, ID]]) error {
+					acked = true
+					return nil
+				},
+				func(pubsub.Message[crud.Change[ENT, ID]]) error {
+					return nil
+				})
+			if !yield(msg, nil) {
+				return
+			}
+			if acked { // otherwise the change is delivered again
+				pos = next
+			}
+		}
+	}
+}
+
+func (r Repository[ENT, ID]) nextChange(ctx context.Context, pos changeFeedPosition) (crud.Change[ENT, ID], changeFeedPosition, bool, error) {
+	var (
+		change crud.Change[ENT, ID]
+		next   changeFeedPosition
+		kind   string
+		id     []byte
+		data   []byte
+	)
+	row := r.Connection.QueryRowContext(ctx, queryChangeFeedNext, r.Mapping.TableName, pos.TxID, pos.ID)
+	if err := row.Scan(&next.ID, &next.TxID, &kind, &id, &data); err != nil {
+		if errors.Is(err, errNoRows) {
+			return change, pos, false, nil
+		}
+		return change, pos, false, err
+	}
+	change.Kind = crud.ChangeKind(kind)
+	if err := json.Unmarshal(id, &change.ID); err != nil {
+		return change, pos, false, err
+	}
+	if err := json.Unmarshal(data, &change.Entity); err != nil {
+		return change, pos, false, err
+	}
+	return change, next, true, nil
+}
+
+func (r Repository[ENT, ID]) getEmptyFeedBreakTime() time.Duration {
+	const defaultBreakTime = 42 * time.Millisecond
+	if r.ChangeFeed.EmptyFeedBreakTime == 0 {
+		return defaultBreakTime
+	}
+	return r.ChangeFeed.EmptyFeedBreakTime
+}
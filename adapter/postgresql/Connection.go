@@ -2,12 +2,15 @@ package postgresql
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.llib.dev/frameless/pkg/contextkit"
+	"go.llib.dev/frameless/pkg/errorkit"
 	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/frameless/port/comproto"
 )
 
 type Connection struct {
@@ -25,6 +28,49 @@ func Connect(dsn string) (Connection, error) {
 	return MakeConnectionFromPGXPool(pool), nil
 }
 
+// ConnectWithReplicas will connect to a primary PostgreSQL and to its read-only replicas.
+//
+// Outside of a transaction, the read-only queries, such as FindByID and FindAll, are routed to a healthy replica,
+// while the writes and everything within a transaction goes to the primary.
+func ConnectWithReplicas(primaryDSN string, replicaDSNs ...string) (Connection, error) {
+	primary, err := pgxpool.New(context.Background(), primaryDSN)
+	if err != nil {
+		return Connection{}, err
+	}
+	var replicas []*pgxpool.Pool
+	for _, dsn := range replicaDSNs {
+		replica, err := pgxpool.New(context.Background(), dsn)
+		if err != nil {
+			primary.Close()
+			for _, replica := range replicas {
+				replica.Close()
+			}
+			return Connection{}, err
+		}
+		replicas = append(replicas, replica)
+	}
+	return MakeConnectionFromPGXPools(primary, replicas...), nil
+}
+
+// MakeConnectionFromPGXPools makes a Connection that routes the read-only queries to the replica pools,
+// as long as they are not part of a transaction.
+// Closing the Connection closes every pool.
+func MakeConnectionFromPGXPools(primary *pgxpool.Pool, replicas ...*pgxpool.Pool) Connection {
+	c := MakeConnectionFromPGXPool(primary)
+	if len(replicas) == 0 {
+		return c
+	}
+	c.Replicas = &flsql.ReplicaSet[pgxpool.Pool]{Replicas: replicas}
+	c.OnClose = func() error {
+		primary.Close()
+		for _, replica := range replicas {
+			replica.Close()
+		}
+		return nil
+	}
+	return c
+}
+
 func MakeConnectionFromPGXPool(pool *pgxpool.Pool) Connection {
 	return Connection{
 		ConnectionAdapter: flsql.ConnectionAdapter[pgxpool.Pool, pgx.Tx]{
@@ -61,14 +107,62 @@ func MakeConnectionFromPGXPool(pool *pgxpool.Pool) Connection {
 				return (*tx).Rollback(ctx)
 			},
 
+			Prepare: func(ctx context.Context, tx *pgx.Tx, txID string) error {
+				_, err := (*tx).Exec(ctx, "PREPARE TRANSACTION "+quoteLiteral(txID))
+				return err
+			},
+
+			// After PREPARE TRANSACTION, the session is no longer in a transaction,
+			// so the prepared transaction is finished on the same connection,
+			// then the pgx.Tx is closed to release its connection.
+			CommitPrepared: func(ctx context.Context, tx *pgx.Tx, txID string) error {
+				_, err := (*tx).Exec(ctx, "COMMIT PREPARED "+quoteLiteral(txID))
+				return errorkit.Merge(err, (*tx).Commit(ctx))
+			},
+
+			RollbackPrepared: func(ctx context.Context, tx *pgx.Tx, txID string) error {
+				_, err := (*tx).Exec(ctx, "ROLLBACK PREPARED "+quoteLiteral(txID))
+				return errorkit.Merge(err, (*tx).Rollback(ctx))
+			},
+
 			OnClose: func() error {
 				pool.Close()
 				return nil
 			},
+
+			ErrNoRows: errNoRows,
 		},
 	}
 }
 
+var _ comproto.TwoPhaseCommitRecoverer = Connection{}
+
+// CommitPreparedTx commits a prepared transaction by its transaction ID.
+// It is meant to finish the transactions that were prepared by a process that no longer runs.
+//
+// Prepared transactions require the max_prepared_transactions server setting to be greater than zero.
+func (c Connection) CommitPreparedTx(ctx context.Context, txID string) error {
+	return c.finishPreparedTx(ctx, "COMMIT PREPARED "+quoteLiteral(txID))
+}
+
+// RollbackPreparedTx rolls back a prepared transaction by its transaction ID.
+// It is meant to finish the transactions that were prepared by a process that no longer runs.
+func (c Connection) RollbackPreparedTx(ctx context.Context, txID string) error {
+	return c.finishPreparedTx(ctx, "ROLLBACK PREPARED "+quoteLiteral(txID))
+}
+
+func (c Connection) finishPreparedTx(ctx context.Context, query string) error {
+	if _, ok := c.LookupTx(ctx); ok {
+		return fmt.Errorf("prepared transaction can't be finished within a transaction")
+	}
+	const UndefinedObjectErrorCode = "42704"
+	_, err := c.ExecContext(ctx, query)
+	if pgErr, ok := errorkit.As[*pgconn.PgError](err); ok && pgErr.Code == UndefinedObjectErrorCode {
+		return comproto.ErrPreparedTxNotFound
+	}
+	return err
+}
+
 var ContextTxOptions contextkit.ValueHandler[ctxKeyTxOptions, pgx.TxOptions]
 
 type ctxKeyTxOptions struct{}
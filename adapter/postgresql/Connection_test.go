@@ -10,8 +10,12 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.llib.dev/frameless/adapter/postgresql"
+	"go.llib.dev/frameless/pkg/flsql"
 	"go.llib.dev/frameless/pkg/reflectkit"
+	"go.llib.dev/frameless/port/comproto"
+	"go.llib.dev/frameless/port/comproto/comprotocontract"
 	"go.llib.dev/frameless/port/crud/crudcontract"
+	"go.llib.dev/frameless/port/crud/crudtest"
 	"go.llib.dev/testcase"
 	"go.llib.dev/testcase/assert"
 	"go.llib.dev/testcase/random"
@@ -31,6 +35,33 @@ func TestConnect_smoke(t *testing.T) {
 	assert.NoError(t, c.QueryRowContext(ctxWithTx, "SELECT").Scan())
 }
 
+func TestConnectWithReplicas(t *testing.T) {
+	ctx := context.Background()
+	c, err := postgresql.ConnectWithReplicas(DatabaseURL(t), DatabaseURL(t))
+	assert.NoError(t, err)
+	defer c.Close()
+
+	var n int
+	assert.NoError(t, c.QueryRowContext(flsql.ReadOnly(ctx), "SELECT 1").Scan(&n))
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 1, c.Replicas.Healthy())
+
+	t.Run("an unreachable replica fails over to the primary", func(t *testing.T) {
+		c, err := postgresql.ConnectWithReplicas(DatabaseURL(t), "postgres://frameless@127.0.0.1:1/frameless?connect_timeout=1")
+		assert.NoError(t, err)
+		defer c.Close()
+
+		var n int
+		assert.NoError(t, c.QueryRowContext(flsql.ReadOnly(ctx), "SELECT 1").Scan(&n))
+		assert.Equal(t, 1, n)
+		assert.Equal(t, 0, c.Replicas.Healthy())
+
+		rows, err := c.QueryContext(flsql.ReadOnly(ctx), "SELECT 1")
+		assert.NoError(t, err)
+		rows.Close()
+	})
+}
+
 func TestConnect_libpq(t *testing.T) {
 	testcase.UnsetEnv(t, "DATABASE_URL")
 	testcase.UnsetEnv(t, "PG_DATABASE_DSN")
@@ -127,6 +158,32 @@ func TestConnection_OnePhaseCommitProtocolContract(t *testing.T) {
 	crudcontract.OnePhaseCommitProtocol[Entity, string](repo, repo.Connection).Test(t)
 }
 
+var _ comproto.TwoPhaseCommitProtocol = postgresql.Connection{}
+
+func TestConnection_TwoPhaseCommitProtocolContract(t *testing.T) {
+	comprotocontract.TwoPhaseCommitProtocol(GetConnection(t)).Test(t)
+}
+
+func TestConnection_PrepareTx(t *testing.T) {
+	repo := NewEntityRepository(t)
+	ctx := context.Background()
+
+	tx, err := repo.BeginTx(ctx)
+	assert.NoError(t, err)
+	ent := MakeEntityFunc(t)()
+	crudtest.Create[Entity, string](t, repo, tx, &ent)
+
+	txID := random.New(random.CryptoSeed{}).UUID()
+	tx, err = repo.Connection.PrepareTx(comproto.ContextWithTxID(tx, txID))
+	assert.NoError(t, err)
+	crudtest.IsAbsent[Entity, string](t, repo, ctx, ent.ID)
+
+	t.Log("the prepared transaction can be committed from a new session, as it outlives its connection")
+	assert.NoError(t, repo.Connection.CommitPreparedTx(ctx, txID))
+	crudtest.IsPresent[Entity, string](t, repo, ctx, ent.ID)
+	_ = repo.Connection.RollbackTx(tx)
+}
+
 func Test_createSQLRowWithErr(t *testing.T) {
 	err := errors.New("boom")
 	var r sql.Row
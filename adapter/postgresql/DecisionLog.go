@@ -0,0 +1,133 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.llib.dev/frameless/pkg/errorkit"
+	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/logger"
+	"go.llib.dev/frameless/pkg/logging"
+	"go.llib.dev/frameless/pkg/txkit"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/migration"
+)
+
+// DecisionLogRepository is a durable txkit.DecisionLog for the txkit.Coordinator.
+//
+// The Connection of the DecisionLogRepository can be the same as a participant's Connection,
+// since the Coordinator accesses the log outside the coordinated transaction.
+type DecisionLogRepository struct {
+	Connection Connection
+}
+
+var _ txkit.DecisionLog = DecisionLogRepository{}
+
+const decisionLogTableName = "frameless_tx_decisions"
+
+const queryCreateDecisionLogTable = `
+CREATE TABLE IF NOT EXISTS ` + decisionLogTableName + ` (
+	tx_id        TEXT PRIMARY KEY,
+	outcome      TEXT NOT NULL,
+	participants JSON NOT NULL,
+	created_at   TIMESTAMP WITH TIME ZONE NOT NULL
+);
+`
+
+func (r DecisionLogRepository) Migrate(ctx context.Context) error {
+	return MakeMigrator(r.Connection, decisionLogTableName, migration.Steps[Connection]{
+		"0": flsql.MigrationStep[Connection]{UpQuery: queryCreateDecisionLogTable},
+	}).Migrate(ctx)
+}
+
+func (r DecisionLogRepository) Create(ctx context.Context, ptr *txkit.Decision) error {
+	if ptr == nil {
+		return fmt.Errorf("nil entity pointer given to %T#Create", r)
+	}
+	if ptr.TxID == "" {
+		return fmt.Errorf("missing transaction ID")
+	}
+	participants, err := json.Marshal(ptr.Participants)
+	if err != nil {
+		return err
+	}
+
+	const query = `INSERT INTO ` + decisionLogTableName + ` (tx_id, outcome, participants, created_at) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`
+
+	logger.Debug(ctx, "postgresql.DecisionLogRepository#Create", logging.Field("query", query))
+
+	result, err := r.Connection.ExecContext(ctx, query, ptr.TxID, string(ptr.Outcome), participants, ptr.CreatedAt.UTC())
+	if err != nil {
+		return err
+	}
+	if count, err := result.RowsAffected(); err != nil {
+		return err
+	} else if count == 0 {
+		err := crud.ErrAlreadyExists.F(`transaction decision already exists with id: %v`, ptr.TxID)
+		return errorkit.WithContext(err, ctx)
+	}
+	return nil
+}
+
+func (r DecisionLogRepository) Update(ctx context.Context, ptr *txkit.Decision) error {
+	if ptr == nil {
+		return fmt.Errorf("nil entity pointer given to %T#Update", r)
+	}
+	participants, err := json.Marshal(ptr.Participants)
+	if err != nil {
+		return err
+	}
+
+	const query = `UPDATE ` + decisionLogTableName + ` SET outcome = $2, participants = $3, created_at = $4 WHERE tx_id = $1`
+
+	logger.Debug(ctx, "postgresql.DecisionLogRepository#Update", logging.Field("query", query))
+
+	result, err := r.Connection.ExecContext(ctx, query, ptr.TxID, string(ptr.Outcome), participants, ptr.CreatedAt.UTC())
+	if err != nil {
+		return err
+	}
+	if count, err := result.RowsAffected(); err != nil {
+		return err
+	} else if count == 0 {
+		return crud.ErrNotFound
+	}
+	return nil
+}
+
+func (r DecisionLogRepository) FindAll(ctx context.Context) iterkit.SeqE[txkit.Decision] {
+	const query = `SELECT tx_id, outcome, participants, created_at FROM ` + decisionLogTableName + ` ORDER BY created_at, tx_id`
+	return flsql.QueryMany(r.Connection, ctx, func(s flsql.Scanner) (txkit.Decision, error) {
+		var (
+			d            txkit.Decision
+			outcome      string
+			participants []byte
+		)
+		if err := s.Scan(&d.TxID, &outcome, &participants, &d.CreatedAt); err != nil {
+			return d, err
+		}
+		d.Outcome = txkit.DecisionOutcome(outcome)
+		if err := json.Unmarshal(participants, &d.Participants); err != nil {
+			return d, err
+		}
+		return d, nil
+	}, query)
+}
+
+func (r DecisionLogRepository) DeleteByID(ctx context.Context, txID string) error {
+	const query = `DELETE FROM ` + decisionLogTableName + ` WHERE tx_id = $1`
+
+	logger.Debug(ctx, "postgresql.DecisionLogRepository#DeleteByID", logging.Field("query", query))
+
+	result, err := r.Connection.ExecContext(ctx, query, txID)
+	if err != nil {
+		return err
+	}
+	if count, err := result.RowsAffected(); err != nil {
+		return err
+	} else if count == 0 {
+		return crud.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package postgresql_test
+
+import (
+	"context"
+	"testing"
+
+	"go.llib.dev/frameless/adapter/memory"
+	"go.llib.dev/frameless/adapter/postgresql"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/txkit"
+	"go.llib.dev/frameless/port/comproto"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/crudtest"
+	"go.llib.dev/frameless/port/migration"
+	"go.llib.dev/frameless/port/pubsub/pubsubtest"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/random"
+)
+
+var _ migration.Migratable = postgresql.DecisionLogRepository{}
+
+func TestDecisionLogRepository(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		rnd  = random.New(random.CryptoSeed{})
+		repo = postgresql.DecisionLogRepository{Connection: GetConnection(t)}
+	)
+	assert.NoError(t, repo.Migrate(ctx))
+
+	d := txkit.Decision{
+		TxID:         rnd.UUID(),
+		Outcome:      txkit.DecisionPending,
+		Participants: []string{"a", "b"},
+		CreatedAt:    rnd.Time().UTC(),
+	}
+	assert.NoError(t, repo.Create(ctx, &d))
+	t.Cleanup(func() { _ = repo.DeleteByID(ctx, d.TxID) })
+	assert.ErrorIs(t, crud.ErrAlreadyExists, repo.Create(ctx, &d))
+
+	d.Outcome = txkit.DecisionCommit
+	assert.NoError(t, repo.Update(ctx, &d))
+
+	ds, err := iterkit.CollectE(repo.FindAll(ctx))
+	assert.NoError(t, err)
+	assert.OneOf(t, ds, func(t testing.TB, got txkit.Decision) {
+		assert.Equal(t, d.TxID, got.TxID)
+		assert.Equal(t, d.Outcome, got.Outcome)
+		assert.Equal(t, d.Participants, got.Participants)
+		assert.True(t, d.CreatedAt.Equal(got.CreatedAt))
+	})
+
+	assert.NoError(t, repo.DeleteByID(ctx, d.TxID))
+	assert.ErrorIs(t, crud.ErrNotFound, repo.DeleteByID(ctx, d.TxID))
+	assert.ErrorIs(t, crud.ErrNotFound, repo.Update(ctx, &d))
+}
+
+func TestCoordinator_withConnectionAndQueue(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		repo  = NewEntityRepository(t)
+		queue = &memory.Queue[Entity]{}
+		log   = postgresql.DecisionLogRepository{Connection: repo.Connection}
+	)
+	assert.NoError(t, log.Migrate(ctx))
+
+	coordinator := txkit.Coordinator{
+		Participants: map[string]comproto.TwoPhaseCommitProtocol{
+			"db":    repo.Connection,
+			"queue": queue,
+		},
+		DecisionLog: log,
+	}
+	assert.NoError(t, coordinator.Recover(ctx))
+	sub := pubsubtest.Subscribe[Entity](t, queue, ctx)
+
+	tx, err := coordinator.BeginTx(ctx)
+	assert.NoError(t, err)
+	ent := MakeEntityFunc(t)()
+	crudtest.Create[Entity, string](t, repo, tx, &ent)
+	assert.NoError(t, queue.Publish(tx, ent))
+	assert.NoError(t, coordinator.CommitTx(tx))
+
+	crudtest.IsPresent[Entity, string](t, repo, ctx, ent.ID)
+	sub.Eventually(t, func(tb testing.TB, got []Entity) {
+		assert.Equal(tb, []Entity{ent}, got)
+	})
+	ds, err := iterkit.CollectE(log.FindAll(ctx))
+	assert.NoError(t, err)
+	assert.Empty(t, ds)
+}
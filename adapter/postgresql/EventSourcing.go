@@ -0,0 +1,244 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+
+	"go.llib.dev/frameless/pkg/eventsourcing"
+	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/frameless/pkg/logger"
+	"go.llib.dev/frameless/pkg/logging"
+	"go.llib.dev/frameless/port/comproto"
+	"go.llib.dev/frameless/port/migration"
+	"go.llib.dev/testcase/clock"
+)
+
+// EventStore is an eventsourcing.EventStore that stores the records in a shared event table.
+// It also keeps the checkpoints of the projections, as an eventsourcing.CheckpointStore.
+//
+// The appends of an EventStore are serialised with a transaction level advisory lock,
+// thus the records are committed in the order of their positions.
+// A long-running transaction that appended to the EventStore delays the other appends until it finishes.
+//
+// The events are stored as JSON, thus E must be serialisable with the encoding/json package.
+type EventStore[E any] struct {
+	// Name separates the records of different event stores in the event table.
+	Name       string
+	Connection Connection
+}
+
+var (
+	_ eventsourcing.EventStore[any]   = EventStore[any]{}
+	_ eventsourcing.CheckpointStore   = EventStore[any]{}
+	_ comproto.OnePhaseCommitProtocol = EventStore[any]{}
+)
+
+const (
+	eventStoreTableName           = "frameless_eventsourcing_events"
+	eventStoreCheckpointTableName = "frameless_eventsourcing_checkpoints"
+)
+
+const queryCreateEventStoreTables = `
+CREATE TABLE IF NOT EXISTS ` + eventStoreTableName + ` (
+	store       TEXT NOT NULL,
+	stream_id   TEXT NOT NULL,
+	version     BIGINT NOT NULL,
+	position    BIGSERIAL NOT NULL,
+	event       JSON NOT NULL,
+	recorded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	PRIMARY KEY (store, stream_id, version)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS ` + eventStoreTableName + `_position_idx ON ` + eventStoreTableName + ` (store, position);
+CREATE TABLE IF NOT EXISTS ` + eventStoreCheckpointTableName + ` (
+	store      TEXT NOT NULL,
+	projection TEXT NOT NULL,
+	position   BIGINT NOT NULL,
+	PRIMARY KEY (store, projection)
+);
+`
+
+func (s EventStore[E]) Migrate(ctx context.Context) error {
+	return MakeMigrator(s.Connection, eventStoreTableName, migration.Steps[Connection]{
+		"0": flsql.MigrationStep[Connection]{UpQuery: queryCreateEventStoreTables},
+	}).Migrate(ctx)
+}
+
+func (s EventStore[E]) Append(ctx context.Context, streamID eventsourcing.StreamID, expected eventsourcing.Version, events ...E) (rErr error) {
+	if s.Name == "" {
+		return fmt.Errorf("missing event store name")
+	}
+	ctx, err := s.Connection.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer comproto.FinishOnePhaseCommit(&rErr, s.Connection, ctx)
+
+	const queryLock = `SELECT pg_advisory_xact_lock(hashtext($1))`
+	if _, err := s.Connection.ExecContext(ctx, queryLock, eventStoreTableName+":"+s.Name); err != nil {
+		return err
+	}
+
+	const queryVersion = `SELECT COALESCE(MAX(version), 0) FROM ` + eventStoreTableName + ` WHERE store = $1 AND stream_id = $2`
+	var current eventsourcing.Version
+	if err := s.Connection.QueryRowContext(ctx, queryVersion, s.Name, string(streamID)).Scan(&current); err != nil {
+		return err
+	}
+	if current != expected {
+		return eventsourcing.ErrConcurrencyConflict
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	var (
+		nextPH       = makePrepareStatementPlaceholderGenerator()
+		valuesClause []string
+		args         []any
+		now          = clock.Now().UTC()
+	)
+	for i, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		valuesClause = append(valuesClause, fmt.Sprintf("(%s, %s, %s, %s, %s)", nextPH(), nextPH(), nextPH(), nextPH(), nextPH()))
+		args = append(args, s.Name, string(streamID), int64(expected)+int64(i+1), data, now)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (store, stream_id, version, event, recorded_at)\nVALUES %s",
+		eventStoreTableName, strings.Join(valuesClause, ",\n"))
+
+	logger.Debug(ctx, "postgresql.EventStore#Append", logging.Field("query", query))
+
+	_, err = s.Connection.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (s EventStore[E]) Load(ctx context.Context, streamID eventsourcing.StreamID, after eventsourcing.Version) iter.Seq2[eventsourcing.Record[E], error] {
+	const query = `SELECT stream_id, version, position, event, recorded_at FROM ` + eventStoreTableName + `
+WHERE store = $1 AND stream_id = $2 AND version > $3 ORDER BY version`
+	return flsql.QueryMany(s.Connection, ctx, s.scanRecord, query, s.Name, string(streamID), int64(after))
+}
+
+func (s EventStore[E]) LoadAll(ctx context.Context, after eventsourcing.Position) iter.Seq2[eventsourcing.Record[E], error] {
+	const query = `SELECT stream_id, version, position, event, recorded_at FROM ` + eventStoreTableName + `
+WHERE store = $1 AND position > $2 ORDER BY position`
+	return flsql.QueryMany(s.Connection, ctx, s.scanRecord, query, s.Name, int64(after))
+}
+
+func (s EventStore[E]) scanRecord(scanner flsql.Scanner) (eventsourcing.Record[E], error) {
+	var (
+		rec  eventsourcing.Record[E]
+		data []byte
+	)
+	if err := scanner.Scan(&rec.StreamID, &rec.Version, &rec.Position, &data, &rec.RecordedAt); err != nil {
+		return rec, err
+	}
+	if err := json.Unmarshal(data, &rec.Event); err != nil {
+		return rec, err
+	}
+	return rec, nil
+}
+
+func (s EventStore[E]) LoadCheckpoint(ctx context.Context, projection string) (eventsourcing.Position, error) {
+	const query = `SELECT position FROM ` + eventStoreCheckpointTableName + ` WHERE store = $1 AND projection = $2`
+	var position eventsourcing.Position
+	err := s.Connection.QueryRowContext(ctx, query, s.Name, projection).Scan(&position)
+	if errors.Is(err, errNoRows) {
+		return 0, nil
+	}
+	return position, err
+}
+
+func (s EventStore[E]) SaveCheckpoint(ctx context.Context, projection string, position eventsourcing.Position) error {
+	const query = `INSERT INTO ` + eventStoreCheckpointTableName + ` (store, projection, position) VALUES ($1, $2, $3)
+ON CONFLICT (store, projection) DO UPDATE SET position = EXCLUDED.position`
+
+	logger.Debug(ctx, "postgresql.EventStore#SaveCheckpoint", logging.Field("query", query))
+
+	_, err := s.Connection.ExecContext(ctx, query, s.Name, projection, int64(position))
+	return err
+}
+
+func (s EventStore[E]) BeginTx(ctx context.Context) (context.Context, error) {
+	return s.Connection.BeginTx(ctx)
+}
+
+func (s EventStore[E]) CommitTx(ctx context.Context) error {
+	return s.Connection.CommitTx(ctx)
+}
+
+func (s EventStore[E]) RollbackTx(ctx context.Context) error {
+	return s.Connection.RollbackTx(ctx)
+}
+
+// SnapshotStore is an eventsourcing.SnapshotStore that stores the snapshots in a shared snapshot table.
+//
+// The states are stored as JSON, thus S must be serialisable with the encoding/json package.
+type SnapshotStore[S any] struct {
+	// Name separates the snapshots of different snapshot stores in the snapshot table.
+	Name       string
+	Connection Connection
+}
+
+var _ eventsourcing.SnapshotStore[any] = SnapshotStore[any]{}
+
+const snapshotStoreTableName = "frameless_eventsourcing_snapshots"
+
+const queryCreateSnapshotStoreTable = `
+CREATE TABLE IF NOT EXISTS ` + snapshotStoreTableName + ` (
+	store     TEXT NOT NULL,
+	stream_id TEXT NOT NULL,
+	version   BIGINT NOT NULL,
+	state     JSON NOT NULL,
+	taken_at  TIMESTAMP WITH TIME ZONE NOT NULL,
+	PRIMARY KEY (store, stream_id)
+);
+`
+
+func (s SnapshotStore[S]) Migrate(ctx context.Context) error {
+	return MakeMigrator(s.Connection, snapshotStoreTableName, migration.Steps[Connection]{
+		"0": flsql.MigrationStep[Connection]{UpQuery: queryCreateSnapshotStoreTable},
+	}).Migrate(ctx)
+}
+
+func (s SnapshotStore[S]) SaveSnapshot(ctx context.Context, snapshot eventsourcing.Snapshot[S]) error {
+	if s.Name == "" {
+		return fmt.Errorf("missing snapshot store name")
+	}
+	state, err := json.Marshal(snapshot.State)
+	if err != nil {
+		return err
+	}
+
+	const query = `INSERT INTO ` + snapshotStoreTableName + ` (store, stream_id, version, state, taken_at) VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (store, stream_id) DO UPDATE SET version = EXCLUDED.version, state = EXCLUDED.state, taken_at = EXCLUDED.taken_at`
+
+	logger.Debug(ctx, "postgresql.SnapshotStore#SaveSnapshot", logging.Field("query", query))
+
+	_, err = s.Connection.ExecContext(ctx, query, s.Name, string(snapshot.StreamID), int64(snapshot.Version), state, snapshot.TakenAt.UTC())
+	return err
+}
+
+func (s SnapshotStore[S]) LoadSnapshot(ctx context.Context, streamID eventsourcing.StreamID) (eventsourcing.Snapshot[S], bool, error) {
+	const query = `SELECT stream_id, version, state, taken_at FROM ` + snapshotStoreTableName + ` WHERE store = $1 AND stream_id = $2`
+	var (
+		snapshot eventsourcing.Snapshot[S]
+		state    []byte
+	)
+	err := s.Connection.QueryRowContext(ctx, query, s.Name, string(streamID)).
+		Scan(&snapshot.StreamID, &snapshot.Version, &state, &snapshot.TakenAt)
+	if errors.Is(err, errNoRows) {
+		return snapshot, false, nil
+	}
+	if err != nil {
+		return snapshot, false, err
+	}
+	if err := json.Unmarshal(state, &snapshot.State); err != nil {
+		return snapshot, false, err
+	}
+	return snapshot, true, nil
+}
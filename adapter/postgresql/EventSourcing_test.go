@@ -0,0 +1,52 @@
+package postgresql_test
+
+import (
+	"testing"
+
+	"go.llib.dev/frameless/adapter/postgresql"
+	"go.llib.dev/frameless/pkg/eventsourcing/eventsourcingcontract"
+	"go.llib.dev/frameless/port/migration"
+	"go.llib.dev/frameless/testing/testent"
+	"go.llib.dev/testcase/assert"
+)
+
+var (
+	_ migration.Migratable = postgresql.EventStore[testent.Foo]{}
+	_ migration.Migratable = postgresql.SnapshotStore[testent.Foo]{}
+)
+
+func TestEventStore(t *testing.T) {
+	cm := GetConnection(t)
+
+	store := postgresql.EventStore[testent.Foo]{
+		Name:       "test_event_store",
+		Connection: cm,
+	}
+	assert.NoError(t, store.Migrate(MakeContext(t)))
+
+	eventsourcingcontract.EventStore[testent.Foo](store,
+		eventsourcingcontract.Config[testent.Foo]{
+			MakeContext:    MakeContext,
+			MakeValue:      testent.MakeFoo,
+			OnePhaseCommit: cm,
+		},
+	).Test(t)
+}
+
+func TestSnapshotStore(t *testing.T) {
+	cm := GetConnection(t)
+
+	store := postgresql.SnapshotStore[testent.Foo]{
+		Name:       "test_snapshot_store",
+		Connection: cm,
+	}
+	assert.NoError(t, store.Migrate(MakeContext(t)))
+
+	eventsourcingcontract.SnapshotStore[testent.Foo](store,
+		eventsourcingcontract.Config[testent.Foo]{
+			MakeContext:    MakeContext,
+			MakeValue:      testent.MakeFoo,
+			OnePhaseCommit: cm,
+		},
+	).Test(t)
+}
@@ -0,0 +1,114 @@
+package postgresql
+
+import (
+	"context"
+
+	"go.llib.dev/frameless/pkg/dtokit"
+	"go.llib.dev/frameless/pkg/slicekit"
+	"go.llib.dev/frameless/port/comproto"
+	"go.llib.dev/frameless/port/pubsub"
+)
+
+// TopicExchange delivers messages to the Queue-s whose binding pattern matches the routing key of the message.
+// The routing key is a string of dot-separated words,
+// and the binding pattern can use "*" to match a single word, or "#" to match zero or more words.
+// For the details, see pubsub.MatchTopic.
+//
+// The bindings are part of the exchange's configuration,
+// thus every publisher of the exchange should use the same Bindings.
+type TopicExchange[Entity, JSONDTO any] struct {
+	Connection Connection
+	Mapping    dtokit.MapperTo[Entity, JSONDTO]
+	// Bindings are the names of the queues that are bound to the exchange with their routing key pattern.
+	Bindings []TopicBinding
+}
+
+type TopicBinding struct {
+	Pattern string
+	// Queue is the name of the bound Queue.
+	Queue string
+}
+
+var _ pubsub.Publisher[pubsub.Envelope[any]] = &TopicExchange[any, any]{}
+
+// Publish will publish the data to all queues with a matching binding in a single transaction.
+// A queue receives the message only once, even if multiple of its bindings match.
+// A message without a matching binding is dropped.
+// The headers of the envelope are published as the headers of the message.
+func (e *TopicExchange[Entity, JSONDTO]) Publish(ctx context.Context, env pubsub.Envelope[Entity]) error {
+	var queues []string
+	for _, b := range e.Bindings {
+		if pubsub.MatchTopic(b.Pattern, env.RoutingKey) {
+			queues = append(queues, b.Queue)
+		}
+	}
+	return publishToQueues(pubsub.ContextWithHeaders(ctx, env.Headers), e.Connection, e.Mapping, queues, env.Data)
+}
+
+// MakeQueue binds a Queue with the given name to the exchange with the routing key pattern.
+func (e *TopicExchange[Entity, JSONDTO]) MakeQueue(name, pattern string) Queue[Entity, JSONDTO] {
+	e.Bindings = append(e.Bindings, TopicBinding{Pattern: pattern, Queue: name})
+	return Queue[Entity, JSONDTO]{Name: name, Connection: e.Connection, Mapping: e.Mapping}
+}
+
+// HeadersExchange delivers messages to the Queue-s whose headers binding matches the headers of the message.
+// For the details, see pubsub.HeadersBinding.
+//
+// The bindings are part of the exchange's configuration,
+// thus every publisher of the exchange should use the same Bindings.
+type HeadersExchange[Entity, JSONDTO any] struct {
+	Connection Connection
+	Mapping    dtokit.MapperTo[Entity, JSONDTO]
+	// Bindings are the names of the queues that are bound to the exchange with their headers binding.
+	Bindings []HeadersBinding
+}
+
+type HeadersBinding struct {
+	pubsub.HeadersBinding
+	// Queue is the name of the bound Queue.
+	Queue string
+}
+
+var _ pubsub.Publisher[pubsub.Envelope[any]] = &HeadersExchange[any, any]{}
+
+// Publish will publish the data to all queues with a matching binding in a single transaction.
+// A queue receives the message only once, even if multiple of its bindings match.
+// A message without a matching binding is dropped.
+// The headers of the envelope are published as the headers of the message.
+func (e *HeadersExchange[Entity, JSONDTO]) Publish(ctx context.Context, env pubsub.Envelope[Entity]) error {
+	var queues []string
+	for _, b := range e.Bindings {
+		if b.Matches(env.Headers) {
+			queues = append(queues, b.Queue)
+		}
+	}
+	return publishToQueues(pubsub.ContextWithHeaders(ctx, env.Headers), e.Connection, e.Mapping, queues, env.Data)
+}
+
+// MakeQueue binds a Queue with the given name to the exchange with the headers binding.
+func (e *HeadersExchange[Entity, JSONDTO]) MakeQueue(name string, binding pubsub.HeadersBinding) Queue[Entity, JSONDTO] {
+	e.Bindings = append(e.Bindings, HeadersBinding{HeadersBinding: binding, Queue: name})
+	return Queue[Entity, JSONDTO]{Name: name, Connection: e.Connection, Mapping: e.Mapping}
+}
+
+func publishToQueues[Entity, JSONDTO any](ctx context.Context, conn Connection, mapping dtokit.MapperTo[Entity, JSONDTO], queues []string, data Entity) (rErr error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	queues = slicekit.Unique(queues)
+	if len(queues) == 0 {
+		return nil
+	}
+	ctx, err := conn.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer comproto.FinishOnePhaseCommit(&rErr, conn, ctx)
+	for _, name := range queues {
+		q := Queue[Entity, JSONDTO]{Name: name, Connection: conn, Mapping: mapping}
+		if err := q.Publish(ctx, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/logger"
+	"go.llib.dev/frameless/pkg/logging"
+	"go.llib.dev/frameless/pkg/mapkit"
+	"go.llib.dev/frameless/pkg/slicekit"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/migration"
+	"go.llib.dev/testcase/clock"
+)
+
+// HistoryConfig configures the crud.TemporalFinder support of the Repository.
+//
+// When the history is enabled, every mutation of the Repository records the new state of the affected rows
+// into a history table, within the same transaction as the mutation itself.
+// Each history row has a validity period, from the time of the change until the next change of the same entity.
+//
+// Only the changes made after enabling the history are known,
+// thus entities which were not changed since then are absent from the past views.
+type HistoryConfig struct {
+	// Enabled flag will make the Repository record the states of its entities into the history table.
+	// Use Repository.MigrateHistory to create the history table.
+	Enabled bool
+	// TableName [optional] is the name of the history table.
+	//
+	// default: <Mapping.TableName>_history
+	TableName string
+}
+
+const (
+	historyValidFromColumn = "valid_from"
+	historyValidToColumn   = "valid_to"
+)
+
+// MigrateHistory creates the history table, which has the columns of the Repository's table and the validity period.
+// The Repository's table must exist before MigrateHistory.
+func (r Repository[ENT, ID]) MigrateHistory(ctx context.Context) error {
+	var (
+		table   = r.historyTableIdentifier().Sanitize()
+		idxName = pgx.Identifier{r.historyTableName() + "_entity_idx"}.Sanitize()
+	)
+	idCols, err := r.idColumns()
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n\tLIKE %s,\n\t%q TIMESTAMP WITH TIME ZONE NOT NULL,\n\t%q TIMESTAMP WITH TIME ZONE\n);\n",
+		table, r.tableIdentifier().Sanitize(), historyValidFromColumn, historyValidToColumn)
+	query += fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s, %q);\n",
+		idxName, table, r.quotedColumnsClause(idCols), historyValidFromColumn)
+	return MakeMigrator(r.Connection, r.historyTableName(), migration.Steps[Connection]{
+		"0": flsql.MigrationStep[Connection]{UpQuery: query},
+	}).Migrate(ctx)
+}
+
+// recordHistory closes the validity period of the entities' current states in the history table,
+// and unless they are deleted, it records their new states.
+// It should be called within the transaction of the mutation.
+func (r Repository[ENT, ID]) recordHistory(ctx context.Context, kind crud.ChangeKind, ents ...ENT) error {
+	if !r.History.Enabled || len(ents) == 0 {
+		return nil
+	}
+	var (
+		table = r.historyTableIdentifier().Sanitize()
+		now   = clock.Now().UTC()
+		ids   = make([]ID, 0, len(ents))
+	)
+	for _, ent := range ents {
+		ids = append(ids, r.Mapping.ID.Get(ent))
+	}
+
+	nextPH := makePrepareStatementPlaceholderGenerator()
+	validTo := nextPH()
+	idsWhere, idsArgs, err := r.Mapping.IDsWhere(ids, quoteColumn, nextPH)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("UPDATE %s SET %q = %s WHERE %s AND %q IS NULL",
+		table, historyValidToColumn, validTo, idsWhere, historyValidToColumn)
+
+	logger.Debug(ctx, "postgresql.Repository#recordHistory", logging.Field("query", query))
+
+	if _, err := r.Connection.ExecContext(ctx, query, append([]any{now}, idsArgs...)...); err != nil {
+		return err
+	}
+	if kind == crud.Deleted {
+		return nil
+	}
+
+	var (
+		columns      []flsql.ColumnName
+		states       []flsql.QueryArgs
+		valuesClause []string
+		args         []any
+	)
+	for _, ent := range ents {
+		state, err := r.Mapping.ToArgs(ent)
+		if err != nil {
+			return err
+		}
+		states = append(states, state)
+		columns = slicekit.Unique(append(columns, mapkit.Keys(state)...))
+	}
+	nextPH = makePrepareStatementPlaceholderGenerator()
+	for _, state := range states {
+		var valueClause []string
+		for _, col := range columns {
+			valueClause = append(valueClause, nextPH())
+			args = append(args, state[col])
+		}
+		valueClause = append(valueClause, nextPH())
+		args = append(args, now)
+		valuesClause = append(valuesClause, fmt.Sprintf("(%s)", strings.Join(valueClause, ", ")))
+	}
+	query = fmt.Sprintf("INSERT INTO %s (%s, %q)\nVALUES %s",
+		table, r.quotedColumnsClause(columns), historyValidFromColumn, strings.Join(valuesClause, ",\n"))
+
+	logger.Debug(ctx, "postgresql.Repository#recordHistory", logging.Field("query", query))
+
+	_, err = r.Connection.ExecContext(ctx, query, args...)
+	return err
+}
+
+// AsOf implements crud.TemporalFinder with the states recorded in the history table.
+func (r Repository[ENT, ID]) AsOf(ctx context.Context, at time.Time) (crud.Finder[ENT, ID], error) {
+	if !r.History.Enabled {
+		return nil, fmt.Errorf("history is not enabled for the %s table", r.Mapping.TableName)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return historyFinder[ENT, ID]{Repository: r, At: at.UTC()}, nil
+}
+
+type historyFinder[ENT, ID any] struct {
+	Repository Repository[ENT, ID]
+	At         time.Time
+}
+
+func (f historyFinder[ENT, ID]) FindByID(ctx context.Context, id ID) (ENT, bool, error) {
+	nextPH := makePrepareStatementPlaceholderGenerator()
+	query, args := f.query(ctx, nextPH)
+	whereClause, idArgs, err := f.Repository.idQuery(id, nextPH)
+	if err != nil {
+		return *new(ENT), false, fmt.Errorf("QueryID: %w", err)
+	}
+	query += " AND " + strings.Join(whereClause, " AND ")
+
+	logger.Debug(ctx, "postgresql.Repository#AsOf#FindByID", logging.Field("query", query))
+
+	_, scan := f.Repository.Mapping.ToQuery(ctx)
+	var v ENT
+	err = scan(&v, f.Repository.Connection.QueryRowContext(flsql.ReadOnly(ctx), query, append(args, idArgs...)...))
+	if errors.Is(err, errNoRows) {
+		return *new(ENT), false, nil
+	}
+	if err != nil {
+		return *new(ENT), false, err
+	}
+	return v, true, nil
+}
+
+func (f historyFinder[ENT, ID]) FindAll(ctx context.Context) iterkit.SeqE[ENT] {
+	query, args := f.query(ctx, makePrepareStatementPlaceholderGenerator())
+
+	logger.Debug(ctx, "postgresql.Repository#AsOf#FindAll", logging.Field("query", query))
+
+	_, scan := f.Repository.Mapping.ToQuery(ctx)
+	return flsql.QueryMany(f.Repository.Connection, flsql.ReadOnly(ctx), scan.Map, query, args...)
+}
+
+// query selects the states from the history table, which were valid at the time of the finder.
+func (f historyFinder[ENT, ID]) query(ctx context.Context, nextPH func() string) (string, []any) {
+	cols, _ := f.Repository.Mapping.ToQuery(ctx)
+	at := nextPH()
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %q <= %s AND (%q IS NULL OR %s < %q)",
+		f.Repository.quotedColumnsClause(cols), f.Repository.historyTableIdentifier().Sanitize(),
+		historyValidFromColumn, at, historyValidToColumn, at, historyValidToColumn)
+	return query, []any{f.At}
+}
+
+func (r Repository[ENT, ID]) historyTableName() string {
+	if r.History.TableName != "" {
+		return r.History.TableName
+	}
+	return r.Mapping.TableName + "_history"
+}
+
+func (r Repository[ENT, ID]) historyTableIdentifier() pgx.Identifier {
+	ident := strings.Split(r.historyTableName(), ".")
+	ident = slicekit.Map(ident, strings.TrimSpace)
+	return pgx.Identifier(ident)
+}
+
+func (r Repository[ENT, ID]) idColumns() ([]flsql.ColumnName, error) {
+	idArgs, err := r.Mapping.QueryID(*new(ID))
+	if err != nil {
+		return nil, err
+	}
+	cols, _ := flsql.SplitArgs(idArgs)
+	return cols, nil
+}
+
+func (r Repository[ENT, ID]) idQuery(id ID, nextPlaceholder func() string) (whereClause []string, queryArgs []any, _ error) {
+	idArgs, err := r.Mapping.QueryID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	cols, queryArgs := flsql.SplitArgs(idArgs)
+	for _, col := range cols {
+		whereClause = append(whereClause, fmt.Sprintf("%q = %s", col, nextPlaceholder()))
+	}
+	return whereClause, queryArgs, nil
+}
+
+func quoteColumn(c flsql.ColumnName) string {
+	return fmt.Sprintf("%q", c)
+}
+
+func (r Repository[ENT, ID]) quotedColumnsClause(cols []flsql.ColumnName) string {
+	return flsql.JoinColumnName(cols, "%q", ", ")
+}
@@ -0,0 +1,151 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.llib.dev/frameless/pkg/errorkit"
+	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/logger"
+	"go.llib.dev/frameless/pkg/logging"
+	"go.llib.dev/frameless/pkg/outbox"
+	"go.llib.dev/frameless/pkg/uuid"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/crudquery"
+	"go.llib.dev/frameless/port/migration"
+)
+
+// OutboxRepository is an outbox.Repository that stores the records in a shared outbox table.
+// Publishing into an outbox.Outbox backed by it, takes part in the transaction of the Connection.
+//
+// The Data is stored as JSON, thus it must be serialisable with the encoding/json package.
+type OutboxRepository[Data any] struct {
+	// Name separates the records of different outboxes in the outbox table.
+	Name       string
+	Connection Connection
+}
+
+var _ outbox.Repository[any] = OutboxRepository[any]{}
+var _ crud.Querier[outbox.Record[any]] = OutboxRepository[any]{}
+
+const outboxTableName = "frameless_outbox_records"
+
+const queryCreateOutboxTable = `
+CREATE TABLE IF NOT EXISTS ` + outboxTableName + ` (
+	outbox     TEXT NOT NULL,
+	id         TEXT NOT NULL,
+	data       JSON NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	PRIMARY KEY (outbox, id)
+);
+`
+
+func (r OutboxRepository[Data]) Migrate(ctx context.Context) error {
+	return MakeMigrator(r.Connection, outboxTableName, migration.Steps[Connection]{
+		"0": flsql.MigrationStep[Connection]{UpQuery: queryCreateOutboxTable},
+	}).Migrate(ctx)
+}
+
+func (r OutboxRepository[Data]) Create(ctx context.Context, ptr *outbox.Record[Data]) error {
+	if ptr == nil {
+		return fmt.Errorf("nil entity pointer given to %T#Create", r)
+	}
+	if r.Name == "" {
+		return fmt.Errorf("missing outbox name")
+	}
+	if ptr.ID == "" {
+		id, err := uuid.MakeV7()
+		if err != nil {
+			return err
+		}
+		ptr.ID = outbox.RecordID(id.String())
+	}
+	data, err := json.Marshal(ptr.Data)
+	if err != nil {
+		return err
+	}
+
+	// ON CONFLICT avoids aborting the caller's transaction on a duplicate record.
+	const query = `INSERT INTO ` + outboxTableName + ` (outbox, id, data, created_at) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`
+
+	logger.Debug(ctx, "postgresql.OutboxRepository#Create", logging.Field("query", query))
+
+	result, err := r.Connection.ExecContext(ctx, query, r.Name, string(ptr.ID), data, ptr.CreatedAt.UTC())
+	if err != nil {
+		return err
+	}
+	if count, err := result.RowsAffected(); err != nil {
+		return err
+	} else if count == 0 {
+		err := crud.ErrAlreadyExists.F(`outbox record already exists with id: %v`, ptr.ID)
+		return errorkit.WithContext(err, ctx)
+	}
+	return nil
+}
+
+func (r OutboxRepository[Data]) FindAll(ctx context.Context) iterkit.SeqE[outbox.Record[Data]] {
+	const query = `SELECT id, data, created_at FROM ` + outboxTableName + ` WHERE outbox = $1 ORDER BY created_at, id`
+	return flsql.QueryMany(r.Connection, ctx, r.scan, query, r.Name)
+}
+
+// Query implements crud.Querier, so the outbox.Relay can fetch the pending records in batches.
+// The records can be filtered and ordered by their ID and CreatedAt fields.
+func (r OutboxRepository[Data]) Query(ctx context.Context, q crudquery.Query) iterkit.SeqE[outbox.Record[Data]] {
+	placeholders := Dialect.Placeholders()
+	placeholders() // $1 is the name of the outbox
+	cq, err := flsql.QueryCompiler{
+		Columns:     []flsql.ColumnName{"id", "created_at"},
+		QuoteColumn: Dialect.QuoteColumn,
+		Placeholder: placeholders,
+	}.Compile(q)
+	if err != nil {
+		return iterkit.Error[outbox.Record[Data]](err)
+	}
+	query := `SELECT id, data, created_at FROM ` + outboxTableName + ` WHERE outbox = $1`
+	if cq.Where != "" {
+		query += ` AND (` + cq.Where + `)`
+	}
+	if cq.OrderBy != "" {
+		query += ` ORDER BY ` + cq.OrderBy
+	}
+	if 0 < q.Limit {
+		query += fmt.Sprintf(` LIMIT %d`, q.Limit)
+	}
+	if 0 < q.Offset {
+		query += fmt.Sprintf(` OFFSET %d`, q.Offset)
+	}
+	return flsql.QueryMany(r.Connection, ctx, r.scan, query, append([]any{r.Name}, cq.Args...)...)
+}
+
+func (r OutboxRepository[Data]) scan(s flsql.Scanner) (outbox.Record[Data], error) {
+	var (
+		rec  outbox.Record[Data]
+		data []byte
+	)
+	if err := s.Scan(&rec.ID, &data, &rec.CreatedAt); err != nil {
+		return rec, err
+	}
+	if err := json.Unmarshal(data, &rec.Data); err != nil {
+		return rec, err
+	}
+	return rec, nil
+}
+
+func (r OutboxRepository[Data]) DeleteByID(ctx context.Context, id outbox.RecordID) error {
+	const query = `DELETE FROM ` + outboxTableName + ` WHERE outbox = $1 AND id = $2`
+
+	logger.Debug(ctx, "postgresql.OutboxRepository#DeleteByID", logging.Field("query", query))
+
+	result, err := r.Connection.ExecContext(ctx, query, r.Name, string(id))
+	if err != nil {
+		return err
+	}
+	if count, err := result.RowsAffected(); err != nil {
+		return err
+	} else if count == 0 {
+		return crud.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package postgresql_test
+
+import (
+	"testing"
+
+	"go.llib.dev/frameless/adapter/postgresql"
+	"go.llib.dev/frameless/pkg/outbox/outboxcontract"
+	"go.llib.dev/frameless/port/migration"
+	"go.llib.dev/frameless/testing/testent"
+	"go.llib.dev/testcase/assert"
+)
+
+var _ migration.Migratable = postgresql.OutboxRepository[testent.Foo]{}
+
+func TestOutboxRepository(t *testing.T) {
+	cm := GetConnection(t)
+
+	repo := postgresql.OutboxRepository[testent.Foo]{
+		Name:       "test_outbox",
+		Connection: cm,
+	}
+	assert.NoError(t, repo.Migrate(MakeContext(t)))
+
+	outboxcontract.Repository[testent.Foo](repo,
+		outboxcontract.Config[testent.Foo]{
+			MakeContext:    MakeContext,
+			MakeData:       testent.MakeFoo,
+			OnePhaseCommit: cm,
+		},
+	).Test(t)
+}
@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"sort"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -13,6 +16,7 @@ import (
 	"go.llib.dev/frameless/pkg/errorkit"
 	"go.llib.dev/frameless/pkg/flsql"
 	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/port/comproto"
 	"go.llib.dev/frameless/port/migration"
 	"go.llib.dev/frameless/port/pubsub"
 	"go.llib.dev/testcase/clock"
@@ -31,6 +35,32 @@ type Queue[Entity, JSONDTO any] struct {
 
 	// LIFO flag will set the queue to use a Last in First out ordering
 	LIFO bool
+
+	// MaxDeliveryAttempts [optional] is how many times a message is delivered, before it is considered a poison message.
+	// A poison message is NACK-ed on its last delivery attempt, and instead of being redelivered,
+	// it is moved to the DeadLetterQueue.
+	//
+	// Without a VisibilityTimeout, only NACK-ed deliveries are counted,
+	// a delivery to a subscriber that crashed without a NACK is not.
+	//
+	// default: unlimited
+	MaxDeliveryAttempts int
+	// DeadLetterQueue [optional] is the Publisher that receives the poison messages.
+	// When the DeadLetterQueue uses the same Connection, the poison message is moved in the same transaction.
+	// Without a DeadLetterQueue, the poison messages are dropped.
+	DeadLetterQueue pubsub.Publisher[Entity]
+
+	// VisibilityTimeout [optional] is how long a received message is leased to its subscriber.
+	//
+	// Without a VisibilityTimeout, the message is locked by the transaction of its subscriber,
+	// and a crashed subscriber only releases it, when its database connection is closed.
+	// With a VisibilityTimeout, the message is leased instead,
+	// and a message that is neither ACK-ed nor NACK-ed within its lease is delivered again.
+	// The lease can be extended through the pubsub.LeaseExtender of the message.
+	// Every delivery of a leased message is counted towards the MaxDeliveryAttempts, including the crashed ones.
+	//
+	// default: unlimited
+	VisibilityTimeout time.Duration
 }
 
 type QueueMapper[ENT, DTO any] interface {
@@ -43,6 +73,9 @@ func (q Queue[Entity, JSONDTO]) Purge(ctx context.Context) error {
 	return err
 }
 
+// Publish will publish the data to the queue.
+// A message published with pubsub.ContextWithNotBefore is delivered only after its not-before time.
+// The headers of the message are made from the context with pubsub.MakeHeaders.
 func (q Queue[Entity, JSONDTO]) Publish(ctx context.Context, v Entity) error {
 	return q.PublishMany(ctx, v)
 }
@@ -58,14 +91,23 @@ func (q Queue[Entity, JSONDTO]) PublishMany(ctx context.Context, vs ...Entity) e
 		args  []any
 		ids   []string
 	)
-	query += fmt.Sprintf("INSERT INTO %s (id, queue, data, created_at) Values", queueTableName)
+	createdAt := clock.Now().UTC()
+	visibleAt := createdAt
+	if notBefore, ok := pubsub.NotBefore(ctx); ok {
+		visibleAt = notBefore.UTC()
+	}
+	headers, err := marshalHeaders(pubsub.MakeHeaders(ctx))
+	if err != nil {
+		return err
+	}
+	query += fmt.Sprintf("INSERT INTO %s (id, queue, data, created_at, visible_at, headers) Values", queueTableName)
 	for i, v := range vs {
 		if i == 0 {
 			query += "\n"
 		} else {
 			query += ",\n"
 		}
-		query += fmt.Sprintf("(%s, %s, %s, %s)", phg(), phg(), phg(), phg())
+		query += fmt.Sprintf("(%s, %s, %s, %s, %s, %s)", phg(), phg(), phg(), phg(), phg(), phg())
 		dto, err := q.Mapping.MapToDTO(ctx, v)
 		if err != nil {
 			return err
@@ -76,10 +118,10 @@ func (q Queue[Entity, JSONDTO]) PublishMany(ctx context.Context, vs ...Entity) e
 		}
 		id := rnd.UUID()
 		ids = append(ids, id)
-		args = append(args, id, q.Name, data, clock.Now().UTC())
+		args = append(args, id, q.Name, data, createdAt, visibleAt, headers)
 	}
 
-	_, err := q.Connection.ExecContext(ctx, query, args...)
+	_, err = q.Connection.ExecContext(ctx, query, args...)
 
 	if q.Blocking {
 		for {
@@ -109,12 +151,147 @@ CREATE TABLE IF NOT EXISTS ` + queueTableName + ` (
 )
 ;`
 
+const queryAddQueueDeliveryCount = `
+ALTER TABLE ` + queueTableName + ` ADD COLUMN IF NOT EXISTS delivery_count INT NOT NULL DEFAULT 0
+;`
+
+// visible_at is the time from which a message can be delivered.
+// The messages that existed before the column are visible right away.
+const queryAddQueueVisibleAt = `
+ALTER TABLE ` + queueTableName + ` ADD COLUMN IF NOT EXISTS visible_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+;`
+
+const queryCreateQueueVisibleAtIndex = `
+CREATE INDEX IF NOT EXISTS ` + queueTableName + `_queue_visible_at_idx ON ` + queueTableName + ` (queue, visible_at)
+;`
+
+// headers are the pubsub.Metadata headers of a message as a JSON object.
+// A message without headers has NULL headers.
+const queryAddQueueHeaders = `
+ALTER TABLE ` + queueTableName + ` ADD COLUMN IF NOT EXISTS headers JSON
+;`
+
+// leased_until is the end of the lease of a message that is being processed by a subscriber,
+// when the Queue has a VisibilityTimeout.
+// Unlike visible_at, it tells apart the leased messages from the delayed ones.
+// A message that is not leased, or that has an expired lease, has NULL or a past leased_until.
+const queryAddQueueLeasedUntil = `
+ALTER TABLE ` + queueTableName + ` ADD COLUMN IF NOT EXISTS leased_until TIMESTAMP WITH TIME ZONE
+;`
+
+// queryQueueNotLeased is the condition of the messages that are not leased to a subscriber at the given time.
+const queryQueueNotLeased = `(leased_until IS NULL OR leased_until <= $2)`
+
 func (q Queue[Entity, JSONDTO]) Migrate(ctx context.Context) error {
 	return MakeMigrator(q.Connection, queueTableName, migration.Steps[Connection]{
 		"0": flsql.MigrationStep[Connection]{UpQuery: queryCreateQueueTable},
+		"1": flsql.MigrationStep[Connection]{UpQuery: queryAddQueueDeliveryCount},
+		"2": flsql.MigrationStep[Connection]{UpQuery: queryAddQueueVisibleAt},
+		"3": flsql.MigrationStep[Connection]{UpQuery: queryCreateQueueVisibleAtIndex},
+		"4": flsql.MigrationStep[Connection]{UpQuery: queryAddQueueHeaders},
+		"5": flsql.MigrationStep[Connection]{UpQuery: queryAddQueueLeasedUntil},
 	}).Migrate(ctx)
 }
 
+// Inspect lists the messages of the queue without consuming them, in the order of their delivery.
+// Messages that are being processed by a subscriber are not listed,
+// neither the ones locked by a transaction, nor the ones leased for the VisibilityTimeout.
+func (q Queue[Entity, JSONDTO]) Inspect(ctx context.Context) iterkit.SeqE[Entity] {
+	var ordering = "ASC"
+	if q.LIFO {
+		ordering = "DESC"
+	}
+	query := fmt.Sprintf("SELECT data FROM %s WHERE queue = $1 AND %s ORDER BY created_at %s",
+		queueTableName, queryQueueNotLeased, ordering)
+	return flsql.QueryMany(q.Connection, ctx, q.scanData(ctx), query, q.Name, clock.Now().UTC())
+}
+
+// Requeue moves every message of the queue, that is not being processed by a subscriber, to the Publisher.
+// For example, a dead-letter queue can be requeued to its source queue.
+// When the Publisher uses the same Connection, the messages are moved in a single transaction.
+func (q Queue[Entity, JSONDTO]) Requeue(ctx context.Context, to pubsub.Publisher[Entity]) (rErr error) {
+	ctx, err := q.Connection.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer comproto.FinishOnePhaseCommit(&rErr, q.Connection, ctx)
+	query := fmt.Sprintf("DELETE FROM %s WHERE id IN (SELECT id FROM %s WHERE queue = $1 AND %s FOR UPDATE SKIP LOCKED) RETURNING data, created_at, visible_at, headers",
+		queueTableName, queueTableName, queryQueueNotLeased)
+	rows, err := q.Connection.QueryContext(ctx, query, q.Name, clock.Now().UTC())
+	if err != nil {
+		return err
+	}
+	type message struct {
+		Data      []byte
+		CreatedAt time.Time
+		VisibleAt time.Time
+		Headers   []byte
+	}
+	msgs, err := iterkit.CollectE(flsql.MakeRowsIterator(rows, func(s flsql.Scanner) (message, error) {
+		var m message
+		return m, s.Scan(&m.Data, &m.CreatedAt, &m.VisibleAt, &m.Headers)
+	}))
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(msgs, func(i, j int) bool {
+		return msgs[i].CreatedAt.Before(msgs[j].CreatedAt)
+	})
+	for _, m := range msgs {
+		ent, err := q.unmarshal(ctx, m.Data)
+		if err != nil {
+			return err
+		}
+		headers, err := unmarshalHeaders(m.Headers)
+		if err != nil {
+			return err
+		}
+		// the delayed messages keep their delay, and every message keeps its headers.
+		if err := to.Publish(pubsub.ContextWithNotBefore(pubsub.ContextWithHeaders(ctx, headers), m.VisibleAt), ent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q Queue[Entity, JSONDTO]) scanData(ctx context.Context) flsql.RowMapper[Entity] {
+	return func(s flsql.Scanner) (Entity, error) {
+		var data []byte
+		if err := s.Scan(&data); err != nil {
+			var zero Entity
+			return zero, err
+		}
+		return q.unmarshal(ctx, data)
+	}
+}
+
+func (q Queue[Entity, JSONDTO]) unmarshal(ctx context.Context, data []byte) (Entity, error) {
+	var dto JSONDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		var zero Entity
+		return zero, err
+	}
+	return q.Mapping.MapToENT(ctx, dto)
+}
+
+func marshalHeaders(headers map[string]string) ([]byte, error) {
+	if len(headers) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(headers)
+}
+
+func unmarshalHeaders(data []byte) (map[string]string, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var headers map[string]string
+	if err := json.Unmarshal(data, &headers); err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
+
 func (q Queue[Entity, JSONDTO]) Subscribe(ctx context.Context) pubsub.Subscription[Entity] {
 	return iterkit.From(func(yield func(pubsub.Message[Entity]) bool) (rErr error) {
 		if err := ctx.Err(); err != nil {
@@ -168,12 +345,32 @@ DELETE FROM ` + queueTableName + `
     WHERE id = (
       SELECT id
       FROM ` + queueTableName + `
-      WHERE queue = $1
+      WHERE queue = $1 AND visible_at <= $2
       ORDER BY created_at %s
       FOR UPDATE SKIP LOCKED
       LIMIT 1
     )
-    RETURNING id, data;
+    RETURNING id, data, delivery_count, created_at, visible_at, headers;
+`
+
+// queueMessageSavepoint is the savepoint right after a message is taken from the queue.
+// A NACK rolls back to it, so the changes made while processing the message are discarded,
+// but the delivery of the message can be still recorded in the same transaction.
+const queueMessageSavepoint = "frameless_queue_message"
+
+// queryQueueLeaseMessage leases the next visible message by hiding it until the end of its lease.
+const queryQueueLeaseMessage = `
+UPDATE ` + queueTableName + `
+   SET visible_at = $3, leased_until = $3, delivery_count = delivery_count + 1
+ WHERE id = (
+       SELECT id
+         FROM ` + queueTableName + `
+        WHERE queue = $1 AND visible_at <= $2
+        ORDER BY created_at %s
+        FOR UPDATE SKIP LOCKED
+        LIMIT 1
+ )
+RETURNING id, data, delivery_count, created_at, headers;
 `
 
 func (qs *queueSubscription[Entity, JSONDTO]) Next() bool {
@@ -198,27 +395,16 @@ fetch:
 		qs.value = nil
 	}
 
-	tx, err := qs.Queue.Connection.BeginTx(qs.CTX)
-	if err != nil {
-		if errors.Is(err, qs.CTX.Err()) {
-			return false
-		}
-		qs.err = err
-		return false
-	}
-
-	var ordering = "ASC"
-	if qs.Queue.LIFO {
-		ordering = "DESC"
-	}
-
 	var (
-		row  = qs.Queue.Connection.QueryRowContext(tx, fmt.Sprintf(queryQueuePopMessage, ordering), qs.Queue.Name)
-		id   string
-		data []byte
+		msg *queueMessage[Entity, JSONDTO]
+		err error
 	)
-	if err := row.Scan(&id, &data); err != nil {
-		_ = qs.Queue.Connection.RollbackTx(contextkit.Detach(tx))
+	if 0 < qs.Queue.VisibilityTimeout {
+		msg, err = qs.lease()
+	} else {
+		msg, err = qs.pop()
+	}
+	if err != nil {
 		if errors.Is(err, qs.CTX.Err()) {
 			return false
 		}
@@ -235,26 +421,118 @@ fetch:
 		return false
 	}
 
-	var dto JSONDTO
-	if err := json.Unmarshal(data, &dto); err != nil {
+	qs.value = msg
+	return true
+}
+
+func (qs *queueSubscription[Entity, JSONDTO]) ordering() string {
+	if qs.Queue.LIFO {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+// pop takes the next message from the queue in a transaction,
+// that holds the message until the message is ACK-ed or NACK-ed.
+func (qs *queueSubscription[Entity, JSONDTO]) pop() (*queueMessage[Entity, JSONDTO], error) {
+	tx, err := qs.Queue.Connection.BeginTx(qs.CTX)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		row           = qs.Queue.Connection.QueryRowContext(tx, fmt.Sprintf(queryQueuePopMessage, qs.ordering()), qs.Queue.Name, clock.Now().UTC())
+		id            string
+		data          []byte
+		deliveryCount int
+		createdAt     time.Time
+		visibleAt     time.Time
+		rawHeaders    []byte
+	)
+	if err := row.Scan(&id, &data, &deliveryCount, &createdAt, &visibleAt, &rawHeaders); err != nil {
 		_ = qs.Queue.Connection.RollbackTx(contextkit.Detach(tx))
-		qs.err = err
-		return false
+		return nil, err
 	}
 
-	ent, err := qs.Queue.Mapping.MapToENT(qs.CTX, dto)
+	ent, err := qs.Queue.unmarshal(qs.CTX, data)
 	if err != nil {
 		_ = qs.Queue.Connection.RollbackTx(contextkit.Detach(tx))
-		qs.err = err
-		return false
+		return nil, err
 	}
 
-	qs.value = &queueMessage[Entity, JSONDTO]{
-		q:    qs.Queue,
-		tx:   tx,
-		data: ent,
+	headers, err := unmarshalHeaders(rawHeaders)
+	if err != nil {
+		_ = qs.Queue.Connection.RollbackTx(contextkit.Detach(tx))
+		return nil, err
 	}
-	return true
+
+	if _, err := qs.Queue.Connection.ExecContext(tx, "SAVEPOINT "+queueMessageSavepoint); err != nil {
+		_ = qs.Queue.Connection.RollbackTx(contextkit.Detach(tx))
+		return nil, err
+	}
+
+	return &queueMessage[Entity, JSONDTO]{
+		q:             qs.Queue,
+		ctx:           qs.CTX,
+		tx:            pubsub.ExtractContext(tx, headers),
+		id:            id,
+		raw:           data,
+		data:          ent,
+		headers:       headers,
+		rawHeaders:    rawHeaders,
+		createdAt:     createdAt,
+		visibleAt:     visibleAt,
+		deliveryCount: deliveryCount + 1,
+	}, nil
+}
+
+// lease takes the next message from the queue for the VisibilityTimeout.
+// The lease is committed right away, so when the subscriber crashes, the message is delivered again after the lease.
+func (qs *queueSubscription[Entity, JSONDTO]) lease() (*queueMessage[Entity, JSONDTO], error) {
+	var (
+		now         = clock.Now().UTC()
+		leasedUntil = now.Add(qs.Queue.VisibilityTimeout).Truncate(time.Microsecond)
+		row         = qs.Queue.Connection.QueryRowContext(qs.CTX, fmt.Sprintf(queryQueueLeaseMessage, qs.ordering()),
+			qs.Queue.Name, now, leasedUntil)
+		id            string
+		data          []byte
+		deliveryCount int
+		createdAt     time.Time
+		rawHeaders    []byte
+	)
+	if err := row.Scan(&id, &data, &deliveryCount, &createdAt, &rawHeaders); err != nil {
+		return nil, err
+	}
+
+	msg := &queueMessage[Entity, JSONDTO]{
+		q:             qs.Queue,
+		ctx:           qs.CTX,
+		id:            id,
+		createdAt:     createdAt,
+		leasedUntil:   leasedUntil,
+		deliveryCount: deliveryCount,
+	}
+
+	ent, err := qs.Queue.unmarshal(qs.CTX, data)
+	if err != nil {
+		return nil, errorkit.Merge(err, msg.release())
+	}
+	msg.data = ent
+
+	headers, err := unmarshalHeaders(rawHeaders)
+	if err != nil {
+		return nil, errorkit.Merge(err, msg.release())
+	}
+	msg.headers = headers
+
+	// the message context is still transactional,
+	// and the transaction is committed with the ACK, as long as the lease is held.
+	tx, err := qs.Queue.Connection.BeginTx(qs.CTX)
+	if err != nil {
+		return nil, errorkit.Merge(err, msg.release())
+	}
+	msg.tx = pubsub.ExtractContext(tx, headers)
+	return msg, nil
 }
 
 func (qs *queueSubscription[Entity, JSONDTO]) Value() pubsub.Message[Entity] {
@@ -270,29 +548,200 @@ func (qs *queueSubscription[Entity, JSONDTO]) getEmptyQueueBreakTime() time.Dura
 }
 
 type queueMessage[Entity, JSONDTO any] struct {
-	q    Queue[Entity, JSONDTO]
+	q Queue[Entity, JSONDTO]
+	// ctx is the context of the subscription.
+	ctx  context.Context
 	tx   context.Context
+	id   string
+	raw  []byte
 	data Entity
+
+	headers    map[string]string
+	rawHeaders []byte
+
+	createdAt     time.Time
+	visibleAt     time.Time
+	deliveryCount int
+
+	// leasedUntil is the end of the lease, when the Queue has a VisibilityTimeout.
+	// It also identifies the lease, since it is the visible_at of the leased message.
+	leasedUntil time.Time
+	m           sync.Mutex
+
+	fin sync.Once
+	err error
 }
 
-func (qm queueMessage[Entity, JSONDTO]) Context() context.Context {
+var _ pubsub.LeaseExtender = &queueMessage[any, any]{}
+var _ pubsub.MetadataProvider = &queueMessage[any, any]{}
+
+func (qm *queueMessage[Entity, JSONDTO]) Context() context.Context {
 	return qm.tx
 }
 
-func (qm queueMessage[Entity, JSONDTO]) ACK() error {
+func (qm *queueMessage[Entity, JSONDTO]) isLeased() bool {
+	return !qm.leasedUntil.IsZero()
+}
+
+func (qm *queueMessage[Entity, JSONDTO]) ACK() error {
 	// when context cancellation happens,
 	// the already received message should be still ACK able
 	// Thus detaching from cancellation is acceptable
-	return qm.q.Connection.CommitTx(contextkit.Detach(qm.tx))
+	qm.fin.Do(func() {
+		if qm.isLeased() {
+			qm.err = qm.ackLeased(contextkit.Detach(qm.tx))
+			return
+		}
+		qm.err = qm.q.Connection.CommitTx(contextkit.Detach(qm.tx))
+	})
+	return qm.err
 }
 
-func (qm queueMessage[Entity, JSONDTO]) NACK() error {
+func (qm *queueMessage[Entity, JSONDTO]) NACK() error {
 	// when context cancellation happens,
 	// the already received message should be still ACK able
 	// Thus detaching from cancellation is acceptable
-	return qm.q.Connection.RollbackTx(contextkit.Detach(qm.tx))
+	qm.fin.Do(func() {
+		if qm.isLeased() {
+			qm.err = qm.nackLeased(contextkit.Detach(qm.tx))
+			return
+		}
+		qm.err = qm.nack(contextkit.Detach(qm.tx))
+	})
+	return qm.err
+}
+
+// nack discards the changes made while processing the message,
+// and then puts the message back to the queue with its delivery recorded,
+// or moves it to the dead-letter queue when it ran out of delivery attempts.
+// When the delivery can't be recorded, the message is put back as if it was never delivered.
+func (qm *queueMessage[Entity, JSONDTO]) nack(ctx context.Context) (rErr error) {
+	defer func() {
+		if rErr != nil {
+			rErr = errorkit.Merge(rErr, qm.q.Connection.RollbackTx(ctx))
+		}
+	}()
+	if _, err := qm.q.Connection.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+queueMessageSavepoint); err != nil {
+		return err
+	}
+	if qm.isPoison() {
+		if err := qm.deadLetter(ctx); err != nil {
+			return err
+		}
+		return qm.q.Connection.CommitTx(ctx)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (id, queue, data, created_at, visible_at, delivery_count, headers) VALUES ($1, $2, $3, $4, $5, $6, $7)", queueTableName)
+	if _, err := qm.q.Connection.ExecContext(ctx, query, qm.id, qm.q.Name, qm.raw, qm.createdAt, qm.visibleAt, qm.deliveryCount, qm.rawHeaders); err != nil {
+		return err
+	}
+	return qm.q.Connection.CommitTx(ctx)
+}
+
+func (qm *queueMessage[Entity, JSONDTO]) isPoison() bool {
+	return 0 < qm.q.MaxDeliveryAttempts && qm.q.MaxDeliveryAttempts <= qm.deliveryCount
+}
+
+func (qm *queueMessage[Entity, JSONDTO]) deadLetter(ctx context.Context) error {
+	if qm.q.DeadLetterQueue == nil {
+		return nil
+	}
+	return qm.q.DeadLetterQueue.Publish(pubsub.ContextWithHeaders(ctx, qm.headers), qm.data)
+}
+
+// ackLeased deletes the leased message as part of the message's transaction,
+// unless the lease expired, and the message was delivered again.
+func (qm *queueMessage[Entity, JSONDTO]) ackLeased(tx context.Context) error {
+	if err := qm.deleteLeased(tx); err != nil {
+		return errorkit.Merge(err, qm.q.Connection.RollbackTx(tx))
+	}
+	return qm.q.Connection.CommitTx(tx)
+}
+
+// nackLeased discards the changes made while processing the message,
+// and then makes the message visible again,
+// or moves it to the dead-letter queue when it ran out of delivery attempts.
+func (qm *queueMessage[Entity, JSONDTO]) nackLeased(tx context.Context) (rErr error) {
+	if err := qm.q.Connection.RollbackTx(tx); err != nil {
+		return err
+	}
+	if !qm.isPoison() {
+		return qm.release()
+	}
+	ctx, err := qm.q.Connection.BeginTx(contextkit.Detach(qm.ctx))
+	if err != nil {
+		return err
+	}
+	defer comproto.FinishOnePhaseCommit(&rErr, qm.q.Connection, ctx)
+	if err := qm.deleteLeased(ctx); err != nil {
+		return err
+	}
+	return qm.deadLetter(ctx)
+}
+
+func (qm *queueMessage[Entity, JSONDTO]) deleteLeased(ctx context.Context) error {
+	qm.m.Lock()
+	defer qm.m.Unlock()
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND visible_at = $2", queueTableName)
+	return qm.execLeased(ctx, query, qm.id, qm.leasedUntil)
+}
+
+// release makes the leased message visible again.
+func (qm *queueMessage[Entity, JSONDTO]) release() error {
+	qm.m.Lock()
+	defer qm.m.Unlock()
+	query := fmt.Sprintf("UPDATE %s SET visible_at = $3, leased_until = NULL WHERE id = $1 AND visible_at = $2", queueTableName)
+	return qm.execLeased(contextkit.Detach(qm.ctx), query, qm.id, qm.leasedUntil, clock.Now().UTC())
+}
+
+// ExtendLease implements the pubsub.LeaseExtender.
+// The extension is committed right away, independently from the transaction of the message.
+// Without a Queue.VisibilityTimeout, the message is held until its transaction ends,
+// and extending its lease has no effect.
+func (qm *queueMessage[Entity, JSONDTO]) ExtendLease(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !qm.isLeased() {
+		return nil
+	}
+	qm.m.Lock()
+	defer qm.m.Unlock()
+	leasedUntil := clock.Now().UTC().Add(d).Truncate(time.Microsecond)
+	query := fmt.Sprintf("UPDATE %s SET visible_at = $3, leased_until = $3 WHERE id = $1 AND visible_at = $2", queueTableName)
+	if err := qm.execLeased(contextkit.Detach(qm.ctx), query, qm.id, qm.leasedUntil, leasedUntil); err != nil {
+		return err
+	}
+	qm.leasedUntil = leasedUntil
+	return nil
+}
+
+// execLeased executes a statement that is conditioned to the lease of the message.
+// When the statement affects no rows, the lease is lost.
+func (qm *queueMessage[Entity, JSONDTO]) execLeased(ctx context.Context, query string, args ...any) error {
+	result, err := qm.q.Connection.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return pubsub.ErrLeaseExpired
+	}
+	return nil
+}
+
+// Metadata implements the pubsub.MetadataProvider.
+func (qm *queueMessage[Entity, JSONDTO]) Metadata() pubsub.Metadata {
+	return pubsub.Metadata{
+		ID:            qm.id,
+		Headers:       maps.Clone(qm.headers),
+		PublishedAt:   qm.createdAt,
+		DeliveryCount: qm.deliveryCount,
+	}
 }
 
-func (qm queueMessage[Entity, JSONDTO]) Data() Entity {
+func (qm *queueMessage[Entity, JSONDTO]) Data() Entity {
 	return qm.data
 }
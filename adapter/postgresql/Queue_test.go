@@ -10,7 +10,9 @@ import (
 	"time"
 
 	"go.llib.dev/frameless/adapter/postgresql"
+	"go.llib.dev/frameless/pkg/iterkit"
 	"go.llib.dev/frameless/port/migration"
+	"go.llib.dev/frameless/port/pubsub"
 	"go.llib.dev/frameless/port/pubsub/pubsubcontract"
 	"go.llib.dev/frameless/port/pubsub/pubsubtest"
 	"go.llib.dev/frameless/testing/testent"
@@ -92,9 +94,120 @@ func TestQueue(t *testing.T) {
 		pubsubcontract.Buffered[Entity](basicQueue, basicQueue),
 		pubsubcontract.Blocking[Entity](blockingQueue, blockingQueue),
 		pubsubcontract.Queue[Entity](basicQueue, basicQueue),
+		pubsubcontract.Delayed[Entity](basicQueue, basicQueue),
+		pubsubcontract.Metadata[Entity](basicQueue, basicQueue),
 	)
 }
 
+var _ interface {
+	pubsub.Inspector[Entity]
+	pubsub.Requeuer[Entity]
+} = postgresql.Queue[Entity, EntityDTO]{}
+
+func TestQueue_deadLetter(t *testing.T) {
+	c := GetConnection(t)
+
+	assert.NoError(t,
+		postgresql.Queue[Entity, EntityDTO]{Connection: c}.
+			Migrate(MakeContext(t)))
+
+	mapping := EntityJSONMapping{}
+
+	dlq := postgresql.Queue[Entity, EntityDTO]{
+		Name:       "test_entity_dead_letter",
+		Connection: c,
+		Mapping:    mapping,
+	}
+
+	q := postgresql.Queue[Entity, EntityDTO]{
+		Name:       "test_entity_with_dead_letter",
+		Connection: c,
+		Mapping:    mapping,
+
+		MaxDeliveryAttempts: 3,
+		DeadLetterQueue:     dlq,
+	}
+
+	pubsubcontract.DeadLetter[Entity](q, q, dlq, q.MaxDeliveryAttempts).Test(t)
+}
+
+func TestQueue_visibilityTimeout(t *testing.T) {
+	c := GetConnection(t)
+
+	assert.NoError(t,
+		postgresql.Queue[Entity, EntityDTO]{Connection: c}.
+			Migrate(MakeContext(t)))
+
+	mapping := EntityJSONMapping{}
+
+	q := postgresql.Queue[Entity, EntityDTO]{
+		Name:       "test_entity_with_visibility_timeout",
+		Connection: c,
+		Mapping:    mapping,
+
+		VisibilityTimeout: time.Minute,
+	}
+
+	dlq := postgresql.Queue[Entity, EntityDTO]{
+		Name:       "test_entity_leased_dead_letter",
+		Connection: c,
+		Mapping:    mapping,
+	}
+
+	leasedWithDeadLetter := q
+	leasedWithDeadLetter.MaxDeliveryAttempts = 2
+	leasedWithDeadLetter.DeadLetterQueue = dlq
+
+	testcase.RunSuite(t,
+		pubsubcontract.VisibilityTimeout[Entity](q, q, q.VisibilityTimeout),
+		pubsubcontract.Metadata[Entity](q, q),
+		pubsubcontract.TransactionalMessageContext[Entity](q, q),
+		pubsubcontract.DeadLetter[Entity](leasedWithDeadLetter, leasedWithDeadLetter, dlq, leasedWithDeadLetter.MaxDeliveryAttempts),
+	)
+
+	t.Run("a leased message is neither inspected nor requeued", func(t *testing.T) {
+		ctx := MakeContext(t)
+		leased := q
+		leased.Name = "test_entity_leased_inspect"
+		target := postgresql.Queue[Entity, EntityDTO]{
+			Name:       "test_entity_leased_requeue_target",
+			Connection: c,
+			Mapping:    mapping,
+		}
+		assert.NoError(t, leased.Purge(ctx))
+		assert.NoError(t, target.Purge(ctx))
+		t.Cleanup(func() {
+			_ = leased.Purge(ctx)
+			_ = target.Purge(ctx)
+		})
+
+		rnd := random.New(random.CryptoSeed{})
+		inFlight := Entity{ID: rnd.UUID(), Foo: rnd.String()}
+		waiting := Entity{ID: rnd.UUID(), Foo: rnd.String()}
+		assert.NoError(t, leased.Publish(ctx, inFlight))
+		assert.NoError(t, leased.Publish(ctx, waiting))
+
+		next, stop := iter.Pull2(iter.Seq2[pubsub.Message[Entity], error](leased.Subscribe(ctx)))
+		defer stop()
+		msg, err, ok := next()
+		assert.True(t, ok)
+		assert.NoError(t, err)
+		assert.Equal(t, inFlight, msg.Data())
+
+		got, err := iterkit.CollectE(leased.Inspect(ctx))
+		assert.NoError(t, err)
+		assert.Equal(t, []Entity{waiting}, got)
+
+		assert.NoError(t, leased.Requeue(ctx, target))
+		got, err = iterkit.CollectE(target.Inspect(ctx))
+		assert.NoError(t, err)
+		assert.Equal(t, []Entity{waiting}, got)
+
+		t.Log("and the leased message can be still ACK-ed by its subscriber")
+		assert.NoError(t, msg.ACK())
+	})
+}
+
 func TestQueue_emptyQueueBreakTime(t *testing.T) {
 	if testing.Short() {
 		t.SkipNow()
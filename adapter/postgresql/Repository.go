@@ -3,7 +3,6 @@ package postgresql
 import (
 	"cmp"
 	"context"
-	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -20,155 +19,176 @@ import (
 	"go.llib.dev/frameless/pkg/synckit"
 	"go.llib.dev/frameless/pkg/teardown"
 	"go.llib.dev/frameless/pkg/uuid"
-	"go.llib.dev/frameless/pkg/zerokit"
 	"go.llib.dev/frameless/port/comproto"
 	"go.llib.dev/frameless/port/crud"
-	"go.llib.dev/frameless/port/crud/extid"
+	"go.llib.dev/frameless/port/crud/crudquery"
 	"go.llib.dev/testcase/clock"
 )
 
+// Dialect is the flsql.Dialect of PostgreSQL.
+// It lets an flsql.Repository or an flsql query builder work with a PostgreSQL Connection.
+var Dialect = flsql.DialectPostgreSQL
+
 // Repository is a frameless external resource supplier to store a certain entity type.
 // The Repository supplier itself is a stateless entity.
 //
+// The CRUD operations are handled by flsql.Repository with the PostgreSQL Dialect,
+// while the Repository adds the PostgreSQL specific extras on top of them:
+// the change feed, the history and the COPY based Batch.
+//
 // SRP: DBA
 type Repository[ENT, ID any] struct {
 	Connection Connection
 	Mapping    flsql.Mapping[ENT, ID]
 	BatchConfig
+	// ChangeFeed [optional] enables the crud.ChangeSubscriber support of the Repository.
+	ChangeFeed ChangeFeedConfig
+	// History [optional] enables the crud.TemporalFinder support of the Repository.
+	History HistoryConfig
 }
 
-func (r Repository[ENT, ID]) Create(ctx context.Context, ptr *ENT) (rErr error) {
-	if ptr == nil {
-		return fmt.Errorf("nil entity pointer given to %T#Create", r)
-	}
-
-	ctx, err := r.BeginTx(ctx)
-	if err != nil {
-		return err
-	}
-	defer comproto.FinishOnePhaseCommit(&rErr, r, ctx)
+var _ interface {
+	crud.Creator[any]
+	crud.ByIDFinder[any, any]
+	crud.AllFinder[any]
+	crud.ByIDsFinder[any, any]
+	crud.Updater[any]
+	crud.ByIDDeleter[any]
+	crud.AllDeleter
+	crud.Saver[any]
+	crud.Querier[any]
+	crud.PageFinder[any]
+	crud.SoftDeleter[any, any]
+	crud.ManyCreator[any]
+	crud.ManyUpdater[any]
+	crud.ByIDsDeleter[any]
+	crud.Counter
+	crud.QueryCounter
+	crud.GroupCounter
+	crud.Exister[any]
+	crud.Batcher[any, crud.Batch[any]]
+	crud.ChangeSubscriber[any, any]
+	crud.TemporalFinder[any, any]
+	comproto.OnePhaseCommitProtocol
+} = Repository[any, any]{}
+
+func (r Repository[ENT, ID]) repository() flsql.Repository[ENT, ID] {
+	repo := flsql.Repository[ENT, ID]{
+		Connection: r.Connection,
+		Mapping:    r.Mapping,
+		Dialect:    Dialect,
+	}
+	if r.recordsChanges() {
+		repo.OnChange = r.recordChange
+	}
+	return repo
+}
 
-	id, ok := r.Mapping.ID.Lookup(*ptr)
-	if !ok {
-		return fmt.Errorf("%s doesn't have an %s ext id field",
-			reflectkit.TypeOf[ENT]().String(),
-			reflectkit.TypeOf[ID]().String())
-	}
-	if !zerokit.IsZero(id) {
-		_, found, err := r.FindByID(ctx, id)
-		if err != nil {
-			return err
-		}
-		if found {
-			err := crud.ErrAlreadyExists.F(`%T already exists with id: %v`, *new(ENT), id)
-			err = errorkit.WithContext(err, ctx)
-			return err
+func (r Repository[ENT, ID]) Create(ctx context.Context, ptr *ENT) error {
+	if ptr != nil {
+		if _, ok := r.Mapping.ID.Lookup(*ptr); !ok {
+			return fmt.Errorf("%s doesn't have an %s ext id field",
+				reflectkit.TypeOf[ENT]().String(),
+				reflectkit.TypeOf[ID]().String())
 		}
 	}
+	return r.repository().Create(ctx, ptr)
+}
 
-	// TODO: add serialize TX level here
-	if err := r.Mapping.OnPrepare(ctx, ptr); err != nil {
-		return err
-	}
-
-	args, err := r.Mapping.ToArgs(*ptr)
-	if err != nil {
-		return err
-	}
-
-	var (
-		colums       []flsql.ColumnName
-		valuesClause []string
-		valuesArgs   []any
-		nextPH       = makePrepareStatementPlaceholderGenerator()
-	)
-	for col, arg := range args {
-		colums = append(colums, col)
-		valuesClause = append(valuesClause, nextPH())
-		valuesArgs = append(valuesArgs, arg)
-	}
+func (r Repository[ENT, ID]) FindByID(ctx context.Context, id ID) (ENT, bool, error) {
+	return r.repository().FindByID(ctx, id)
+}
 
-	query := fmt.Sprintf("INSERT INTO %s (%s)\n", r.tableIdentifier().Sanitize(), r.quotedColumnsClause(colums))
-	query += fmt.Sprintf("VALUES (%s)\n", strings.Join(valuesClause, ", "))
+func (r Repository[ENT, ID]) FindAll(ctx context.Context) iterkit.SeqE[ENT] {
+	return r.repository().FindAll(ctx)
+}
 
-	logger.Debug(ctx, "postgresql.Repository.Create", logging.Field("query", query))
+func (r Repository[ENT, ID]) FindByIDs(ctx context.Context, ids ...ID) iterkit.SeqE[ENT] {
+	return r.repository().FindByIDs(ctx, ids...)
+}
 
-	if _, err := r.Connection.ExecContext(ctx, query, valuesArgs...); err != nil {
-		return err
-	}
+// Query implements crud.Querier by compiling the query into SQL.
+// Query fields are resolved against the Mapping's ToQuery columns, see crudquery.MatchField.
+func (r Repository[ENT, ID]) Query(ctx context.Context, q crudquery.Query) iterkit.SeqE[ENT] {
+	return r.repository().Query(ctx, q)
+}
 
-	return nil
+// FindPage implements crud.PageFinder with keyset pagination over the ID columns of the table.
+func (r Repository[ENT, ID]) FindPage(ctx context.Context, cursor crud.Cursor, size int) (crud.Page[ENT], error) {
+	return r.repository().FindPage(ctx, cursor, size)
 }
 
-func (r Repository[ENT, ID]) idQuery(id ID, nextPlaceholder func() string) (whereClause []string, queryArgs []any, _ error) {
-	idArgs, err := r.Mapping.QueryID(id)
-	if err != nil {
-		return nil, nil, err
-	}
-	for col, arg := range idArgs {
-		whereClause = append(whereClause, fmt.Sprintf("%q = %s", col, nextPlaceholder()))
-		queryArgs = append(queryArgs, arg)
-	}
-	return whereClause, queryArgs, nil
+func (r Repository[ENT, ID]) Update(ctx context.Context, ptr *ENT) error {
+	return r.repository().Update(ctx, ptr)
 }
 
-func (r Repository[ENT, ID]) FindByID(ctx context.Context, id ID) (ENT, bool, error) {
-	idArgs, err := r.Mapping.QueryID(id)
-	if err != nil {
-		return *new(ENT), false, fmt.Errorf("QueryID: %w", err)
-	}
+func (r Repository[ENT, ID]) Save(ctx context.Context, ptr *ENT) error {
+	return r.repository().Save(ctx, ptr)
+}
 
-	cols, scan := r.Mapping.ToQuery(ctx)
+func (r Repository[ENT, ID]) DeleteByID(ctx context.Context, id ID) error {
+	return r.repository().DeleteByID(ctx, id)
+}
 
-	query := fmt.Sprintf(`SELECT %s FROM %s`, r.quotedColumnsClause(cols), r.tableIdentifier().Sanitize())
+func (r Repository[ENT, ID]) DeleteAll(ctx context.Context) error {
+	return r.repository().DeleteAll(ctx)
+}
 
-	nextPH := makePrepareStatementPlaceholderGenerator()
+// SoftDeleteByID implements crud.SoftDeleter by setting the Mapping.DeletedAtColumn of the row.
+func (r Repository[ENT, ID]) SoftDeleteByID(ctx context.Context, id ID) error {
+	return r.repository().SoftDeleteByID(ctx, id)
+}
 
-	var (
-		whereClause []string
-		queryArgs   []any
-	)
-	for col, arg := range idArgs {
-		whereClause = append(whereClause, fmt.Sprintf("%s = %s", col, nextPH()))
-		queryArgs = append(queryArgs, arg)
-	}
+// Restore implements crud.SoftDeleter by clearing the Mapping.DeletedAtColumn of the row.
+func (r Repository[ENT, ID]) Restore(ctx context.Context, id ID) error {
+	return r.repository().Restore(ctx, id)
+}
 
-	query += " WHERE " + strings.Join(whereClause, " AND ")
+// FindDeleted implements crud.SoftDeleter by listing the rows that has their Mapping.DeletedAtColumn set.
+func (r Repository[ENT, ID]) FindDeleted(ctx context.Context) iterkit.SeqE[ENT] {
+	return r.repository().FindDeleted(ctx)
+}
 
-	logger.Debug(ctx, "postgresql.Repository#FindByID", logging.Field("query", query))
+// CreateMany implements crud.ManyCreator with multi-row INSERT statements within a single transaction.
+// The entities that already exist are reported in a crud.BulkError, and they are left out from the INSERT.
+func (r Repository[ENT, ID]) CreateMany(ctx context.Context, ptrs ...*ENT) error {
+	return r.repository().CreateMany(ctx, ptrs...)
+}
 
-	row := r.Connection.QueryRowContext(ctx, query, queryArgs...)
+// UpdateMany implements crud.ManyUpdater by updating the entities within a single transaction.
+// The absent and the concurrently modified entities are reported in a crud.BulkError.
+func (r Repository[ENT, ID]) UpdateMany(ctx context.Context, ptrs ...*ENT) error {
+	return r.repository().UpdateMany(ctx, ptrs...)
+}
 
-	var v ENT
-	err = scan(&v, row)
+// DeleteByIDs implements crud.ByIDsDeleter with multi-row DELETE statements within a single transaction.
+// The IDs of the absent entities are reported in a crud.BulkError.
+func (r Repository[ENT, ID]) DeleteByIDs(ctx context.Context, ids ...ID) error {
+	return r.repository().DeleteByIDs(ctx, ids...)
+}
 
-	if errors.Is(err, errNoRows) {
-		return *new(ENT), false, nil
-	}
+// Count implements crud.Counter with a SELECT COUNT(*) query.
+func (r Repository[ENT, ID]) Count(ctx context.Context) (int, error) {
+	return r.repository().Count(ctx)
+}
 
-	if err != nil {
-		return *new(ENT), false, err
-	}
+// CountQuery implements crud.QueryCounter by counting the rows that match the query's Where expression.
+func (r Repository[ENT, ID]) CountQuery(ctx context.Context, q crudquery.Query) (int, error) {
+	return r.repository().CountQuery(ctx, q)
+}
 
-	return v, true, nil
+// CountGroups implements crud.GroupCounter with a GROUP BY query over the column that the field refers to.
+func (r Repository[ENT, ID]) CountGroups(ctx context.Context, field string, q crudquery.Query) ([]crud.GroupCount, error) {
+	return r.repository().CountGroups(ctx, field, q)
 }
 
-func (r Repository[ENT, ID]) DeleteAll(ctx context.Context) (rErr error) {
-	ctx, err := r.BeginTx(ctx)
-	if err != nil {
-		return err
-	}
-	defer comproto.FinishOnePhaseCommit(&rErr, r, ctx)
-	var (
-		tableName = r.tableIdentifier().Sanitize()
-		query     = fmt.Sprintf(`DELETE FROM %s`, tableName)
-	)
-	if _, err := r.Connection.ExecContext(ctx, query); err != nil {
-		return fmt.Errorf("failed to delete all entries from %s table: %w", tableName, err)
-	}
-	return nil
+// ExistsByID implements crud.Exister with a SELECT EXISTS query.
+func (r Repository[ENT, ID]) ExistsByID(ctx context.Context, id ID) (bool, error) {
+	return r.repository().ExistsByID(ctx, id)
 }
 
+// Truncate removes every row of the table with a TRUNCATE statement.
+// Unlike DeleteAll, the removal is not recorded in the change feed and the history.
 func (r Repository[ENT, ID]) Truncate(ctx context.Context) (rErr error) {
 	var (
 		tableName = r.tableIdentifier().Sanitize()
@@ -180,256 +200,22 @@ func (r Repository[ENT, ID]) Truncate(ctx context.Context) (rErr error) {
 	return nil
 }
 
-func (r Repository[ENT, ID]) DeleteByID(ctx context.Context, id ID) (rErr error) {
-	idWhereClause, idQueryArgs, err := r.idQuery(id, makePrepareStatementPlaceholderGenerator())
-	if err != nil {
-		return err
-	}
-
-	var query = fmt.Sprintf(`DELETE FROM %s WHERE %s`, r.tableIdentifier().Sanitize(), strings.Join(idWhereClause, " AND "))
-
-	ctx, err = r.BeginTx(ctx)
-	if err != nil {
-		return err
-	}
-	defer comproto.FinishOnePhaseCommit(&rErr, r, ctx)
-
-	logger.Debug(ctx, "postgresql.Repository#DeleteByID", logging.Field("query", query))
-
-	result, err := r.Connection.ExecContext(ctx, query, idQueryArgs...)
-	if err != nil {
-		return err
-	}
-
-	if count, err := result.RowsAffected(); err != nil {
-		return err
-	} else if count == 0 {
-		return crud.ErrNotFound
-	}
-
-	return nil
-}
-
-func (r Repository[ENT, ID]) Update(ctx context.Context, ptr *ENT) (rErr error) {
-	if ptr == nil {
-		return fmt.Errorf("Update: nil entity pointer received")
-	}
-
-	var (
-		query           = fmt.Sprintf("UPDATE %s", r.tableIdentifier().Sanitize())
-		nextPlaceholder = makePrepareStatementPlaceholderGenerator()
-
-		querySetClause   []string
-		queryWhereClause []string
-		queryArgs        []any
-	)
-
-	id, ok := r.Mapping.ID.Lookup(*ptr)
-	if !ok {
-		return fmt.Errorf("%T dones't have an %T ext id field", *ptr, *new(ID))
-	}
-	if zerokit.IsZero(id) {
-		return fmt.Errorf("missing entity id for Update")
-	}
-
-	idWhere, idArgs, err := r.idQuery(id, nextPlaceholder)
-	if err != nil {
-		return err
-	}
-	queryWhereClause = append(queryWhereClause, idWhere...)
-	queryArgs = append(queryArgs, idArgs...)
-
-	setArgs, err := r.Mapping.ToArgs(*ptr)
-	if err != nil {
-		return err
-	}
-
-	for col, arg := range setArgs {
-		querySetClause = append(querySetClause, fmt.Sprintf(`%q = %s`, col, nextPlaceholder()))
-		queryArgs = append(queryArgs, arg)
-	}
-
-	if len(querySetClause) > 0 {
-		query += fmt.Sprintf("\nSET %s", strings.Join(querySetClause, `, `))
-	}
-
-	query += fmt.Sprintf("\nWHERE %s", strings.Join(queryWhereClause, ", "))
-
-	ctx, err = r.BeginTx(ctx)
-	if err != nil {
-		return err
-	}
-	defer comproto.FinishOnePhaseCommit(&rErr, r, ctx)
-
-	if res, err := r.Connection.ExecContext(ctx, query, queryArgs...); err != nil {
-		return err
-	} else {
-		if affected, err := res.RowsAffected(); err != nil {
-			return err
-		} else if affected == 0 {
-			return crud.ErrNotFound
-		}
-	}
-
-	return nil
-}
-
-func (r Repository[ENT, ID]) FindAll(ctx context.Context) iterkit.ErrSeq[ENT] {
-	cols, scan := r.Mapping.ToQuery(ctx)
-	query := fmt.Sprintf(`SELECT %s FROM %s`, r.quotedColumnsClause(cols), r.tableIdentifier().Sanitize())
-	return flsql.QueryMany(r.Connection, ctx, scan.Map, query)
-}
-
-func (r Repository[ENT, ID]) FindByIDs(ctx context.Context, ids ...ID) iterkit.SeqE[ENT] {
-	if len(ids) == 0 {
-		return iterkit.Empty2[ENT, error]()
-	}
-
-	var (
-		whereClause []string
-		queryArgs   []any
-	)
-	nextPlaceholder := makePrepareStatementPlaceholderGenerator()
-	for _, id := range ids {
-		idWhere, idArgs, err := r.idQuery(id, nextPlaceholder)
-		if err != nil {
-			return iterkit.Error[ENT](err)
-		}
-		whereClause = append(whereClause, fmt.Sprintf("(%s)", strings.Join(idWhere, " AND ")))
-		queryArgs = append(queryArgs, idArgs...)
-	}
-
-	selectClause, scan := r.Mapping.ToQuery(ctx)
-
-	query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s`,
-		r.quotedColumnsClause(selectClause), r.tableIdentifier().Sanitize(), strings.Join(whereClause, " OR "))
-
-	var count int
-	coundQuery := fmt.Sprintf(`SELECT COUNT(*) FROM (%s) AS src`, query)
-	if err := r.Connection.QueryRowContext(ctx, coundQuery, queryArgs...).Scan(&count); err != nil {
-		return iterkit.Error[ENT](err)
-	}
-	if count != len(ids) {
-		return iterkit.Error[ENT](crud.ErrNotFound)
-	}
-
-	return flsql.QueryMany(r.Connection, ctx, scan.Map, query, queryArgs...)
-}
-
 // Upsert
 //
 // Deprecated: use Repository.Save instead
 func (r Repository[ENT, ID]) Upsert(ctx context.Context, ptrs ...*ENT) (rErr error) {
-	var (
-		ptrWithID    []*ENT
-		ptrWithoutID []*ENT
-	)
-	for _, ptr := range ptrs {
-		id, _ := extid.Lookup[ID](ptr)
-		if zerokit.IsZero(id) {
-			ptrWithoutID = append(ptrWithoutID, ptr)
-		} else {
-			ptrWithID = append(ptrWithID, ptr)
-		}
-	}
-
-	ctx, err := r.Connection.BeginTx(ctx)
+	ctx, err := r.BeginTx(ctx)
 	if err != nil {
 		return err
 	}
-	defer comproto.FinishOnePhaseCommit(&rErr, r.Connection, ctx)
-	return errorkit.Merge(r.upsertWithID(ctx, ptrWithID...), r.upsertWithoutID(ctx, ptrWithoutID...))
-}
-
-func (r Repository[ENT, ID]) Save(ctx context.Context, ptr *ENT) (rErr error) {
-	if ptr == nil {
-		return fmt.Errorf("nil %T received", ptr)
-	}
-	id := r.Mapping.ID.Get(*ptr)
-	if zerokit.IsZero(id) {
-		return r.upsertWithoutID(ctx, ptr)
-	}
-	return r.upsertWithID(ctx, ptr)
-}
-
-func (r Repository[ENT, ID]) upsertWithoutID(ctx context.Context, ptrs ...*ENT) error {
+	defer comproto.FinishOnePhaseCommit(&rErr, r, ctx)
 	for _, ptr := range ptrs {
-		if err := r.Create(ctx, ptr); err != nil {
+		if err := r.Save(ctx, ptr); err != nil {
 			return err
 		}
 	}
 	return nil
 }
-func (r Repository[ENT, ID]) upsertWithID(ctx context.Context, ptrs ...*ENT) error {
-	if len(ptrs) == 0 {
-		return nil
-	}
-
-	var args []any
-
-	nextPH := makePrepareStatementPlaceholderGenerator()
-
-	var valuesElems []flsql.QueryArgs
-	for _, ptr := range ptrs {
-		valueElem, err := r.Mapping.ToArgs(*ptr)
-		if err != nil {
-			return err
-		}
-		valuesElems = append(valuesElems, valueElem)
-	}
-
-	var columns []flsql.ColumnName
-	for _, value := range valuesElems {
-		columns = slicekit.Unique(append(columns, mapkit.Keys(value)...))
-	}
-
-	var idColumns []flsql.ColumnName
-	var valuesClause []string
-	for _, ptr := range ptrs {
-
-		id, _ := r.Mapping.ID.Lookup(*ptr)
-
-		idArgs, err := r.Mapping.QueryID(id)
-		if err != nil {
-			return err
-		}
-
-		idColumns = slicekit.Unique(append(idColumns, mapkit.Keys(idArgs)...))
-
-		setClauseArgs, err := r.Mapping.ToArgs(*ptr)
-		if err != nil {
-			return err
-		}
-
-		var valueClause []string
-		for _, col := range columns {
-			valueClause = append(valueClause, nextPH())
-			// on no value, it will be NULL which is the expected behaviour
-			// so no need to do a `arg, ok := setClauseArgs[col]`
-			args = append(args, setClauseArgs[col])
-		}
-
-		valuesClause = append(valuesClause, fmt.Sprintf("(%s)", strings.Join(valueClause, ", ")))
-	}
-
-	var onConflictUpdateSetClause []string
-	for _, col := range columns {
-		onConflictUpdateSetClause = append(onConflictUpdateSetClause,
-			fmt.Sprintf("\t\t%s = EXCLUDED.%s", col, col))
-	}
-
-	var query string
-	query += fmt.Sprintf("INSERT INTO %s (%s)\n", r.tableIdentifier().Sanitize(), r.quotedColumnsClause(columns))
-	query += fmt.Sprintf("VALUES \n\t%s\n", strings.Join(valuesClause, ",\n\t"))
-	query += fmt.Sprintf("ON CONFLICT (%s) DO\n", flsql.JoinColumnName(idColumns, "%q", ", "))
-	query += fmt.Sprintf("\tUPDATE SET\n%s\n", strings.Join(onConflictUpdateSetClause, ",\n"))
-
-	if _, err := r.Connection.ExecContext(ctx, query, args...); err != nil {
-		return err
-	}
-
-	return nil
-}
 
 func (r Repository[ENT, ID]) BeginTx(ctx context.Context) (context.Context, error) {
 	return r.Connection.BeginTx(ctx)
@@ -443,10 +229,6 @@ func (r Repository[ENT, ID]) RollbackTx(ctx context.Context) error {
 	return r.Connection.RollbackTx(ctx)
 }
 
-func (r Repository[ENT, ID]) quotedColumnsClause(cols []flsql.ColumnName) string {
-	return flsql.JoinColumnName(cols, "%q", ", ")
-}
-
 func (r Repository[ENT, ID]) tableIdentifier() pgx.Identifier {
 	ident := strings.Split(r.Mapping.TableName, ".")
 	ident = slicekit.Map(ident, strings.TrimSpace)
@@ -568,6 +350,8 @@ func (b *Batch[ENT, ID]) stream(ctx context.Context) (rErr error) {
 			return logging.ErrField(rErr)
 		}))
 
+	var created []ENT // for the change feed and the history
+
 	// Copy data into staging table
 	_, err = b.getBatchDestination(ctx).CopyFrom(
 		ctx,
@@ -590,6 +374,9 @@ func (b *Batch[ENT, ID]) stream(ctx context.Context) (rErr error) {
 				if err != nil {
 					return nil, err
 				}
+				if b.Repository.recordsChanges() {
+					created = append(created, v)
+				}
 				var row []any = make([]any, len(columns))
 				for i, c := range columns {
 					row[i] = args[c]
@@ -603,7 +390,10 @@ func (b *Batch[ENT, ID]) stream(ctx context.Context) (rErr error) {
 		return fmt.Errorf("failed to copy into staging table: %w", err)
 	}
 
-	return nil
+	if err := b.Repository.recordChanges(ctx, crud.Created, created...); err != nil {
+		return err
+	}
+	return b.Repository.recordHistory(ctx, crud.Created, created...)
 }
 
 type batchDestination interface {
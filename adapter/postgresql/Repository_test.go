@@ -10,12 +10,19 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.llib.dev/frameless/adapter/postgresql"
+	"go.llib.dev/frameless/pkg/audit"
 	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/frameless/pkg/iterkit"
 
 	"go.llib.dev/frameless/pkg/cache"
 	"go.llib.dev/frameless/pkg/cache/cachecontract"
+	"go.llib.dev/frameless/pkg/contextkit"
+	"go.llib.dev/frameless/pkg/tenant"
+	"go.llib.dev/frameless/pkg/tenant/tenantcontract"
 	"go.llib.dev/frameless/port/crud/crudcontract"
 	"go.llib.dev/frameless/port/crud/crudtest"
+	"go.llib.dev/frameless/port/crud/relationship"
+	"go.llib.dev/frameless/port/crud/relationship/relationshipcontract"
 	"go.llib.dev/frameless/testing/testent"
 	"go.llib.dev/testcase"
 	"go.llib.dev/testcase/assert"
@@ -96,12 +103,178 @@ func TestRepository(t *testing.T) {
 	testcase.RunSuite(s,
 		crudcontract.Creator[Entity, string](subject, config),
 		crudcontract.Finder[Entity, string](subject, config),
+		crudcontract.ByIDsFinder[Entity, string](subject, config),
 		crudcontract.Updater[Entity, string](subject, config),
+		crudcontract.Saver[Entity, string](subject, config),
 		crudcontract.Deleter[Entity, string](subject, config),
 		crudcontract.OnePhaseCommitProtocol[Entity, string](subject, subject.Connection),
 		crudcontract.Batcher(subject, config))
 }
 
+func TestRepository_Querier(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+
+	subject := &postgresql.Repository[Entity, string]{
+		Connection: cm,
+		Mapping:    EntityMapping(),
+	}
+
+	crudcontract.Querier[Entity, string](subject, crudcontract.QuerierField[Entity]{
+		Name: "foo",
+		Set:  func(ptr *Entity, v string) { ptr.Foo = v },
+	}, crudcontract.Config[Entity, string]{
+		MakeContext:    MakeContext,
+		MakeEntity:     func(tb testing.TB) Entity { return MakeEntityFunc(tb)() },
+		OnePhaseCommit: cm,
+	}).Test(t)
+}
+
+func TestRepository_aggregation(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+
+	subject := &postgresql.Repository[Entity, string]{
+		Connection: cm,
+		Mapping:    EntityMapping(),
+	}
+
+	field := crudcontract.QuerierField[Entity]{
+		Name: "foo",
+		Set:  func(ptr *Entity, v string) { ptr.Foo = v },
+	}
+	conf := crudcontract.Config[Entity, string]{
+		MakeContext:    MakeContext,
+		MakeEntity:     func(tb testing.TB) Entity { return MakeEntityFunc(tb)() },
+		OnePhaseCommit: cm,
+	}
+	testcase.RunSuite(t,
+		crudcontract.Counter[Entity, string](subject, conf),
+		crudcontract.QueryCounter[Entity, string](subject, field, conf),
+		crudcontract.GroupCounter[Entity, string](subject, field, conf),
+		crudcontract.Exister[Entity, string](subject, conf),
+	)
+}
+
+func TestRepository_PageFinder(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+
+	subject := &postgresql.Repository[Entity, string]{
+		Connection: cm,
+		Mapping:    EntityMapping(),
+	}
+
+	crudcontract.PageFinder[Entity, string](subject, crudcontract.Config[Entity, string]{
+		MakeContext: MakeContext,
+		MakeEntity:  func(tb testing.TB) Entity { return MakeEntityFunc(tb)() },
+	}).Test(t)
+}
+
+func TestRepository_VersionedUpdater(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateDoc(t, cm)
+
+	subject := &postgresql.Repository[testent.Doc, testent.DocID]{
+		Connection: cm,
+		Mapping:    DocMapping(),
+	}
+
+	conf := crudcontract.Config[testent.Doc, testent.DocID]{
+		MakeContext:    MakeContext,
+		MakeEntity:     testent.MakeDoc,
+		OnePhaseCommit: cm,
+	}
+	testcase.RunSuite(t,
+		crudcontract.Updater[testent.Doc, testent.DocID](subject, conf),
+		crudcontract.VersionedUpdater[testent.Doc, testent.DocID](subject, conf),
+	)
+}
+
+func TestRepository_SoftDeleter(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+
+	_, err := cm.ExecContext(context.Background(), `ALTER TABLE "test_entities" ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE`)
+	assert.NoError(t, err)
+
+	mapping := EntityMapping()
+	mapping.DeletedAtColumn = "deleted_at"
+
+	subject := &postgresql.Repository[Entity, string]{
+		Connection: cm,
+		Mapping:    mapping,
+	}
+
+	conf := crudcontract.Config[Entity, string]{
+		MakeContext:    MakeContext,
+		MakeEntity:     func(tb testing.TB) Entity { return MakeEntityFunc(tb)() },
+		OnePhaseCommit: cm,
+	}
+	testcase.RunSuite(t,
+		crudcontract.SoftDeleter[Entity, string](subject, conf),
+		crudcontract.Finder[Entity, string](subject, conf),
+		crudcontract.Updater[Entity, string](subject, conf),
+		crudcontract.PageFinder[Entity, string](subject, conf),
+	)
+}
+
+func TestRepository_ChangeSubscriber(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+
+	subject := &postgresql.Repository[Entity, string]{
+		Connection: cm,
+		Mapping:    EntityMapping(),
+		ChangeFeed: postgresql.ChangeFeedConfig{Enabled: true},
+	}
+	assert.NoError(t, subject.MigrateChangeFeed(context.Background()))
+
+	conf := crudcontract.Config[Entity, string]{
+		MakeContext:    MakeContext,
+		MakeEntity:     func(tb testing.TB) Entity { return MakeEntityFunc(tb)() },
+		OnePhaseCommit: cm,
+	}
+	testcase.RunSuite(t,
+		crudcontract.ChangeSubscriber[Entity, string](subject, conf),
+		crudcontract.Creator[Entity, string](subject, conf),
+		crudcontract.Updater[Entity, string](subject, conf),
+		crudcontract.Deleter[Entity, string](subject, conf),
+		crudcontract.Saver[Entity, string](subject, conf),
+	)
+}
+
+func TestRepository_TemporalFinder(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+
+	subject := &postgresql.Repository[Entity, string]{
+		Connection: cm,
+		Mapping:    EntityMapping(),
+		History:    postgresql.HistoryConfig{Enabled: true},
+	}
+	assert.NoError(t, subject.MigrateHistory(context.Background()))
+
+	conf := crudcontract.Config[Entity, string]{
+		MakeContext:    MakeContext,
+		MakeEntity:     func(tb testing.TB) Entity { return MakeEntityFunc(tb)() },
+		OnePhaseCommit: cm,
+	}
+	testcase.RunSuite(t,
+		crudcontract.TemporalFinder[Entity, string](subject, conf),
+		crudcontract.Creator[Entity, string](subject, conf),
+		crudcontract.Updater[Entity, string](subject, conf),
+		crudcontract.Deleter[Entity, string](subject, conf),
+		crudcontract.Saver[Entity, string](subject, conf),
+	)
+
+	t.Run("AsOf fails when the history is not enabled", func(t *testing.T) {
+		subject := postgresql.Repository[Entity, string]{Connection: cm, Mapping: EntityMapping()}
+		_, err := subject.AsOf(context.Background(), time.Now())
+		assert.Error(t, err)
+	})
+}
+
 func TestRepository_Truncate(t *testing.T) {
 	cm, err := postgresql.Connect(DatabaseURL(t))
 	assert.NoError(t, err)
@@ -615,3 +788,174 @@ func Benchmark_batch(b *testing.B) {
 		})
 	}
 }
+
+func TestRepository_bulk(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+
+	subject := &postgresql.Repository[Entity, string]{
+		Connection: cm,
+		Mapping:    EntityMapping(),
+	}
+
+	conf := crudcontract.Config[Entity, string]{
+		MakeContext:    MakeContext,
+		MakeEntity:     func(tb testing.TB) Entity { return MakeEntityFunc(tb)() },
+		OnePhaseCommit: cm,
+	}
+	testcase.RunSuite(t,
+		crudcontract.ManyCreator[Entity, string](subject, conf),
+		crudcontract.ManyUpdater[Entity, string](subject, conf),
+		crudcontract.ByIDsDeleter[Entity, string](subject, conf),
+	)
+}
+
+func TestRepository_compositeID(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateComposite(t, cm)
+
+	subject := &postgresql.Repository[testent.Composite, testent.CompositeID]{
+		Connection: cm,
+		Mapping:    CompositeMapping,
+	}
+
+	conf := crudcontract.Config[testent.Composite, testent.CompositeID]{
+		MakeContext:     MakeContext,
+		MakeEntity:      testent.MakeComposite,
+		SupportIDReuse:  true,
+		SupportRecreate: true,
+		OnePhaseCommit:  cm,
+	}
+	testcase.RunSuite(t,
+		crudcontract.Creator[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.Finder[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.ByIDsFinder[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.Updater[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.Deleter[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.ByIDsDeleter[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.Saver[testent.Composite, testent.CompositeID](subject, conf),
+	)
+}
+
+func TestRepository_tenant(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+
+	type TenantKey struct{}
+	var tenantOf contextkit.ValueHandler[TenantKey, string]
+
+	subject := tenant.Repository[Entity, string, string]{
+		Source: postgresql.Repository[Entity, string]{
+			Connection: cm,
+			Mapping:    EntityMapping(),
+		},
+		Context:     tenantOf,
+		TenantA:     func(v *Entity) *string { return &v.Bar },
+		TenantField: "bar",
+	}
+
+	conf := crudcontract.Config[Entity, string]{
+		MakeContext: func(tb testing.TB) context.Context {
+			return tenantOf.ContextWith(MakeContext(tb), "42")
+		},
+		MakeEntity: func(tb testing.TB) Entity {
+			ent := MakeEntityFunc(tb)()
+			ent.Bar = ""
+			return ent
+		},
+		OnePhaseCommit: cm,
+	}
+	testcase.RunSuite(t,
+		crudcontract.Creator[Entity, string](subject, conf),
+		crudcontract.Finder[Entity, string](subject, conf),
+		crudcontract.Updater[Entity, string](subject, conf),
+		crudcontract.Deleter[Entity, string](subject, conf),
+		tenantcontract.Repository(subject, tenantcontract.Config[Entity, string]{
+			MakeContext: MakeContext,
+			MakeEntity:  func(tb testing.TB) Entity { return MakeEntityFunc(tb)() },
+		}),
+	)
+}
+
+func TestRepository_audit(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+
+	events := postgresql.AuditEventRepository[string]{Name: "test_entities", Connection: cm}
+	assert.NoError(t, events.Migrate(MakeContext(t)))
+
+	subject := audit.Repository[Entity, string]{
+		Source: postgresql.Repository[Entity, string]{Connection: cm, Mapping: EntityMapping()},
+		Events: events,
+	}
+
+	conf := crudcontract.Config[Entity, string]{
+		MakeContext:    MakeContext,
+		MakeEntity:     func(tb testing.TB) Entity { return MakeEntityFunc(tb)() },
+		OnePhaseCommit: cm,
+	}
+	testcase.RunSuite(t,
+		crudcontract.Creator[Entity, string](subject, conf),
+		crudcontract.Finder[Entity, string](subject, conf),
+		crudcontract.Updater[Entity, string](subject, conf),
+		crudcontract.Deleter[Entity, string](subject, conf),
+	)
+
+	t.Run("the changes are recorded in the audit table", func(t *testing.T) {
+		ctx := MakeContext(t)
+		ent := MakeEntityFunc(t)()
+		assert.NoError(t, subject.Create(ctx, &ent))
+		t.Cleanup(func() { _ = subject.DeleteByID(ctx, ent.ID) })
+
+		history, err := iterkit.CollectE(subject.History(ctx, ent.ID))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(history))
+		assert.Equal(t, audit.Create, history[0].Action)
+	})
+}
+
+func TestRepository_relationship(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+	MigrateFoo(t, cm)
+
+	// testent.Foo belongs to the Entity through its Bar field
+	t.Cleanup(relationship.BelongsTo[testent.Foo, Entity](func(foo *testent.Foo) *string {
+		return &foo.Bar
+	}))
+
+	var (
+		entities = &postgresql.Repository[Entity, string]{Connection: cm, Mapping: EntityMapping()}
+		foos     = &postgresql.Repository[testent.Foo, testent.FooID]{Connection: cm, Mapping: FooMapping}
+	)
+	subjects := func(policy relationship.Policy) (relationship.Repository[Entity, string], relationship.Repository[testent.Foo, testent.FooID]) {
+		return relationship.Repository[Entity, string]{
+				Source: entities,
+				Dependents: []relationship.Dependent{
+					relationship.Referrer[testent.Foo, testent.FooID]{Repository: foos, Policy: policy},
+				},
+			},
+			relationship.Repository[testent.Foo, testent.FooID]{
+				Source:  foos,
+				Targets: []relationship.Target{relationship.Referenced[Entity, string]{Repository: entities}},
+			}
+	}
+
+	conf := relationshipcontract.Config[Entity, testent.Foo]{
+		MakeContext: MakeContext,
+		MakeEntity:  func(tb testing.TB) Entity { return MakeEntityFunc(tb)() },
+		MakeReferrer: func(tb testing.TB) testent.Foo {
+			foo := testent.MakeFoo(tb)
+			foo.Bar = ""
+			return foo
+		},
+	}
+
+	restrictEntities, restrictFoos := subjects(relationship.Restrict)
+	cascadeEntities, cascadeFoos := subjects(relationship.Cascade)
+	testcase.RunSuite(t,
+		relationshipcontract.Restrict[Entity, string, testent.Foo, testent.FooID](restrictEntities, restrictFoos, conf),
+		relationshipcontract.Cascade[Entity, string, testent.Foo, testent.FooID](cascadeEntities, cascadeFoos, conf),
+		relationshipcontract.References[testent.Foo, testent.FooID, Entity, string](restrictFoos, restrictEntities, conf),
+	)
+}
@@ -5,7 +5,7 @@ go 1.25.0
 require (
 	github.com/jackc/pgx/v5 v5.10.0
 	go.llib.dev/frameless v0.332.0
-	go.llib.dev/testcase v0.192.0
+	go.llib.dev/testcase v0.193.0
 )
 
 require (
@@ -15,3 +15,5 @@ require (
 	golang.org/x/sync v0.22.0 // indirect
 	golang.org/x/text v0.40.0 // indirect
 )
+
+replace go.llib.dev/frameless => ../..
@@ -1,6 +1,9 @@
 package postgresql
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 func makePrepareStatementPlaceholderGenerator() func() string {
 	var index = 0
@@ -9,3 +12,8 @@ func makePrepareStatementPlaceholderGenerator() func() string {
 		return fmt.Sprintf(`$%d`, index)
 	}
 }
+
+// quoteLiteral quotes a string literal for the statements that don't accept query parameters.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
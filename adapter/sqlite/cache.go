@@ -0,0 +1,213 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.llib.dev/frameless/adapter/sqlite/internal/queries"
+	"go.llib.dev/frameless/pkg/cache"
+	"go.llib.dev/frameless/pkg/dtokit"
+	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/frameless/pkg/zerokit"
+	"go.llib.dev/frameless/port/crud/extid"
+	"go.llib.dev/frameless/port/migration"
+)
+
+// CacheRepository is a generic implementation for using SQLite as a caching backend with `frameless/pkg/cache.Cache`.
+// CacheRepository implements `cache.Repository[ENT,ID]`
+type CacheRepository[ENT, ID any] struct {
+	Connection Connection
+	// ID [required] is unique identifier.  the table name prefix used to create the cache repository tables.
+	//
+	// Example:
+	// 		ID: "foo"
+	// 			-> "foo_cache_entities"
+	//
+	ID string
+	// JSONDTOM [optional] is the mapping between an ENT type and a JSON DTO type,
+	// which is used to encode entities within the entity repository.
+	// This mapping is important because if the entity type changes during refactoring,
+	// the previously cached data can still be correctly decoded using the JSON DTO.
+	// This means you won’t need to delete cached data or worry about data corruption.
+	// It provides a safeguard, ensuring smooth transitions without affecting stored data.
+	JSONDTOM dtokit.Mapper[ENT]
+	// IDA is the ID accessor, that explains how the ID field of the ENT can be accessed.
+	IDA extid.Accessor[ENT, ID]
+	// IDM is the mapping between ID and the string type which is used in the CacheRepository tables to represent the ID value.
+	// If the ID is a string type, then this field can be ignored.
+	IDM dtokit.MapperTo[ID, string]
+}
+
+func (r CacheRepository[ENT, ID]) getIDM() dtokit.MapperTo[ID, string] {
+	if r.IDM != nil {
+		return r.IDM
+	}
+	// fallback mapping logic
+	return dtokit.Mapping[ID, string]{}
+}
+
+func (r CacheRepository[ENT, ID]) tableName(name string) string {
+	var prefix = r.ID
+	if prefix == "" {
+		const format = "implementation error: missing CacheRepository.ID field (%#v)"
+		panic(fmt.Errorf(format, r))
+	}
+	return strings.Join([]string{prefix, "cache", name}, "_")
+}
+
+func (r CacheRepository[ENT, ID]) tableNameEntities() string {
+	return r.tableName("entities")
+}
+
+func (r CacheRepository[ENT, ID]) tableNameHits() string {
+	return r.tableName("hits")
+}
+
+func (r CacheRepository[ENT, ID]) jsonDTOM() dtokit.Mapper[ENT] {
+	return zerokit.Coalesce[dtokit.Mapper[ENT]](r.JSONDTOM, dtokit.Mapping[ENT, ENT]{})
+}
+
+func (r CacheRepository[ENT, ID]) BeginTx(ctx context.Context) (context.Context, error) {
+	return r.Connection.BeginTx(ctx)
+}
+
+func (r CacheRepository[ENT, ID]) CommitTx(ctx context.Context) error {
+	return r.Connection.CommitTx(ctx)
+}
+
+func (r CacheRepository[ENT, ID]) RollbackTx(ctx context.Context) error {
+	return r.Connection.RollbackTx(ctx)
+}
+
+func (r CacheRepository[ENT, ID]) Migrate(ctx context.Context) error {
+	entitiesTableName := r.tableNameEntities()
+	hitsTableName := r.tableNameHits()
+	m := MakeMigrator(r.Connection, r.tableName("migration"), migration.Steps[Connection]{
+		"1": flsql.MigrationStep[Connection]{
+			UpQuery:   fmt.Sprintf(queries.CreateTableCacheEntitiesTmpl, entitiesTableName),
+			DownQuery: fmt.Sprintf(queries.DropTableTmpl, entitiesTableName),
+		},
+		"2": flsql.MigrationStep[Connection]{
+			UpQuery:   fmt.Sprintf(queries.CreateTableCacheHitsTmpl, hitsTableName),
+			DownQuery: fmt.Sprintf(queries.DropTableTmpl, hitsTableName),
+		},
+	})
+	return m.Migrate(ctx)
+}
+
+func (r CacheRepository[ENT, ID]) Entities() cache.EntityRepository[ENT, ID] {
+	return Repository[ENT, ID]{
+		Connection: r.Connection,
+		Mapping: flsql.Mapping[ENT, ID]{
+			TableName: r.tableNameEntities(),
+			ID:        r.IDA,
+			ToQuery: func(ctx context.Context) ([]flsql.ColumnName, flsql.MapScan[ENT]) {
+				return []flsql.ColumnName{"id", "data"},
+					func(v *ENT, s flsql.Scanner) error {
+						if v == nil {
+							return fmt.Errorf("nil %T pointer given for scanning", v)
+						}
+						var (
+							idDTO      string
+							dataDTOPtr = r.jsonDTOM().NewDTO()
+						)
+						if err := s.Scan(&idDTO, JSON(&dataDTOPtr)); err != nil {
+							return err
+						}
+						id, err := r.getIDM().MapToENT(ctx, idDTO)
+						if err != nil {
+							return err
+						}
+						ent, err := r.jsonDTOM().MapFromDTO(ctx, dataDTOPtr)
+						if err != nil {
+							return err
+						}
+						*v = ent
+						return r.IDA.Set(v, id)
+					}
+			},
+			QueryID: func(id ID) (flsql.QueryArgs, error) {
+				ctx := context.Background()
+				idDTO, err := r.getIDM().MapToDTO(ctx, id)
+				if err != nil {
+					return nil, err
+				}
+				return flsql.QueryArgs{"id": idDTO}, nil
+			},
+			ToArgs: func(e ENT) (flsql.QueryArgs, error) {
+				ctx := context.Background()
+				id, _ := r.IDA.Lookup(e)
+				idDTO, err := r.getIDM().MapToDTO(ctx, id)
+				if err != nil {
+					return nil, err
+				}
+				return flsql.QueryArgs{
+					"id":   idDTO,
+					"data": JSON(&e),
+				}, nil
+			},
+		},
+	}
+}
+
+func (r CacheRepository[ENT, ID]) Hits() cache.HitRepository[ID] {
+	return Repository[cache.Hit[ID], cache.HitID]{
+		Connection: r.Connection,
+		Mapping: flsql.Mapping[cache.Hit[ID], cache.HitID]{
+			TableName: r.tableNameHits(),
+			ID: func(h *cache.Hit[ID]) *cache.HitID {
+				return &h.ID
+			},
+			ToQuery: func(ctx context.Context) ([]flsql.ColumnName, flsql.MapScan[cache.Hit[ID]]) {
+				return []flsql.ColumnName{"query_id", "ent_ids", "timestamp"},
+					func(v *cache.Hit[ID], s flsql.Scanner) error {
+						if v == nil {
+							return fmt.Errorf("nil %T was given for scanning", v)
+						}
+						var idDTOs []string
+						if err := s.Scan(&v.ID, JSON(&idDTOs), Timestamp(&v.Timestamp)); err != nil {
+							return err
+						}
+						v.EntityIDs = nil
+						for _, idDTO := range idDTOs {
+							id, err := r.getIDM().MapToENT(ctx, idDTO)
+							if err != nil {
+								return err
+							}
+							v.EntityIDs = append(v.EntityIDs, id)
+						}
+						return nil
+					}
+			},
+			QueryID: func(id cache.HitID) (flsql.QueryArgs, error) {
+				return flsql.QueryArgs{"query_id": id}, nil
+			},
+			ToArgs: func(h cache.Hit[ID]) (flsql.QueryArgs, error) {
+				ctx := context.Background()
+				var idDTOs []string
+				for _, id := range h.EntityIDs {
+					idDTO, err := r.getIDM().MapToDTO(ctx, id)
+					if err != nil {
+						return nil, err
+					}
+					idDTOs = append(idDTOs, idDTO)
+				}
+				return flsql.QueryArgs{
+					"query_id":  h.ID,
+					"ent_ids":   JSON(&idDTOs),
+					"timestamp": Timestamp(&h.Timestamp),
+				}, nil
+			},
+			Prepare: func(ctx context.Context, h *cache.Hit[ID]) error {
+				if h == nil {
+					return fmt.Errorf("nil %T was sent for %T.Hits().Create", h, r)
+				}
+				if h.ID == "" {
+					return fmt.Errorf("empty query id was given for %T", h)
+				}
+				return nil
+			},
+		},
+	}
+}
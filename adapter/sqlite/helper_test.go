@@ -0,0 +1,248 @@
+package sqlite_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"go.llib.dev/frameless/adapter/sqlite"
+	"go.llib.dev/frameless/pkg/env"
+	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/frameless/pkg/zerokit"
+	"go.llib.dev/frameless/testing/testent"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/random"
+)
+
+var (
+	_Connection     sqlite.Connection
+	mutexConnection sync.Mutex
+)
+
+func GetConnection(tb testing.TB) sqlite.Connection {
+	mutexConnection.Lock()
+	defer mutexConnection.Unlock()
+	if zerokit.IsZero(_Connection) {
+		conn, err := sqlite.Connect(DatabaseDSN(tb))
+		assert.NoError(tb, err)
+		assert.NotNil(tb, conn)
+		_Connection = conn
+	}
+	assert.NoError(tb, _Connection.DB.Ping())
+	return _Connection
+}
+
+var (
+	_DatabaseDSN     string
+	mutexDatabaseDSN sync.Mutex
+)
+
+// DatabaseDSN returns the SQLITE_DATABASE_DSN env variable,
+// or a database file in a temporary directory that lives as long as the test process.
+func DatabaseDSN(tb testing.TB) string {
+	const envKey = "SQLITE_DATABASE_DSN"
+	dsn, ok, err := env.Lookup[string](envKey)
+	assert.NoError(tb, err)
+	if ok {
+		return dsn
+	}
+	mutexDatabaseDSN.Lock()
+	defer mutexDatabaseDSN.Unlock()
+	if _DatabaseDSN == "" {
+		dir, err := os.MkdirTemp("", "frameless-sqlite-*")
+		assert.NoError(tb, err)
+		_DatabaseDSN = filepath.Join(dir, "test.db")
+	}
+	return _DatabaseDSN
+}
+
+var rnd = random.New(random.CryptoSeed{})
+
+func MakeContext(testing.TB) context.Context { return context.Background() }
+
+type Entity struct {
+	ID  string `ext:"ID"`
+	Foo string
+	Bar string
+	Baz string
+}
+
+type EntityDTO struct {
+	ID  string `ext:"ID" json:"id"`
+	Foo string `json:"foo"`
+	Bar string `json:"bar"`
+	Baz string `json:"baz"`
+}
+
+type EntityJSONMapping struct{}
+
+func (n EntityJSONMapping) MapToDTO(_ context.Context, ent Entity) (EntityDTO, error) {
+	return EntityDTO{ID: ent.ID, Foo: ent.Foo, Bar: ent.Bar, Baz: ent.Baz}, nil
+}
+
+func (n EntityJSONMapping) MapToENT(_ context.Context, dto EntityDTO) (Entity, error) {
+	return Entity{ID: dto.ID, Foo: dto.Foo, Bar: dto.Bar, Baz: dto.Baz}, nil
+}
+
+func EntityMapping() flsql.Mapping[Entity, string] {
+	return flsql.Mapping[Entity, string]{
+		TableName: "test_entities",
+
+		QueryID: func(id string) (flsql.QueryArgs, error) {
+			return flsql.QueryArgs{"id": id}, nil
+		},
+
+		ToArgs: func(e Entity) (flsql.QueryArgs, error) {
+			return flsql.QueryArgs{
+				`id`:  e.ID,
+				`foo`: e.Foo,
+				`bar`: e.Bar,
+				`baz`: e.Baz,
+			}, nil
+		},
+
+		ToQuery: func(ctx context.Context) ([]flsql.ColumnName, flsql.MapScan[Entity]) {
+			return []flsql.ColumnName{`id`, `foo`, `bar`, `baz`},
+				func(v *Entity, scan flsql.Scanner) error {
+					return scan.Scan(&v.ID, &v.Foo, &v.Bar, &v.Baz)
+				}
+		},
+
+		Prepare: func(ctx context.Context, e *Entity) error {
+			if zerokit.IsZero(e.ID) {
+				e.ID = rnd.UUID()
+			}
+			return nil
+		},
+	}
+}
+
+func MigrateEntity(tb testing.TB, cm flsql.Connection) {
+	migrate(tb, cm, testMigrateUP, testMigrateDOWN)
+}
+
+const testMigrateUP = `
+CREATE TABLE test_entities (
+    id         TEXT      NOT NULL PRIMARY KEY,
+    foo        TEXT      NOT NULL,
+    bar        TEXT      NOT NULL,
+    baz        TEXT      NOT NULL,
+    deleted_at TIMESTAMP NULL
+);
+`
+
+const testMigrateDOWN = `
+DROP TABLE IF EXISTS test_entities;
+`
+
+var DocMapping = flsql.Mapping[testent.Doc, testent.DocID]{
+	TableName:     "test_docs",
+	VersionColumn: "version",
+
+	QueryID: func(id testent.DocID) (flsql.QueryArgs, error) {
+		return flsql.QueryArgs{"id": id}, nil
+	},
+
+	ToArgs: func(d testent.Doc) (flsql.QueryArgs, error) {
+		return flsql.QueryArgs{
+			`id`:      d.ID,
+			`title`:   d.Title,
+			`body`:    d.Body,
+			`version`: d.Version,
+		}, nil
+	},
+
+	ToQuery: func(ctx context.Context) ([]flsql.ColumnName, flsql.MapScan[testent.Doc]) {
+		return []flsql.ColumnName{`id`, `title`, `body`, `version`},
+			func(v *testent.Doc, s flsql.Scanner) error {
+				return s.Scan(&v.ID, &v.Title, &v.Body, &v.Version)
+			}
+	},
+
+	Prepare: func(ctx context.Context, d *testent.Doc) error {
+		if zerokit.IsZero(d.ID) {
+			d.ID = testent.DocID(rnd.UUID())
+		}
+		return nil
+	},
+}
+
+func MigrateDoc(tb testing.TB, cm flsql.Connection) {
+	migrate(tb, cm, testMigrateDocUP, testMigrateDocDOWN)
+}
+
+const testMigrateDocUP = `
+CREATE TABLE test_docs (
+    id      TEXT    NOT NULL PRIMARY KEY,
+    title   TEXT    NOT NULL,
+    body    TEXT    NOT NULL,
+    version INTEGER NOT NULL
+);
+`
+
+const testMigrateDocDOWN = `
+DROP TABLE IF EXISTS test_docs;
+`
+
+var CompositeMapping = flsql.Mapping[testent.Composite, testent.CompositeID]{
+	TableName: "composites",
+
+	ToQuery: func(ctx context.Context) ([]flsql.ColumnName, flsql.MapScan[testent.Composite]) {
+		return []flsql.ColumnName{"tenant_id", "local_id", "value"},
+			func(v *testent.Composite, sf flsql.Scanner) error {
+				return sf.Scan(&v.TenantID, &v.LocalID, &v.Value)
+			}
+	},
+
+	QueryID: func(id testent.CompositeID) (flsql.QueryArgs, error) {
+		return flsql.QueryArgs{"tenant_id": id.TenantID, "local_id": id.LocalID}, nil
+	},
+
+	ToArgs: func(v testent.Composite) (flsql.QueryArgs, error) {
+		return flsql.QueryArgs{
+			"tenant_id": v.TenantID,
+			"local_id":  v.LocalID,
+			"value":     v.Value,
+		}, nil
+	},
+
+	Prepare: func(ctx context.Context, v *testent.Composite) error {
+		if zerokit.IsZero(v.TenantID) && zerokit.IsZero(v.LocalID) {
+			v.TenantID = rnd.UUID()
+			v.LocalID = rnd.IntBetween(1, 1<<30)
+		}
+		return nil
+	},
+}
+
+func MigrateComposite(tb testing.TB, cm flsql.Connection) {
+	migrate(tb, cm, testMigrateCompositeUP, testMigrateCompositeDOWN)
+}
+
+const testMigrateCompositeUP = `
+CREATE TABLE composites (
+    tenant_id TEXT    NOT NULL,
+    local_id  INTEGER NOT NULL,
+    value     TEXT    NOT NULL,
+    PRIMARY KEY (tenant_id, local_id)
+);
+`
+
+const testMigrateCompositeDOWN = `
+DROP TABLE IF EXISTS composites;
+`
+
+func migrate(tb testing.TB, cm flsql.Connection, up, down string) {
+	ctx := context.Background()
+	_, err := cm.ExecContext(ctx, down)
+	assert.Nil(tb, err)
+	_, err = cm.ExecContext(ctx, up)
+	assert.Nil(tb, err)
+
+	tb.Cleanup(func() {
+		_, err := cm.ExecContext(ctx, down)
+		assert.Nil(tb, err)
+	})
+}
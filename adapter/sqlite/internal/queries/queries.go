@@ -0,0 +1,44 @@
+package queries
+
+import "fmt"
+
+const CreateTableSchemaMigrationsTmpl = `CREATE TABLE IF NOT EXISTS %s (
+	namespace  TEXT    NOT NULL,
+	version    TEXT    NOT NULL,
+	dirty      BOOLEAN NOT NULL,
+	PRIMARY KEY (namespace, version)
+)`
+
+const DropTableTmpl = `DROP TABLE IF EXISTS %s`
+
+const CreateTableCacheEntitiesTmpl = `CREATE TABLE %s (
+    id   TEXT PRIMARY KEY,
+    data TEXT NOT NULL
+)`
+
+const CreateTableCacheHitsTmpl = `CREATE TABLE %s (
+    query_id  TEXT      PRIMARY KEY,
+    ent_ids   TEXT      NOT NULL,
+    timestamp TIMESTAMP NOT NULL
+)`
+
+const CreateTableLocker = `
+CREATE TABLE IF NOT EXISTS frameless_guard_locks (
+    name       TEXT    PRIMARY KEY,
+    token      TEXT    NOT NULL,
+    expires_at INTEGER NOT NULL
+)`
+
+const CreateTableQueue = `
+CREATE TABLE IF NOT EXISTS frameless_queue_messages (
+    seq          INTEGER PRIMARY KEY AUTOINCREMENT,
+    id           TEXT    NOT NULL UNIQUE,
+    queue        TEXT    NOT NULL,
+    data         TEXT    NOT NULL,
+    created_at   TIMESTAMP NOT NULL,
+    leased_until INTEGER NULL
+);
+CREATE INDEX IF NOT EXISTS frameless_queue_messages_queue_idx ON frameless_queue_messages (queue, seq);
+`
+
+var DropTableQueue = fmt.Sprintf(DropTableTmpl, "frameless_queue_messages")
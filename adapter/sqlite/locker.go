@@ -0,0 +1,232 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.llib.dev/frameless/adapter/sqlite/internal/queries"
+	"go.llib.dev/frameless/pkg/contextkit"
+	"go.llib.dev/frameless/pkg/errorkit"
+	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/frameless/port/guard"
+	"go.llib.dev/frameless/port/migration"
+	"go.llib.dev/testcase/clock"
+	"go.llib.dev/testcase/random"
+)
+
+// Locker is a SQLite-based shared mutex implementation.
+//
+// SQLite has no advisory locks, and a transaction that holds a lock would block every writer of the database,
+// thus a lock is a row in the locks table, that is held with a lease.
+// The lease is renewed while the lock is held,
+// and it expires when the lock holder process stops without unlocking.
+// When the lease can't be renewed, the lock context is cancelled.
+type Locker struct {
+	Name       string
+	Connection Connection
+	// Lease [optional] is how long a lock stays valid without renewal.
+	//
+	// default: 30 seconds
+	Lease time.Duration
+}
+
+const (
+	defaultLockLease        = 30 * time.Second
+	lockAcquireRetryBackoff = 10 * time.Millisecond
+)
+
+func (l Locker) lease() time.Duration {
+	if l.Lease <= 0 {
+		return defaultLockLease
+	}
+	return l.Lease
+}
+
+func (l Locker) Lock(ctx context.Context) (context.Context, error) {
+	if ctx == nil {
+		return nil, errNoContext
+	}
+	if _, ok := l.lookup(ctx); ok {
+		return ctx, nil
+	}
+	for {
+		lockCtx, ok, err := l.TryLock(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return lockCtx, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-clock.After(lockAcquireRetryBackoff):
+		}
+	}
+}
+
+const queryTryLock = `INSERT INTO frameless_guard_locks (name, token, expires_at) VALUES (?, ?, ?)
+ON CONFLICT (name) DO UPDATE SET token = excluded.token, expires_at = excluded.expires_at
+WHERE frameless_guard_locks.expires_at < ?`
+
+func (l Locker) TryLock(ctx context.Context) (context.Context, bool, error) {
+	if ctx == nil {
+		return nil, false, errNoContext
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	if _, ok := l.lookup(ctx); ok {
+		return ctx, true, nil
+	}
+
+	var (
+		now   = clock.Now()
+		token = random.New(random.CryptoSeed{}).UUID()
+	)
+	// the lock is not part of the caller's transaction,
+	// otherwise it would be only visible to others after the transaction is committed.
+	result, err := l.Connection.DB.ExecContext(ctx, queryTryLock, l.Name, token, now.Add(l.lease()).UnixNano(), now.UnixNano())
+	if err != nil {
+		return nil, false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return nil, false, err
+	}
+	if n == 0 {
+		return nil, false, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	lck := &lockerCtxValue{
+		locker: l,
+		token:  token,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go lck.renew()
+	context.AfterFunc(ctx, func() {
+		_ = lck.Unlock(ctx)
+	})
+	return context.WithValue(ctx, lockerCtxKey{name: l.Name}, lck), true, nil
+}
+
+func (l Locker) Unlock(ctx context.Context) error {
+	if ctx == nil {
+		return guard.ErrNoLock
+	}
+	lck, ok := l.lookup(ctx)
+	if !ok {
+		return guard.ErrNoLock
+	}
+	return lck.Unlock(ctx)
+}
+
+func (l Locker) lookup(ctx context.Context) (*lockerCtxValue, bool) {
+	v, ok := ctx.Value(lockerCtxKey{name: l.Name}).(*lockerCtxValue)
+	return v, ok
+}
+
+type (
+	lockerCtxKey   struct{ name string }
+	lockerCtxValue struct {
+		locker   Locker
+		token    string
+		ctx      context.Context
+		cancel   func()
+		done     chan struct{}
+		onUnlock sync.Once
+
+		Error error
+	}
+)
+
+const (
+	queryRenewLock = `UPDATE frameless_guard_locks SET expires_at = ? WHERE name = ? AND token = ?`
+	queryUnlock    = `DELETE FROM frameless_guard_locks WHERE name = ? AND token = ?`
+)
+
+// renew keeps extending the lease of the lock until it is unlocked.
+func (lck *lockerCtxValue) renew() {
+	var (
+		lease = lck.locker.lease()
+		ctx   = contextkit.WithoutValues(lck.ctx)
+	)
+	for {
+		select {
+		case <-lck.done:
+			return
+		case <-clock.After(lease / 3):
+		}
+		result, err := lck.locker.Connection.DB.ExecContext(ctx, queryRenewLock,
+			clock.Now().Add(lease).UnixNano(), lck.locker.Name, lck.token)
+		if err != nil {
+			continue // the next renewal can still succeed within the lease
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			lck.cancel() // the lease expired, and someone else took the lock
+			return
+		}
+	}
+}
+
+func (lck *lockerCtxValue) Unlock(ctx context.Context) error {
+	lck.onUnlock.Do(func() {
+		lckCtxErr := lck.ctx.Err()
+		close(lck.done)
+		// unlock must succeed even when the lock context is already cancelled
+		_, unlockErr := lck.locker.Connection.DB.ExecContext(context.WithoutCancel(ctx), queryUnlock, lck.locker.Name, lck.token)
+		lck.Error = errorkit.Merge(unlockErr, lckCtxErr, ctx.Err())
+		lck.cancel()
+	})
+	return lck.Error
+}
+
+const locksTableName = "frameless_guard_locks"
+
+func (l Locker) Migrate(ctx context.Context) error {
+	return MakeMigrator(l.Connection, locksTableName, migration.Steps[Connection]{
+		"1": flsql.MigrationStep[Connection]{UpQuery: queries.CreateTableLocker},
+	}).Migrate(ctx)
+}
+
+type LockerFactory[Key comparable] struct {
+	Connection Connection
+	// Namespace [optional] allows you to make isolation between locks generated with the same key but for a different namesapce.
+	Namespace string
+	// Lease [optional] is the lease of the issued Locker-s, see Locker.Lease.
+	Lease time.Duration
+}
+
+func (lf LockerFactory[Key]) Migrate(ctx context.Context) error {
+	return Locker{Connection: lf.Connection}.Migrate(ctx)
+}
+
+func (lf LockerFactory[Key]) Purge(ctx context.Context) error {
+	_, err := lf.Connection.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", locksTableName))
+	return err
+}
+
+func (lf LockerFactory[Key]) name(key Key) string {
+	name := fmt.Sprintf("%T:%v", key, key)
+	if lf.Namespace != "" {
+		name = lf.Namespace + "/" + name
+	}
+	return name
+}
+
+func (lf LockerFactory[Key]) locker(key Key) Locker {
+	return Locker{Name: lf.name(key), Connection: lf.Connection, Lease: lf.Lease}
+}
+
+func (lf LockerFactory[Key]) NonBlockingLockerFor(key Key) guard.NonBlockingLocker {
+	return lf.locker(key)
+}
+
+func (lf LockerFactory[Key]) LockerFor(key Key) guard.Locker {
+	return lf.locker(key)
+}
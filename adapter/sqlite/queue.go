@@ -0,0 +1,352 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.llib.dev/frameless/adapter/sqlite/internal/queries"
+	"go.llib.dev/frameless/pkg/contextkit"
+	"go.llib.dev/frameless/pkg/dtokit"
+	"go.llib.dev/frameless/pkg/errorkit"
+	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/port/migration"
+	"go.llib.dev/frameless/port/pubsub"
+	"go.llib.dev/testcase/clock"
+	"go.llib.dev/testcase/random"
+)
+
+// Queue is a polling pubsub queue on top of a SQLite table.
+//
+// A received message is leased to its subscriber for the VisibilityTimeout,
+// instead of being locked by a transaction, since an open write transaction would block every writer in SQLite.
+// A message that is neither ACK-ed nor NACK-ed within its VisibilityTimeout is delivered again.
+// The lease can be extended through the pubsub.LeaseExtender of the message.
+type Queue[Entity, JSONDTO any] struct {
+	Name       string
+	Connection Connection
+	Mapping    dtokit.MapperTo[Entity, JSONDTO]
+
+	// EmptyQueueBreakTime is the time.Duration that the queue waits when the queue is empty for the given queue Name.
+	EmptyQueueBreakTime time.Duration
+	// VisibilityTimeout [optional] is how long a received message is hidden from the other subscribers.
+	//
+	// default: 1 minute
+	VisibilityTimeout time.Duration
+	// Blocking flag will cause the Queue.Publish method to wait until the message is processed.
+	Blocking bool
+
+	// LIFO flag will set the queue to use a Last in First out ordering
+	LIFO bool
+}
+
+const queueTableName = "frameless_queue_messages"
+
+func (q Queue[Entity, JSONDTO]) Migrate(ctx context.Context) error {
+	return MakeMigrator(q.Connection, queueTableName, migration.Steps[Connection]{
+		"0": flsql.MigrationStep[Connection]{
+			UpQuery:   queries.CreateTableQueue,
+			DownQuery: queries.DropTableQueue,
+		},
+	}).Migrate(ctx)
+}
+
+func (q Queue[Entity, JSONDTO]) Purge(ctx context.Context) error {
+	_, err := q.Connection.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", queueTableName))
+	return err
+}
+
+func (q Queue[Entity, JSONDTO]) Publish(ctx context.Context, v Entity) error {
+	return q.PublishMany(ctx, v)
+}
+
+func (q Queue[Entity, JSONDTO]) PublishMany(ctx context.Context, vs ...Entity) error {
+	if q.Name == "" {
+		return fmt.Errorf("missing queue name")
+	}
+	if len(vs) == 0 {
+		return nil
+	}
+	var (
+		rnd    = random.New(random.CryptoSeed{})
+		values []string
+		args   []any
+		ids    []any
+	)
+	for _, v := range vs {
+		dto, err := q.Mapping.MapToDTO(ctx, v)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(dto)
+		if err != nil {
+			return err
+		}
+		id := rnd.UUID()
+		ids = append(ids, id)
+		values = append(values, "(?, ?, ?, ?)")
+		args = append(args, id, q.Name, string(data), clock.Now().UTC())
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (id, queue, data, created_at) VALUES %s",
+		queueTableName, strings.Join(values, ", "))
+
+	if _, err := q.Connection.ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+
+	if q.Blocking {
+		checkQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE id IN (%s)",
+			queueTableName, strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", "))
+		for {
+			var count int
+			if err := q.Connection.QueryRowContext(ctx, checkQuery, ids...).Scan(&count); err != nil {
+				return err
+			}
+			if count == 0 {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-clock.After(q.getEmptyQueueBreakTime()):
+			}
+		}
+	}
+
+	return nil
+}
+
+func (q Queue[Entity, JSONDTO]) Subscribe(ctx context.Context) pubsub.Subscription[Entity] {
+	return iterkit.From(func(yield func(pubsub.Message[Entity]) bool) (rErr error) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := q.Connection.DB.PingContext(ctx); err != nil {
+			return err
+		}
+		sub := &queueSubscription[Entity, JSONDTO]{
+			Queue: q,
+			CTX:   ctx,
+		}
+		defer errorkit.Finish(&rErr, sub.Close)
+		for sub.Next() {
+			if !yield(sub.Value()) {
+				return nil
+			}
+		}
+		return sub.Err()
+	})
+}
+
+func (q Queue[Entity, JSONDTO]) getEmptyQueueBreakTime() time.Duration {
+	const defaultBreakTime = 42 * time.Millisecond
+	if q.EmptyQueueBreakTime == 0 {
+		return defaultBreakTime
+	}
+	return q.EmptyQueueBreakTime
+}
+
+func (q Queue[Entity, JSONDTO]) getVisibilityTimeout() time.Duration {
+	const defaultVisibilityTimeout = time.Minute
+	if q.VisibilityTimeout <= 0 {
+		return defaultVisibilityTimeout
+	}
+	return q.VisibilityTimeout
+}
+
+type queueSubscription[Entity, JSONDTO any] struct {
+	CTX   context.Context
+	Queue Queue[Entity, JSONDTO]
+
+	idle   int32
+	closed bool
+	err    error
+	value  *queueMessage[Entity, JSONDTO]
+}
+
+func (qs *queueSubscription[Entity, JSONDTO]) IsIdle() bool {
+	return atomic.LoadInt32(&qs.idle) == 0
+}
+
+func (qs *queueSubscription[Entity, JSONDTO]) Close() error {
+	if qs.value != nil {
+		_ = qs.value.NACK()
+	}
+	qs.closed = true
+	return nil
+}
+
+func (qs *queueSubscription[Entity, JSONDTO]) Err() error {
+	return qs.err
+}
+
+// queryQueueLeaseMessage leases the next visible message.
+// SQLite has a single writer at a time, thus the lease is taken atomically.
+const queryQueueLeaseMessage = `
+UPDATE ` + queueTableName + `
+   SET leased_until = ?
+ WHERE seq = (
+       SELECT seq
+         FROM ` + queueTableName + `
+        WHERE queue = ? AND (leased_until IS NULL OR leased_until <= ?)
+        ORDER BY seq %s
+        LIMIT 1
+ )
+RETURNING seq, data;
+`
+
+func (qs *queueSubscription[Entity, JSONDTO]) Next() bool {
+fetch:
+
+	if err := qs.CTX.Err(); err != nil {
+		return false
+	}
+
+	if qs.closed {
+		return false
+	}
+
+	if qs.err != nil {
+		return false
+	}
+
+	atomic.StoreInt32(&qs.idle, 1)
+
+	if qs.value != nil {
+		_ = qs.value.NACK()
+		qs.value = nil
+	}
+
+	var ordering = "ASC"
+	if qs.Queue.LIFO {
+		ordering = "DESC"
+	}
+
+	var (
+		now         = clock.Now()
+		leasedUntil = now.Add(qs.Queue.getVisibilityTimeout()).UnixNano()
+		row         = qs.Queue.Connection.QueryRowContext(qs.CTX, fmt.Sprintf(queryQueueLeaseMessage, ordering),
+			leasedUntil, qs.Queue.Name, now.UnixNano())
+		seq  int64
+		data string
+	)
+	if err := row.Scan(&seq, &data); err != nil {
+		if errors.Is(err, qs.CTX.Err()) {
+			return false
+		}
+		if errors.Is(err, errNoRows) {
+			atomic.StoreInt32(&qs.idle, 0)
+			select {
+			case <-qs.CTX.Done():
+				return false
+			case <-clock.After(qs.Queue.getEmptyQueueBreakTime()):
+				goto fetch
+			}
+		}
+		qs.err = err
+		return false
+	}
+
+	msg := &queueMessage[Entity, JSONDTO]{
+		q:           qs.Queue,
+		ctx:         qs.CTX,
+		seq:         seq,
+		leasedUntil: leasedUntil,
+	}
+
+	var dto JSONDTO
+	if err := json.Unmarshal([]byte(data), &dto); err != nil {
+		qs.err = errorkit.Merge(err, msg.NACK())
+		return false
+	}
+
+	ent, err := qs.Queue.Mapping.MapToENT(qs.CTX, dto)
+	if err != nil {
+		qs.err = errorkit.Merge(err, msg.NACK())
+		return false
+	}
+
+	msg.data = ent
+	qs.value = msg
+	return true
+}
+
+func (qs *queueSubscription[Entity, JSONDTO]) Value() pubsub.Message[Entity] {
+	return qs.value
+}
+
+type queueMessage[Entity, JSONDTO any] struct {
+	q           Queue[Entity, JSONDTO]
+	ctx         context.Context
+	seq         int64
+	leasedUntil int64
+	data        Entity
+}
+
+// ErrLeaseExpired is returned when a message is ACK-ed or NACK-ed after its visibility timeout,
+// since by then it could be delivered to another subscriber.
+const ErrLeaseExpired = pubsub.ErrLeaseExpired
+
+var _ pubsub.LeaseExtender = &queueMessage[any, any]{}
+
+func (qm *queueMessage[Entity, JSONDTO]) Context() context.Context {
+	return qm.ctx
+}
+
+func (qm *queueMessage[Entity, JSONDTO]) ACK() error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE seq = ? AND leased_until = ?", queueTableName)
+	return qm.release(query)
+}
+
+func (qm *queueMessage[Entity, JSONDTO]) NACK() error {
+	query := fmt.Sprintf("UPDATE %s SET leased_until = NULL WHERE seq = ? AND leased_until = ?", queueTableName)
+	return qm.release(query)
+}
+
+// ExtendLease implements the pubsub.LeaseExtender.
+func (qm *queueMessage[Entity, JSONDTO]) ExtendLease(ctx context.Context, d time.Duration) error {
+	leasedUntil := clock.Now().Add(d).UnixNano()
+	query := fmt.Sprintf("UPDATE %s SET leased_until = ? WHERE seq = ? AND leased_until = ?", queueTableName)
+	result, err := qm.q.Connection.ExecContext(ctx, query, leasedUntil, qm.seq, qm.leasedUntil)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLeaseExpired
+	}
+	qm.leasedUntil = leasedUntil
+	return nil
+}
+
+func (qm *queueMessage[Entity, JSONDTO]) release(query string) error {
+	// when context cancellation happens,
+	// the already received message should be still ACK able
+	// Thus detaching from cancellation is acceptable
+	ctx := contextkit.Detach(qm.ctx)
+	result, err := qm.q.Connection.ExecContext(ctx, query, qm.seq, qm.leasedUntil)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLeaseExpired
+	}
+	return nil
+}
+
+func (qm *queueMessage[Entity, JSONDTO]) Data() Entity {
+	return qm.data
+}
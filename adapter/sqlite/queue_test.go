@@ -0,0 +1,137 @@
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"testing"
+	"time"
+
+	"go.llib.dev/frameless/adapter/sqlite"
+	"go.llib.dev/frameless/port/migration"
+	"go.llib.dev/frameless/port/pubsub/pubsubcontract"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/random"
+)
+
+var _ migration.Migratable = sqlite.Queue[Entity, EntityDTO]{}
+
+func ExampleQueue() {
+	cm, err := sqlite.Connect("file:app.db")
+	if err != nil {
+		panic(err)
+	}
+	defer cm.Close()
+
+	q := sqlite.Queue[Entity, EntityDTO]{
+		Name:       "queue_name",
+		Connection: cm,
+		Mapping:    EntityJSONMapping{},
+	}
+
+	ctx := context.Background()
+	ent := Entity{Foo: "foo"}
+
+	err = q.Publish(ctx, ent)
+	if err != nil {
+		panic(err)
+	}
+
+	for msg, err := range q.Subscribe(ctx) {
+		if err != nil {
+			break
+		}
+		fmt.Println(msg.Data())
+		_ = msg.ACK()
+	}
+}
+
+func TestQueue(t *testing.T) {
+	const queueName = "test_entity"
+	c := GetConnection(t)
+
+	assert.NoError(t,
+		sqlite.Queue[Entity, EntityDTO]{Name: queueName, Connection: c}.
+			Migrate(MakeContext(t)))
+
+	mapping := EntityJSONMapping{}
+
+	basicQueue := sqlite.Queue[Entity, EntityDTO]{
+		Name:       queueName,
+		Connection: c,
+		Mapping:    mapping,
+	}
+
+	lifoQueue := sqlite.Queue[Entity, EntityDTO]{
+		Name:       queueName,
+		Connection: c,
+		Mapping:    mapping,
+
+		LIFO: true,
+	}
+
+	blockingQueue := sqlite.Queue[Entity, EntityDTO]{
+		Name:       queueName,
+		Connection: c,
+		Mapping:    mapping,
+
+		Blocking: true,
+	}
+
+	leasedQueue := sqlite.Queue[Entity, EntityDTO]{
+		Name:       queueName,
+		Connection: c,
+		Mapping:    mapping,
+
+		VisibilityTimeout: time.Minute,
+	}
+
+	testcase.RunSuite(t,
+		pubsubcontract.FIFO[Entity](basicQueue, basicQueue),
+		pubsubcontract.LIFO[Entity](lifoQueue, lifoQueue),
+		pubsubcontract.Buffered[Entity](basicQueue, basicQueue),
+		pubsubcontract.Blocking[Entity](blockingQueue, blockingQueue),
+		pubsubcontract.Queue[Entity](basicQueue, basicQueue),
+		pubsubcontract.VisibilityTimeout[Entity](leasedQueue, leasedQueue, leasedQueue.VisibilityTimeout),
+	)
+}
+
+func TestQueue_visibilityTimeout(t *testing.T) {
+	ctx := context.Background()
+	c := GetConnection(t)
+
+	q := sqlite.Queue[Entity, EntityDTO]{
+		Name:              "test_visibility_" + rnd.StringNC(5, random.CharsetAlpha()),
+		Connection:        c,
+		Mapping:           EntityJSONMapping{},
+		VisibilityTimeout: 100 * time.Millisecond,
+	}
+	assert.NoError(t, q.Migrate(ctx))
+
+	ent := Entity{ID: rnd.UUID(), Foo: "foo"}
+	assert.NoError(t, q.Publish(ctx, ent))
+
+	sub1ctx, cancel1 := context.WithCancel(ctx)
+	defer cancel1()
+	next1, stop1 := iter.Pull2(q.Subscribe(sub1ctx))
+	defer stop1()
+	msg1, err, ok := next1()
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, ent, msg1.Data())
+
+	t.Log("the crashed consumer neither ACK-s nor NACK-s the message, so it is delivered again after the visibility timeout")
+	sub2ctx, cancel2 := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel2()
+	next2, stop2 := iter.Pull2(q.Subscribe(sub2ctx))
+	defer stop2()
+	msg2, err, ok := next2()
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, ent, msg2.Data())
+	assert.NoError(t, msg2.ACK())
+
+	t.Log("the late ACK of the expired lease is rejected")
+	assert.ErrorIs(t, sqlite.ErrLeaseExpired, msg1.ACK())
+}
@@ -0,0 +1,326 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"go.llib.dev/frameless/adapter/sqlite/internal/queries"
+	"go.llib.dev/frameless/pkg/errorkit"
+	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/port/comproto"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/crudquery"
+	"go.llib.dev/frameless/port/migration"
+)
+
+// Connect opens a SQLite database with the pure Go modernc.org/sqlite driver.
+// The dsn is either a file path or a "file:" URI, for example "file:app.db?_pragma=foreign_keys(1)".
+//
+// Unless the dsn configures them already, Connect sets:
+//   - the WAL journal mode, so reads don't wait for the writers,
+//   - a busy timeout, so a writer waits for the other writers instead of failing with SQLITE_BUSY.
+//
+// Every connection of an in-memory database is a separate database,
+// use a file, or a shared cache in-memory database ("file:name?mode=memory&cache=shared") instead.
+func Connect(dsn string) (Connection, error) {
+	db, err := sql.Open("sqlite", withDefaults(dsn))
+	if err != nil {
+		return Connection{}, err
+	}
+	return Connection{ConnectionAdapter: flsql.SQLConnectionAdapter(db)}, nil
+}
+
+func withDefaults(dsn string) string {
+	var base, rawQuery, _ = strings.Cut(dsn, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return dsn
+	}
+	var hasPragma = func(name string) bool {
+		for _, pragma := range query["_pragma"] {
+			if strings.HasPrefix(strings.ToLower(strings.TrimSpace(pragma)), name) {
+				return true
+			}
+		}
+		return false
+	}
+	if !hasPragma("journal_mode") {
+		query.Add("_pragma", "journal_mode(WAL)")
+	}
+	if !hasPragma("busy_timeout") {
+		query.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", defaultBusyTimeout.Milliseconds()))
+	}
+	return base + "?" + query.Encode()
+}
+
+const defaultBusyTimeout = 10 * time.Second
+
+type Connection struct {
+	flsql.ConnectionAdapter[sql.DB, sql.Tx]
+}
+
+// Dialect is the flsql.Dialect of SQLite.
+// SQLite follows the PostgreSQL syntax for quoting identifiers, upserts, UPDATE FROM and RETURNING.
+var Dialect = flsql.Dialect{
+	Placeholder:     func(int) string { return "?" },
+	QuoteIdentifier: flsql.DialectPostgreSQL.QuoteIdentifier,
+	Returning:       true,
+	UpdateFrom:      true,
+	Upsert:          flsql.DialectPostgreSQL.Upsert,
+}
+
+// Repository implements CRUD operations for a specific entity type in SQLite.
+//
+// The CRUD operations are handled by flsql.Repository with the SQLite Dialect.
+type Repository[ENT, ID any] struct {
+	Connection Connection
+	Mapping    flsql.Mapping[ENT, ID]
+}
+
+var _ interface {
+	crud.Creator[any]
+	crud.ByIDFinder[any, any]
+	crud.AllFinder[any]
+	crud.ByIDsFinder[any, any]
+	crud.Updater[any]
+	crud.ByIDDeleter[any]
+	crud.AllDeleter
+	crud.Saver[any]
+	crud.Querier[any]
+	crud.PageFinder[any]
+	crud.SoftDeleter[any, any]
+	crud.ManyCreator[any]
+	crud.ManyUpdater[any]
+	crud.ByIDsDeleter[any]
+	crud.Counter
+	crud.QueryCounter
+	crud.GroupCounter
+	crud.Exister[any]
+} = Repository[any, any]{}
+
+func (r Repository[ENT, ID]) repository() flsql.Repository[ENT, ID] {
+	return flsql.Repository[ENT, ID]{
+		Connection: r.Connection,
+		Mapping:    r.Mapping,
+		Dialect:    Dialect,
+	}
+}
+
+func (r Repository[ENT, ID]) Create(ctx context.Context, ptr *ENT) error {
+	return r.repository().Create(ctx, ptr)
+}
+
+func (r Repository[ENT, ID]) FindByID(ctx context.Context, id ID) (ENT, bool, error) {
+	return r.repository().FindByID(ctx, id)
+}
+
+func (r Repository[ENT, ID]) FindAll(ctx context.Context) iterkit.SeqE[ENT] {
+	return r.repository().FindAll(ctx)
+}
+
+func (r Repository[ENT, ID]) FindByIDs(ctx context.Context, ids ...ID) iterkit.SeqE[ENT] {
+	return r.repository().FindByIDs(ctx, ids...)
+}
+
+// Query implements crud.Querier by compiling the query into SQL.
+// Query fields are resolved against the Mapping's ToQuery columns, see crudquery.MatchField.
+func (r Repository[ENT, ID]) Query(ctx context.Context, q crudquery.Query) iterkit.SeqE[ENT] {
+	return r.repository().Query(ctx, q)
+}
+
+// FindPage implements crud.PageFinder with keyset pagination over the ID columns of the table.
+func (r Repository[ENT, ID]) FindPage(ctx context.Context, cursor crud.Cursor, size int) (crud.Page[ENT], error) {
+	return r.repository().FindPage(ctx, cursor, size)
+}
+
+func (r Repository[ENT, ID]) Update(ctx context.Context, ptr *ENT) error {
+	return r.repository().Update(ctx, ptr)
+}
+
+func (r Repository[ENT, ID]) Save(ctx context.Context, ptr *ENT) error {
+	return r.repository().Save(ctx, ptr)
+}
+
+func (r Repository[ENT, ID]) DeleteByID(ctx context.Context, id ID) error {
+	return r.repository().DeleteByID(ctx, id)
+}
+
+func (r Repository[ENT, ID]) DeleteAll(ctx context.Context) error {
+	return r.repository().DeleteAll(ctx)
+}
+
+// SoftDeleteByID implements crud.SoftDeleter by setting the Mapping.DeletedAtColumn of the row.
+func (r Repository[ENT, ID]) SoftDeleteByID(ctx context.Context, id ID) error {
+	return r.repository().SoftDeleteByID(ctx, id)
+}
+
+// Restore implements crud.SoftDeleter by clearing the Mapping.DeletedAtColumn of the row.
+func (r Repository[ENT, ID]) Restore(ctx context.Context, id ID) error {
+	return r.repository().Restore(ctx, id)
+}
+
+// FindDeleted implements crud.SoftDeleter by listing the rows that has their Mapping.DeletedAtColumn set.
+func (r Repository[ENT, ID]) FindDeleted(ctx context.Context) iterkit.SeqE[ENT] {
+	return r.repository().FindDeleted(ctx)
+}
+
+// CreateMany implements crud.ManyCreator with multi-row INSERT statements within a single transaction.
+// The entities that already exist are reported in a crud.BulkError, and they are left out from the INSERT.
+func (r Repository[ENT, ID]) CreateMany(ctx context.Context, ptrs ...*ENT) error {
+	return r.repository().CreateMany(ctx, ptrs...)
+}
+
+// UpdateMany implements crud.ManyUpdater by updating the entities within a single transaction.
+// The absent and the concurrently modified entities are reported in a crud.BulkError.
+func (r Repository[ENT, ID]) UpdateMany(ctx context.Context, ptrs ...*ENT) error {
+	return r.repository().UpdateMany(ctx, ptrs...)
+}
+
+// DeleteByIDs implements crud.ByIDsDeleter with multi-row DELETE statements within a single transaction.
+// The IDs of the absent entities are reported in a crud.BulkError.
+func (r Repository[ENT, ID]) DeleteByIDs(ctx context.Context, ids ...ID) error {
+	return r.repository().DeleteByIDs(ctx, ids...)
+}
+
+// Count implements crud.Counter with a SELECT COUNT(*) query.
+func (r Repository[ENT, ID]) Count(ctx context.Context) (int, error) {
+	return r.repository().Count(ctx)
+}
+
+// CountQuery implements crud.QueryCounter by counting the rows that match the query's Where expression.
+func (r Repository[ENT, ID]) CountQuery(ctx context.Context, q crudquery.Query) (int, error) {
+	return r.repository().CountQuery(ctx, q)
+}
+
+// CountGroups implements crud.GroupCounter with a GROUP BY query over the column that the field refers to.
+func (r Repository[ENT, ID]) CountGroups(ctx context.Context, field string, q crudquery.Query) ([]crud.GroupCount, error) {
+	return r.repository().CountGroups(ctx, field, q)
+}
+
+// ExistsByID implements crud.Exister with a SELECT EXISTS query.
+func (r Repository[ENT, ID]) ExistsByID(ctx context.Context, id ID) (bool, error) {
+	return r.repository().ExistsByID(ctx, id)
+}
+
+// BeginTx implements the comproto.OnePhaseCommitter interface.
+func (r Repository[ENT, ID]) BeginTx(ctx context.Context) (context.Context, error) {
+	return r.Connection.BeginTx(ctx)
+}
+
+// CommitTx implements the comproto.OnePhaseCommitter interface.
+func (r Repository[ENT, ID]) CommitTx(ctx context.Context) error {
+	return r.Connection.CommitTx(ctx)
+}
+
+// RollbackTx implements the comproto.OnePhaseCommitter interface.
+func (r Repository[ENT, ID]) RollbackTx(ctx context.Context) error {
+	return r.Connection.RollbackTx(ctx)
+}
+
+// Timestamp is a SQLite DTO Model for the timestamp type mapping.
+// SQLite has no timestamp type, thus the timestamp is stored as an RFC 3339 text in UTC.
+// Use it from your scan and argument mapping.
+func Timestamp(ptr *time.Time) flsql.DTO {
+	return flsql.Timestamp(ptr, time.RFC3339Nano, time.UTC)
+}
+
+func JSON[T any](ptr *T) flsql.DTO {
+	return flsql.JSON[T](ptr)
+}
+
+// migration //
+
+func MakeMigrator(conn Connection, namespace string, steps migration.Steps[Connection]) Migrator {
+	return Migrator{Migrator: migration.Migrator[Connection]{
+		Namespace:       namespace,
+		Resource:        conn,
+		StateRepository: MakeMigrationStateRepository(conn),
+		EnsureStateRepository: func(ctx context.Context) error {
+			_, err := conn.ExecContext(ctx, fmt.Sprintf(queries.CreateTableSchemaMigrationsTmpl, tableNameSchemaMigrations))
+			return err
+		},
+		Steps: steps,
+	}}
+}
+
+// Migrator is a migration.Migrator that runs the whole migration in a single transaction.
+//
+// migration.Migrator uses separate transactions for the migration steps and for the migration state,
+// but SQLite allows only a single writer at a time,
+// so the second transaction would wait for the first one forever.
+// As a bonus, SQLite supports transactional DDL, thus a failed migration leaves no trace.
+type Migrator struct {
+	migration.Migrator[Connection]
+}
+
+func (m Migrator) Migrate(ctx context.Context) (rErr error) {
+	ctx, err := m.Resource.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer comproto.FinishOnePhaseCommit(&rErr, m.Resource, ctx)
+	return m.Migrator.Migrate(ctx)
+}
+
+func (m Migrator) MigrateDown(ctx context.Context, targetVersion migration.Version) (rErr error) {
+	ctx, err := m.Resource.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer comproto.FinishOnePhaseCommit(&rErr, m.Resource, ctx)
+	return m.Migrator.MigrateDown(ctx, targetVersion)
+}
+
+const tableNameSchemaMigrations = "frameless_schema_migrations"
+
+func MakeMigrationStateRepository(conn Connection) Repository[migration.State, migration.StateID] {
+	return Repository[migration.State, migration.StateID]{
+		Connection: conn,
+		Mapping: flsql.Mapping[migration.State, migration.StateID]{
+			TableName: tableNameSchemaMigrations,
+			ToQuery: func(ctx context.Context) ([]flsql.ColumnName, flsql.MapScan[migration.State]) {
+				return []flsql.ColumnName{"namespace", "version", "dirty"},
+					func(v *migration.State, s flsql.Scanner) error {
+						return s.Scan(&v.ID.Namespace, &v.ID.Version, &v.Dirty)
+					}
+			},
+			QueryID: func(id migration.StateID) (flsql.QueryArgs, error) {
+				return flsql.QueryArgs{
+					"namespace": id.Namespace,
+					"version":   id.Version,
+				}, nil
+			},
+
+			ToArgs: func(s migration.State) (flsql.QueryArgs, error) {
+				return flsql.QueryArgs{
+					"namespace": s.ID.Namespace,
+					"version":   s.ID.Version,
+					"dirty":     s.Dirty,
+				}, nil
+			},
+
+			Prepare: func(ctx context.Context, s *migration.State) error {
+				if s.ID.Namespace == "" {
+					return fmt.Errorf("sqlite.MigrationStateRepository requires a non-empty namespace for Create")
+				}
+				if s.ID.Version == "" {
+					return fmt.Errorf("sqlite.MigrationStateRepository requires a non-empty version for Create")
+				}
+				return nil
+			},
+
+			ID: func(s *migration.State) *migration.StateID { return &s.ID },
+		},
+	}
+}
+
+const errNoContext errorkit.Error = "ErrNoContext"
+
+var errNoRows = sql.ErrNoRows
@@ -0,0 +1,522 @@
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+
+	"go.llib.dev/frameless/adapter/sqlite"
+	"go.llib.dev/frameless/pkg/cache"
+	"go.llib.dev/frameless/pkg/cache/cachecontract"
+	"go.llib.dev/frameless/pkg/dtokit"
+	"go.llib.dev/frameless/pkg/errorkit"
+	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/frameless/pkg/logger"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/crudcontract"
+	"go.llib.dev/frameless/port/guard/guardcontract"
+	"go.llib.dev/frameless/port/migration"
+	"go.llib.dev/frameless/port/migration/migrationcontract"
+	"go.llib.dev/frameless/testing/testent"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/random"
+)
+
+func ExampleRepository() {
+	cm, err := sqlite.Connect("file:app.db")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer cm.Close()
+
+	repo := sqlite.Repository[Entity, string]{
+		Connection: cm,
+		Mapping:    EntityMapping(),
+	}
+
+	ent := Entity{Foo: "foo"}
+	if err := repo.Create(context.Background(), &ent); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func TestRepository(t *testing.T) {
+	logger.Testing(t)
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+
+	subject := &sqlite.Repository[Entity, string]{
+		Connection: cm,
+		Mapping:    EntityMapping(),
+	}
+
+	config := crudcontract.Config[Entity, string]{
+		MakeContext:     MakeContext,
+		SupportIDReuse:  true,
+		SupportRecreate: true,
+		OnePhaseCommit:  cm,
+	}
+
+	testcase.RunSuite(t,
+		crudcontract.Creator[Entity, string](subject, config),
+		crudcontract.Finder[Entity, string](subject, config),
+		crudcontract.ByIDsFinder[Entity, string](subject, config),
+		crudcontract.Updater[Entity, string](subject, config),
+		crudcontract.Saver[Entity, string](subject, config),
+		crudcontract.Deleter[Entity, string](subject, config),
+		crudcontract.OnePhaseCommitProtocol[Entity, string](subject, subject.Connection),
+		crudcontract.PageFinder[Entity, string](subject, config),
+		crudcontract.Querier[Entity, string](subject, crudcontract.QuerierField[Entity]{
+			Name: "foo",
+			Set:  func(ptr *Entity, v string) { ptr.Foo = v },
+		}, config),
+	)
+}
+
+func TestRepository_aggregation(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+
+	subject := &sqlite.Repository[Entity, string]{
+		Connection: cm,
+		Mapping:    EntityMapping(),
+	}
+
+	field := crudcontract.QuerierField[Entity]{
+		Name: "foo",
+		Set:  func(ptr *Entity, v string) { ptr.Foo = v },
+	}
+	conf := crudcontract.Config[Entity, string]{
+		MakeContext:    MakeContext,
+		OnePhaseCommit: cm,
+	}
+	testcase.RunSuite(t,
+		crudcontract.Counter[Entity, string](subject, conf),
+		crudcontract.QueryCounter[Entity, string](subject, field, conf),
+		crudcontract.GroupCounter[Entity, string](subject, field, conf),
+		crudcontract.Exister[Entity, string](subject, conf),
+	)
+}
+
+func TestRepository_bulk(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+
+	subject := &sqlite.Repository[Entity, string]{
+		Connection: cm,
+		Mapping:    EntityMapping(),
+	}
+
+	conf := crudcontract.Config[Entity, string]{
+		MakeContext:    MakeContext,
+		OnePhaseCommit: cm,
+	}
+	testcase.RunSuite(t,
+		crudcontract.ManyCreator[Entity, string](subject, conf),
+		crudcontract.ManyUpdater[Entity, string](subject, conf),
+		crudcontract.ByIDsDeleter[Entity, string](subject, conf),
+	)
+}
+
+func TestRepository_flsqlOnChange(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+
+	type change struct {
+		Kind crud.ChangeKind
+		ID   string
+	}
+	var changes []change
+
+	mapping := EntityMapping()
+	mapping.DeletedAtColumn = "deleted_at"
+	subject := flsql.Repository[Entity, string]{
+		Connection: cm,
+		Mapping:    mapping,
+		Dialect:    sqlite.Dialect,
+		OnChange: func(ctx context.Context, kind crud.ChangeKind, ents ...Entity) error {
+			for _, ent := range ents {
+				changes = append(changes, change{Kind: kind, ID: ent.ID})
+			}
+			return nil
+		},
+	}
+	ctx := MakeContext(t)
+
+	ent1 := Entity{Foo: "foo"}
+	assert.NoError(t, subject.Create(ctx, &ent1))
+	ent1.Foo = "bar"
+	assert.NoError(t, subject.Update(ctx, &ent1))
+	assert.NoError(t, subject.SoftDeleteByID(ctx, ent1.ID))
+	assert.NoError(t, subject.Restore(ctx, ent1.ID))
+	assert.NoError(t, subject.SoftDeleteByID(ctx, ent1.ID))
+	assert.NoError(t, subject.DeleteByID(ctx, ent1.ID))
+
+	ent2, ent3 := Entity{Foo: "foo"}, Entity{Foo: "foo"}
+	assert.NoError(t, subject.CreateMany(ctx, &ent2, &ent3))
+	ent2.Foo, ent3.Foo = "bar", "bar"
+	assert.NoError(t, subject.UpdateMany(ctx, &ent2, &ent3))
+	assert.NoError(t, subject.SoftDeleteByID(ctx, ent3.ID))
+	assert.NoError(t, subject.DeleteAll(ctx))
+
+	assert.Equal(t, []change{
+		{Kind: crud.Created, ID: ent1.ID},
+		{Kind: crud.Updated, ID: ent1.ID},
+		{Kind: crud.Deleted, ID: ent1.ID},
+		{Kind: crud.Created, ID: ent1.ID},
+		{Kind: crud.Deleted, ID: ent1.ID},
+		{Kind: crud.Created, ID: ent2.ID},
+		{Kind: crud.Created, ID: ent3.ID},
+		{Kind: crud.Updated, ID: ent2.ID},
+		{Kind: crud.Updated, ID: ent3.ID},
+		{Kind: crud.Deleted, ID: ent3.ID},
+		{Kind: crud.Deleted, ID: ent2.ID},
+	}, changes)
+}
+
+func TestRepository_VersionedUpdater(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateDoc(t, cm)
+
+	subject := &sqlite.Repository[testent.Doc, testent.DocID]{
+		Connection: cm,
+		Mapping:    DocMapping,
+	}
+
+	conf := crudcontract.Config[testent.Doc, testent.DocID]{
+		MakeContext:    MakeContext,
+		MakeEntity:     testent.MakeDoc,
+		OnePhaseCommit: cm,
+	}
+	testcase.RunSuite(t,
+		crudcontract.Updater[testent.Doc, testent.DocID](subject, conf),
+		crudcontract.VersionedUpdater[testent.Doc, testent.DocID](subject, conf),
+		crudcontract.ManyUpdater[testent.Doc, testent.DocID](subject, conf),
+	)
+}
+
+func TestRepository_UpdateMany(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateDoc(t, cm)
+	MigrateEntity(t, cm)
+
+	ctx := MakeContext(t)
+
+	t.Run("stale and absent versioned entities are reported, while the rest is updated", func(t *testing.T) {
+		subject := sqlite.Repository[testent.Doc, testent.DocID]{Connection: cm, Mapping: DocMapping}
+
+		fresh, stale, absent := testent.MakeDoc(t), testent.MakeDoc(t), testent.MakeDoc(t)
+		assert.NoError(t, subject.CreateMany(ctx, &fresh, &stale))
+		defer subject.DeleteByIDs(ctx, fresh.ID, stale.ID)
+		absent.ID = testent.DocID(random.New(random.CryptoSeed{}).UUID())
+
+		concurrent := stale
+		concurrent.Title = "concurrent"
+		assert.NoError(t, subject.Update(ctx, &concurrent))
+
+		fresh.Title, stale.Title, absent.Title = "fresh", "stale", "absent"
+		err := subject.UpdateMany(ctx, &fresh, &stale, &absent)
+		bulkErr, ok := errorkit.As[*crud.BulkError](err)
+		assert.True(t, ok, "*crud.BulkError was expected")
+		assert.Equal(t, 2, len(bulkErr.Failures))
+		assert.Equal(t, 1, bulkErr.Failures[0].Index)
+		assert.ErrorIs(t, bulkErr.Failures[0].Err, crud.ErrConcurrentModification)
+		assert.Equal(t, 2, bulkErr.Failures[1].Index)
+		assert.ErrorIs(t, bulkErr.Failures[1].Err, crud.ErrNotFound)
+
+		got, found, err := subject.FindByID(ctx, fresh.ID)
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, fresh, got)
+		assert.Equal(t, "fresh", got.Title)
+
+		got, found, err = subject.FindByID(ctx, stale.ID)
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, concurrent, got)
+	})
+
+	t.Run("a soft deleted entity is reported as absent", func(t *testing.T) {
+		mapping := EntityMapping()
+		mapping.DeletedAtColumn = "deleted_at"
+		subject := sqlite.Repository[Entity, string]{Connection: cm, Mapping: mapping}
+
+		live, deleted := Entity{Foo: "foo"}, Entity{Foo: "foo"}
+		assert.NoError(t, subject.CreateMany(ctx, &live, &deleted))
+		defer subject.DeleteByIDs(ctx, live.ID, deleted.ID)
+		assert.NoError(t, subject.SoftDeleteByID(ctx, deleted.ID))
+
+		live.Foo, deleted.Foo = "bar", "bar"
+		err := subject.UpdateMany(ctx, &deleted, &live)
+		bulkErr, ok := errorkit.As[*crud.BulkError](err)
+		assert.True(t, ok, "*crud.BulkError was expected")
+		assert.Equal(t, 1, len(bulkErr.Failures))
+		assert.Equal(t, 0, bulkErr.Failures[0].Index)
+		assert.ErrorIs(t, bulkErr.Failures[0].Err, crud.ErrNotFound)
+
+		got, found, err := subject.FindByID(ctx, live.ID)
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "bar", got.Foo)
+	})
+}
+
+func TestRepository_SoftDeleter(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateEntity(t, cm)
+
+	mapping := EntityMapping()
+	mapping.DeletedAtColumn = "deleted_at"
+
+	subject := &sqlite.Repository[Entity, string]{
+		Connection: cm,
+		Mapping:    mapping,
+	}
+
+	conf := crudcontract.Config[Entity, string]{
+		MakeContext:    MakeContext,
+		OnePhaseCommit: cm,
+	}
+	testcase.RunSuite(t,
+		crudcontract.SoftDeleter[Entity, string](subject, conf),
+		crudcontract.Finder[Entity, string](subject, conf),
+		crudcontract.Updater[Entity, string](subject, conf),
+		crudcontract.PageFinder[Entity, string](subject, conf),
+	)
+}
+
+func TestRepository_compositeID(t *testing.T) {
+	cm := GetConnection(t)
+	MigrateComposite(t, cm)
+
+	subject := &sqlite.Repository[testent.Composite, testent.CompositeID]{
+		Connection: cm,
+		Mapping:    CompositeMapping,
+	}
+
+	conf := crudcontract.Config[testent.Composite, testent.CompositeID]{
+		MakeContext:     MakeContext,
+		MakeEntity:      testent.MakeComposite,
+		SupportIDReuse:  true,
+		SupportRecreate: true,
+		OnePhaseCommit:  cm,
+	}
+	testcase.RunSuite(t,
+		crudcontract.Creator[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.Finder[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.ByIDsFinder[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.Updater[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.Deleter[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.Saver[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.PageFinder[testent.Composite, testent.CompositeID](subject, conf),
+		crudcontract.ManyUpdater[testent.Composite, testent.CompositeID](subject, conf),
+	)
+}
+
+func TestCacheRepository(t *testing.T) {
+	logger.Testing(t)
+	ctx := context.Background()
+	cm := GetConnection(t)
+
+	subject := sqlite.CacheRepository[testent.Foo, testent.FooID]{
+		Connection: cm,
+		ID:         "foo",
+		JSONDTOM:   testent.FooJSONMapping(),
+		IDA: func(f *testent.Foo) *testent.FooID {
+			return &f.ID
+		},
+		IDM: dtokit.Mapping[testent.FooID, string]{
+			ToENT: func(ctx context.Context, dto string) (testent.FooID, error) {
+				return testent.FooID(dto), nil
+			},
+			ToDTO: func(ctx context.Context, ent testent.FooID) (string, error) {
+				return ent.String(), nil
+			},
+		},
+	}
+	assert.NoError(t, subject.Migrate(ctx))
+
+	conf := cachecontract.Config[testent.Foo, testent.FooID]{
+		CRUD: crudcontract.Config[testent.Foo, testent.FooID]{
+			MakeEntity: func(tb testing.TB) testent.Foo {
+				foo := testent.MakeFoo(tb)
+				foo.ID = testent.FooID(testcase.ToT(&tb).Random.UUID())
+				return foo
+			},
+		},
+	}
+
+	// cachecontract.Repository makes its cache.Hit fixtures by creating entities outside of an ongoing transaction,
+	// which waits for the transaction's write lock with SQLite's single writer, thus the sub repositories are tested separately.
+	testcase.RunSuite(t,
+		cachecontract.EntityRepository[testent.Foo, testent.FooID](subject.Entities(), cm, conf),
+		cachecontract.HitRepository[testent.FooID](subject.Hits(), cm, crudcontract.Config[cache.Hit[testent.FooID], cache.HitID]{
+			MakeEntity: func(tb testing.TB) cache.Hit[testent.FooID] {
+				t := tb.(*testcase.T)
+				return cache.Hit[testent.FooID]{
+					ID: cache.HitID(t.Random.UUID()),
+					EntityIDs: random.Slice(t.Random.IntBetween(0, 7), func() testent.FooID {
+						return testent.FooID(t.Random.UUID())
+					}),
+					Timestamp: t.Random.Time().UTC(),
+				}
+			},
+		}),
+	)
+}
+
+func TestMigrationStateRepository(t *testing.T) {
+	logger.Testing(t)
+	ctx := context.Background()
+	conn := GetConnection(t)
+
+	repo := sqlite.MakeMigrationStateRepository(conn)
+	repo.Mapping.TableName += "_test"
+
+	_, err := conn.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	namespace  TEXT    NOT NULL,
+	version    TEXT    NOT NULL,
+	dirty      BOOLEAN NOT NULL,
+	PRIMARY KEY (namespace, version)
+)`, repo.Mapping.TableName))
+	assert.NoError(t, err)
+	t.Cleanup(func() { _, _ = conn.ExecContext(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS %s`, repo.Mapping.TableName)) })
+
+	migrationcontract.StateRepository(repo).Test(t)
+}
+
+func TestMakeMigrator(t *testing.T) {
+	ctx := context.Background()
+	conn := GetConnection(t)
+
+	namespace := "test_" + rnd.StringNC(8, random.CharsetAlpha())
+	m := sqlite.MakeMigrator(conn, namespace, migration.Steps[sqlite.Connection]{
+		"0": flsql.MigrationStep[sqlite.Connection]{
+			UpQuery:   fmt.Sprintf("CREATE TABLE %s (id TEXT PRIMARY KEY)", namespace),
+			DownQuery: fmt.Sprintf("DROP TABLE %s", namespace),
+		},
+		"1": flsql.MigrationStep[sqlite.Connection]{
+			UpQuery:   fmt.Sprintf("ALTER TABLE %s ADD COLUMN name TEXT NULL", namespace),
+			DownQuery: fmt.Sprintf("ALTER TABLE %s DROP COLUMN name", namespace),
+		},
+	})
+	t.Cleanup(func() { _, _ = conn.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", namespace)) })
+
+	assert.NoError(t, m.Migrate(ctx))
+	assert.NoError(t, m.Migrate(ctx), "migrate is idempotent")
+
+	_, err := conn.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (id, name) VALUES (?, ?)", namespace), "1", "foo")
+	assert.NoError(t, err)
+}
+
+func ExampleLocker() {
+	cm, err := sqlite.Connect("file:app.db")
+	if err != nil {
+		panic(err)
+	}
+
+	l := sqlite.Locker{
+		Name:       "my-lock",
+		Connection: cm,
+	}
+
+	ctx, err := l.Lock(context.Background())
+	if err != nil {
+		panic(err)
+	}
+
+	if err := l.Unlock(ctx); err != nil {
+		panic(err)
+	}
+}
+
+var _ migration.Migratable = sqlite.Locker{}
+
+func TestLocker(t *testing.T) {
+	cm := GetConnection(t)
+
+	l := sqlite.Locker{
+		Name:       rnd.StringNC(5, random.CharsetAlpha()),
+		Connection: cm,
+	}
+	assert.NoError(t, l.Migrate(context.Background()))
+
+	testcase.RunSuite(t,
+		guardcontract.Locker(l),
+		guardcontract.NonBlockingLocker(l),
+	)
+}
+
+func TestLocker_lease(t *testing.T) {
+	ctx := context.Background()
+	cm := GetConnection(t)
+
+	name := rnd.StringNC(5, random.CharsetAlpha())
+	l1 := sqlite.Locker{Name: name, Connection: cm, Lease: 50 * time.Millisecond}
+	l2 := sqlite.Locker{Name: name, Connection: cm, Lease: 50 * time.Millisecond}
+	assert.NoError(t, l1.Migrate(ctx))
+
+	lockCtx, err := l1.Lock(ctx)
+	assert.NoError(t, err)
+	defer l1.Unlock(lockCtx)
+
+	t.Log("the lease is renewed while the lock is held")
+	time.Sleep(200 * time.Millisecond)
+	_, ok, err := l2.TryLock(ctx)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.NoError(t, lockCtx.Err())
+
+	t.Log("after unlock, the lock is available right away")
+	assert.NoError(t, l1.Unlock(lockCtx))
+	lockCtx2, ok, err := l2.TryLock(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NoError(t, l2.Unlock(lockCtx2))
+}
+
+func ExampleLockerFactory() {
+	cm, err := sqlite.Connect("file:app.db")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	lockerFactory := sqlite.LockerFactory[string]{Connection: cm}
+	if err := lockerFactory.Migrate(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+
+	locker := lockerFactory.LockerFor("hello world")
+
+	ctx, err := locker.Lock(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := locker.Unlock(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
+
+var _ migration.Migratable = sqlite.LockerFactory[int]{}
+
+func TestLockerFactory(t *testing.T) {
+	ctx := context.Background()
+	cm := GetConnection(t)
+
+	lockerFactoryStrKey := sqlite.LockerFactory[string]{Connection: cm}
+	assert.NoError(t, lockerFactoryStrKey.Migrate(ctx))
+	assert.NoError(t, lockerFactoryStrKey.Purge(ctx))
+
+	lockerFactoryIntKey := sqlite.LockerFactory[int]{Connection: cm, Namespace: "int"}
+
+	testcase.RunSuite(t,
+		guardcontract.LockerFactory[string](lockerFactoryStrKey),
+		guardcontract.NonBlockingLockerFactory[string](lockerFactoryStrKey),
+		guardcontract.LockerFactory[int](lockerFactoryIntKey),
+		guardcontract.NonBlockingLockerFactory[int](lockerFactoryIntKey),
+	)
+}
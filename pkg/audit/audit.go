@@ -0,0 +1,288 @@
+// Package audit records who changed an entity, when and what changed,
+// by decorating a crud repository with an audit trail.
+package audit
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"reflect"
+	"time"
+
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/reflectkit"
+	"go.llib.dev/frameless/pkg/uuid"
+	"go.llib.dev/frameless/port/comproto"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/extid"
+	"go.llib.dev/testcase/clock"
+)
+
+// Action is the kind of change that an Event records.
+type Action string
+
+const (
+	Create Action = "create"
+	Update Action = "update"
+	Delete Action = "delete"
+)
+
+// Event is the record of a change on the entity with the EntityID.
+type Event[ID any] struct {
+	ID       EventID `ext:"id"`
+	EntityID ID
+	Action   Action
+	// Actor is who made the change, or empty when the context didn't tell it.
+	Actor     string
+	Timestamp time.Time
+	// Changes are the field-level differences between the entity's state before and after the change.
+	Changes []Change
+}
+
+type EventID string
+
+// Change is the difference of a single field between two states of an entity.
+// Nested struct fields are represented with a dot separated path, such as "Address.City".
+type Change struct {
+	Field  string
+	Before any
+	After  any
+}
+
+// EventRepository is the storage of the audit events.
+//
+// To record the events together with the changes they describe,
+// the EventRepository should take part in the transaction of the decorated repository.
+type EventRepository[ID any] interface {
+	crud.Creator[Event[ID]]
+	HistoryFinder[ID]
+}
+
+// HistoryFinder finds the audit events of an entity.
+type HistoryFinder[ID any] interface {
+	// FindByEntityID yields the events of the entity in the order they happened.
+	FindByEntityID(ctx context.Context, entityID ID) iter.Seq2[Event[ID], error]
+}
+
+// ActorContext tells the actor of an operation from its context, such as a contextkit.ValueHandler.
+type ActorContext interface {
+	Lookup(context.Context) (string, bool)
+}
+
+// Repository decorates a crud repository and records an Event about every Create, Update and Delete in the Events.
+type Repository[ENT, ID any] struct {
+	// Source is the decorated repository.
+	Source Source[ENT, ID]
+	// Events is where the audit events are recorded.
+	Events EventRepository[ID]
+	// Actor [optional] tells who makes the changes.
+	// Usually it is a contextkit.ValueHandler, which is set up by an authentication middleware.
+	Actor ActorContext
+	// IDA [optional] is the ENT's ID accessor.
+	//
+	// default: extid Lookup/Set
+	IDA extid.Accessor[ENT, ID]
+}
+
+// Source is the repository that a Repository decorates.
+type Source[ENT, ID any] interface {
+	crud.Creator[ENT]
+	crud.ByIDFinder[ENT, ID]
+	crud.AllFinder[ENT]
+	crud.Updater[ENT]
+	crud.ByIDDeleter[ID]
+}
+
+func (r Repository[ENT, ID]) Create(ctx context.Context, ptr *ENT) error {
+	if ptr == nil {
+		return r.Source.Create(ctx, ptr)
+	}
+	return r.inTx(ctx, func(ctx context.Context) error {
+		if err := r.Source.Create(ctx, ptr); err != nil {
+			return err
+		}
+		return r.record(ctx, Create, r.IDA.Get(*ptr), *new(ENT), *ptr)
+	})
+}
+
+func (r Repository[ENT, ID]) FindByID(ctx context.Context, id ID) (ENT, bool, error) {
+	return r.Source.FindByID(ctx, id)
+}
+
+func (r Repository[ENT, ID]) FindAll(ctx context.Context) iter.Seq2[ENT, error] {
+	return r.Source.FindAll(ctx)
+}
+
+func (r Repository[ENT, ID]) Update(ctx context.Context, ptr *ENT) error {
+	if ptr == nil {
+		return r.Source.Update(ctx, ptr)
+	}
+	return r.inTx(ctx, func(ctx context.Context) error {
+		id := r.IDA.Get(*ptr)
+		before, found, err := r.Source.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !found { // the Source tells how it treats an absent entity
+			return r.Source.Update(ctx, ptr)
+		}
+		if err := r.Source.Update(ctx, ptr); err != nil {
+			return err
+		}
+		return r.record(ctx, Update, id, before, *ptr)
+	})
+}
+
+func (r Repository[ENT, ID]) DeleteByID(ctx context.Context, id ID) error {
+	return r.inTx(ctx, func(ctx context.Context) error {
+		return r.deleteByID(ctx, id)
+	})
+}
+
+// DeleteAll deletes every entity one by one, so each deletion is recorded with its own Event.
+func (r Repository[ENT, ID]) DeleteAll(ctx context.Context) error {
+	return r.inTx(ctx, func(ctx context.Context) error {
+		// the IDs are collected first, as deleting while iterating is not supported by every Source
+		ents, err := iterkit.CollectE(r.Source.FindAll(ctx))
+		if err != nil {
+			return err
+		}
+		for _, ent := range ents {
+			// an entity that is deleted meanwhile is already where DeleteAll wants it to be
+			if err := r.deleteByID(ctx, r.IDA.Get(ent)); err != nil && !errors.Is(err, crud.ErrNotFound) {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// History yields the recorded events of the entity in the order they happened.
+func (r Repository[ENT, ID]) History(ctx context.Context, id ID) iter.Seq2[Event[ID], error] {
+	return r.Events.FindByEntityID(ctx, id)
+}
+
+func (r Repository[ENT, ID]) BeginTx(ctx context.Context) (context.Context, error) {
+	if c, ok := r.Source.(comproto.OnePhaseCommitProtocol); ok {
+		return c.BeginTx(ctx)
+	}
+	return ctx, nil
+}
+
+func (r Repository[ENT, ID]) CommitTx(ctx context.Context) error {
+	if c, ok := r.Source.(comproto.OnePhaseCommitProtocol); ok {
+		return c.CommitTx(ctx)
+	}
+	return nil
+}
+
+func (r Repository[ENT, ID]) RollbackTx(ctx context.Context) error {
+	if c, ok := r.Source.(comproto.OnePhaseCommitProtocol); ok {
+		return c.RollbackTx(ctx)
+	}
+	return nil
+}
+
+func (r Repository[ENT, ID]) deleteByID(ctx context.Context, id ID) error {
+	before, found, err := r.Source.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := r.Source.DeleteByID(ctx, id); err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+	return r.record(ctx, Delete, id, before, *new(ENT))
+}
+
+// inTx runs fn in a transaction, when the Source supports it,
+// so a change and its Event are either recorded together or not at all.
+func (r Repository[ENT, ID]) inTx(ctx context.Context, fn func(ctx context.Context) error) (rErr error) {
+	if c, ok := r.Source.(comproto.OnePhaseCommitProtocol); ok {
+		tx, err := c.BeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer comproto.FinishOnePhaseCommit(&rErr, c, tx)
+		ctx = tx
+	}
+	return fn(ctx)
+}
+
+func (r Repository[ENT, ID]) record(ctx context.Context, action Action, id ID, before, after ENT) error {
+	eid, err := uuid.MakeV7()
+	if err != nil {
+		return err
+	}
+	event := Event[ID]{
+		ID:        EventID(eid.String()),
+		EntityID:  id,
+		Action:    action,
+		Timestamp: clock.Now().UTC(),
+		Changes:   Diff(before, after),
+	}
+	if r.Actor != nil {
+		event.Actor, _ = r.Actor.Lookup(ctx)
+	}
+	return r.Events.Create(ctx, &event)
+}
+
+// Diff compares the exported fields of two entity states, and returns the fields that differ.
+// Nested structs which only have exported fields are compared field by field,
+// while any other value, such as a time.Time, is compared as a whole with reflectkit.Equal.
+func Diff[ENT any](before, after ENT) []Change {
+	var (
+		changes []Change
+		vBefore = reflect.ValueOf(&before).Elem()
+		vAfter  = reflect.ValueOf(&after).Elem()
+	)
+	if vBefore.Kind() == reflect.Struct {
+		diffFields(&changes, "", vBefore, vAfter)
+	} else {
+		diff(&changes, "", vBefore, vAfter)
+	}
+	return changes
+}
+
+func diff(changes *[]Change, path string, before, after reflect.Value) {
+	if isDiffableStruct(before.Type()) {
+		diffFields(changes, path, before, after)
+		return
+	}
+	if !reflectkit.Equal(before.Interface(), after.Interface()) {
+		*changes = append(*changes, Change{
+			Field:  path,
+			Before: before.Interface(),
+			After:  after.Interface(),
+		})
+	}
+}
+
+func diffFields(changes *[]Change, path string, before, after reflect.Value) {
+	for field, value := range reflectkit.IterStructFields(before) {
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if path != "" {
+			name = path + "." + name
+		}
+		diff(changes, name, value, after.FieldByIndex(field.Index))
+	}
+}
+
+// isDiffableStruct tells if a nested struct type can be compared field by field.
+// Structs with unexported fields, such as time.Time, keep their state hidden, so they are compared as a whole.
+func isDiffableStruct(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct || typ.NumField() == 0 {
+		return false
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		if !typ.Field(i).IsExported() {
+			return false
+		}
+	}
+	return true
+}
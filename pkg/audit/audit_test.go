@@ -0,0 +1,221 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.llib.dev/frameless/adapter/memory"
+	"go.llib.dev/frameless/pkg/audit"
+	"go.llib.dev/frameless/pkg/contextkit"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/port/crud/crudcontract"
+	"go.llib.dev/frameless/testing/testent"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/clock/timecop"
+)
+
+type ActorKey struct{}
+
+// ActorOf is the context handler of the actor, which is usually set by an authentication middleware.
+var ActorOf contextkit.ValueHandler[ActorKey, string]
+
+func ExampleRepository() {
+	m := memory.NewMemory()
+	repo := audit.Repository[testent.Foo, testent.FooID]{
+		Source: memory.NewRepository[testent.Foo, testent.FooID](m),
+		Events: memory.NewAuditEventRepository[testent.FooID](m, "foos"),
+		Actor:  ActorOf,
+	}
+
+	ctx := ActorOf.ContextWith(context.Background(), "alice")
+
+	foo := testent.Foo{Foo: "foo"}
+	_ = repo.Create(ctx, &foo)
+	foo.Foo = "bar"
+	_ = repo.Update(ctx, &foo)
+
+	for event, err := range repo.History(ctx, foo.ID) {
+		if err != nil {
+			return
+		}
+		_ = event.Actor   // "alice"
+		_ = event.Changes // create: Foo "" -> "foo", update: Foo "foo" -> "bar"
+	}
+}
+
+func TestRepository(t *testing.T) {
+	s := testcase.NewSpec(t)
+
+	var (
+		m      = testcase.Let(s, func(t *testcase.T) *memory.Memory { return memory.NewMemory() })
+		events = testcase.Let(s, func(t *testcase.T) *memory.AuditEventRepository[testent.FooID] {
+			return memory.NewAuditEventRepository[testent.FooID](m.Get(t), "foos")
+		})
+		subject = testcase.Let(s, func(t *testcase.T) audit.Repository[testent.Foo, testent.FooID] {
+			return audit.Repository[testent.Foo, testent.FooID]{
+				Source: memory.NewRepository[testent.Foo, testent.FooID](m.Get(t)),
+				Events: events.Get(t),
+				Actor:  ActorOf,
+			}
+		})
+		actor = testcase.Let(s, func(t *testcase.T) string { return t.Random.String() })
+		ctx   = testcase.Let(s, func(t *testcase.T) context.Context {
+			return ActorOf.ContextWith(context.Background(), actor.Get(t))
+		})
+	)
+
+	history := func(t *testcase.T, id testent.FooID) []audit.Event[testent.FooID] {
+		events, err := iterkit.CollectE(subject.Get(t).History(ctx.Get(t), id))
+		assert.NoError(t, err)
+		return events
+	}
+
+	s.Test("Create records who created the entity, when and with what", func(t *testcase.T) {
+		now := time.Now().UTC()
+		timecop.Travel(t, now, timecop.Freeze)
+
+		foo := testent.Foo{Foo: "foo"}
+		assert.NoError(t, subject.Get(t).Create(ctx.Get(t), &foo))
+
+		events := history(t, foo.ID)
+		assert.Equal(t, 1, len(events))
+		assert.NotEmpty(t, events[0].ID)
+		assert.Equal(t, foo.ID, events[0].EntityID)
+		assert.Equal(t, audit.Create, events[0].Action)
+		assert.Equal(t, actor.Get(t), events[0].Actor)
+		assert.True(t, now.Equal(events[0].Timestamp))
+		assert.ContainsExactly(t, []audit.Change{
+			{Field: "ID", Before: testent.FooID(""), After: foo.ID},
+			{Field: "Foo", Before: "", After: "foo"},
+		}, events[0].Changes)
+	})
+
+	s.Test("Update records the changed fields", func(t *testcase.T) {
+		foo := testent.Foo{Foo: "foo", Bar: "bar"}
+		assert.NoError(t, subject.Get(t).Create(ctx.Get(t), &foo))
+		foo.Bar = "baz"
+		assert.NoError(t, subject.Get(t).Update(ctx.Get(t), &foo))
+
+		events := history(t, foo.ID)
+		assert.Equal(t, 2, len(events))
+		assert.Equal(t, audit.Update, events[1].Action)
+		assert.Equal(t, []audit.Change{{Field: "Bar", Before: "bar", After: "baz"}}, events[1].Changes)
+	})
+
+	s.Test("DeleteByID records the state of the deleted entity", func(t *testcase.T) {
+		foo := testent.Foo{Foo: "foo"}
+		assert.NoError(t, subject.Get(t).Create(ctx.Get(t), &foo))
+		assert.NoError(t, subject.Get(t).DeleteByID(ctx.Get(t), foo.ID))
+
+		events := history(t, foo.ID)
+		assert.Equal(t, 2, len(events))
+		assert.Equal(t, audit.Delete, events[1].Action)
+		assert.ContainsExactly(t, []audit.Change{
+			{Field: "ID", Before: foo.ID, After: testent.FooID("")},
+			{Field: "Foo", Before: "foo", After: ""},
+		}, events[1].Changes)
+	})
+
+	s.Test("DeleteAll records the deletion of each entity", func(t *testcase.T) {
+		foo1, foo2 := testent.MakeFoo(t), testent.MakeFoo(t)
+		assert.NoError(t, subject.Get(t).Create(ctx.Get(t), &foo1))
+		assert.NoError(t, subject.Get(t).Create(ctx.Get(t), &foo2))
+		assert.NoError(t, subject.Get(t).DeleteAll(ctx.Get(t)))
+
+		for _, id := range []testent.FooID{foo1.ID, foo2.ID} {
+			events := history(t, id)
+			assert.Equal(t, 2, len(events))
+			assert.Equal(t, audit.Delete, events[1].Action)
+		}
+	})
+
+	s.Test("a failed change is not recorded", func(t *testcase.T) {
+		foo := testent.MakeFoo(t)
+		assert.NoError(t, subject.Get(t).Create(ctx.Get(t), &foo))
+		assert.Error(t, subject.Get(t).Create(ctx.Get(t), &foo))
+
+		assert.Equal(t, 1, len(history(t, foo.ID)))
+	})
+
+	s.Test("the actor is empty when the context doesn't tell it", func(t *testcase.T) {
+		foo := testent.MakeFoo(t)
+		assert.NoError(t, subject.Get(t).Create(context.Background(), &foo))
+
+		events := history(t, foo.ID)
+		assert.Equal(t, 1, len(events))
+		assert.Empty(t, events[0].Actor)
+	})
+
+	s.Test("a change within a rolled back transaction is not recorded", func(t *testcase.T) {
+		tx, err := subject.Get(t).BeginTx(ctx.Get(t))
+		assert.NoError(t, err)
+		foo := testent.MakeFoo(t)
+		assert.NoError(t, subject.Get(t).Create(tx, &foo))
+		assert.NoError(t, subject.Get(t).RollbackTx(tx))
+
+		assert.Empty(t, history(t, foo.ID))
+	})
+
+	s.Context("implements crud", func(s *testcase.Spec) {
+		m := memory.NewMemory()
+		repo := audit.Repository[testent.Foo, testent.FooID]{
+			Source: memory.NewRepository[testent.Foo, testent.FooID](m),
+			Events: memory.NewAuditEventRepository[testent.FooID](m, "foos"),
+		}
+		conf := crudcontract.Config[testent.Foo, testent.FooID]{
+			MakeEntity:     testent.MakeFoo,
+			OnePhaseCommit: m,
+		}
+		testcase.RunSuite(s,
+			crudcontract.Creator[testent.Foo, testent.FooID](repo, conf),
+			crudcontract.Finder[testent.Foo, testent.FooID](repo, conf),
+			crudcontract.Updater[testent.Foo, testent.FooID](repo, conf),
+			crudcontract.Deleter[testent.Foo, testent.FooID](repo, conf),
+		)
+	})
+}
+
+func TestDiff(t *testing.T) {
+	type Address struct {
+		City   string
+		Street string
+	}
+	type Person struct {
+		Name      string
+		Address   Address
+		BirthDate time.Time
+		Tags      []string
+		secret    string
+	}
+
+	t.Run("equal states have no changes", func(t *testing.T) {
+		p := Person{Name: "Jane", Tags: []string{"a"}, secret: "x"}
+		assert.Empty(t, audit.Diff(p, p))
+	})
+
+	t.Run("nested structs are compared field by field", func(t *testing.T) {
+		before := Person{Address: Address{City: "Zurich", Street: "Main"}}
+		after := Person{Address: Address{City: "Bern", Street: "Main"}}
+		assert.Equal(t, []audit.Change{{Field: "Address.City", Before: "Zurich", After: "Bern"}}, audit.Diff(before, after))
+	})
+
+	t.Run("values with hidden state are compared as a whole", func(t *testing.T) {
+		bd := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		before := Person{BirthDate: bd, Tags: []string{"a"}}
+		after := Person{BirthDate: bd.Add(time.Hour), Tags: []string{"a", "b"}}
+		assert.Equal(t, []audit.Change{
+			{Field: "BirthDate", Before: bd, After: bd.Add(time.Hour)},
+			{Field: "Tags", Before: []string{"a"}, After: []string{"a", "b"}},
+		}, audit.Diff(before, after))
+	})
+
+	t.Run("unexported fields are ignored", func(t *testing.T) {
+		assert.Empty(t, audit.Diff(Person{secret: "a"}, Person{secret: "b"}))
+	})
+
+	t.Run("non struct values are compared as a whole", func(t *testing.T) {
+		assert.Equal(t, []audit.Change{{Before: 1, After: 2}}, audit.Diff(1, 2))
+	})
+}
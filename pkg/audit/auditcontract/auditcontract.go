@@ -0,0 +1,153 @@
+package auditcontract
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.llib.dev/frameless/internal/spechelper"
+	"go.llib.dev/frameless/pkg/audit"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/reflectkit"
+	"go.llib.dev/frameless/port/comproto"
+	"go.llib.dev/frameless/port/contract"
+	"go.llib.dev/frameless/port/option"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/let"
+)
+
+type Option[ID any] option.Option[Config[ID]]
+
+type Config[ID any] struct {
+	MakeContext  func(testing.TB) context.Context
+	MakeEntityID func(testing.TB) ID
+	// OnePhaseCommit [optional] is the transaction manager, which transactions the EventRepository takes part in.
+	OnePhaseCommit comproto.OnePhaseCommitProtocol
+}
+
+func (c *Config[ID]) Init() {
+	c.MakeContext = func(testing.TB) context.Context {
+		return context.Background()
+	}
+	c.MakeEntityID = spechelper.MakeValue[ID]
+}
+
+func (c Config[ID]) Configure(t *Config[ID]) {
+	*t = reflectkit.MergeStruct(*t, c)
+}
+
+// EventRepository verifies that the subject stores the audit events,
+// and finds them back by their entity ID in the order they happened.
+//
+// The Change values are JSON friendly strings, as a storage is allowed to serialise them.
+func EventRepository[ID any](subject audit.EventRepository[ID], opts ...Option[ID]) contract.Contract {
+	c := option.ToConfig[Config[ID]](opts)
+	s := testcase.NewSpec(nil)
+
+	var (
+		ctx      = let.With[context.Context](s, c.MakeContext)
+		entityID = let.Var(s, func(t *testcase.T) ID { return c.MakeEntityID(t) })
+	)
+
+	makeEvent := func(t *testcase.T, id ID, at time.Time) audit.Event[ID] {
+		return audit.Event[ID]{
+			ID:        audit.EventID(t.Random.UUID()),
+			EntityID:  id,
+			Action:    t.Random.Pick([]audit.Action{audit.Create, audit.Update, audit.Delete}).(audit.Action),
+			Actor:     t.Random.String(),
+			Timestamp: at,
+			Changes: []audit.Change{{
+				Field:  t.Random.StringNC(5, "ABCDEFGHIJKLMNOPQRSTUVWXYZ"),
+				Before: t.Random.String(),
+				After:  t.Random.String(),
+			}},
+		}
+	}
+	// the timestamps are truncated, as not every storage keeps nanoseconds
+	now := func() time.Time { return time.Now().UTC().Truncate(time.Millisecond) }
+
+	s.Test("a created event is found by its entity ID", func(t *testcase.T) {
+		event := makeEvent(t, entityID.Get(t), now())
+		assert.NoError(t, subject.Create(ctx.Get(t), &event))
+
+		got, err := iterkit.CollectE(subject.FindByEntityID(ctx.Get(t), entityID.Get(t)))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(got))
+		assert.True(t, event.Timestamp.Equal(got[0].Timestamp))
+		got[0].Timestamp = event.Timestamp
+		assert.Equal(t, event, got[0])
+	})
+
+	s.Test("an event without an ID receives one", func(t *testcase.T) {
+		event := makeEvent(t, entityID.Get(t), now())
+		event.ID = ""
+		assert.NoError(t, subject.Create(ctx.Get(t), &event))
+		assert.NotEmpty(t, event.ID)
+	})
+
+	s.Test("the events are found in the order they happened", func(t *testcase.T) {
+		var (
+			ts     = now()
+			events []audit.Event[ID]
+		)
+		t.Random.Repeat(2, 5, func() {
+			ts = ts.Add(time.Second)
+			events = append(events, makeEvent(t, entityID.Get(t), ts))
+		})
+		// created in reverse, so the order can't come from the order of creation
+		for i := len(events) - 1; 0 <= i; i-- {
+			assert.NoError(t, subject.Create(ctx.Get(t), &events[i]))
+		}
+
+		got, err := iterkit.CollectE(subject.FindByEntityID(ctx.Get(t), entityID.Get(t)))
+		assert.NoError(t, err)
+		assert.Equal(t, len(events), len(got))
+		for i := range events {
+			assert.Equal(t, events[i].ID, got[i].ID)
+		}
+	})
+
+	s.Test("the events of other entities are not found", func(t *testcase.T) {
+		event := makeEvent(t, c.MakeEntityID(t), now())
+		assert.NoError(t, subject.Create(ctx.Get(t), &event))
+
+		got, err := iterkit.CollectE(subject.FindByEntityID(ctx.Get(t), entityID.Get(t)))
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	s.Context("transaction", func(s *testcase.Spec) {
+		s.Before(func(t *testcase.T) {
+			if c.OnePhaseCommit == nil {
+				t.Skip("Config.OnePhaseCommit is not supplied")
+			}
+		})
+
+		s.Test("an event created within a rolled back transaction is not found", func(t *testcase.T) {
+			tx, err := c.OnePhaseCommit.BeginTx(ctx.Get(t))
+			assert.NoError(t, err)
+			event := makeEvent(t, entityID.Get(t), now())
+			assert.NoError(t, subject.Create(tx, &event))
+			assert.NoError(t, c.OnePhaseCommit.RollbackTx(tx))
+
+			got, err := iterkit.CollectE(subject.FindByEntityID(ctx.Get(t), entityID.Get(t)))
+			assert.NoError(t, err)
+			assert.Empty(t, got)
+		})
+
+		s.Test("an event created within a committed transaction is found", func(t *testcase.T) {
+			tx, err := c.OnePhaseCommit.BeginTx(ctx.Get(t))
+			assert.NoError(t, err)
+			event := makeEvent(t, entityID.Get(t), now())
+			assert.NoError(t, subject.Create(tx, &event))
+			assert.NoError(t, c.OnePhaseCommit.CommitTx(tx))
+
+			got, err := iterkit.CollectE(subject.FindByEntityID(ctx.Get(t), entityID.Get(t)))
+			assert.NoError(t, err)
+			assert.Equal(t, 1, len(got))
+		})
+	})
+
+	return s.AsSuite("audit.EventRepository")
+}
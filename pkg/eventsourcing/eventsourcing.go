@@ -0,0 +1,206 @@
+// Package eventsourcing keeps the state of aggregates as a stream of events.
+//
+// An Aggregate is rebuilt by applying the events of its stream,
+// and it is changed by raising new events, which the Repository appends to the EventStore.
+// The EventStore uses optimistic concurrency, based on the version of the stream,
+// so two concurrent changes on the same aggregate can't both succeed.
+//
+// Snapshots shorten the rebuilding of aggregates with long streams,
+// and the ProjectionRunner builds read models from the events of every stream.
+package eventsourcing
+
+import (
+	"context"
+	"iter"
+	"reflect"
+	"time"
+
+	"go.llib.dev/frameless/pkg/errorkit"
+	"go.llib.dev/frameless/pkg/logger"
+	"go.llib.dev/frameless/pkg/logging"
+	"go.llib.dev/frameless/pkg/reflectkit"
+	"go.llib.dev/testcase/clock"
+)
+
+// StreamID identifies the event stream of an aggregate.
+type StreamID string
+
+// Version is the position of an event within its stream.
+// The first event of a stream is at version 1, and a stream without any event is at version 0.
+type Version int64
+
+// Position is the position of an event across every stream of an EventStore.
+type Position int64
+
+// Record is an event, as it is stored in an EventStore.
+type Record[E any] struct {
+	StreamID   StreamID
+	Version    Version
+	Position   Position
+	Event      E
+	RecordedAt time.Time
+}
+
+// ErrConcurrencyConflict is returned when the stream is not at the expected version,
+// because it was changed since the aggregate was loaded.
+const ErrConcurrencyConflict errorkit.Error = "eventsourcing: the stream was changed concurrently"
+
+// EventStore is the storage of the event streams.
+type EventStore[E any] interface {
+	// Append appends the events to the end of the stream, when the stream is at the expected version.
+	// Otherwise, it fails with ErrConcurrencyConflict, and none of the events are appended.
+	Append(ctx context.Context, streamID StreamID, expected Version, events ...E) error
+	// Load yields the records of the stream with a version after the given one, in the order of their versions.
+	Load(ctx context.Context, streamID StreamID, after Version) iter.Seq2[Record[E], error]
+	// LoadAll yields the records of every stream with a position after the given one, in the order they were appended.
+	LoadAll(ctx context.Context, after Position) iter.Seq2[Record[E], error]
+}
+
+// Snapshot is the state of an aggregate at a version of its stream.
+type Snapshot[S any] struct {
+	StreamID StreamID `ext:"id"`
+	Version  Version
+	State    S
+	TakenAt  time.Time
+}
+
+// SnapshotStore keeps the latest snapshot of the aggregates.
+type SnapshotStore[S any] interface {
+	// SaveSnapshot stores the snapshot, replacing the previous snapshot of the stream.
+	SaveSnapshot(ctx context.Context, snapshot Snapshot[S]) error
+	// LoadSnapshot returns the latest snapshot of the stream.
+	LoadSnapshot(ctx context.Context, streamID StreamID) (Snapshot[S], bool, error)
+}
+
+// Aggregate is a consistency boundary, which state is the result of its events.
+//
+// The commands of an Aggregate don't change its state directly,
+// instead they tell the events that the command would cause, and those events are raised on its Root.
+type Aggregate[E any] interface {
+	// Apply changes the state of the aggregate according to the event.
+	// Apply can't fail, as the event is a fact that already happened.
+	Apply(event E)
+}
+
+// Root is an Aggregate together with its stream, and the events that were raised since it was loaded.
+type Root[A Aggregate[E], E any] struct {
+	StreamID  StreamID
+	Aggregate A
+	// Version is the version of the stream, which the Root was loaded or last saved at.
+	Version Version
+
+	pending []E
+}
+
+// Raise applies the events on the Aggregate, and keeps them until the Root is saved.
+func (r *Root[A, E]) Raise(events ...E) {
+	for _, event := range events {
+		r.Aggregate.Apply(event)
+		r.pending = append(r.pending, event)
+	}
+}
+
+// Pending returns the events that were raised since the Root was loaded or saved.
+func (r *Root[A, E]) Pending() []E {
+	return append([]E{}, r.pending...)
+}
+
+// Repository loads and saves aggregates through an EventStore.
+type Repository[A Aggregate[E], E any] struct {
+	Events EventStore[E]
+	// New [optional] makes a new Aggregate, without any event applied.
+	//
+	// default: the zero value of A, or a pointer to a new zero value, when A is a pointer type.
+	New func() A
+	// Snapshots [optional] stores the snapshots of the aggregates.
+	// Without it, an aggregate is always rebuilt from the beginning of its stream.
+	Snapshots SnapshotStore[A]
+	// SnapshotEvery [optional] is the number of events, after which a new snapshot is taken.
+	//
+	// default: 100
+	SnapshotEvery int
+}
+
+// Load rebuilds the aggregate from its latest snapshot and from the events that came after it.
+// An aggregate without any event is returned at version 0.
+func (r Repository[A, E]) Load(ctx context.Context, streamID StreamID) (*Root[A, E], error) {
+	root := &Root[A, E]{StreamID: streamID, Aggregate: r.new()}
+	if r.Snapshots != nil {
+		snapshot, found, err := r.Snapshots.LoadSnapshot(ctx, streamID)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			root.Aggregate = snapshot.State
+			root.Version = snapshot.Version
+		}
+	}
+	for rec, err := range r.Events.Load(ctx, streamID, root.Version) {
+		if err != nil {
+			return nil, err
+		}
+		root.Aggregate.Apply(rec.Event)
+		root.Version = rec.Version
+	}
+	return root, nil
+}
+
+// Save appends the pending events of the Root to its stream.
+// When the stream was changed since the Root was loaded, Save fails with ErrConcurrencyConflict.
+func (r Repository[A, E]) Save(ctx context.Context, root *Root[A, E]) error {
+	if len(root.pending) == 0 {
+		return nil
+	}
+	if err := r.Events.Append(ctx, root.StreamID, root.Version, root.pending...); err != nil {
+		return err
+	}
+	before := root.Version
+	root.Version += Version(len(root.pending))
+	root.pending = nil
+	if r.Snapshots != nil && before/r.getSnapshotEvery() < root.Version/r.getSnapshotEvery() {
+		snapshot := Snapshot[A]{
+			StreamID: root.StreamID,
+			Version:  root.Version,
+			State:    root.Aggregate,
+			TakenAt:  clock.Now().UTC(),
+		}
+		// the events are already stored, a missing snapshot only makes the next Load slower
+		if err := r.Snapshots.SaveSnapshot(ctx, snapshot); err != nil {
+			logger.Warn(ctx, "eventsourcing Repository failed to save a snapshot",
+				logging.Field("stream", string(root.StreamID)), logging.ErrField(err))
+		}
+	}
+	return nil
+}
+
+// Execute loads the aggregate, raises the events that the command tells, and saves the aggregate.
+// On ErrConcurrencyConflict, the caller may Execute the command again on the newer state.
+func (r Repository[A, E]) Execute(ctx context.Context, streamID StreamID, command func(A) ([]E, error)) error {
+	root, err := r.Load(ctx, streamID)
+	if err != nil {
+		return err
+	}
+	events, err := command(root.Aggregate)
+	if err != nil {
+		return err
+	}
+	root.Raise(events...)
+	return r.Save(ctx, root)
+}
+
+func (r Repository[A, E]) new() A {
+	if r.New != nil {
+		return r.New()
+	}
+	if typ := reflectkit.TypeOf[A](); typ.Kind() == reflect.Pointer {
+		return reflect.New(typ.Elem()).Interface().(A)
+	}
+	return *new(A)
+}
+
+func (r Repository[A, E]) getSnapshotEvery() Version {
+	if r.SnapshotEvery <= 0 {
+		return 100
+	}
+	return Version(r.SnapshotEvery)
+}
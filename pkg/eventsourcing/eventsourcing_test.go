@@ -0,0 +1,305 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.llib.dev/frameless/adapter/memory"
+	"go.llib.dev/frameless/pkg/eventsourcing"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+)
+
+type AccountEvent struct {
+	Deposited int
+	Withdrawn int
+}
+
+type Account struct {
+	Balance int
+	Applied int
+}
+
+func (a *Account) Apply(event AccountEvent) {
+	a.Balance += event.Deposited - event.Withdrawn
+	a.Applied++
+}
+
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+func (a *Account) Withdraw(amount int) ([]AccountEvent, error) {
+	if a.Balance < amount {
+		return nil, ErrInsufficientFunds
+	}
+	return []AccountEvent{{Withdrawn: amount}}, nil
+}
+
+func ExampleRepository() {
+	el := memory.NewEventLog()
+	accounts := eventsourcing.Repository[*Account, AccountEvent]{
+		Events:    memory.NewEventStore[AccountEvent](el),
+		Snapshots: memory.NewSnapshotStore[*Account](el),
+	}
+
+	ctx := context.Background()
+	root, _ := accounts.Load(ctx, "account-1")
+	root.Raise(AccountEvent{Deposited: 100})
+	_ = accounts.Save(ctx, root)
+
+	_ = accounts.Execute(ctx, "account-1", func(a *Account) ([]AccountEvent, error) {
+		return a.Withdraw(30)
+	})
+}
+
+func ExampleProjectionRunner() {
+	var (
+		el       = memory.NewEventLog()
+		events   = memory.NewEventStore[AccountEvent](el)
+		balances = map[eventsourcing.StreamID]int{}
+	)
+	runner := eventsourcing.ProjectionRunner[AccountEvent]{
+		Name:        "balances",
+		Events:      events,
+		Checkpoints: events,
+		Projection: eventsourcing.ProjectionFunc[AccountEvent](func(ctx context.Context, rec eventsourcing.Record[AccountEvent]) error {
+			balances[rec.StreamID] += rec.Event.Deposited - rec.Event.Withdrawn
+			return nil
+		}),
+	}
+
+	ctx := context.Background()
+	_ = events.Append(ctx, "account-1", 0, AccountEvent{Deposited: 100})
+	_ = runner.CatchUp(ctx) // or run it in the background with tasker.Main(ctx, runner.Run)
+}
+
+func TestRepository(t *testing.T) {
+	s := testcase.NewSpec(t)
+
+	var (
+		el     = testcase.Let(s, func(t *testcase.T) *memory.EventLog { return memory.NewEventLog() })
+		events = testcase.Let(s, func(t *testcase.T) *memory.EventStore[AccountEvent] {
+			return memory.NewEventStore[AccountEvent](el.Get(t))
+		})
+		snapshots = testcase.Let(s, func(t *testcase.T) *memory.SnapshotStore[*Account] {
+			return memory.NewSnapshotStore[*Account](el.Get(t))
+		})
+		subject = testcase.Let(s, func(t *testcase.T) eventsourcing.Repository[*Account, AccountEvent] {
+			return eventsourcing.Repository[*Account, AccountEvent]{Events: events.Get(t)}
+		})
+		ctx      = testcase.Let(s, func(t *testcase.T) context.Context { return context.Background() })
+		streamID = testcase.Let(s, func(t *testcase.T) eventsourcing.StreamID {
+			return eventsourcing.StreamID(t.Random.UUID())
+		})
+	)
+
+	load := func(t *testcase.T) *eventsourcing.Root[*Account, AccountEvent] {
+		t.Helper()
+		root, err := subject.Get(t).Load(ctx.Get(t), streamID.Get(t))
+		assert.NoError(t, err)
+		return root
+	}
+
+	deposit := func(t *testcase.T, amounts ...int) {
+		t.Helper()
+		root := load(t)
+		for _, amount := range amounts {
+			root.Raise(AccountEvent{Deposited: amount})
+		}
+		assert.NoError(t, subject.Get(t).Save(ctx.Get(t), root))
+	}
+
+	s.Test("a new aggregate is loaded at version zero", func(t *testcase.T) {
+		root := load(t)
+		assert.Equal(t, streamID.Get(t), root.StreamID)
+		assert.Equal(t, eventsourcing.Version(0), root.Version)
+		assert.NotNil(t, root.Aggregate)
+		assert.Equal(t, Account{}, *root.Aggregate)
+	})
+
+	s.Test("raised events are applied on the aggregate and kept until saving", func(t *testcase.T) {
+		root := load(t)
+		root.Raise(AccountEvent{Deposited: 42})
+		assert.Equal(t, 42, root.Aggregate.Balance)
+		assert.Equal(t, []AccountEvent{{Deposited: 42}}, root.Pending())
+
+		assert.NoError(t, subject.Get(t).Save(ctx.Get(t), root))
+		assert.Empty(t, root.Pending())
+		assert.Equal(t, eventsourcing.Version(1), root.Version)
+	})
+
+	s.Test("a saved aggregate is rebuilt from its events", func(t *testcase.T) {
+		deposit(t, 10, 20)
+		deposit(t, 30)
+
+		root := load(t)
+		assert.Equal(t, eventsourcing.Version(3), root.Version)
+		assert.Equal(t, 60, root.Aggregate.Balance)
+	})
+
+	s.Test("saving an aggregate that was changed meanwhile fails with a concurrency conflict", func(t *testcase.T) {
+		root := load(t)
+		deposit(t, 10)
+
+		root.Raise(AccountEvent{Deposited: 20})
+		assert.ErrorIs(t, subject.Get(t).Save(ctx.Get(t), root), eventsourcing.ErrConcurrencyConflict)
+		assert.Equal(t, 10, load(t).Aggregate.Balance)
+	})
+
+	s.Test("Execute raises the events of the command", func(t *testcase.T) {
+		deposit(t, 100)
+		assert.NoError(t, subject.Get(t).Execute(ctx.Get(t), streamID.Get(t), func(a *Account) ([]AccountEvent, error) {
+			return a.Withdraw(30)
+		}))
+		assert.Equal(t, 70, load(t).Aggregate.Balance)
+	})
+
+	s.Test("Execute doesn't save anything when the command fails", func(t *testcase.T) {
+		deposit(t, 10)
+		err := subject.Get(t).Execute(ctx.Get(t), streamID.Get(t), func(a *Account) ([]AccountEvent, error) {
+			return a.Withdraw(30)
+		})
+		assert.ErrorIs(t, err, ErrInsufficientFunds)
+		assert.Equal(t, eventsourcing.Version(1), load(t).Version)
+	})
+
+	s.Context("with snapshots", func(s *testcase.Spec) {
+		subject.Let(s, func(t *testcase.T) eventsourcing.Repository[*Account, AccountEvent] {
+			return eventsourcing.Repository[*Account, AccountEvent]{
+				Events:        events.Get(t),
+				Snapshots:     snapshots.Get(t),
+				SnapshotEvery: 3,
+			}
+		})
+
+		s.Test("a snapshot is taken when the stream passes the next multiple of SnapshotEvery", func(t *testcase.T) {
+			deposit(t, 1, 1)
+			_, found, err := snapshots.Get(t).LoadSnapshot(ctx.Get(t), streamID.Get(t))
+			assert.NoError(t, err)
+			assert.False(t, found)
+
+			deposit(t, 1, 1)
+			snapshot, found, err := snapshots.Get(t).LoadSnapshot(ctx.Get(t), streamID.Get(t))
+			assert.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, eventsourcing.Version(4), snapshot.Version)
+			assert.Equal(t, 4, snapshot.State.Balance)
+		})
+
+		s.Test("the aggregate is rebuilt from its snapshot and the events after it", func(t *testcase.T) {
+			deposit(t, 1, 1, 1)
+			deposit(t, 1)
+			// a snapshot with a distinct state tells if the events before it are applied again
+			assert.NoError(t, snapshots.Get(t).SaveSnapshot(ctx.Get(t), eventsourcing.Snapshot[*Account]{
+				StreamID: streamID.Get(t),
+				Version:  3,
+				State:    &Account{Balance: 100},
+			}))
+
+			root := load(t)
+			assert.Equal(t, eventsourcing.Version(4), root.Version)
+			assert.Equal(t, 101, root.Aggregate.Balance)
+			assert.Equal(t, 1, root.Aggregate.Applied)
+		})
+
+		s.Test("changing the aggregate after saving doesn't change its snapshot", func(t *testcase.T) {
+			root := load(t)
+			root.Raise(AccountEvent{Deposited: 1}, AccountEvent{Deposited: 1}, AccountEvent{Deposited: 1})
+			assert.NoError(t, subject.Get(t).Save(ctx.Get(t), root))
+			root.Raise(AccountEvent{Deposited: 100})
+
+			snapshot, found, err := snapshots.Get(t).LoadSnapshot(ctx.Get(t), streamID.Get(t))
+			assert.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, 3, snapshot.State.Balance)
+		})
+	})
+}
+
+func TestProjectionRunner(t *testing.T) {
+	s := testcase.NewSpec(t)
+
+	var (
+		events = testcase.Let(s, func(t *testcase.T) *memory.EventStore[AccountEvent] {
+			return memory.NewEventStore[AccountEvent](memory.NewEventLog())
+		})
+		projected  = testcase.LetValue[[]eventsourcing.Record[AccountEvent]](s, nil)
+		projectErr = testcase.LetValue[error](s, nil)
+		subject    = testcase.Let(s, func(t *testcase.T) eventsourcing.ProjectionRunner[AccountEvent] {
+			return eventsourcing.ProjectionRunner[AccountEvent]{
+				Name:        t.Random.UUID(),
+				Events:      events.Get(t),
+				Checkpoints: events.Get(t),
+				BatchSize:   2,
+				Projection: eventsourcing.ProjectionFunc[AccountEvent](func(ctx context.Context, rec eventsourcing.Record[AccountEvent]) error {
+					if err := projectErr.Get(t); err != nil {
+						return err
+					}
+					projected.Set(t, append(projected.Get(t), rec))
+					return nil
+				}),
+			}
+		})
+		ctx = testcase.Let(s, func(t *testcase.T) context.Context { return context.Background() })
+	)
+
+	appendEvents := func(t *testcase.T, n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			assert.NoError(t, events.Get(t).Append(ctx.Get(t), eventsourcing.StreamID(t.Random.UUID()), 0, AccountEvent{Deposited: i}))
+		}
+	}
+
+	s.Test("CatchUp projects every record in the order they were appended, across batches", func(t *testcase.T) {
+		appendEvents(t, 5)
+		assert.NoError(t, subject.Get(t).CatchUp(ctx.Get(t)))
+
+		assert.Equal(t, 5, len(projected.Get(t)))
+		for i, rec := range projected.Get(t) {
+			assert.Equal(t, eventsourcing.Position(i+1), rec.Position)
+		}
+	})
+
+	s.Test("CatchUp continues from the checkpoint", func(t *testcase.T) {
+		appendEvents(t, 2)
+		assert.NoError(t, subject.Get(t).CatchUp(ctx.Get(t)))
+		appendEvents(t, 1)
+		assert.NoError(t, subject.Get(t).CatchUp(ctx.Get(t)))
+
+		assert.Equal(t, 3, len(projected.Get(t)))
+		position, err := events.Get(t).LoadCheckpoint(ctx.Get(t), subject.Get(t).Name)
+		assert.NoError(t, err)
+		assert.Equal(t, eventsourcing.Position(3), position)
+	})
+
+	s.Test("a failed projection stops the catching up, and the record is projected again", func(t *testcase.T) {
+		appendEvents(t, 2)
+		projectErr.Set(t, t.Random.Error())
+		assert.ErrorIs(t, subject.Get(t).CatchUp(ctx.Get(t)), projectErr.Get(t))
+
+		position, err := events.Get(t).LoadCheckpoint(ctx.Get(t), subject.Get(t).Name)
+		assert.NoError(t, err)
+		assert.Equal(t, eventsourcing.Position(0), position)
+
+		projectErr.Set(t, nil)
+		assert.NoError(t, subject.Get(t).CatchUp(ctx.Get(t)))
+		assert.Equal(t, 2, len(projected.Get(t)))
+	})
+
+	s.Test("Run catches up until the context is cancelled", func(t *testcase.T) {
+		appendEvents(t, 3)
+		runner := subject.Get(t)
+		runner.Interval = nil
+
+		cctx, cancel := context.WithCancel(ctx.Get(t))
+		done := make(chan error)
+		go func() { done <- runner.Run(cctx) }()
+
+		assert.Eventually(t, 3 * time.Second, func(it testing.TB) {
+			assert.Equal(it, 3, len(projected.Get(t)))
+		})
+		cancel()
+		assert.Within(t, 3 * time.Second, func(context.Context) { <-done })
+	})
+}
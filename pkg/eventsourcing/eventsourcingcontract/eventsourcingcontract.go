@@ -0,0 +1,340 @@
+package eventsourcingcontract
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.llib.dev/frameless/internal/spechelper"
+	"go.llib.dev/frameless/pkg/eventsourcing"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/reflectkit"
+	"go.llib.dev/frameless/port/comproto"
+	"go.llib.dev/frameless/port/contract"
+	"go.llib.dev/frameless/port/option"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/let"
+)
+
+type Option[T any] option.Option[Config[T]]
+
+type Config[T any] struct {
+	MakeContext func(testing.TB) context.Context
+	// MakeValue makes an event for the EventStore contract, and a state for the SnapshotStore contract.
+	MakeValue func(testing.TB) T
+	// OnePhaseCommit [optional] is the transaction manager, which transactions the subject takes part in.
+	OnePhaseCommit comproto.OnePhaseCommitProtocol
+}
+
+func (c *Config[T]) Init() {
+	c.MakeContext = func(testing.TB) context.Context {
+		return context.Background()
+	}
+	c.MakeValue = spechelper.MakeValue[T]
+}
+
+func (c Config[T]) Configure(t *Config[T]) {
+	*t = reflectkit.MergeStruct(*t, c)
+}
+
+// EventStore verifies the optimistic concurrency and the ordering guarantees of an eventsourcing.EventStore.
+//
+// When the subject also implements eventsourcing.CheckpointStore,
+// its checkpoints are verified together with an eventsourcing.ProjectionRunner.
+func EventStore[E any](subject eventsourcing.EventStore[E], opts ...Option[E]) contract.Contract {
+	c := option.ToConfig[Config[E]](opts)
+	s := testcase.NewSpec(nil)
+	s.HasSideEffect()
+
+	var (
+		ctx      = let.With[context.Context](s, c.MakeContext)
+		streamID = let.Var(s, func(t *testcase.T) eventsourcing.StreamID {
+			return eventsourcing.StreamID(t.Random.UUID())
+		})
+	)
+
+	makeEvents := func(t *testcase.T) []E {
+		var events []E
+		t.Random.Repeat(1, 5, func() { events = append(events, c.MakeValue(t)) })
+		return events
+	}
+
+	load := func(t *testcase.T, id eventsourcing.StreamID, after eventsourcing.Version) []eventsourcing.Record[E] {
+		t.Helper()
+		recs, err := iterkit.CollectE(subject.Load(ctx.Get(t), id, after))
+		assert.NoError(t, err)
+		return recs
+	}
+
+	s.Test("the appended events are loaded in the order of their versions", func(t *testcase.T) {
+		events := makeEvents(t)
+		assert.NoError(t, subject.Append(ctx.Get(t), streamID.Get(t), 0, events...))
+
+		recs := load(t, streamID.Get(t), 0)
+		assert.Equal(t, len(events), len(recs))
+		for i, rec := range recs {
+			assert.Equal(t, streamID.Get(t), rec.StreamID)
+			assert.Equal(t, eventsourcing.Version(i+1), rec.Version)
+			assert.Equal(t, events[i], rec.Event)
+			assert.False(t, rec.RecordedAt.IsZero())
+		}
+	})
+
+	s.Test("a stream without events loads nothing", func(t *testcase.T) {
+		assert.Empty(t, load(t, streamID.Get(t), 0))
+	})
+
+	s.Test("the events are loaded after the given version", func(t *testcase.T) {
+		first, second := c.MakeValue(t), c.MakeValue(t)
+		assert.NoError(t, subject.Append(ctx.Get(t), streamID.Get(t), 0, first))
+		assert.NoError(t, subject.Append(ctx.Get(t), streamID.Get(t), 1, second))
+
+		recs := load(t, streamID.Get(t), 1)
+		assert.Equal(t, 1, len(recs))
+		assert.Equal(t, eventsourcing.Version(2), recs[0].Version)
+		assert.Equal(t, second, recs[0].Event)
+	})
+
+	s.Test("appending at a stale version fails with a concurrency conflict, and appends nothing", func(t *testcase.T) {
+		assert.NoError(t, subject.Append(ctx.Get(t), streamID.Get(t), 0, c.MakeValue(t)))
+
+		err := subject.Append(ctx.Get(t), streamID.Get(t), 0, makeEvents(t)...)
+		assert.ErrorIs(t, err, eventsourcing.ErrConcurrencyConflict)
+		assert.Equal(t, 1, len(load(t, streamID.Get(t), 0)))
+	})
+
+	s.Test("appending ahead of the stream fails with a concurrency conflict", func(t *testcase.T) {
+		err := subject.Append(ctx.Get(t), streamID.Get(t), eventsourcing.Version(t.Random.IntBetween(1, 42)), c.MakeValue(t))
+		assert.ErrorIs(t, err, eventsourcing.ErrConcurrencyConflict)
+		assert.Empty(t, load(t, streamID.Get(t), 0))
+	})
+
+	s.Test("the streams have their own versions", func(t *testcase.T) {
+		other := eventsourcing.StreamID(t.Random.UUID())
+		assert.NoError(t, subject.Append(ctx.Get(t), streamID.Get(t), 0, c.MakeValue(t)))
+		assert.NoError(t, subject.Append(ctx.Get(t), other, 0, c.MakeValue(t)))
+
+		assert.Equal(t, 1, len(load(t, streamID.Get(t), 0)))
+		assert.Equal(t, 1, len(load(t, other, 0)))
+	})
+
+	s.Test("LoadAll yields the records of every stream in the order they were appended", func(t *testcase.T) {
+		var (
+			other = eventsourcing.StreamID(t.Random.UUID())
+			first = c.MakeValue(t)
+		)
+		assert.NoError(t, subject.Append(ctx.Get(t), streamID.Get(t), 0, first))
+		assert.NoError(t, subject.Append(ctx.Get(t), other, 0, c.MakeValue(t)))
+		assert.NoError(t, subject.Append(ctx.Get(t), streamID.Get(t), 1, c.MakeValue(t)))
+
+		head := load(t, streamID.Get(t), 0)[0].Position
+		recs, err := iterkit.CollectE(subject.LoadAll(ctx.Get(t), head-1))
+		assert.NoError(t, err)
+
+		var got []eventsourcing.StreamID
+		for i, rec := range recs {
+			if 0 < i {
+				assert.True(t, recs[i-1].Position < rec.Position, "positions are expected to increase")
+			}
+			if rec.StreamID == streamID.Get(t) || rec.StreamID == other {
+				got = append(got, rec.StreamID)
+			}
+		}
+		assert.Equal(t, []eventsourcing.StreamID{streamID.Get(t), other, streamID.Get(t)}, got)
+		assert.Equal(t, first, recs[0].Event)
+	})
+
+	s.Context("transaction", func(s *testcase.Spec) {
+		s.Before(func(t *testcase.T) {
+			if c.OnePhaseCommit == nil {
+				t.Skip("Config.OnePhaseCommit is not supplied")
+			}
+		})
+
+		s.Test("events appended within a rolled back transaction are not loaded", func(t *testcase.T) {
+			tx, err := c.OnePhaseCommit.BeginTx(ctx.Get(t))
+			assert.NoError(t, err)
+			assert.NoError(t, subject.Append(tx, streamID.Get(t), 0, makeEvents(t)...))
+			assert.NoError(t, c.OnePhaseCommit.RollbackTx(tx))
+
+			assert.Empty(t, load(t, streamID.Get(t), 0))
+		})
+
+		s.Test("events appended within a committed transaction are loaded", func(t *testcase.T) {
+			tx, err := c.OnePhaseCommit.BeginTx(ctx.Get(t))
+			assert.NoError(t, err)
+			events := makeEvents(t)
+			assert.NoError(t, subject.Append(tx, streamID.Get(t), 0, events...))
+			assert.NoError(t, c.OnePhaseCommit.CommitTx(tx))
+
+			assert.Equal(t, len(events), len(load(t, streamID.Get(t), 0)))
+		})
+
+		s.Test("only one of two concurrent transactions can append the same version", func(t *testcase.T) {
+			tx1, err := c.OnePhaseCommit.BeginTx(ctx.Get(t))
+			assert.NoError(t, err)
+			events := makeEvents(t)
+			assert.NoError(t, subject.Append(tx1, streamID.Get(t), 0, events...))
+
+			tx2, err := c.OnePhaseCommit.BeginTx(ctx.Get(t))
+			assert.NoError(t, err)
+			defer c.OnePhaseCommit.RollbackTx(tx2)
+			// the second Append either fails right away, or waits for the first transaction to finish.
+			conflict := make(chan error, 1)
+			go func() { conflict <- subject.Append(tx2, streamID.Get(t), 0, makeEvents(t)...) }()
+			var (
+				err2     error
+				finished bool
+			)
+			select {
+			case err2 = <-conflict:
+				finished = true
+			case <-time.After(250 * time.Millisecond):
+			}
+
+			assert.NoError(t, c.OnePhaseCommit.CommitTx(tx1))
+			if !finished {
+				select {
+				case err2 = <-conflict:
+				case <-time.After(time.Minute):
+					t.Fatal("the second Append didn't finish")
+				}
+			}
+			assert.ErrorIs(t, err2, eventsourcing.ErrConcurrencyConflict)
+
+			recs := load(t, streamID.Get(t), 0)
+			assert.Equal(t, len(events), len(recs))
+			for i, rec := range recs {
+				assert.Equal(t, events[i], rec.Event)
+			}
+		})
+	})
+
+	s.Context("CheckpointStore", func(s *testcase.Spec) {
+		checkpoints := let.Var(s, func(t *testcase.T) eventsourcing.CheckpointStore {
+			cs, ok := subject.(eventsourcing.CheckpointStore)
+			if !ok {
+				t.Skipf("%T doesn't implement eventsourcing.CheckpointStore", subject)
+			}
+			return cs
+		})
+		projection := let.Var(s, func(t *testcase.T) string { return t.Random.UUID() })
+
+		s.Test("a projection without a checkpoint starts from the beginning", func(t *testcase.T) {
+			position, err := checkpoints.Get(t).LoadCheckpoint(ctx.Get(t), projection.Get(t))
+			assert.NoError(t, err)
+			assert.Equal(t, eventsourcing.Position(0), position)
+		})
+
+		s.Test("the last saved checkpoint of a projection is loaded", func(t *testcase.T) {
+			assert.NoError(t, checkpoints.Get(t).SaveCheckpoint(ctx.Get(t), projection.Get(t), 1))
+			assert.NoError(t, checkpoints.Get(t).SaveCheckpoint(ctx.Get(t), projection.Get(t), 42))
+			assert.NoError(t, checkpoints.Get(t).SaveCheckpoint(ctx.Get(t), t.Random.UUID(), 24))
+
+			position, err := checkpoints.Get(t).LoadCheckpoint(ctx.Get(t), projection.Get(t))
+			assert.NoError(t, err)
+			assert.Equal(t, eventsourcing.Position(42), position)
+		})
+
+		s.Test("a ProjectionRunner projects the records appended since its checkpoint", func(t *testcase.T) {
+			// the checkpoint is set to the current end of the store, to skip the records of other tests
+			assert.NoError(t, subject.Append(ctx.Get(t), streamID.Get(t), 0, c.MakeValue(t)))
+			head := load(t, streamID.Get(t), 0)[0].Position
+			assert.NoError(t, checkpoints.Get(t).SaveCheckpoint(ctx.Get(t), projection.Get(t), head))
+
+			var projected []E
+			runner := eventsourcing.ProjectionRunner[E]{
+				Name:        projection.Get(t),
+				Events:      subject,
+				Checkpoints: checkpoints.Get(t),
+				Projection: eventsourcing.ProjectionFunc[E](func(ctx context.Context, rec eventsourcing.Record[E]) error {
+					if rec.StreamID == streamID.Get(t) {
+						projected = append(projected, rec.Event)
+					}
+					return nil
+				}),
+			}
+
+			events := makeEvents(t)
+			assert.NoError(t, subject.Append(ctx.Get(t), streamID.Get(t), 1, events...))
+			assert.NoError(t, runner.CatchUp(ctx.Get(t)))
+			assert.Equal(t, events, projected)
+
+			assert.NoError(t, runner.CatchUp(ctx.Get(t)))
+			assert.Equal(t, events, projected, "already projected records should not be projected again")
+		})
+	})
+
+	return s.AsSuite("eventsourcing.EventStore")
+}
+
+// SnapshotStore verifies that an eventsourcing.SnapshotStore keeps the latest snapshot of each stream.
+func SnapshotStore[S any](subject eventsourcing.SnapshotStore[S], opts ...Option[S]) contract.Contract {
+	c := option.ToConfig[Config[S]](opts)
+	s := testcase.NewSpec(nil)
+	s.HasSideEffect()
+
+	var (
+		ctx      = let.With[context.Context](s, c.MakeContext)
+		streamID = let.Var(s, func(t *testcase.T) eventsourcing.StreamID {
+			return eventsourcing.StreamID(t.Random.UUID())
+		})
+	)
+
+	makeSnapshot := func(t *testcase.T, version eventsourcing.Version) eventsourcing.Snapshot[S] {
+		return eventsourcing.Snapshot[S]{
+			StreamID: streamID.Get(t),
+			Version:  version,
+			State:    c.MakeValue(t),
+			TakenAt:  t.Random.Time().UTC(),
+		}
+	}
+
+	assertSnapshot := func(t *testcase.T, exp, got eventsourcing.Snapshot[S]) {
+		t.Helper()
+		// not every storage keeps the time zone and nanoseconds of the time
+		assert.True(t, exp.TakenAt.Sub(got.TakenAt).Abs() < time.Microsecond)
+		got.TakenAt = exp.TakenAt
+		assert.Equal(t, exp, got)
+	}
+
+	s.Test("a stream without a snapshot is not found", func(t *testcase.T) {
+		_, found, err := subject.LoadSnapshot(ctx.Get(t), streamID.Get(t))
+		assert.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	s.Test("a saved snapshot is loaded", func(t *testcase.T) {
+		snapshot := makeSnapshot(t, 1)
+		assert.NoError(t, subject.SaveSnapshot(ctx.Get(t), snapshot))
+
+		got, found, err := subject.LoadSnapshot(ctx.Get(t), streamID.Get(t))
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assertSnapshot(t, snapshot, got)
+	})
+
+	s.Test("a newer snapshot replaces the previous one", func(t *testcase.T) {
+		assert.NoError(t, subject.SaveSnapshot(ctx.Get(t), makeSnapshot(t, 1)))
+		snapshot := makeSnapshot(t, 2)
+		assert.NoError(t, subject.SaveSnapshot(ctx.Get(t), snapshot))
+
+		got, found, err := subject.LoadSnapshot(ctx.Get(t), streamID.Get(t))
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assertSnapshot(t, snapshot, got)
+	})
+
+	s.Test("the snapshots of other streams are not loaded", func(t *testcase.T) {
+		snapshot := makeSnapshot(t, 1)
+		snapshot.StreamID = eventsourcing.StreamID(t.Random.UUID())
+		assert.NoError(t, subject.SaveSnapshot(ctx.Get(t), snapshot))
+
+		_, found, err := subject.LoadSnapshot(ctx.Get(t), streamID.Get(t))
+		assert.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	return s.AsSuite("eventsourcing.SnapshotStore")
+}
@@ -0,0 +1,135 @@
+package eventsourcing
+
+import (
+	"context"
+	"time"
+
+	"go.llib.dev/frameless/pkg/logger"
+	"go.llib.dev/frameless/pkg/logging"
+	"go.llib.dev/frameless/pkg/tasker"
+	"go.llib.dev/frameless/port/comproto"
+)
+
+// Projection builds a read model from the records of an EventStore.
+type Projection[E any] interface {
+	Project(ctx context.Context, record Record[E]) error
+}
+
+// ProjectionFunc is a Projection made from a function.
+type ProjectionFunc[E any] func(ctx context.Context, record Record[E]) error
+
+func (fn ProjectionFunc[E]) Project(ctx context.Context, record Record[E]) error {
+	return fn(ctx, record)
+}
+
+// CheckpointStore keeps track of how far the projections got in the EventStore.
+type CheckpointStore interface {
+	// LoadCheckpoint returns the position of the last record that the named projection processed,
+	// or zero when it didn't process any record yet.
+	LoadCheckpoint(ctx context.Context, projection string) (Position, error)
+	// SaveCheckpoint stores the position of the last record that the named projection processed.
+	SaveCheckpoint(ctx context.Context, projection string, position Position) error
+}
+
+// ProjectionRunner feeds the records of an EventStore to a Projection, in the order they were appended.
+//
+// When the Checkpoints takes part in the transactions of a comproto.OnePhaseCommitProtocol,
+// each record is projected in the same transaction as its checkpoint is saved,
+// thus a read model in the same database is updated exactly once.
+// Otherwise, a record might be projected again after a failure, so the Projection should be idempotent.
+type ProjectionRunner[E any] struct {
+	// Name identifies the projection's checkpoint.
+	Name        string
+	Events      EventStore[E]
+	Projection  Projection[E]
+	Checkpoints CheckpointStore
+	// BatchSize [optional] is the number of records that are loaded at once.
+	//
+	// default: 100
+	BatchSize int
+	// Interval [optional] is the time between two catch up rounds when the ProjectionRunner is Run.
+	//
+	// default: 1 second
+	Interval tasker.Interval
+}
+
+// CatchUp projects the records which were appended since the last checkpoint.
+// The first failure stops the catching up, to keep the ordering of the records.
+func (r ProjectionRunner[E]) CatchUp(ctx context.Context) error {
+	position, err := r.Checkpoints.LoadCheckpoint(ctx, r.Name)
+	if err != nil {
+		return err
+	}
+	for {
+		batch, err := r.next(ctx, position)
+		if err != nil {
+			return err
+		}
+		for _, rec := range batch {
+			if err := r.project(ctx, rec); err != nil {
+				return err
+			}
+			position = rec.Position
+		}
+		if len(batch) < r.getBatchSize() {
+			return nil
+		}
+	}
+}
+
+// Run implements tasker.Runnable, and catches up repeatedly, until the context is cancelled.
+// A failed round doesn't stop the ProjectionRunner, the remaining records are retried in the next round.
+func (r ProjectionRunner[E]) Run(ctx context.Context) error {
+	return tasker.WithRepeat(r.getInterval(), func(ctx context.Context) error {
+		if err := r.CatchUp(ctx); err != nil && ctx.Err() == nil {
+			logger.Warn(ctx, "eventsourcing ProjectionRunner failed to catch up",
+				logging.Field("projection", r.Name), logging.ErrField(err))
+		}
+		return nil
+	})(ctx)
+}
+
+// next loads the next batch of records, which is collected before projecting them,
+// so the EventStore isn't read while the Projection writes.
+func (r ProjectionRunner[E]) next(ctx context.Context, after Position) ([]Record[E], error) {
+	var batch []Record[E]
+	for rec, err := range r.Events.LoadAll(ctx, after) {
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, rec)
+		if len(batch) == r.getBatchSize() {
+			break
+		}
+	}
+	return batch, nil
+}
+
+func (r ProjectionRunner[E]) project(ctx context.Context, rec Record[E]) (rErr error) {
+	if c, ok := r.Checkpoints.(comproto.OnePhaseCommitProtocol); ok {
+		tx, err := c.BeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer comproto.FinishOnePhaseCommit(&rErr, c, tx)
+		ctx = tx
+	}
+	if err := r.Projection.Project(ctx, rec); err != nil {
+		return err
+	}
+	return r.Checkpoints.SaveCheckpoint(ctx, r.Name, rec.Position)
+}
+
+func (r ProjectionRunner[E]) getBatchSize() int {
+	if r.BatchSize <= 0 {
+		return 100
+	}
+	return r.BatchSize
+}
+
+func (r ProjectionRunner[E]) getInterval() tasker.Interval {
+	if r.Interval == nil {
+		return tasker.Every(time.Second)
+	}
+	return r.Interval
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 
 	"go.llib.dev/frameless/pkg/contextkit"
+	"go.llib.dev/frameless/pkg/errorkit"
 	"go.llib.dev/frameless/pkg/logger"
 	"go.llib.dev/frameless/pkg/logging"
 	"go.llib.dev/frameless/pkg/reflectkit"
@@ -33,6 +34,19 @@ type ConnectionAdapter[DB, TX any] struct {
 	Commit func(ctx context.Context, tx *TX) error
 	// Rollback is a function that must rollback a given transaction.
 	Rollback func(ctx context.Context, tx *TX) error
+	// Prepare [optional] is a function that prepares a given transaction for a later commit,
+	// as the first phase of a two-phase commit.
+	// The txID identifies the prepared transaction.
+	// Without Prepare, the ConnectionAdapter doesn't support PrepareTx.
+	Prepare func(ctx context.Context, tx *TX, txID string) error
+	// CommitPrepared [optional] is a function that commits a given prepared transaction.
+	//
+	// default: Commit
+	CommitPrepared func(ctx context.Context, tx *TX, txID string) error
+	// RollbackPrepared [optional] is a function that rolls back a given prepared transaction.
+	//
+	// default: Rollback
+	RollbackPrepared func(ctx context.Context, tx *TX, txID string) error
 
 	// OnClose [optional] is used to implement the io.Closer.
 	// If The ConnectionAdapter needs to close something,
@@ -40,6 +54,17 @@ type ConnectionAdapter[DB, TX any] struct {
 	//
 	// default: DB.Close()
 	OnClose func() error
+	// Replicas [optional] are the read-only replicas of the DB.
+	// Outside of a transaction, the queries of a ContextReadOnly context are routed to a healthy replica,
+	// while everything else, including all the queries of a transaction, goes to the DB.
+	//
+	// Without an OnClose, Close also closes the replicas that implement io.Closer.
+	Replicas *ReplicaSet[DB]
+	// ErrNoRows [optional] is the error returned when a single row query has no result.
+	// A replica query that fails with ErrNoRows doesn't trigger a failover.
+	//
+	// default: sql.ErrNoRows
+	ErrNoRows error
 	// ErrTxDone is the error returned when the transaction is already finished.
 	// ErrTxDone is an optional field.
 	//
@@ -57,10 +82,17 @@ func (c ConnectionAdapter[DB, TX]) txm() txkit.Manager[DB, TX, Queryable] {
 		TxAdapter: c.TxAdapter,
 		DBAdapter: c.DBAdapter,
 		OnClose:   c.OnClose,
+
+		Prepare:          c.Prepare,
+		CommitPrepared:   c.CommitPrepared,
+		RollbackPrepared: c.RollbackPrepared,
 	}
 }
 
 func (c ConnectionAdapter[DB, TX]) Close() error {
+	if c.OnClose == nil && c.Replicas != nil {
+		return errorkit.Merge(c.txm().Close(), c.Replicas.close())
+	}
 	return c.txm().Close()
 }
 
@@ -83,6 +115,12 @@ func (c ConnectionAdapter[DB, TX]) RollbackTx(ctx context.Context) error {
 	return c.txm().RollbackTx(ctx)
 }
 
+// PrepareTx prepares the transaction of the context for a two-phase commit.
+// It requires the Prepare function of the ConnectionAdapter.
+func (c ConnectionAdapter[DB, TX]) PrepareTx(ctx context.Context) (context.Context, error) {
+	return c.txm().PrepareTx(ctx)
+}
+
 func (c ConnectionAdapter[DB, TX]) LookupTx(ctx context.Context) (*TX, bool) {
 	return c.txm().LookupTx(ctx)
 }
@@ -94,12 +132,20 @@ func (c ConnectionAdapter[DB, TX]) ExecContext(ctx context.Context, query string
 }
 
 func (c ConnectionAdapter[DB, TX]) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	if c.routesToReplica(ctx) {
+		if rows, ok, err := c.queryReplica(ctx, map[int]struct{}{}, query, args); ok {
+			return rows, err
+		}
+	}
 	conn := c.txm().Q(ctx)
 	c.debugLogExec(ctx, conn, "QueryContext", query, args)
 	return conn.QueryContext(ctx, query, args...)
 }
 
 func (c ConnectionAdapter[DB, TX]) QueryRowContext(ctx context.Context, query string, args ...any) Row {
+	if c.routesToReplica(ctx) {
+		return c.queryRowReplica(ctx, map[int]struct{}{}, query, args)
+	}
 	conn := c.txm().Q(ctx)
 	c.debugLogExec(ctx, conn, "QueryRowContext", query, args)
 	return conn.QueryRowContext(ctx, query, args...)
@@ -112,6 +158,13 @@ func (c ConnectionAdapter[DB, TX]) txDoneErr() error {
 	return sql.ErrTxDone
 }
 
+func (c ConnectionAdapter[DB, TX]) noRowsErr() error {
+	if c.ErrNoRows != nil {
+		return c.ErrNoRows
+	}
+	return sql.ErrNoRows
+}
+
 func (c ConnectionAdapter[DB, TX]) debugLogExec(ctx context.Context, conn Queryable, method string, query string, args []any) {
 	logger.Debug(ctx, "QueryableAdapter", logging.LazyDetail(func() logging.Detail {
 		fs := logging.Fields{
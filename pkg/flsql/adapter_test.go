@@ -5,10 +5,13 @@ import (
 	"database/sql"
 	"os"
 	"testing"
+	"time"
 
 	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/frameless/pkg/iterkit"
 	"go.llib.dev/frameless/port/comproto/comprotocontract"
 	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/clock/timecop"
 	"go.llib.dev/testcase/random"
 )
 
@@ -238,3 +241,174 @@ func TestConnectionAdapter_LookupTx(t *testing.T) {
 		assert.ErrorIs(t, ctx.Err(), subject.RollbackTx(ctx))
 	})
 }
+
+func TestConnectionAdapter_Replicas(t *testing.T) {
+	type DB struct {
+		Name string
+		Err  error
+	}
+	type TX struct{ DB *DB }
+
+	var (
+		primary  = &DB{Name: "primary"}
+		replica1 = &DB{Name: "replica-1"}
+		replica2 = &DB{Name: "replica-2"}
+	)
+	queryable := func(name string, err error) flsql.Queryable {
+		return flsql.QueryableAdapter{
+			QueryFunc: func(ctx context.Context, query string, args ...any) (flsql.Rows, error) {
+				if err != nil {
+					return nil, err
+				}
+				var done bool
+				return &mockRows{
+					StubNext: func() bool {
+						defer func() { done = true }()
+						return !done
+					},
+					StubScan: func(dest ...any) error {
+						*dest[0].(*string) = name
+						return nil
+					},
+				}, nil
+			},
+			QueryRowFunc: func(ctx context.Context, query string, args ...any) flsql.Row {
+				return &mockRow{StubScan: func(dest ...any) error {
+					if err != nil {
+						return err
+					}
+					*dest[0].(*string) = name
+					return nil
+				}}
+			},
+		}
+	}
+	makeSubject := func(replicas ...*DB) flsql.ConnectionAdapter[DB, TX] {
+		return flsql.ConnectionAdapter[DB, TX]{
+			DB: primary,
+			DBAdapter: func(db *DB) flsql.Queryable {
+				return queryable(db.Name, db.Err)
+			},
+			TxAdapter: func(tx *TX) flsql.Queryable {
+				return queryable("tx:"+tx.DB.Name, nil)
+			},
+			Begin: func(ctx context.Context, db *DB) (*TX, error) {
+				return &TX{DB: db}, nil
+			},
+			Commit:   func(ctx context.Context, tx *TX) error { return nil },
+			Rollback: func(ctx context.Context, tx *TX) error { return nil },
+			Replicas: &flsql.ReplicaSet[DB]{Replicas: replicas},
+		}
+	}
+	queryRow := func(tb testing.TB, subject flsql.ConnectionAdapter[DB, TX], ctx context.Context) string {
+		var name string
+		assert.NoError(tb, subject.QueryRowContext(ctx, "SELECT name").Scan(&name))
+		return name
+	}
+	query := func(tb testing.TB, subject flsql.ConnectionAdapter[DB, TX], ctx context.Context) string {
+		rows, err := subject.QueryContext(ctx, "SELECT name")
+		assert.NoError(tb, err)
+		var name string
+		assert.NoError(tb, rows.Scan(&name))
+		return name
+	}
+
+	t.Run("queries without the read-only mark go to the primary", func(t *testing.T) {
+		subject := makeSubject(replica1, replica2)
+		ctx := context.Background()
+		assert.Equal(t, "primary", queryRow(t, subject, ctx))
+		assert.Equal(t, "primary", query(t, subject, ctx))
+	})
+
+	t.Run("read-only queries are balanced between the replicas", func(t *testing.T) {
+		subject := makeSubject(replica1, replica2)
+		ctx := flsql.ReadOnly(context.Background())
+		got := map[string]struct{}{}
+		for range 4 {
+			got[queryRow(t, subject, ctx)] = struct{}{}
+			got[query(t, subject, ctx)] = struct{}{}
+		}
+		assert.Equal(t, map[string]struct{}{"replica-1": {}, "replica-2": {}}, got)
+	})
+
+	t.Run("read-only queries within a transaction are pinned to the primary", func(t *testing.T) {
+		subject := makeSubject(replica1, replica2)
+		ctx, err := subject.BeginTx(flsql.ReadOnly(context.Background()))
+		assert.NoError(t, err)
+		defer subject.RollbackTx(ctx)
+		assert.Equal(t, "tx:primary", queryRow(t, subject, ctx))
+		assert.Equal(t, "tx:primary", query(t, subject, ctx))
+	})
+
+	t.Run("an explicit false read-only mark pins the queries to the primary", func(t *testing.T) {
+		subject := makeSubject(replica1, replica2)
+		ctx := flsql.ReadOnly(flsql.ContextReadOnly.ContextWith(context.Background(), false))
+		assert.Equal(t, "primary", queryRow(t, subject, ctx))
+		assert.Equal(t, "primary", query(t, subject, ctx))
+	})
+
+	t.Run("QueryMany leaves the routing to the read-only mark of the context", func(t *testing.T) {
+		subject := makeSubject(replica1)
+		queryMany := func(ctx context.Context) []string {
+			vs, err := iterkit.CollectE(flsql.QueryMany(subject, ctx, func(s flsql.Scanner) (string, error) {
+				var name string
+				return name, s.Scan(&name)
+			}, "SELECT name"))
+			assert.NoError(t, err)
+			return vs
+		}
+		assert.Equal(t, []string{"primary"}, queryMany(context.Background()))
+		assert.Equal(t, []string{"replica-1"}, queryMany(flsql.ReadOnly(context.Background())))
+	})
+
+	t.Run("a failing replica fails over and is taken out from the routing", func(t *testing.T) {
+		broken := &DB{Name: "broken", Err: rnd.Error()}
+		subject := makeSubject(broken, replica1)
+		subject.Replicas.Cooldown = time.Hour
+		ctx := flsql.ReadOnly(context.Background())
+
+		for range 4 {
+			assert.Equal(t, "replica-1", queryRow(t, subject, ctx))
+		}
+		assert.Equal(t, 1, subject.Replicas.Healthy())
+
+		subject = makeSubject(broken, replica1)
+		for range 4 {
+			assert.Equal(t, "replica-1", query(t, subject, ctx))
+		}
+		assert.Equal(t, 1, subject.Replicas.Healthy())
+	})
+
+	t.Run("when no replica is healthy, the primary serves the read-only queries", func(t *testing.T) {
+		broken := &DB{Name: "broken", Err: rnd.Error()}
+		subject := makeSubject(broken)
+		ctx := flsql.ReadOnly(context.Background())
+		assert.Equal(t, "primary", queryRow(t, subject, ctx))
+		assert.Equal(t, "primary", query(t, subject, ctx))
+		assert.Equal(t, 0, subject.Replicas.Healthy())
+	})
+
+	t.Run("a replica returns to the routing after the cooldown", func(t *testing.T) {
+		broken := &DB{Name: "broken", Err: rnd.Error()}
+		subject := makeSubject(broken)
+		subject.Replicas.Cooldown = time.Minute
+		ctx := flsql.ReadOnly(context.Background())
+		assert.Equal(t, "primary", queryRow(t, subject, ctx))
+		assert.Equal(t, 0, subject.Replicas.Healthy())
+
+		timecop.Travel(t, time.Minute+time.Second)
+		assert.Equal(t, 1, subject.Replicas.Healthy())
+	})
+
+	t.Run("no rows is not a replica failure", func(t *testing.T) {
+		subject := makeSubject(replica1)
+		subject.DBAdapter = func(db *DB) flsql.Queryable {
+			return flsql.QueryableAdapter{QueryRowFunc: func(ctx context.Context, query string, args ...any) flsql.Row {
+				return &mockRow{StubScan: func(dest ...any) error { return sql.ErrNoRows }}
+			}}
+		}
+		ctx := flsql.ReadOnly(context.Background())
+		assert.ErrorIs(t, sql.ErrNoRows, subject.QueryRowContext(ctx, "SELECT name").Scan())
+		assert.Equal(t, 1, subject.Replicas.Healthy())
+	})
+}
@@ -0,0 +1,101 @@
+package flsql
+
+import (
+	"context"
+	"fmt"
+
+	"go.llib.dev/frameless/pkg/errorkit"
+	"go.llib.dev/frameless/pkg/logger"
+	"go.llib.dev/frameless/pkg/logging"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/crudquery"
+)
+
+// Count implements crud.Counter with a SELECT COUNT(*) query.
+func (r Repository[ENT, ID]) Count(ctx context.Context) (int, error) {
+	return r.CountQuery(ctx, crudquery.Query{})
+}
+
+// CountQuery implements crud.QueryCounter by counting the rows that match the query's Where expression.
+func (r Repository[ENT, ID]) CountQuery(ctx context.Context, q crudquery.Query) (int, error) {
+	query, args, err := r.selectWhere(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	query = fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS %s", query, r.Dialect.Quote("src"))
+
+	logger.Debug(ctx, "flsql.Repository#CountQuery", logging.Field("query", query))
+
+	var n int
+	if err := r.Connection.QueryRowContext(ctx, query, args...).Scan(&n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// CountGroups implements crud.GroupCounter with a GROUP BY query over the column that the field refers to.
+func (r Repository[ENT, ID]) CountGroups(ctx context.Context, field string, q crudquery.Query) (_ []crud.GroupCount, rErr error) {
+	cols, _ := r.Mapping.ToQuery(ctx)
+	col, ok := r.column(cols, field)
+	if !ok {
+		return nil, crudquery.ErrInvalidQuery.F("unknown field: %q", field)
+	}
+	query, args, err := r.selectWhere(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	group := r.Dialect.QuoteColumn(col)
+	query = fmt.Sprintf("SELECT %s, COUNT(*) FROM (%s) AS %s GROUP BY %s", group, query, r.Dialect.Quote("src"), group)
+
+	logger.Debug(ctx, "flsql.Repository#CountGroups", logging.Field("query", query))
+
+	rows, err := r.Connection.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer errorkit.Finish(&rErr, rows.Close)
+
+	var groups []crud.GroupCount
+	for rows.Next() {
+		var g crud.GroupCount
+		if err := rows.Scan(&g.Value, &g.Count); err != nil {
+			return nil, err
+		}
+		// some drivers yield the text columns as []byte
+		if bs, ok := g.Value.([]byte); ok {
+			g.Value = string(bs)
+		}
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
+
+// ExistsByID implements crud.Exister with a SELECT EXISTS query.
+func (r Repository[ENT, ID]) ExistsByID(ctx context.Context, id ID) (bool, error) {
+	query, args, err := Select(ctx, r.Mapping).Where(MatchID(r.Mapping, id)).Build(r.Dialect)
+	if err != nil {
+		return false, err
+	}
+	query = fmt.Sprintf("SELECT EXISTS (%s)", query)
+
+	logger.Debug(ctx, "flsql.Repository#ExistsByID", logging.Field("query", query))
+
+	var ok bool
+	if err := r.Connection.QueryRowContext(ctx, query, args...).Scan(&ok); err != nil {
+		return false, err
+	}
+	return ok, nil
+}
+
+// selectWhere builds the SELECT statement of the rows that match the query's Where expression.
+func (r Repository[ENT, ID]) selectWhere(ctx context.Context, q crudquery.Query) (string, []any, error) {
+	q = crudquery.Query{Where: q.Where}
+	if err := q.Validate(); err != nil {
+		return "", nil, err
+	}
+	sel := Select(ctx, r.Mapping)
+	if q.Where != nil {
+		sel = sel.Where(FromQuery(*q.Where))
+	}
+	return sel.Build(r.Dialect)
+}
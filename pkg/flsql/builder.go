@@ -0,0 +1,814 @@
+package flsql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/mapkit"
+	"go.llib.dev/frameless/pkg/slicekit"
+	"go.llib.dev/frameless/port/crud/crudquery"
+)
+
+// Dialect describes the syntax differences between SQL databases that the query builder has to know about.
+type Dialect struct {
+	// Placeholder returns the placeholder of the n-th positional argument, counting from 1.
+	Placeholder func(n int) string
+	// QuoteIdentifier quotes a single table or column name.
+	QuoteIdentifier func(name string) string
+	// Returning tells whether INSERT statements support the RETURNING clause.
+	// Without RETURNING, the generated values of a new row can't be read back in the same statement.
+	Returning bool
+	// UpdateFrom tells whether UPDATE statements support joining a row source with a FROM clause,
+	// and reading back the updated rows with a RETURNING clause.
+	// With UpdateFrom, the Repository updates multiple entities with a single statement.
+	UpdateFrom bool
+	// Upsert [optional] makes the clause of an INSERT statement
+	// that updates the columns of the row that conflicts with the inserted one.
+	// Without Upsert, the Dialect doesn't support upserts.
+	Upsert func(quote func(ColumnName) string, conflict, update []ColumnName) string
+}
+
+var (
+	// DialectPostgreSQL uses "$1" style placeholders and double-quoted identifiers.
+	DialectPostgreSQL = Dialect{
+		Placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+		QuoteIdentifier: func(name string) string {
+			return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+		},
+		Returning:  true,
+		UpdateFrom: true,
+		Upsert:     onConflictDoUpdate,
+	}
+	// DialectMySQL uses "?" placeholders and backtick-quoted identifiers, as MySQL and MariaDB do.
+	// MariaDB also supports the RETURNING clause, which MySQL doesn't.
+	DialectMySQL = Dialect{
+		Placeholder: func(int) string { return "?" },
+		QuoteIdentifier: func(name string) string {
+			return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+		},
+		Upsert: onDuplicateKeyUpdate,
+	}
+)
+
+func onConflictDoUpdate(quote func(ColumnName) string, conflict, update []ColumnName) string {
+	var sets []string
+	for _, col := range update {
+		sets = append(sets, fmt.Sprintf("%s = EXCLUDED.%s", quote(col), quote(col)))
+	}
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s",
+		strings.Join(slicekit.Map(conflict, quote), ", "),
+		strings.Join(sets, ", "))
+}
+
+func onDuplicateKeyUpdate(quote func(ColumnName) string, _, update []ColumnName) string {
+	var sets []string
+	for _, col := range update {
+		sets = append(sets, fmt.Sprintf("%s = VALUES(%s)", quote(col), quote(col)))
+	}
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+}
+
+// Quote quotes a possibly qualified identifier, such as "schema.table" or "table.column", part by part.
+func (d Dialect) Quote(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = d.QuoteIdentifier(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// QuoteColumn quotes a column name.
+// It can be used as the QuoteColumn of a QueryCompiler or as the quote function of Mapping.IDWhere.
+func (d Dialect) QuoteColumn(c ColumnName) string {
+	return d.Quote(string(c))
+}
+
+// Placeholders returns a generator that yields the placeholders of the positional arguments in order.
+// It can be used as the Placeholder of a QueryCompiler or as the placeholder function of Mapping.IDWhere.
+func (d Dialect) Placeholders() func() string {
+	var n int
+	return func() string {
+		n++
+		return d.Placeholder(n)
+	}
+}
+
+// Statement is an SQL statement that is built for a given Dialect.
+type Statement interface {
+	Build(d Dialect) (query string, args []any, err error)
+}
+
+// Exec builds the statement and executes it.
+func Exec(c Queryable, ctx context.Context, d Dialect, s Statement) (Result, error) {
+	query, args, err := s.Build(d)
+	if err != nil {
+		return nil, err
+	}
+	return c.ExecContext(ctx, query, args...)
+}
+
+// QuerySelect builds the SELECT statement and maps its rows with the scan function of its Mapping.
+func QuerySelect[ENT any](c Queryable, ctx context.Context, d Dialect, s SelectStatement[ENT]) iterkit.SeqE[ENT] {
+	query, args, err := s.Build(d)
+	if err != nil {
+		return iterkit.Error[ENT](err)
+	}
+	return QueryMany(c, ctx, s.scan.Map, query, args...)
+}
+
+// Expr is a boolean SQL expression, such as a condition of a WHERE clause or of a JOIN.
+//
+// An unqualified column in an Expr must be one of the columns of the statement's Mapping,
+// while a qualified column, such as "other_table.column", can refer to the columns of the joined tables.
+type Expr interface {
+	writeExpr(b *builder) error
+}
+
+// Eq matches the rows where the column equals to the value.
+// A nil value yields an IS NULL condition.
+func Eq(col ColumnName, value any) Expr { return comparison{Column: col, Op: "=", Value: value} }
+
+// Ne matches the rows where the column doesn't equal to the value.
+// A nil value yields an IS NOT NULL condition.
+func Ne(col ColumnName, value any) Expr { return comparison{Column: col, Op: "<>", Value: value} }
+
+// Lt matches the rows where the column is less than the value.
+func Lt(col ColumnName, value any) Expr { return comparison{Column: col, Op: "<", Value: value} }
+
+// Lte matches the rows where the column is less than or equal to the value.
+func Lte(col ColumnName, value any) Expr { return comparison{Column: col, Op: "<=", Value: value} }
+
+// Gt matches the rows where the column is greater than the value.
+func Gt(col ColumnName, value any) Expr { return comparison{Column: col, Op: ">", Value: value} }
+
+// Gte matches the rows where the column is greater than or equal to the value.
+func Gte(col ColumnName, value any) Expr { return comparison{Column: col, Op: ">=", Value: value} }
+
+// In matches the rows where the column equals to any of the values.
+// Without values, In matches no row.
+func In(col ColumnName, values ...any) Expr { return in{Column: col, Values: values} }
+
+// EqColumn matches the rows where the two columns are equal, which is the usual condition of a JOIN.
+func EqColumn(left, right ColumnName) Expr {
+	return columnComparison{Left: left, Op: "=", Right: right}
+}
+
+// IsNull matches the rows where the column is NULL.
+func IsNull(col ColumnName) Expr { return null{Column: col} }
+
+// IsNotNull matches the rows where the column is not NULL.
+func IsNotNull(col ColumnName) Expr { return null{Column: col, Not: true} }
+
+// And matches the rows where all the expressions match.
+// Without expressions, And matches every row.
+func And(exprs ...Expr) Expr { return logical{Op: "AND", Exprs: exprs} }
+
+// Or matches the rows where any of the expressions match.
+// Without expressions, Or matches no row.
+func Or(exprs ...Expr) Expr { return logical{Op: "OR", Exprs: exprs} }
+
+// Not negates the expression.
+func Not(expr Expr) Expr { return not{Expr: expr} }
+
+// Raw is an expression written in SQL, where each "?" stands for the next argument,
+// and it is replaced with the placeholder of the Dialect.
+// The columns of a Raw expression are not validated, nor quoted.
+func Raw(sql string, args ...any) Expr { return raw{SQL: sql, Args: args} }
+
+// FromQuery is an expression made from a crudquery.Expr,
+// where the query fields are resolved against the columns of the statement's Mapping.
+func FromQuery(expr crudquery.Expr) Expr { return fromQuery{Expr: expr} }
+
+// MatchID matches the row of the ID, using the QueryID of the Mapping.
+// A composite ID yields a condition for each of its columns.
+func MatchID[ENT, ID any](m Mapping[ENT, ID], id ID) Expr {
+	idArgs, err := m.QueryID(id)
+	if err != nil {
+		return errExpr{Err: err}
+	}
+	cols, args := SplitArgs(idArgs)
+	if len(cols) == 0 {
+		return errExpr{Err: fmt.Errorf("QueryID returned no column for the %T id", id)}
+	}
+	var exprs []Expr
+	for i, col := range cols {
+		exprs = append(exprs, Eq(col, args[i]))
+	}
+	return And(exprs...)
+}
+
+// MatchIDs matches the rows of the IDs, using the QueryID of the Mapping.
+// Single column IDs are matched with an IN list, while composite IDs with a condition for each ID.
+// Without IDs, MatchIDs matches no row.
+func MatchIDs[ENT, ID any](m Mapping[ENT, ID], ids ...ID) Expr {
+	if len(ids) == 0 {
+		return Or()
+	}
+	var (
+		exprs  []Expr
+		col    ColumnName
+		values []any
+	)
+	for _, id := range ids {
+		idArgs, err := m.QueryID(id)
+		if err != nil {
+			return errExpr{Err: err}
+		}
+		if len(idArgs) == 1 {
+			cols, args := SplitArgs(idArgs)
+			if col == "" || col == cols[0] {
+				col = cols[0]
+				values = append(values, args[0])
+				continue
+			}
+		}
+		exprs = append(exprs, MatchID(m, id))
+	}
+	if len(exprs) == 0 {
+		return In(col, values...)
+	}
+	if 0 < len(values) {
+		exprs = append(exprs, In(col, values...))
+	}
+	return Or(exprs...)
+}
+
+type comparison struct {
+	Column ColumnName
+	Op     string
+	Value  any
+}
+
+func (e comparison) writeExpr(b *builder) error {
+	col, err := b.column(e.Column)
+	if err != nil {
+		return err
+	}
+	if e.Value == nil {
+		switch e.Op {
+		case "=":
+			return null{Column: e.Column}.writeExpr(b)
+		case "<>":
+			return null{Column: e.Column, Not: true}.writeExpr(b)
+		}
+	}
+	b.write(col, " ", e.Op, " ")
+	b.arg(e.Value)
+	return nil
+}
+
+type columnComparison struct {
+	Left  ColumnName
+	Op    string
+	Right ColumnName
+}
+
+func (e columnComparison) writeExpr(b *builder) error {
+	left, err := b.column(e.Left)
+	if err != nil {
+		return err
+	}
+	right, err := b.column(e.Right)
+	if err != nil {
+		return err
+	}
+	b.write(left, " ", e.Op, " ", right)
+	return nil
+}
+
+type in struct {
+	Column ColumnName
+	Values []any
+}
+
+func (e in) writeExpr(b *builder) error {
+	col, err := b.column(e.Column)
+	if err != nil {
+		return err
+	}
+	if len(e.Values) == 0 {
+		b.write("1 = 0")
+		return nil
+	}
+	b.write(col, " IN (")
+	for i, v := range e.Values {
+		if 0 < i {
+			b.write(", ")
+		}
+		b.arg(v)
+	}
+	b.write(")")
+	return nil
+}
+
+type null struct {
+	Column ColumnName
+	Not    bool
+}
+
+func (e null) writeExpr(b *builder) error {
+	col, err := b.column(e.Column)
+	if err != nil {
+		return err
+	}
+	if e.Not {
+		b.write(col, " IS NOT NULL")
+	} else {
+		b.write(col, " IS NULL")
+	}
+	return nil
+}
+
+type logical struct {
+	Op    string
+	Exprs []Expr
+}
+
+func (e logical) writeExpr(b *builder) error {
+	if len(e.Exprs) == 0 {
+		if e.Op == "AND" {
+			b.write("1 = 1")
+		} else {
+			b.write("1 = 0")
+		}
+		return nil
+	}
+	if len(e.Exprs) == 1 {
+		return e.Exprs[0].writeExpr(b)
+	}
+	for i, expr := range e.Exprs {
+		if 0 < i {
+			b.write(" ", e.Op, " ")
+		}
+		b.write("(")
+		if err := expr.writeExpr(b); err != nil {
+			return err
+		}
+		b.write(")")
+	}
+	return nil
+}
+
+type not struct{ Expr Expr }
+
+func (e not) writeExpr(b *builder) error {
+	b.write("NOT (")
+	if err := e.Expr.writeExpr(b); err != nil {
+		return err
+	}
+	b.write(")")
+	return nil
+}
+
+type raw struct {
+	SQL  string
+	Args []any
+}
+
+func (e raw) writeExpr(b *builder) error {
+	if n := strings.Count(e.SQL, "?"); n != len(e.Args) {
+		return fmt.Errorf("raw expression has %d placeholders, but %d arguments: %s", n, len(e.Args), e.SQL)
+	}
+	parts := strings.Split(e.SQL, "?")
+	for i, part := range parts {
+		b.write(part)
+		if i < len(e.Args) {
+			b.arg(e.Args[i])
+		}
+	}
+	return nil
+}
+
+type fromQuery struct{ Expr crudquery.Expr }
+
+func (e fromQuery) writeExpr(b *builder) error {
+	qc := QueryCompiler{
+		Columns: b.columns,
+		QuoteColumn: func(c ColumnName) string {
+			quoted, _ := b.column(c) // the QueryCompiler only passes known columns
+			return quoted
+		},
+		Placeholder: func() string {
+			// the arguments are registered after the compilation,
+			// thus the placeholders are counted ahead of them.
+			b.pending++
+			return b.dialect.Placeholder(len(b.args) + b.pending)
+		},
+	}
+	where, args, err := qc.CompileExpr(e.Expr)
+	b.pending = 0
+	if err != nil {
+		return err
+	}
+	b.write(where)
+	b.args = append(b.args, args...)
+	return nil
+}
+
+type errExpr struct{ Err error }
+
+func (e errExpr) writeExpr(*builder) error { return e.Err }
+
+type builder struct {
+	dialect Dialect
+	columns []ColumnName
+	// qualifier is the table name that qualifies the known columns.
+	qualifier string
+	sql       strings.Builder
+	args      []any
+	pending   int
+}
+
+func newBuilder(d Dialect, columns []ColumnName) (*builder, error) {
+	if d.Placeholder == nil || d.QuoteIdentifier == nil {
+		return nil, fmt.Errorf("flsql.Dialect is missing the Placeholder or the QuoteIdentifier function")
+	}
+	return &builder{dialect: d, columns: columns}, nil
+}
+
+func (b *builder) write(ss ...string) {
+	for _, s := range ss {
+		b.sql.WriteString(s)
+	}
+}
+
+func (b *builder) arg(v any) {
+	b.args = append(b.args, v)
+	b.sql.WriteString(b.dialect.Placeholder(len(b.args)))
+}
+
+// column quotes the column after it checked that the column is known.
+// Qualified columns are left unchecked, as they refer to joined tables.
+func (b *builder) column(c ColumnName) (string, error) {
+	if strings.Contains(string(c), ".") {
+		return b.dialect.QuoteColumn(c), nil
+	}
+	if !slices.Contains(b.columns, c) {
+		return "", fmt.Errorf("unknown column: %q", c)
+	}
+	if b.qualifier != "" {
+		// with joins, the columns of the table are qualified to avoid ambiguity.
+		c = ColumnName(b.qualifier + "." + string(c))
+	}
+	return b.dialect.QuoteColumn(c), nil
+}
+
+func (b *builder) where(exprs []Expr) error {
+	if len(exprs) == 0 {
+		return nil
+	}
+	b.write(" WHERE ")
+	return And(exprs...).writeExpr(b)
+}
+
+// Select starts a SELECT statement of the Mapping's table, which selects the columns of Mapping.ToQuery.
+// When the Mapping uses soft deletion, the soft deleted rows are filtered out.
+func Select[ENT, ID any](ctx context.Context, m Mapping[ENT, ID]) SelectStatement[ENT] {
+	cols, scan := m.ToQuery(ctx)
+	s := SelectStatement[ENT]{
+		table:   m.TableName,
+		columns: cols,
+		scan:    scan,
+		known:   knownColumns(m, cols),
+	}
+	if m.IsSoftDeletable() {
+		s.where = append(s.where, IsNull(m.DeletedAtColumn))
+	}
+	return s
+}
+
+func knownColumns[ENT, ID any](m Mapping[ENT, ID], cols []ColumnName) []ColumnName {
+	known := slices.Clone(cols)
+	for _, col := range []ColumnName{m.VersionColumn, m.DeletedAtColumn} {
+		if col != "" && !slices.Contains(known, col) {
+			known = append(known, col)
+		}
+	}
+	return known
+}
+
+// SelectStatement is a SELECT statement of a Mapping's table.
+// Its methods return an extended copy of the statement.
+type SelectStatement[ENT any] struct {
+	table   string
+	columns []ColumnName
+	scan    MapScan[ENT]
+	known   []ColumnName
+	joins   []join
+	where   []Expr
+	orderBy []order
+	limit   int
+	offset  int
+}
+
+type join struct {
+	Kind  string
+	Table string
+	On    Expr
+}
+
+type order struct {
+	Column ColumnName
+	Desc   bool
+}
+
+// Join adds an INNER JOIN of the table on the condition.
+// The columns of the joined table must be qualified with its name in the expressions.
+func (s SelectStatement[ENT]) Join(table string, on Expr) SelectStatement[ENT] {
+	s.joins = append(slices.Clip(s.joins), join{Kind: "JOIN", Table: table, On: on})
+	return s
+}
+
+// LeftJoin adds a LEFT JOIN of the table on the condition.
+// The columns of the joined table must be qualified with its name in the expressions.
+func (s SelectStatement[ENT]) LeftJoin(table string, on Expr) SelectStatement[ENT] {
+	s.joins = append(slices.Clip(s.joins), join{Kind: "LEFT JOIN", Table: table, On: on})
+	return s
+}
+
+// Where adds conditions to the statement, which all must match.
+func (s SelectStatement[ENT]) Where(exprs ...Expr) SelectStatement[ENT] {
+	s.where = append(slices.Clip(s.where), exprs...)
+	return s
+}
+
+// OrderBy adds an ordering term to the statement.
+func (s SelectStatement[ENT]) OrderBy(col ColumnName, desc bool) SelectStatement[ENT] {
+	s.orderBy = append(slices.Clip(s.orderBy), order{Column: col, Desc: desc})
+	return s
+}
+
+// Limit sets the maximum number of rows to return.
+func (s SelectStatement[ENT]) Limit(n int) SelectStatement[ENT] {
+	s.limit = n
+	return s
+}
+
+// Offset sets the number of rows to skip.
+func (s SelectStatement[ENT]) Offset(n int) SelectStatement[ENT] {
+	s.offset = n
+	return s
+}
+
+func (s SelectStatement[ENT]) Build(d Dialect) (string, []any, error) {
+	b, err := newBuilder(d, s.known)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(s.columns) == 0 {
+		return "", nil, fmt.Errorf("the select statement of %s has no column", s.table)
+	}
+	if 0 < len(s.joins) {
+		b.qualifier = s.table
+	}
+	b.write("SELECT ")
+	for i, col := range s.columns {
+		if 0 < i {
+			b.write(", ")
+		}
+		quoted, err := b.column(col)
+		if err != nil {
+			return "", nil, err
+		}
+		b.write(quoted)
+	}
+	b.write(" FROM ", d.Quote(s.table))
+	for _, j := range s.joins {
+		b.write(" ", j.Kind, " ", d.Quote(j.Table), " ON ")
+		if err := j.On.writeExpr(b); err != nil {
+			return "", nil, err
+		}
+	}
+	if err := b.where(s.where); err != nil {
+		return "", nil, err
+	}
+	for i, o := range s.orderBy {
+		if i == 0 {
+			b.write(" ORDER BY ")
+		} else {
+			b.write(", ")
+		}
+		col, err := b.column(o.Column)
+		if err != nil {
+			return "", nil, err
+		}
+		b.write(col)
+		if o.Desc {
+			b.write(" DESC")
+		} else {
+			b.write(" ASC")
+		}
+	}
+	switch {
+	case 0 < s.limit:
+		b.write(" LIMIT ", strconv.Itoa(s.limit))
+	case 0 < s.offset:
+		// not every SQL database supports an OFFSET without a LIMIT
+		b.write(" LIMIT ", strconv.FormatInt(math.MaxInt64, 10))
+	}
+	if 0 < s.offset {
+		b.write(" OFFSET ", strconv.Itoa(s.offset))
+	}
+	return b.sql.String(), b.args, nil
+}
+
+// Insert makes an INSERT statement of the entity into the Mapping's table, with the values of Mapping.ToArgs.
+func Insert[ENT, ID any](m Mapping[ENT, ID], v ENT) InsertStatement {
+	args, err := m.ToArgs(v)
+	return InsertStatement{table: m.TableName, values: args, err: err}
+}
+
+// InsertStatement is an INSERT statement of a single row.
+type InsertStatement struct {
+	table     string
+	values    QueryArgs
+	conflict  []ColumnName
+	returning []ColumnName
+	err       error
+}
+
+// OnConflictUpdate turns the statement into an upsert,
+// which updates every inserted column of the row that conflicts on the given unique columns.
+// It requires a Dialect with Upsert support.
+func (s InsertStatement) OnConflictUpdate(conflict ...ColumnName) InsertStatement {
+	s.conflict = conflict
+	return s
+}
+
+// Returning sets the columns that the statement returns from the inserted row.
+// RETURNING is supported by PostgreSQL, SQLite and MariaDB, but not by MySQL.
+func (s InsertStatement) Returning(cols ...ColumnName) InsertStatement {
+	s.returning = cols
+	return s
+}
+
+func (s InsertStatement) Build(d Dialect) (string, []any, error) {
+	if s.err != nil {
+		return "", nil, s.err
+	}
+	b, err := newBuilder(d, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	cols, args := SplitArgs(s.values)
+	if len(cols) == 0 {
+		return "", nil, fmt.Errorf("the insert statement of %s has no value", s.table)
+	}
+	b.write("INSERT INTO ", d.Quote(s.table), " (")
+	for i, col := range cols {
+		if 0 < i {
+			b.write(", ")
+		}
+		b.write(d.QuoteColumn(col))
+	}
+	b.write(") VALUES (")
+	for i, arg := range args {
+		if 0 < i {
+			b.write(", ")
+		}
+		b.arg(arg)
+	}
+	b.write(")")
+	if 0 < len(s.conflict) {
+		if d.Upsert == nil {
+			return "", nil, fmt.Errorf("the dialect doesn't support upserts")
+		}
+		b.write(" ", d.Upsert(d.QuoteColumn, s.conflict, cols))
+	}
+	writeReturning(b, s.returning)
+	return b.sql.String(), b.args, nil
+}
+
+func writeReturning(b *builder, cols []ColumnName) {
+	if len(cols) == 0 {
+		return
+	}
+	b.write(" RETURNING ")
+	for i, col := range cols {
+		if 0 < i {
+			b.write(", ")
+		}
+		b.write(b.dialect.QuoteColumn(col))
+	}
+}
+
+// Update makes an UPDATE statement of the Mapping's table, which sets the columns to the values of Mapping.ToArgs.
+// The rows to update are selected with Where, usually with MatchID.
+func Update[ENT, ID any](m Mapping[ENT, ID], v ENT) UpdateStatement {
+	args, err := m.ToArgs(v)
+	return UpdateStatement{table: m.TableName, values: args, known: knownColumns(m, mapkit.Keys(args, slices.Sort)), err: err}
+}
+
+// UpdateStatement is an UPDATE statement of a Mapping's table.
+// Its methods return an extended copy of the statement.
+type UpdateStatement struct {
+	table     string
+	values    QueryArgs
+	known     []ColumnName
+	where     []Expr
+	returning []ColumnName
+	err       error
+}
+
+// Set overrides the value of a column, or adds a column to the updated ones.
+func (s UpdateStatement) Set(col ColumnName, value any) UpdateStatement {
+	values := make(QueryArgs, len(s.values)+1)
+	for k, v := range s.values {
+		values[k] = v
+	}
+	values[col] = value
+	s.values = values
+	if !slices.Contains(s.known, col) {
+		s.known = append(slices.Clip(s.known), col)
+	}
+	return s
+}
+
+// Where adds conditions to the statement, which all must match.
+func (s UpdateStatement) Where(exprs ...Expr) UpdateStatement {
+	s.where = append(slices.Clip(s.where), exprs...)
+	return s
+}
+
+// Returning sets the columns that the statement returns from the updated rows.
+// RETURNING in UPDATE statements is supported by PostgreSQL and SQLite, but not by MySQL and MariaDB.
+func (s UpdateStatement) Returning(cols ...ColumnName) UpdateStatement {
+	s.returning = cols
+	return s
+}
+
+func (s UpdateStatement) Build(d Dialect) (string, []any, error) {
+	if s.err != nil {
+		return "", nil, s.err
+	}
+	b, err := newBuilder(d, s.known)
+	if err != nil {
+		return "", nil, err
+	}
+	cols, args := SplitArgs(s.values)
+	if len(cols) == 0 {
+		return "", nil, fmt.Errorf("the update statement of %s has no value", s.table)
+	}
+	b.write("UPDATE ", d.Quote(s.table), " SET ")
+	for i, col := range cols {
+		if 0 < i {
+			b.write(", ")
+		}
+		b.write(d.QuoteColumn(col), " = ")
+		b.arg(args[i])
+	}
+	if err := b.where(s.where); err != nil {
+		return "", nil, err
+	}
+	writeReturning(b, s.returning)
+	return b.sql.String(), b.args, nil
+}
+
+// Delete makes a DELETE statement of the Mapping's table.
+// The rows to delete are selected with Where, usually with MatchID.
+//
+// Delete removes the rows, even when the Mapping uses soft deletion.
+func Delete[ENT, ID any](ctx context.Context, m Mapping[ENT, ID]) DeleteStatement {
+	cols, _ := m.ToQuery(ctx)
+	return DeleteStatement{table: m.TableName, known: knownColumns(m, cols)}
+}
+
+// DeleteStatement is a DELETE statement of a Mapping's table.
+// Its methods return an extended copy of the statement.
+type DeleteStatement struct {
+	table     string
+	known     []ColumnName
+	where     []Expr
+	returning []ColumnName
+}
+
+// Where adds conditions to the statement, which all must match.
+func (s DeleteStatement) Where(exprs ...Expr) DeleteStatement {
+	s.where = append(slices.Clip(s.where), exprs...)
+	return s
+}
+
+// Returning sets the columns that the statement returns from the deleted rows.
+// RETURNING in DELETE statements is supported by PostgreSQL, SQLite and MariaDB, but not by MySQL.
+func (s DeleteStatement) Returning(cols ...ColumnName) DeleteStatement {
+	s.returning = cols
+	return s
+}
+
+func (s DeleteStatement) Build(d Dialect) (string, []any, error) {
+	b, err := newBuilder(d, s.known)
+	if err != nil {
+		return "", nil, err
+	}
+	b.write("DELETE FROM ", d.Quote(s.table))
+	if err := b.where(s.where); err != nil {
+		return "", nil, err
+	}
+	writeReturning(b, s.returning)
+	return b.sql.String(), b.args, nil
+}
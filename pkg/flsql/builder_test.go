@@ -0,0 +1,212 @@
+package flsql_test
+
+import (
+	"context"
+	"testing"
+
+	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/port/crud/crudquery"
+	"go.llib.dev/frameless/testing/testent"
+	"go.llib.dev/testcase/assert"
+)
+
+var fooMapping = flsql.Mapping[testent.Foo, testent.FooID]{
+	TableName: "foos",
+	ToQuery: func(ctx context.Context) ([]flsql.ColumnName, flsql.MapScan[testent.Foo]) {
+		return []flsql.ColumnName{"id", "foo", "bar", "baz"},
+			func(v *testent.Foo, s flsql.Scanner) error {
+				return s.Scan(&v.ID, &v.Foo, &v.Bar, &v.Baz)
+			}
+	},
+	QueryID: func(id testent.FooID) (flsql.QueryArgs, error) {
+		return flsql.QueryArgs{"id": id}, nil
+	},
+	ToArgs: func(foo testent.Foo) (flsql.QueryArgs, error) {
+		return flsql.QueryArgs{"id": foo.ID, "foo": foo.Foo, "bar": foo.Bar, "baz": foo.Baz}, nil
+	},
+}
+
+func ExampleSelect() {
+	var (
+		ctx  = context.Background()
+		conn flsql.Connection
+	)
+	sel := flsql.Select(ctx, fooMapping).
+		Where(flsql.Eq("foo", "a"), flsql.In("bar", "x", "y")).
+		OrderBy("baz", true).
+		Limit(10)
+
+	for foo, err := range flsql.QuerySelect(conn, ctx, flsql.DialectPostgreSQL, sel) {
+		_, _ = foo, err
+	}
+}
+
+func TestSelect(t *testing.T) {
+	ctx := context.Background()
+	sel := flsql.Select(ctx, fooMapping).
+		Where(flsql.Eq("foo", "a"), flsql.Or(flsql.Gt("bar", "x"), flsql.IsNull("baz"))).
+		OrderBy("baz", true).
+		OrderBy("id", false).
+		Limit(10).
+		Offset(20)
+
+	query, args, err := sel.Build(flsql.DialectPostgreSQL)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT "id", "foo", "bar", "baz" FROM "foos" WHERE ("foo" = $1) AND (("bar" > $2) OR ("baz" IS NULL)) ORDER BY "baz" DESC, "id" ASC LIMIT 10 OFFSET 20`, query)
+	assert.Equal(t, []any{"a", "x"}, args)
+
+	query, args, err = sel.Build(flsql.DialectMySQL)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT `id`, `foo`, `bar`, `baz` FROM `foos` WHERE (`foo` = ?) AND ((`bar` > ?) OR (`baz` IS NULL)) ORDER BY `baz` DESC, `id` ASC LIMIT 10 OFFSET 20", query)
+	assert.Equal(t, []any{"a", "x"}, args)
+
+	t.Run("statements are immutable", func(t *testing.T) {
+		base := flsql.Select(ctx, fooMapping).Where(flsql.Eq("foo", "a"))
+		_ = base.Where(flsql.Eq("bar", "b"))
+		query, _, err := base.Build(flsql.DialectPostgreSQL)
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT "id", "foo", "bar", "baz" FROM "foos" WHERE "foo" = $1`, query)
+	})
+
+	t.Run("unknown columns are rejected", func(t *testing.T) {
+		_, _, err := flsql.Select(ctx, fooMapping).Where(flsql.Eq("qux; DROP TABLE foos", 1)).Build(flsql.DialectPostgreSQL)
+		assert.Error(t, err)
+		_, _, err = flsql.Select(ctx, fooMapping).OrderBy("qux", false).Build(flsql.DialectPostgreSQL)
+		assert.Error(t, err)
+	})
+
+	t.Run("offset without limit", func(t *testing.T) {
+		query, _, err := flsql.Select(ctx, fooMapping).Offset(1).Build(flsql.DialectPostgreSQL)
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT "id", "foo", "bar", "baz" FROM "foos" LIMIT 9223372036854775807 OFFSET 1`, query)
+	})
+
+	t.Run("soft deleted rows are filtered out", func(t *testing.T) {
+		m := fooMapping
+		m.DeletedAtColumn = "deleted_at"
+		query, _, err := flsql.Select(ctx, m).Where(flsql.MatchID(m, "foo-1")).Build(flsql.DialectPostgreSQL)
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT "id", "foo", "bar", "baz" FROM "foos" WHERE ("deleted_at" IS NULL) AND ("id" = $1)`, query)
+	})
+
+	t.Run("join", func(t *testing.T) {
+		query, args, err := flsql.Select(ctx, fooMapping).
+			Join("bars", flsql.EqColumn("bar", "bars.id")).
+			Where(flsql.Eq("bars.name", "x"), flsql.Ne("foo", nil)).
+			Build(flsql.DialectPostgreSQL)
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT "foos"."id", "foos"."foo", "foos"."bar", "foos"."baz" FROM "foos" JOIN "bars" ON "foos"."bar" = "bars"."id" WHERE ("bars"."name" = $1) AND ("foos"."foo" IS NOT NULL)`, query)
+		assert.Equal(t, []any{"x"}, args)
+	})
+
+	t.Run("raw and crudquery expressions", func(t *testing.T) {
+		query, args, err := flsql.Select(ctx, fooMapping).
+			Where(flsql.Raw("length(bar) > ?", 3), flsql.FromQuery(crudquery.In("baz", "a", "b")), flsql.Eq("foo", "c")).
+			Build(flsql.DialectPostgreSQL)
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT "id", "foo", "bar", "baz" FROM "foos" WHERE (length(bar) > $1) AND ("baz" IN ($2, $3)) AND ("foo" = $4)`, query)
+		assert.Equal(t, []any{3, "a", "b", "c"}, args)
+
+		_, _, err = flsql.Select(ctx, fooMapping).Where(flsql.Raw("foo = ?")).Build(flsql.DialectPostgreSQL)
+		assert.Error(t, err)
+	})
+}
+
+func TestQuerySelect(t *testing.T) {
+	ctx := context.Background()
+	q := flsql.QueryableAdapter{
+		QueryFunc: func(ctx context.Context, query string, args ...any) (flsql.Rows, error) {
+			assert.Equal(t, "SELECT `id`, `foo`, `bar`, `baz` FROM `foos` WHERE `id` IN (?, ?)", query)
+			assert.Equal(t, []any{"1", "2"}, args)
+			var n int
+			return &mockRows{
+				StubNext: func() bool { n++; return n <= 2 },
+				StubScan: func(dest ...any) error {
+					*dest[0].(*testent.FooID) = testent.FooID(rune('0' + n))
+					return nil
+				},
+			}, nil
+		},
+	}
+	sel := flsql.Select(ctx, fooMapping).Where(flsql.In("id", "1", "2"))
+	vs, err := iterkit.CollectE(flsql.QuerySelect(q, ctx, flsql.DialectMySQL, sel))
+	assert.NoError(t, err)
+	assert.Equal(t, []testent.Foo{{ID: "1"}, {ID: "2"}}, vs)
+}
+
+func TestInsert(t *testing.T) {
+	query, args, err := flsql.Insert(fooMapping, testent.Foo{ID: "1", Foo: "a", Bar: "b", Baz: "c"}).
+		Returning("id").
+		Build(flsql.DialectPostgreSQL)
+	assert.NoError(t, err)
+	assert.Equal(t, `INSERT INTO "foos" ("bar", "baz", "foo", "id") VALUES ($1, $2, $3, $4) RETURNING "id"`, query)
+	assert.Equal(t, []any{"b", "c", "a", testent.FooID("1")}, args)
+}
+
+func TestInsert_onConflictUpdate(t *testing.T) {
+	foo := testent.Foo{ID: "1", Foo: "a"}
+	query, _, err := flsql.Insert(fooMapping, foo).OnConflictUpdate("id").Build(flsql.DialectPostgreSQL)
+	assert.NoError(t, err)
+	assert.Equal(t, `INSERT INTO "foos" ("bar", "baz", "foo", "id") VALUES ($1, $2, $3, $4) `+
+		`ON CONFLICT ("id") DO UPDATE SET "bar" = EXCLUDED."bar", "baz" = EXCLUDED."baz", "foo" = EXCLUDED."foo", "id" = EXCLUDED."id"`, query)
+
+	query, _, err = flsql.Insert(fooMapping, foo).OnConflictUpdate("id").Build(flsql.DialectMySQL)
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO `foos` (`bar`, `baz`, `foo`, `id`) VALUES (?, ?, ?, ?) "+
+		"ON DUPLICATE KEY UPDATE `bar` = VALUES(`bar`), `baz` = VALUES(`baz`), `foo` = VALUES(`foo`), `id` = VALUES(`id`)", query)
+
+	_, _, err = flsql.Insert(fooMapping, foo).OnConflictUpdate("id").Build(flsql.Dialect{
+		Placeholder:     flsql.DialectPostgreSQL.Placeholder,
+		QuoteIdentifier: flsql.DialectPostgreSQL.QuoteIdentifier,
+	})
+	assert.Error(t, err)
+}
+
+func TestMatchIDs(t *testing.T) {
+	ctx := context.Background()
+	query, args, err := flsql.Select(ctx, fooMapping).Where(flsql.MatchIDs(fooMapping, "1", "2")).Build(flsql.DialectPostgreSQL)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT "id", "foo", "bar", "baz" FROM "foos" WHERE "id" IN ($1, $2)`, query)
+	assert.Equal(t, []any{testent.FooID("1"), testent.FooID("2")}, args)
+}
+
+func TestUpdate(t *testing.T) {
+	foo := testent.Foo{ID: "1", Foo: "a", Bar: "b", Baz: "c"}
+	query, args, err := flsql.Update(fooMapping, foo).
+		Set("baz", "z").
+		Where(flsql.MatchID(fooMapping, foo.ID)).
+		Build(flsql.DialectMySQL)
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE `foos` SET `bar` = ?, `baz` = ?, `foo` = ?, `id` = ? WHERE `id` = ?", query)
+	assert.Equal(t, []any{"b", "z", "a", testent.FooID("1"), testent.FooID("1")}, args)
+
+	query, _, err = flsql.Update(fooMapping, foo).
+		Where(flsql.MatchID(fooMapping, foo.ID)).
+		Returning("id", "foo").
+		Build(flsql.DialectPostgreSQL)
+	assert.NoError(t, err)
+	assert.Equal(t, `UPDATE "foos" SET "bar" = $1, "baz" = $2, "foo" = $3, "id" = $4 WHERE "id" = $5 RETURNING "id", "foo"`, query)
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	var got []string
+	q := flsql.QueryableAdapter{
+		ExecFunc: func(ctx context.Context, query string, args ...any) (flsql.Result, error) {
+			got = append(got, query)
+			assert.Equal(t, []any{"a", "b"}, args)
+			return &mockResult{StubRowsAffected: 1}, nil
+		},
+	}
+	_, err := flsql.Exec(q, ctx, flsql.DialectPostgreSQL, flsql.Delete(ctx, fooMapping).Where(flsql.In("foo", "a", "b")))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`DELETE FROM "foos" WHERE "foo" IN ($1, $2)`}, got)
+
+	_, err = flsql.Exec(q, ctx, flsql.DialectPostgreSQL, flsql.Delete(ctx, fooMapping).Where(flsql.Eq("qux", 1)))
+	assert.Error(t, err)
+
+	query, _, err := flsql.Delete(ctx, fooMapping).Where(flsql.In("foo", "a", "b")).Returning("id").Build(flsql.DialectPostgreSQL)
+	assert.NoError(t, err)
+	assert.Equal(t, `DELETE FROM "foos" WHERE "foo" IN ($1, $2) RETURNING "id"`, query)
+}
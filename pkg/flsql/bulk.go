@@ -0,0 +1,633 @@
+package flsql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.llib.dev/frameless/pkg/contextkit"
+	"go.llib.dev/frameless/pkg/errorkit"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/logger"
+	"go.llib.dev/frameless/pkg/logging"
+	"go.llib.dev/frameless/pkg/mapkit"
+	"go.llib.dev/frameless/pkg/slicekit"
+	"go.llib.dev/frameless/pkg/zerokit"
+	"go.llib.dev/frameless/port/comproto"
+	"go.llib.dev/frameless/port/crud"
+)
+
+// MultiRow groups the query arguments of multiple entities under the same columns,
+// so they can be inserted with a single multi-row statement.
+// A column that is missing from an entity's arguments is filled with NULL.
+type MultiRow struct {
+	Columns []ColumnName
+	Values  [][]any
+}
+
+// MakeMultiRow makes a MultiRow from the query arguments of the entities, see Mapping.ToArgs.
+func MakeMultiRow(argss ...QueryArgs) MultiRow {
+	var mr MultiRow
+	for _, args := range argss {
+		mr.Columns = slicekit.Unique(append(mr.Columns, mapkit.Keys(args)...))
+	}
+	slices.Sort(mr.Columns)
+	for _, args := range argss {
+		var row = make([]any, len(mr.Columns))
+		for i, col := range mr.Columns {
+			row[i] = args[col]
+		}
+		mr.Values = append(mr.Values, row)
+	}
+	return mr
+}
+
+// ValuesClause returns the row value lists of a VALUES clause, and the query arguments in the same order.
+func (mr MultiRow) ValuesClause(placeholder func() string) (string, []any) {
+	var (
+		rows []string
+		args []any
+	)
+	for _, values := range mr.Values {
+		var phs = make([]string, len(values))
+		for i := range values {
+			phs[i] = placeholder()
+		}
+		rows = append(rows, fmt.Sprintf("(%s)", strings.Join(phs, ", ")))
+		args = append(args, values...)
+	}
+	return strings.Join(rows, ", "), args
+}
+
+// ExistsByIDs tells for each ID whether the table has a row with it, including the soft deleted rows.
+// The IDs are checked with a single query that yields one boolean column per ID.
+func (m Mapping[ENT, ID]) ExistsByIDs(ctx context.Context, c Queryable, table string, quote func(ColumnName) string, placeholder func() string, ids ...ID) ([]bool, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var (
+		exprs []string
+		args  []any
+	)
+	for _, id := range ids {
+		idArgs, err := m.QueryID(id)
+		if err != nil {
+			return nil, err
+		}
+		var conds []string
+		for _, col := range mapkit.Keys(idArgs, slices.Sort) {
+			conds = append(conds, fmt.Sprintf("%s = %s", quote(col), placeholder()))
+			args = append(args, idArgs[col])
+		}
+		exprs = append(exprs, fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE %s)", table, strings.Join(conds, " AND ")))
+	}
+	var (
+		exists = make([]bool, len(ids))
+		dest   = make([]any, len(ids))
+	)
+	for i := range exists {
+		dest[i] = &exists[i]
+	}
+	query := fmt.Sprintf("SELECT %s", strings.Join(exprs, ", "))
+	if err := c.QueryRowContext(ctx, query, args...).Scan(dest...); err != nil {
+		return nil, err
+	}
+	return exists, nil
+}
+
+// IDKey returns a comparable key for the ID, which can be used to spot the repeated IDs of a bulk operation.
+func (m Mapping[ENT, ID]) IDKey(id ID) (string, error) {
+	idArgs, err := m.QueryID(id)
+	if err != nil {
+		return "", err
+	}
+	// fmt prints maps in key order
+	return fmt.Sprintf("%v", map[ColumnName]any(idArgs)), nil
+}
+
+// bulkChunkSize limits the number of entities in a single multi-row statement,
+// to keep the statements within the bind parameter limits of the databases.
+const bulkChunkSize = 1000
+
+// CreateMany implements crud.ManyCreator with multi-row INSERT statements within a single transaction.
+// The entities that already exist are reported in a crud.BulkError, and they are left out from the INSERT.
+// Without RETURNING support in the Dialect, the entities are inserted one by one to read back their generated values.
+func (r Repository[ENT, ID]) CreateMany(ctx context.Context, ptrs ...*ENT) error {
+	return r.bulk(ctx, func(ctx context.Context, bulkErr *crud.BulkError) error {
+		for offset := 0; offset < len(ptrs); offset += bulkChunkSize {
+			chunk := ptrs[offset:min(offset+bulkChunkSize, len(ptrs))]
+			if err := r.createMany(ctx, bulkErr, offset, chunk); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r Repository[ENT, ID]) createMany(ctx context.Context, bulkErr *crud.BulkError, offset int, ptrs []*ENT) error {
+	var (
+		pending []int
+		ids     []ID
+		idIndex []int
+	)
+	for i, ptr := range ptrs {
+		if ptr == nil {
+			bulkErr.Add(offset+i, fmt.Errorf("nil entity pointer given to CreateMany"))
+			continue
+		}
+		if err := r.Mapping.OnPrepare(ctx, ptr); err != nil {
+			return err
+		}
+		if err := r.Mapping.InitVersion(ptr); err != nil {
+			return err
+		}
+		pending = append(pending, i)
+		if id, ok := r.Mapping.ID.Lookup(*ptr); ok && !zerokit.IsZero(id) {
+			ids = append(ids, id)
+			idIndex = append(idIndex, i)
+		}
+	}
+
+	exists, err := r.existsByIDs(ctx, ids...)
+	if err != nil {
+		return err
+	}
+	var (
+		conflicts = make(map[int]struct{})
+		seen      = make(map[string]struct{})
+	)
+	for j, i := range idIndex {
+		key, err := r.Mapping.IDKey(ids[j])
+		if err != nil {
+			return err
+		}
+		_, repeated := seen[key]
+		seen[key] = struct{}{}
+		if exists[j] || repeated {
+			err := crud.ErrAlreadyExists.F(`%T already exists with id: %v`, *new(ENT), ids[j])
+			bulkErr.Add(offset+i, errorkit.WithContext(err, ctx))
+			conflicts[i] = struct{}{}
+		}
+	}
+
+	var (
+		rows    []QueryArgs
+		created []*ENT
+	)
+	for _, i := range pending {
+		if _, ok := conflicts[i]; ok {
+			continue
+		}
+		args, err := r.Mapping.ToArgs(*ptrs[i])
+		if err != nil {
+			return err
+		}
+		rows = append(rows, args)
+		created = append(created, ptrs[i])
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if !r.Dialect.Returning {
+		for _, ptr := range created {
+			if err := r.insert(ctx, crud.Created, ptr, Insert(r.Mapping, *ptr)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var (
+		mr           = MakeMultiRow(rows...)
+		values, args = mr.ValuesClause(r.Dialect.Placeholders())
+		cols, scan   = r.Mapping.ToQuery(contextkit.WithoutValues(ctx))
+	)
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s RETURNING %s",
+		r.Dialect.Quote(r.Mapping.TableName), r.quoteColumns(mr.Columns), values, r.quoteColumns(cols))
+
+	logger.Debug(ctx, "flsql.Repository#CreateMany", logging.Field("query", query))
+
+	// the returned rows follow the order of the inserted rows
+	ents, err := r.scanInto(ctx, scan, created, query, args...)
+	if err != nil {
+		return err
+	}
+	return r.changed(ctx, crud.Created, ents...)
+}
+
+// updateChunkSize limits the number of entities in a single UPDATE ... FROM statement.
+// The row source of the statement is a compound SELECT,
+// and SQLite limits the number of terms in a compound SELECT to 500 by default.
+const updateChunkSize = 250
+
+// UpdateMany implements crud.ManyUpdater by updating the entities within a single transaction.
+// With a Dialect that supports UpdateFrom, each chunk of the entities is updated with a single UPDATE ... FROM statement.
+// Without UpdateFrom, but with Upsert and Returning support in the Dialect,
+// the present entities of each chunk are updated with a single multi-row upsert,
+// unless the entities are versioned or soft deletable, as an upsert can't check those conditions.
+// Otherwise the entities are updated one by one.
+// The absent and the concurrently modified entities are reported in a crud.BulkError.
+func (r Repository[ENT, ID]) UpdateMany(ctx context.Context, ptrs ...*ENT) error {
+	return r.bulk(ctx, func(ctx context.Context, bulkErr *crud.BulkError) error {
+		if !r.Dialect.UpdateFrom && r.canUpsertMany() {
+			for offset := 0; offset < len(ptrs); offset += bulkChunkSize {
+				chunk := ptrs[offset:min(offset+bulkChunkSize, len(ptrs))]
+				if err := r.upsertMany(ctx, bulkErr, offset, chunk); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if !r.Dialect.UpdateFrom {
+			for i, ptr := range ptrs {
+				if err := r.updateOne(ctx, bulkErr, i, ptr); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		for offset := 0; offset < len(ptrs); offset += updateChunkSize {
+			chunk := ptrs[offset:min(offset+updateChunkSize, len(ptrs))]
+			if err := r.updateMany(ctx, bulkErr, offset, chunk); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// updateOne updates a single entity of a bulk operation,
+// and reports its failure in the bulk error, when the failure leaves the transaction intact.
+func (r Repository[ENT, ID]) updateOne(ctx context.Context, bulkErr *crud.BulkError, index int, ptr *ENT) error {
+	err := r.update(ctx, ptr)
+	if err == nil {
+		return nil
+	}
+	// these errors leave the transaction intact, unlike a failed statement
+	if ptr == nil || errors.Is(err, crud.ErrNotFound) || errors.Is(err, crud.ErrConcurrentModification) {
+		bulkErr.Add(index, err)
+		return nil
+	}
+	return err
+}
+
+func (r Repository[ENT, ID]) updateMany(ctx context.Context, bulkErr *crud.BulkError, offset int, ptrs []*ENT) error {
+	var (
+		rows    []QueryArgs
+		pending []int
+		keys    []string
+		repeats []int
+		idCols  []ColumnName
+		seen    = make(map[string]struct{})
+	)
+	for i, ptr := range ptrs {
+		if ptr == nil {
+			bulkErr.Add(offset+i, fmt.Errorf("nil entity pointer received in UpdateMany"))
+			continue
+		}
+		id, ok := r.Mapping.ID.Lookup(*ptr)
+		if !ok {
+			return fmt.Errorf("missing entity ID for UpdateMany")
+		}
+		key, err := r.Mapping.IDKey(id)
+		if err != nil {
+			return err
+		}
+		if _, ok := seen[key]; ok {
+			repeats = append(repeats, i)
+			continue
+		}
+		seen[key] = struct{}{}
+		args, err := r.Mapping.ToArgs(*ptr)
+		if err != nil {
+			return err
+		}
+		idArgs, err := r.Mapping.QueryID(id)
+		if err != nil {
+			return err
+		}
+		row := make(QueryArgs, len(args)+len(idArgs)+1)
+		for col, arg := range args {
+			row[col] = arg
+		}
+		for col, arg := range idArgs {
+			row[col] = arg
+		}
+		if r.Mapping.IsVersioned() {
+			row[r.Mapping.VersionColumn] = r.Mapping.Version.Get(*ptr)
+		}
+		idCols = mapkit.Keys(idArgs, slices.Sort)
+		rows = append(rows, row)
+		pending = append(pending, i)
+		keys = append(keys, key)
+	}
+
+	if 0 < len(rows) {
+		updated, err := r.updateFrom(ctx, idCols, rows)
+		if err != nil {
+			return err
+		}
+		byKey := make(map[string]ENT, len(updated))
+		for _, ent := range updated {
+			key, err := r.Mapping.IDKey(r.Mapping.ID.Get(ent))
+			if err != nil {
+				return err
+			}
+			byKey[key] = ent
+		}
+		var (
+			missed []int
+			ents   = make([]ENT, 0, len(updated))
+		)
+		for j, i := range pending {
+			if ent, ok := byKey[keys[j]]; ok {
+				*ptrs[i] = ent
+				ents = append(ents, ent)
+				continue
+			}
+			missed = append(missed, i)
+		}
+		if err := r.updateMisses(ctx, bulkErr, offset, ptrs, missed); err != nil {
+			return err
+		}
+		if err := r.changed(ctx, crud.Updated, ents...); err != nil {
+			return err
+		}
+	}
+
+	// a repeated entity is updated after the previous occurrences, as a separate Update call would be.
+	for _, i := range repeats {
+		if err := r.updateOne(ctx, bulkErr, offset+i, ptrs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r Repository[ENT, ID]) canUpsertMany() bool {
+	return r.Dialect.Upsert != nil && r.Dialect.Returning &&
+		!r.Mapping.IsVersioned() && !r.Mapping.IsSoftDeletable()
+}
+
+// upsertMany updates the present entities with a single multi-row INSERT statement,
+// which updates the conflicting rows through the Dialect's Upsert clause.
+// The absent entities are reported in the bulk error, and they are left out from the statement.
+func (r Repository[ENT, ID]) upsertMany(ctx context.Context, bulkErr *crud.BulkError, offset int, ptrs []*ENT) error {
+	var (
+		ids     []ID
+		idIndex []int
+	)
+	for i, ptr := range ptrs {
+		if ptr == nil {
+			bulkErr.Add(offset+i, fmt.Errorf("nil entity pointer received in UpdateMany"))
+			continue
+		}
+		id, ok := r.Mapping.ID.Lookup(*ptr)
+		if !ok {
+			return fmt.Errorf("missing entity ID for UpdateMany")
+		}
+		ids = append(ids, id)
+		idIndex = append(idIndex, i)
+	}
+
+	exists, err := r.existsByIDs(ctx, ids...)
+	if err != nil {
+		return err
+	}
+	var (
+		rows    []QueryArgs
+		updated []*ENT
+		idCols  []ColumnName
+	)
+	for j, i := range idIndex {
+		if !exists[j] {
+			bulkErr.Add(offset+i, crud.ErrNotFound.F(`%T is not found with id: %v`, *new(ENT), ids[j]))
+			continue
+		}
+		args, err := r.Mapping.ToArgs(*ptrs[i])
+		if err != nil {
+			return err
+		}
+		idArgs, err := r.Mapping.QueryID(ids[j])
+		if err != nil {
+			return err
+		}
+		idCols = mapkit.Keys(idArgs, slices.Sort)
+		rows = append(rows, args)
+		updated = append(updated, ptrs[i])
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	var (
+		mr           = MakeMultiRow(rows...)
+		values, args = mr.ValuesClause(r.Dialect.Placeholders())
+		cols, scan   = r.Mapping.ToQuery(contextkit.WithoutValues(ctx))
+	)
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s %s RETURNING %s",
+		r.Dialect.Quote(r.Mapping.TableName), r.quoteColumns(mr.Columns), values,
+		r.Dialect.Upsert(r.Dialect.QuoteColumn, idCols, mr.Columns), r.quoteColumns(cols))
+
+	logger.Debug(ctx, "flsql.Repository#UpdateMany", logging.Field("query", query))
+
+	// the returned rows follow the order of the inserted rows
+	ents, err := r.scanInto(ctx, scan, updated, query, args...)
+	if err != nil {
+		return err
+	}
+	return r.changed(ctx, crud.Updated, ents...)
+}
+
+// updateFrom updates the rows that match the ID columns of the given rows with a single UPDATE ... FROM statement,
+// and returns the updated entities.
+//
+// The row source is a compound SELECT, where the first, empty SELECT of the table
+// lets the database infer the types of the placeholders from the table's columns.
+func (r Repository[ENT, ID]) updateFrom(ctx context.Context, idCols []ColumnName, rows []QueryArgs) ([]ENT, error) {
+	var (
+		table      = r.Dialect.Quote(r.Mapping.TableName)
+		src        = r.Dialect.Quote("src")
+		mr         = MakeMultiRow(rows...)
+		cols, scan = r.Mapping.ToQuery(contextkit.WithoutValues(ctx))
+		nextPH     = r.Dialect.Placeholders()
+		selects    = []string{fmt.Sprintf("SELECT %s FROM %s WHERE 1 = 0", r.quoteColumns(mr.Columns), table)}
+		args       []any
+		sets       []string
+		where      []string
+		returning  []string
+	)
+	for _, values := range mr.Values {
+		var phs = make([]string, len(values))
+		for i := range values {
+			phs[i] = nextPH()
+		}
+		selects = append(selects, "SELECT "+strings.Join(phs, ", "))
+		args = append(args, values...)
+	}
+	for _, col := range mr.Columns {
+		if slices.Contains(idCols, col) {
+			continue
+		}
+		if r.Mapping.IsVersioned() && col == r.Mapping.VersionColumn {
+			sets = append(sets, fmt.Sprintf("%s = %s.%s + 1", r.Dialect.QuoteColumn(col), src, r.Dialect.QuoteColumn(col)))
+			continue
+		}
+		sets = append(sets, fmt.Sprintf("%s = %s.%s", r.Dialect.QuoteColumn(col), src, r.Dialect.QuoteColumn(col)))
+	}
+	if len(sets) == 0 { // an entity that only has ID columns
+		for _, col := range idCols {
+			sets = append(sets, fmt.Sprintf("%s = %s.%s", r.Dialect.QuoteColumn(col), src, r.Dialect.QuoteColumn(col)))
+		}
+	}
+	matchCols := slices.Clone(idCols)
+	if r.Mapping.IsVersioned() {
+		matchCols = append(matchCols, r.Mapping.VersionColumn)
+	}
+	for _, col := range matchCols {
+		where = append(where, fmt.Sprintf("%s.%s = %s.%s", table, r.Dialect.QuoteColumn(col), src, r.Dialect.QuoteColumn(col)))
+	}
+	if r.Mapping.IsSoftDeletable() {
+		where = append(where, fmt.Sprintf("%s.%s IS NULL", table, r.Dialect.QuoteColumn(r.Mapping.DeletedAtColumn)))
+	}
+	for _, col := range cols {
+		returning = append(returning, fmt.Sprintf("%s.%s", table, r.Dialect.QuoteColumn(col)))
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s FROM (%s) AS %s WHERE %s RETURNING %s",
+		table, strings.Join(sets, ", "), strings.Join(selects, " UNION ALL "), src,
+		strings.Join(where, " AND "), strings.Join(returning, ", "))
+
+	logger.Debug(ctx, "flsql.Repository#UpdateMany", logging.Field("query", query))
+
+	return iterkit.CollectE(QueryMany(r.Connection, ctx, scan.Map, query, args...))
+}
+
+// updateMisses reports the entities that the UPDATE statement didn't affect.
+// A versioned entity that still exists was modified since the received version was read.
+func (r Repository[ENT, ID]) updateMisses(ctx context.Context, bulkErr *crud.BulkError, offset int, ptrs []*ENT, missed []int) error {
+	if len(missed) == 0 {
+		return nil
+	}
+	var live = make(map[string]struct{})
+	if r.Mapping.IsVersioned() {
+		var ids []ID
+		for _, i := range missed {
+			ids = append(ids, r.Mapping.ID.Get(*ptrs[i]))
+		}
+		for ent, err := range QuerySelect(r.Connection, ctx, r.Dialect, Select(ctx, r.Mapping).Where(MatchIDs(r.Mapping, ids...))) {
+			if err != nil {
+				return err
+			}
+			key, err := r.Mapping.IDKey(r.Mapping.ID.Get(ent))
+			if err != nil {
+				return err
+			}
+			live[key] = struct{}{}
+		}
+	}
+	for _, i := range missed {
+		id := r.Mapping.ID.Get(*ptrs[i])
+		key, err := r.Mapping.IDKey(id)
+		if err != nil {
+			return err
+		}
+		if _, ok := live[key]; !ok {
+			bulkErr.Add(offset+i, crud.ErrNotFound)
+			continue
+		}
+		err = crud.ErrConcurrentModification.F(`%T with id %v was modified concurrently (expected version %d)`,
+			*new(ENT), id, r.Mapping.Version.Get(*ptrs[i]))
+		bulkErr.Add(offset+i, errorkit.WithContext(err, ctx))
+	}
+	return nil
+}
+
+// DeleteByIDs implements crud.ByIDsDeleter with multi-row DELETE statements within a single transaction.
+// The IDs of the absent entities are reported in a crud.BulkError.
+func (r Repository[ENT, ID]) DeleteByIDs(ctx context.Context, ids ...ID) error {
+	return r.bulk(ctx, func(ctx context.Context, bulkErr *crud.BulkError) error {
+		for offset := 0; offset < len(ids); offset += bulkChunkSize {
+			chunk := ids[offset:min(offset+bulkChunkSize, len(ids))]
+			if err := r.deleteByIDs(ctx, bulkErr, offset, chunk); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r Repository[ENT, ID]) deleteByIDs(ctx context.Context, bulkErr *crud.BulkError, offset int, ids []ID) error {
+	exists, err := r.existsByIDs(ctx, ids...)
+	if err != nil {
+		return err
+	}
+	var present []ID
+	for i, id := range ids {
+		if !exists[i] {
+			bulkErr.Add(offset+i, crud.ErrNotFound.F(`%T is not found with id: %v`, *new(ENT), id))
+			continue
+		}
+		present = append(present, id)
+	}
+	if len(present) == 0 {
+		return nil
+	}
+	_, err = r.delete(ctx, "DeleteByIDs", MatchIDs(r.Mapping, present...))
+	return err
+}
+
+// existsByIDs tells for each ID whether the table has a row with it, including the soft deleted rows.
+func (r Repository[ENT, ID]) existsByIDs(ctx context.Context, ids ...ID) ([]bool, error) {
+	return r.Mapping.ExistsByIDs(ctx, r.Connection, r.Dialect.Quote(r.Mapping.TableName),
+		r.Dialect.QuoteColumn, r.Dialect.Placeholders(), ids...)
+}
+
+// scanInto scans the returned rows of the query into the entity pointers, in order.
+func (r Repository[ENT, ID]) scanInto(ctx context.Context, scan MapScan[ENT], ptrs []*ENT, query string, args ...any) (_ []ENT, rErr error) {
+	rows, err := r.Connection.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer errorkit.Finish(&rErr, rows.Close)
+	var ents = make([]ENT, 0, len(ptrs))
+	for _, ptr := range ptrs {
+		if !rows.Next() {
+			return nil, errorkit.Merge(fmt.Errorf("expected a returned row for each entity"), rows.Err())
+		}
+		var got ENT
+		if err := scan(&got, rows); err != nil {
+			return nil, err
+		}
+		*ptr = got
+		ents = append(ents, got)
+	}
+	return ents, rows.Err()
+}
+
+func (r Repository[ENT, ID]) quoteColumns(cols []ColumnName) string {
+	return strings.Join(slicekit.Map(cols, r.Dialect.QuoteColumn), ", ")
+}
+
+// bulk runs a bulk operation within a single transaction.
+// The failures that the operation collects into the crud.BulkError don't prevent the commit.
+func (r Repository[ENT, ID]) bulk(ctx context.Context, op func(ctx context.Context, bulkErr *crud.BulkError) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var bulkErr crud.BulkError
+	err := func() (rErr error) {
+		ctx, err := r.BeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer comproto.FinishOnePhaseCommit(&rErr, r, ctx)
+		return op(ctx, &bulkErr)
+	}()
+	if err != nil {
+		return err
+	}
+	return bulkErr.ErrOrNil()
+}
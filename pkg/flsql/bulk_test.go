@@ -0,0 +1,70 @@
+package flsql_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/testcase/assert"
+)
+
+func TestMakeMultiRow(t *testing.T) {
+	mr := flsql.MakeMultiRow(
+		flsql.QueryArgs{"id": 1, "name": "foo"},
+		flsql.QueryArgs{"id": 2, "age": 42},
+	)
+	assert.Equal(t, []flsql.ColumnName{"age", "id", "name"}, mr.Columns)
+	assert.Equal(t, [][]any{{nil, 1, "foo"}, {42, 2, nil}}, mr.Values)
+
+	values, args := mr.ValuesClause(makePlaceholders())
+	assert.Equal(t, `($1, $2, $3), ($4, $5, $6)`, values)
+	assert.Equal(t, []any{nil, 1, "foo", 42, 2, nil}, args)
+}
+
+func TestMapping_ExistsByIDs(t *testing.T) {
+	m := flsql.Mapping[compositeID, compositeID]{
+		QueryID: func(id compositeID) (flsql.QueryArgs, error) {
+			return flsql.QueryArgs{"version": id.Version, "namespace": id.Namespace}, nil
+		},
+	}
+	quote := func(c flsql.ColumnName) string { return fmt.Sprintf("%q", c) }
+	q := flsql.QueryableAdapter{
+		QueryRowFunc: func(ctx context.Context, query string, args ...any) flsql.Row {
+			assert.Equal(t, `SELECT EXISTS (SELECT 1 FROM "ns" WHERE "namespace" = $1 AND "version" = $2), `+
+				`EXISTS (SELECT 1 FROM "ns" WHERE "namespace" = $3 AND "version" = $4)`, query)
+			assert.Equal(t, []any{"foo", 1, "bar", 2}, args)
+			return &mockRow{StubScan: func(dest ...any) error {
+				assert.Equal(t, 2, len(dest))
+				*dest[0].(*bool) = true
+				return nil
+			}}
+		},
+	}
+
+	exists, err := m.ExistsByIDs(context.Background(), q, `"ns"`, quote, makePlaceholders(),
+		compositeID{Namespace: "foo", Version: 1},
+		compositeID{Namespace: "bar", Version: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, false}, exists)
+
+	exists, err = m.ExistsByIDs(context.Background(), q, `"ns"`, quote, makePlaceholders())
+	assert.NoError(t, err)
+	assert.Empty(t, exists)
+}
+
+func TestMapping_IDKey(t *testing.T) {
+	m := flsql.Mapping[compositeID, compositeID]{
+		QueryID: func(id compositeID) (flsql.QueryArgs, error) {
+			return flsql.QueryArgs{"version": id.Version, "namespace": id.Namespace}, nil
+		},
+	}
+	k1, err := m.IDKey(compositeID{Namespace: "foo", Version: 1})
+	assert.NoError(t, err)
+	k2, err := m.IDKey(compositeID{Namespace: "foo", Version: 1})
+	assert.NoError(t, err)
+	k3, err := m.IDKey(compositeID{Namespace: "foo", Version: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, k1, k2)
+	assert.NotEqual(t, k1, k3)
+}
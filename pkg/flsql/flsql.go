@@ -9,13 +9,16 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 
+	"go.llib.dev/frameless/pkg/mapkit"
 	"go.llib.dev/frameless/pkg/reflectkit"
 	"go.llib.dev/frameless/pkg/slicekit"
 	"go.llib.dev/frameless/port/comproto"
 	"go.llib.dev/frameless/port/crud/extid"
+	"go.llib.dev/frameless/port/crud/extver"
 )
 
 // Connection represent an open connection.
@@ -75,6 +78,25 @@ func JoinColumnName(cns []ColumnName, format string, sep string) string {
 	return strings.Join(slicekit.Map(cns, func(n ColumnName) string { return fmt.Sprintf(format, n) }), sep)
 }
 
+// WhereClause joins the non-empty conditions into a WHERE clause.
+// WhereClause returns an empty string when there is no condition at all.
+func WhereClause(conds ...string) string {
+	var parts []string
+	for _, cond := range conds {
+		if cond != "" {
+			parts = append(parts, cond)
+		}
+	}
+	switch len(parts) {
+	case 0:
+		return ""
+	case 1:
+		return " WHERE " + parts[0]
+	default:
+		return " WHERE (" + strings.Join(parts, ") AND (") + ")"
+	}
+}
+
 type Scanner interface{ Scan(dest ...any) error }
 
 type MapScan[ENT any] func(v *ENT, s Scanner) error
@@ -115,10 +137,111 @@ type Mapping[ENT, ID any] struct {
 	//
 	// default: extid.Lookup, extid.Set, which will use either the `ext:"id"` tag, or the `ENT.ID()` & `ENT.SetID()` methods.
 	ID extid.Accessor[ENT, ID]
+	// VersionColumn [optional] is the column that holds the version of a versioned entity.
+	// Setting it opts the table into optimistic concurrency control:
+	// an update is only applied when the version column still holds the version of the received entity,
+	// and the version is incremented as part of the same statement.
+	// The column is expected to be part of the ToQuery columns and the ToArgs arguments.
+	VersionColumn ColumnName
+	// Version [optional] is a function that allows the version lookup from an entity.
+	//
+	// default: extver.Lookup, extver.Set, which will use the `ext:"version"` tag.
+	Version extver.Accessor[ENT]
+	// DeletedAtColumn [optional] is a nullable timestamp column that marks a row as soft deleted.
+	// Setting it enables crud.SoftDeleter in the Repository implementations,
+	// and hides the soft deleted rows from the regular queries, such as FindByID and FindAll.
+	// The column doesn't need to be part of the ToQuery columns or the ToArgs arguments.
+	DeletedAtColumn ColumnName
 }
 
 type QueryArgs map[ColumnName]any
 
+// IsVersioned reports whether the table uses optimistic concurrency control.
+func (m Mapping[ENT, ID]) IsVersioned() bool {
+	return m.VersionColumn != ""
+}
+
+// IsSoftDeletable reports whether the table uses soft deletion.
+func (m Mapping[ENT, ID]) IsSoftDeletable() bool {
+	return m.DeletedAtColumn != ""
+}
+
+// NotDeleted returns the condition that filters out the soft deleted rows.
+// NotDeleted returns an empty string when the table doesn't use soft deletion.
+func (m Mapping[ENT, ID]) NotDeleted(quote func(ColumnName) string) string {
+	if !m.IsSoftDeletable() {
+		return ""
+	}
+	return quote(m.DeletedAtColumn) + " IS NULL"
+}
+
+// IDWhere builds the condition that matches the row of the ID.
+// A composite ID yields a condition for each of its columns, in the order of the column names.
+func (m Mapping[ENT, ID]) IDWhere(id ID, quote func(ColumnName) string, placeholder func() string) (string, []any, error) {
+	idArgs, err := m.QueryID(id)
+	if err != nil {
+		return "", nil, err
+	}
+	cols, args := SplitArgs(idArgs)
+	if len(cols) == 0 {
+		return "", nil, fmt.Errorf("QueryID returned no column for the %T id", id)
+	}
+	var conds []string
+	for _, col := range cols {
+		conds = append(conds, fmt.Sprintf("%s = %s", quote(col), placeholder()))
+	}
+	return strings.Join(conds, " AND "), args, nil
+}
+
+// IDsWhere builds the condition that matches the rows of the IDs with an IN list.
+// A composite ID is matched as a row value, such as ("tenant_id", "local_id") IN (($1, $2), ($3, $4)).
+func (m Mapping[ENT, ID]) IDsWhere(ids []ID, quote func(ColumnName) string, placeholder func() string) (string, []any, error) {
+	if len(ids) == 0 {
+		return "", nil, fmt.Errorf("IDsWhere requires at least one %s id", reflectkit.TypeOf[ID]())
+	}
+	var (
+		cols   []ColumnName
+		values []string
+		args   []any
+	)
+	for _, id := range ids {
+		idArgs, err := m.QueryID(id)
+		if err != nil {
+			return "", nil, err
+		}
+		idCols, idVals := SplitArgs(idArgs)
+		if cols == nil {
+			cols = idCols
+		}
+		if !slices.Equal(cols, idCols) || len(cols) == 0 {
+			return "", nil, fmt.Errorf("QueryID returned inconsistent columns for the %T ids: %v and %v", id, cols, idCols)
+		}
+		var phs = make([]string, len(idVals))
+		for i := range idVals {
+			phs[i] = placeholder()
+		}
+		values = append(values, rowValue(phs))
+		args = append(args, idVals...)
+	}
+	return fmt.Sprintf("%s IN (%s)", rowValue(slicekit.Map(cols, quote)), strings.Join(values, ", ")), args, nil
+}
+
+func rowValue(vs []string) string {
+	if len(vs) == 1 {
+		return vs[0]
+	}
+	return "(" + strings.Join(vs, ", ") + ")"
+}
+
+// InitVersion sets the initial version of a versioned entity prior to its creation,
+// unless the entity already has a version.
+func (m Mapping[ENT, ID]) InitVersion(ptr *ENT) error {
+	if !m.IsVersioned() || m.Version.Get(*ptr) != 0 {
+		return nil
+	}
+	return m.Version.Set(ptr, extver.Initial)
+}
+
 func (m Mapping[ENT, ID]) OnPrepare(ctx context.Context, ptr *ENT) error {
 	// TODO: add support for CreatedAt, UpdatedAt field updates
 	if m.Prepare != nil {
@@ -129,14 +252,14 @@ func (m Mapping[ENT, ID]) OnPrepare(ctx context.Context, ptr *ENT) error {
 	return nil
 }
 
+// SplitArgs splits the query arguments into columns and their values, in the order of the column names.
 func SplitArgs(cargs map[ColumnName]any) ([]ColumnName, []any) {
 	var (
-		cols []ColumnName
-		args []any
+		cols = mapkit.Keys(cargs, slices.Sort)
+		args = make([]any, 0, len(cols))
 	)
-	for col, arg := range cargs {
-		cols = append(cols, col)
-		args = append(args, arg)
+	for _, col := range cols {
+		args = append(args, cargs[col])
 	}
 	return cols, args
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -236,6 +237,80 @@ func TestJoinColumnName_smoke(t *testing.T) {
 	assert.Equal(t, `"foo" AND "bar" AND "baz"`, q)
 }
 
+func TestWhereClause(t *testing.T) {
+	assert.Empty(t, flsql.WhereClause())
+	assert.Empty(t, flsql.WhereClause("", ""))
+	assert.Equal(t, ` WHERE "id" = $1`, flsql.WhereClause(`"id" = $1`, ""))
+	assert.Equal(t, ` WHERE ("a" = $1 OR "b" = $2) AND ("deleted_at" IS NULL)`,
+		flsql.WhereClause(`"a" = $1 OR "b" = $2`, `"deleted_at" IS NULL`))
+}
+
+func TestMapping_NotDeleted(t *testing.T) {
+	quote := func(c flsql.ColumnName) string { return fmt.Sprintf("%q", c) }
+
+	var m flsql.Mapping[testent.Foo, testent.FooID]
+	assert.False(t, m.IsSoftDeletable())
+	assert.Empty(t, m.NotDeleted(quote))
+
+	m.DeletedAtColumn = "deleted_at"
+	assert.True(t, m.IsSoftDeletable())
+	assert.Equal(t, `"deleted_at" IS NULL`, m.NotDeleted(quote))
+}
+
+func TestMapping_IDWhere(t *testing.T) {
+	quote := func(c flsql.ColumnName) string { return fmt.Sprintf("%q", c) }
+
+	single := flsql.Mapping[testent.Foo, testent.FooID]{
+		QueryID: func(id testent.FooID) (flsql.QueryArgs, error) {
+			return flsql.QueryArgs{"id": id}, nil
+		},
+	}
+	where, args, err := single.IDWhere("foo", quote, makePlaceholders())
+	assert.NoError(t, err)
+	assert.Equal(t, `"id" = $1`, where)
+	assert.Equal(t, []any{testent.FooID("foo")}, args)
+
+	composite := flsql.Mapping[compositeID, compositeID]{
+		QueryID: func(id compositeID) (flsql.QueryArgs, error) {
+			return flsql.QueryArgs{"version": id.Version, "namespace": id.Namespace}, nil
+		},
+	}
+	where, args, err = composite.IDWhere(compositeID{Namespace: "foo", Version: 42}, quote, makePlaceholders())
+	assert.NoError(t, err)
+	assert.Equal(t, `"namespace" = $1 AND "version" = $2`, where)
+	assert.Equal(t, []any{"foo", 42}, args)
+}
+
+func TestMapping_IDsWhere(t *testing.T) {
+	quote := func(c flsql.ColumnName) string { return fmt.Sprintf("%q", c) }
+
+	single := flsql.Mapping[testent.Foo, testent.FooID]{
+		QueryID: func(id testent.FooID) (flsql.QueryArgs, error) {
+			return flsql.QueryArgs{"id": id}, nil
+		},
+	}
+	where, args, err := single.IDsWhere([]testent.FooID{"foo", "bar"}, quote, makePlaceholders())
+	assert.NoError(t, err)
+	assert.Equal(t, `"id" IN ($1, $2)`, where)
+	assert.Equal(t, []any{testent.FooID("foo"), testent.FooID("bar")}, args)
+
+	composite := flsql.Mapping[compositeID, compositeID]{
+		QueryID: func(id compositeID) (flsql.QueryArgs, error) {
+			return flsql.QueryArgs{"version": id.Version, "namespace": id.Namespace}, nil
+		},
+	}
+	where, args, err = composite.IDsWhere([]compositeID{
+		{Namespace: "foo", Version: 1},
+		{Namespace: "bar", Version: 2},
+	}, quote, makePlaceholders())
+	assert.NoError(t, err)
+	assert.Equal(t, `("namespace", "version") IN (($1, $2), ($3, $4))`, where)
+	assert.Equal(t, []any{"foo", 1, "bar", 2}, args)
+
+	_, _, err = composite.IDsWhere(nil, quote, makePlaceholders())
+	assert.Error(t, err)
+}
+
 func TestJSON_Strict(t *testing.T) {
 	type T struct {
 		Foo string `json:"foo"`
@@ -7,6 +7,10 @@ import (
 	"go.llib.dev/frameless/pkg/iterkit"
 )
 
+// QueryMany runs a query and maps its rows with the mapper.
+// QueryMany doesn't mark the query as ReadOnly,
+// since the caller might read the data to write based on it;
+// use ReadOnly on the context to allow a ConnectionAdapter with Replicas to route the query to a replica.
 func QueryMany[T any](c Queryable, ctx context.Context, mapper RowMapper[T], query string, args ...any) iterkit.SeqE[T] {
 	return func(yield func(T, error) bool) {
 		rows, err := c.QueryContext(ctx, query, args...)
@@ -0,0 +1,123 @@
+package flsql
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/mapkit"
+	"go.llib.dev/frameless/port/crud"
+)
+
+// EncodeCursor makes an opaque crud.Cursor that holds the ID of the last entity on a page.
+func EncodeCursor[ID any](id ID) (crud.Cursor, error) {
+	data, err := json.Marshal(id)
+	if err != nil {
+		return "", err
+	}
+	return crud.Cursor(base64.RawURLEncoding.EncodeToString(data)), nil
+}
+
+// DecodeCursor returns the ID from a crud.Cursor that was made with EncodeCursor.
+func DecodeCursor[ID any](cursor crud.Cursor) (ID, error) {
+	var id ID
+	data, err := base64.RawURLEncoding.DecodeString(string(cursor))
+	if err != nil {
+		return id, crud.ErrInvalidCursor.F("malformed cursor: %q", cursor)
+	}
+	if err := json.Unmarshal(data, &id); err != nil {
+		return id, crud.ErrInvalidCursor.F("malformed cursor: %q", cursor)
+	}
+	return id, nil
+}
+
+// Keyset describes the keyset pagination of a table, where rows are ordered by their ID columns.
+type Keyset struct {
+	// Columns are the ID columns in the order they are used to sort the rows.
+	Columns []ColumnName
+	// Args are the ID column values of the last row on the previous page, in the same order as Columns.
+	// Args is empty when the first page is requested.
+	Args []any
+}
+
+// Keyset returns the keyset pagination of the mapped table for the page that comes after the cursor.
+// An empty cursor stands for the first page.
+func (m Mapping[ENT, ID]) Keyset(cursor crud.Cursor) (Keyset, error) {
+	var id ID
+	if cursor != "" {
+		var err error
+		id, err = DecodeCursor[ID](cursor)
+		if err != nil {
+			return Keyset{}, err
+		}
+	}
+	idArgs, err := m.QueryID(id)
+	if err != nil {
+		return Keyset{}, err
+	}
+	var ks Keyset
+	ks.Columns = mapkit.Keys(idArgs, slices.Sort)
+	if cursor != "" {
+		for _, col := range ks.Columns {
+			ks.Args = append(ks.Args, idArgs[col])
+		}
+	}
+	return ks, nil
+}
+
+// Cursor returns the cursor that points right after the given entity.
+func (m Mapping[ENT, ID]) Cursor(ent ENT) (crud.Cursor, error) {
+	return EncodeCursor(m.ID.Get(ent))
+}
+
+// Where returns the condition that selects the rows after the previous page,
+// using a row value comparison to support IDs that span multiple columns.
+// Where returns an empty string for the first page.
+func (ks Keyset) Where(quote func(ColumnName) string, placeholder func() string) string {
+	if len(ks.Args) == 0 {
+		return ""
+	}
+	var (
+		cols []string
+		phs  []string
+	)
+	for _, col := range ks.Columns {
+		cols = append(cols, quote(col))
+		phs = append(phs, placeholder())
+	}
+	return fmt.Sprintf("(%s) > (%s)", strings.Join(cols, ", "), strings.Join(phs, ", "))
+}
+
+// OrderBy returns the ordering terms that keep the pages stable.
+func (ks Keyset) OrderBy(quote func(ColumnName) string) string {
+	var terms []string
+	for _, col := range ks.Columns {
+		terms = append(terms, quote(col)+" ASC")
+	}
+	return strings.Join(terms, ", ")
+}
+
+// QueryPage runs a keyset pagination query and turns its result into a crud.Page.
+// The query is expected to select at most size+1 rows,
+// where the extra row only signals that a next page exists.
+func QueryPage[ENT, ID any](c Queryable, ctx context.Context, m Mapping[ENT, ID], scan MapScan[ENT], size int, query string, args ...any) (crud.Page[ENT], error) {
+	ents, err := iterkit.CollectE(QueryMany(c, ctx, scan.Map, query, args...))
+	if err != nil {
+		return crud.Page[ENT]{}, err
+	}
+	var page crud.Page[ENT]
+	if len(ents) <= size {
+		page.Entries = ents
+		return page, nil
+	}
+	page.Entries = ents[:size]
+	page.Next, err = m.Cursor(page.Entries[size-1])
+	if err != nil {
+		return crud.Page[ENT]{}, err
+	}
+	return page, nil
+}
@@ -0,0 +1,56 @@
+package flsql_test
+
+import (
+	"fmt"
+	"testing"
+
+	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/testcase/assert"
+)
+
+type compositeID struct {
+	Namespace string
+	Version   int
+}
+
+func TestEncodeCursor(t *testing.T) {
+	id := compositeID{Namespace: "foo", Version: 42}
+
+	cursor, err := flsql.EncodeCursor(id)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, cursor)
+
+	got, err := flsql.DecodeCursor[compositeID](cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, id, got)
+
+	_, err = flsql.DecodeCursor[compositeID]("!not a cursor!")
+	assert.ErrorIs(t, err, crud.ErrInvalidCursor)
+}
+
+func TestMapping_Keyset(t *testing.T) {
+	m := flsql.Mapping[compositeID, compositeID]{
+		QueryID: func(id compositeID) (flsql.QueryArgs, error) {
+			return flsql.QueryArgs{"version": id.Version, "namespace": id.Namespace}, nil
+		},
+		ID: func(v *compositeID) *compositeID { return v },
+	}
+	quote := func(c flsql.ColumnName) string { return fmt.Sprintf("%q", c) }
+
+	first, err := m.Keyset("")
+	assert.NoError(t, err)
+	assert.Empty(t, first.Where(quote, makePlaceholders()))
+	assert.Equal(t, `"namespace" ASC, "version" ASC`, first.OrderBy(quote))
+
+	cursor, err := m.Cursor(compositeID{Namespace: "foo", Version: 42})
+	assert.NoError(t, err)
+
+	next, err := m.Keyset(cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, `("namespace", "version") > ($1, $2)`, next.Where(quote, makePlaceholders()))
+	assert.Equal(t, []any{"foo", 42}, next.Args)
+
+	_, err = m.Keyset("!not a cursor!")
+	assert.ErrorIs(t, err, crud.ErrInvalidCursor)
+}
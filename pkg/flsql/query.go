@@ -0,0 +1,163 @@
+package flsql
+
+import (
+	"fmt"
+	"strings"
+
+	"go.llib.dev/frameless/port/crud/crudquery"
+)
+
+// QueryCompiler translates a crudquery.Query into SQL clauses.
+//
+// Query fields are resolved against Columns with crudquery.MatchField,
+// which means only known columns can appear in the generated SQL,
+// and every value is passed as a positional argument.
+type QueryCompiler struct {
+	// Columns are the columns the query fields can refer to.
+	// Usually these are the columns returned by Mapping.ToQuery.
+	Columns []ColumnName
+	// QuoteColumn formats a column name as a quoted SQL identifier.
+	//
+	// default: `"column"`
+	QuoteColumn func(ColumnName) string
+	// Placeholder returns the next positional argument placeholder, such as "$1" or "?".
+	Placeholder func() string
+}
+
+// CompiledQuery holds the SQL clauses of a compiled crudquery.Query.
+type CompiledQuery struct {
+	// Where is the boolean expression of the WHERE clause, without the WHERE keyword.
+	// It is empty when the query has no filter.
+	Where string
+	// OrderBy is the list of ordering terms, without the ORDER BY keyword.
+	// It is empty when the query has no ordering.
+	OrderBy string
+	// Args are the positional arguments for the placeholders in Where.
+	Args []any
+}
+
+// Compile validates the query and translates its filter and ordering into SQL clauses.
+// Limit and Offset are left to the caller, as their syntax varies between SQL dialects.
+func (c QueryCompiler) Compile(q crudquery.Query) (CompiledQuery, error) {
+	if err := q.Validate(); err != nil {
+		return CompiledQuery{}, err
+	}
+	var cq CompiledQuery
+	if q.Where != nil {
+		where, args, err := c.CompileExpr(*q.Where)
+		if err != nil {
+			return CompiledQuery{}, err
+		}
+		cq.Where = where
+		cq.Args = args
+	}
+	var orderBy []string
+	for _, o := range q.OrderBy {
+		col, err := c.Column(o.Field)
+		if err != nil {
+			return CompiledQuery{}, err
+		}
+		dir := "ASC"
+		if o.Desc {
+			dir = "DESC"
+		}
+		orderBy = append(orderBy, fmt.Sprintf("%s %s", col, dir))
+	}
+	cq.OrderBy = strings.Join(orderBy, ", ")
+	return cq, nil
+}
+
+// CompileExpr translates a crudquery.Expr into an SQL boolean expression and its arguments.
+func (c QueryCompiler) CompileExpr(expr crudquery.Expr) (string, []any, error) {
+	if c.Placeholder == nil {
+		return "", nil, fmt.Errorf("flsql.QueryCompiler is missing the Placeholder function")
+	}
+	switch expr.Op {
+	case crudquery.OpAnd, crudquery.OpOr:
+		if len(expr.Exprs) == 0 {
+			if expr.Op == crudquery.OpAnd {
+				return "1 = 1", nil, nil
+			}
+			return "1 = 0", nil, nil
+		}
+		var (
+			parts []string
+			args  []any
+		)
+		for _, sub := range expr.Exprs {
+			part, subArgs, err := c.CompileExpr(sub)
+			if err != nil {
+				return "", nil, err
+			}
+			parts = append(parts, "("+part+")")
+			args = append(args, subArgs...)
+		}
+		return strings.Join(parts, " "+strings.ToUpper(string(expr.Op))+" "), args, nil
+
+	case crudquery.OpNot:
+		if err := expr.Validate(); err != nil {
+			return "", nil, err
+		}
+		part, args, err := c.CompileExpr(expr.Exprs[0])
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT (" + part + ")", args, nil
+
+	case crudquery.OpIn:
+		col, err := c.Column(expr.Field)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(expr.Values) == 0 {
+			return "1 = 0", nil, nil
+		}
+		var phs []string
+		for range expr.Values {
+			phs = append(phs, c.Placeholder())
+		}
+		return fmt.Sprintf("%s IN (%s)", col, strings.Join(phs, ", ")), append([]any{}, expr.Values...), nil
+
+	case crudquery.OpEq, crudquery.OpNe, crudquery.OpLt, crudquery.OpLte, crudquery.OpGt, crudquery.OpGte:
+		if err := expr.Validate(); err != nil {
+			return "", nil, err
+		}
+		col, err := c.Column(expr.Field)
+		if err != nil {
+			return "", nil, err
+		}
+		if expr.Value == nil {
+			if expr.Op == crudquery.OpNe {
+				return col + " IS NOT NULL", nil, nil
+			}
+			return col + " IS NULL", nil, nil
+		}
+		return fmt.Sprintf("%s %s %s", col, comparisonOperators[expr.Op], c.Placeholder()), []any{expr.Value}, nil
+
+	default:
+		return "", nil, expr.Validate()
+	}
+}
+
+var comparisonOperators = map[crudquery.Operator]string{
+	crudquery.OpEq:  "=",
+	crudquery.OpNe:  "<>",
+	crudquery.OpLt:  "<",
+	crudquery.OpLte: "<=",
+	crudquery.OpGt:  ">",
+	crudquery.OpGte: ">=",
+}
+
+// Column resolves a query field into the quoted name of the matching column.
+// An unknown field yields crudquery.ErrInvalidQuery.
+func (c QueryCompiler) Column(field string) (string, error) {
+	for _, col := range c.Columns {
+		if crudquery.MatchField(field, string(col)) {
+			if c.QuoteColumn != nil {
+				return c.QuoteColumn(col), nil
+			}
+			return fmt.Sprintf("%q", col), nil
+		}
+	}
+	return "", crudquery.ErrInvalidQuery.F("unknown field: %q", field)
+}
@@ -0,0 +1,88 @@
+package flsql_test
+
+import (
+	"fmt"
+	"testing"
+
+	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/frameless/port/crud/crudquery"
+	"go.llib.dev/testcase/assert"
+)
+
+func makePlaceholders() func() string {
+	var n int
+	return func() string {
+		n++
+		return fmt.Sprintf("$%d", n)
+	}
+}
+
+func TestQueryCompiler_Compile(t *testing.T) {
+	c := flsql.QueryCompiler{
+		Columns:     []flsql.ColumnName{"id", "status", "total", "created_at"},
+		Placeholder: makePlaceholders(),
+	}
+
+	q := crudquery.Where(crudquery.And(
+		crudquery.Eq("status", "paid"),
+		crudquery.Or(
+			crudquery.Gte("total", 100),
+			crudquery.In("id", "1", "2"),
+		),
+		crudquery.Not(crudquery.Eq("created_at", nil)),
+	)).Desc("createdAt").Asc("id")
+
+	got, err := c.Compile(q)
+	assert.NoError(t, err)
+	assert.Equal(t, `("status" = $1) AND (("total" >= $2) OR ("id" IN ($3, $4))) AND (NOT ("created_at" IS NULL))`, got.Where)
+	assert.Equal(t, `"created_at" DESC, "id" ASC`, got.OrderBy)
+	assert.Equal(t, []any{"paid", 100, "1", "2"}, got.Args)
+}
+
+func TestQueryCompiler_Compile_emptyQuery(t *testing.T) {
+	c := flsql.QueryCompiler{Columns: []flsql.ColumnName{"id"}, Placeholder: makePlaceholders()}
+	got, err := c.Compile(crudquery.Query{})
+	assert.NoError(t, err)
+	assert.Empty(t, got.Where)
+	assert.Empty(t, got.OrderBy)
+	assert.Empty(t, got.Args)
+}
+
+func TestQueryCompiler_CompileExpr(t *testing.T) {
+	c := flsql.QueryCompiler{
+		Columns:     []flsql.ColumnName{"id", "status"},
+		QuoteColumn: func(c flsql.ColumnName) string { return fmt.Sprintf("`%s`", c) },
+		Placeholder: func() string { return "?" },
+	}
+
+	for _, tc := range []struct {
+		Expr  crudquery.Expr
+		Where string
+		Args  []any
+	}{
+		{Expr: crudquery.Ne("status", "new"), Where: "`status` <> ?", Args: []any{"new"}},
+		{Expr: crudquery.Lt("id", 3), Where: "`id` < ?", Args: []any{3}},
+		{Expr: crudquery.Ne("status", nil), Where: "`status` IS NOT NULL"},
+		{Expr: crudquery.In("status"), Where: "1 = 0"},
+		{Expr: crudquery.And(), Where: "1 = 1"},
+		{Expr: crudquery.Or(), Where: "1 = 0"},
+	} {
+		where, args, err := c.CompileExpr(tc.Expr)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.Where, where)
+		assert.Equal(t, tc.Args, args)
+	}
+}
+
+func TestQueryCompiler_Compile_invalid(t *testing.T) {
+	c := flsql.QueryCompiler{Columns: []flsql.ColumnName{"id"}, Placeholder: makePlaceholders()}
+
+	_, err := c.Compile(crudquery.Where(crudquery.Eq("id; DROP TABLE x", 1)))
+	assert.ErrorIs(t, err, crudquery.ErrInvalidQuery)
+
+	_, err = c.Compile(crudquery.Query{}.Asc("unknown"))
+	assert.ErrorIs(t, err, crudquery.ErrInvalidQuery)
+
+	_, err = c.Compile(crudquery.Where(crudquery.Expr{Op: "like", Field: "id"}))
+	assert.ErrorIs(t, err, crudquery.ErrInvalidQuery)
+}
@@ -0,0 +1,195 @@
+package flsql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"go.llib.dev/frameless/pkg/contextkit"
+	"go.llib.dev/frameless/pkg/errorkit"
+	"go.llib.dev/frameless/pkg/logger"
+	"go.llib.dev/frameless/pkg/logging"
+	"go.llib.dev/testcase/clock"
+)
+
+// ReplicaSet is a group of read-only replicas of a primary database.
+//
+// A ConnectionAdapter with a ReplicaSet routes the read-only queries,
+// which are made outside of a transaction, to the replicas in a round-robin fashion.
+// When a query fails on a replica, the replica is taken out from the routing for the Cooldown period,
+// and the query fails over to the next healthy replica, or to the primary database when none is left.
+//
+// The zero value with the Replicas field set is ready to use.
+type ReplicaSet[DB any] struct {
+	// Replicas are the read-only copies of the primary database.
+	Replicas []*DB
+	// Cooldown [optional] is how long a failing replica is left out from the routing.
+	// After the Cooldown, the replica receives queries again.
+	//
+	// default: 5 seconds
+	Cooldown time.Duration
+
+	mutex     sync.Mutex
+	next      int
+	unhealthy map[int]time.Time
+}
+
+const defaultReplicaCooldown = 5 * time.Second
+
+func (rs *ReplicaSet[DB]) cooldown() time.Duration {
+	if rs.Cooldown <= 0 {
+		return defaultReplicaCooldown
+	}
+	return rs.Cooldown
+}
+
+// pick selects the next healthy replica which is not in the tried set.
+func (rs *ReplicaSet[DB]) pick(tried map[int]struct{}) (int, *DB, bool) {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+	var now = clock.Now()
+	for range len(rs.Replicas) {
+		i := rs.next % len(rs.Replicas)
+		rs.next = i + 1
+		if _, ok := tried[i]; ok {
+			continue
+		}
+		if until, ok := rs.unhealthy[i]; ok {
+			if now.Before(until) {
+				continue
+			}
+			delete(rs.unhealthy, i)
+		}
+		return i, rs.Replicas[i], true
+	}
+	return 0, nil, false
+}
+
+func (rs *ReplicaSet[DB]) fail(ctx context.Context, i int, err error) {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+	if rs.unhealthy == nil {
+		rs.unhealthy = make(map[int]time.Time)
+	}
+	rs.unhealthy[i] = clock.Now().Add(rs.cooldown())
+	logger.Warn(ctx, "replica query failed, replica is taken out from the routing",
+		logging.Field("replica", i),
+		logging.ErrField(err))
+}
+
+// Healthy tells how many replicas are currently part of the routing.
+func (rs *ReplicaSet[DB]) Healthy() int {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+	var (
+		now = clock.Now()
+		n   int
+	)
+	for i := range rs.Replicas {
+		if until, ok := rs.unhealthy[i]; ok && now.Before(until) {
+			continue
+		}
+		n++
+	}
+	return n
+}
+
+func (rs *ReplicaSet[DB]) close() error {
+	var errs []error
+	for _, replica := range rs.Replicas {
+		if closer, ok := any(replica).(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errorkit.Merge(errs...)
+}
+
+// ContextReadOnly marks whether the queries of a context only read data.
+// Outside of a transaction, a ConnectionAdapter with Replicas routes the read-only queries to a replica.
+//
+// Replicas may lag behind the primary database,
+// thus when a read must see the preceding writes,
+// mark the context with false to pin its queries to the primary database.
+var ContextReadOnly contextkit.ValueHandler[ctxKeyReadOnly, bool]
+
+type ctxKeyReadOnly struct{}
+
+// ReadOnly marks the context as read-only,
+// unless the context already has an explicit ContextReadOnly value.
+func ReadOnly(ctx context.Context) context.Context {
+	if _, ok := ContextReadOnly.Lookup(ctx); ok {
+		return ctx
+	}
+	return ContextReadOnly.ContextWith(ctx, true)
+}
+
+func (c ConnectionAdapter[DB, TX]) routesToReplica(ctx context.Context) bool {
+	if c.Replicas == nil || len(c.Replicas.Replicas) == 0 || c.DBAdapter == nil {
+		return false
+	}
+	if readOnly, _ := ContextReadOnly.Lookup(ctx); !readOnly {
+		return false
+	}
+	_, inTx := c.LookupTx(ctx)
+	return !inTx
+}
+
+func (c ConnectionAdapter[DB, TX]) queryReplica(ctx context.Context, tried map[int]struct{}, query string, args []any) (Rows, bool, error) {
+	for {
+		i, replica, ok := c.Replicas.pick(tried)
+		if !ok {
+			return nil, false, nil
+		}
+		tried[i] = struct{}{}
+		conn := c.DBAdapter(replica)
+		c.debugLogExec(ctx, conn, "QueryContext", query, args)
+		rows, err := conn.QueryContext(ctx, query, args...)
+		if err == nil || ctx.Err() != nil {
+			return rows, true, err
+		}
+		c.Replicas.fail(ctx, i, err)
+	}
+}
+
+func (c ConnectionAdapter[DB, TX]) queryRowReplica(ctx context.Context, tried map[int]struct{}, query string, args []any) Row {
+	i, replica, ok := c.Replicas.pick(tried)
+	if !ok {
+		conn := c.txm().Q(ctx)
+		c.debugLogExec(ctx, conn, "QueryRowContext", query, args)
+		return conn.QueryRowContext(ctx, query, args...)
+	}
+	tried[i] = struct{}{}
+	conn := c.DBAdapter(replica)
+	c.debugLogExec(ctx, conn, "QueryRowContext", query, args)
+	return failoverRow{
+		Row: conn.QueryRowContext(ctx, query, args...),
+		OnError: func(err error) (Row, bool) {
+			if errors.Is(err, c.noRowsErr()) || ctx.Err() != nil {
+				return nil, false
+			}
+			c.Replicas.fail(ctx, i, err)
+			return c.queryRowReplica(ctx, tried, query, args), true
+		},
+	}
+}
+
+// failoverRow defers the failover decision to the Scan,
+// since most drivers only report the errors of a single row query there.
+type failoverRow struct {
+	Row     Row
+	OnError func(err error) (Row, bool)
+}
+
+func (r failoverRow) Scan(dest ...any) error {
+	err := r.Row.Scan(dest...)
+	if err == nil {
+		return nil
+	}
+	next, ok := r.OnError(err)
+	if !ok {
+		return err
+	}
+	return next.Scan(dest...)
+}
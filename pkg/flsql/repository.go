@@ -0,0 +1,542 @@
+package flsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"go.llib.dev/frameless/pkg/contextkit"
+	"go.llib.dev/frameless/pkg/errorkit"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/logger"
+	"go.llib.dev/frameless/pkg/logging"
+	"go.llib.dev/frameless/pkg/zerokit"
+	"go.llib.dev/frameless/port/comproto"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/crudquery"
+	"go.llib.dev/testcase/clock"
+)
+
+// Repository is a dialect agnostic implementation of the crud port on top of a Connection and a Mapping.
+// The SQL syntax differences between databases are handled by the Dialect,
+// thus a new SQL backend only needs a Connection and a Dialect to have a Repository.
+//
+// Repository supports optimistic concurrency control with Mapping.VersionColumn,
+// and soft deletion with Mapping.DeletedAtColumn.
+type Repository[ENT, ID any] struct {
+	Connection Connection
+	Mapping    Mapping[ENT, ID]
+	Dialect    Dialect
+	// OnChange [optional] receives the entities affected by a mutation, within the transaction of the mutation.
+	// This allows recording the changes atomically with the mutation, for example into an outbox table.
+	// A soft deletion is reported as crud.Deleted and a restore as crud.Created,
+	// while the hard deletion of an already soft deleted row is not reported again.
+	//
+	// OnChange requires a Dialect that supports RETURNING in UPDATE and DELETE statements, such as PostgreSQL or SQLite.
+	OnChange func(ctx context.Context, kind crud.ChangeKind, ents ...ENT) error
+}
+
+var _ interface {
+	crud.Creator[any]
+	crud.ByIDFinder[any, any]
+	crud.AllFinder[any]
+	crud.ByIDsFinder[any, any]
+	crud.Updater[any]
+	crud.ByIDDeleter[any]
+	crud.AllDeleter
+	crud.Saver[any]
+	crud.Querier[any]
+	crud.PageFinder[any]
+	crud.SoftDeleter[any, any]
+	crud.ManyCreator[any]
+	crud.ManyUpdater[any]
+	crud.ByIDsDeleter[any]
+	crud.Counter
+	crud.QueryCounter
+	crud.GroupCounter
+	crud.Exister[any]
+	comproto.OnePhaseCommitProtocol
+} = Repository[any, any]{}
+
+func (r Repository[ENT, ID]) Create(ctx context.Context, ptr *ENT) (rErr error) {
+	if ptr == nil {
+		return fmt.Errorf("nil entity pointer given to Create")
+	}
+	ctx, err := r.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer comproto.FinishOnePhaseCommit(&rErr, r, ctx)
+
+	if id, ok := r.Mapping.ID.Lookup(*ptr); ok && !zerokit.IsZero(id) {
+		// the existence check includes the soft deleted rows, which still occupy their ID in the table.
+		exists, err := r.existsByIDs(ctx, id)
+		if err != nil {
+			return err
+		}
+		if exists[0] {
+			err := crud.ErrAlreadyExists.F(`%T already exists with id: %v`, *new(ENT), id)
+			return errorkit.WithContext(err, ctx)
+		}
+	}
+
+	if err := r.Mapping.OnPrepare(ctx, ptr); err != nil {
+		return err
+	}
+	if err := r.Mapping.InitVersion(ptr); err != nil {
+		return err
+	}
+	return r.insert(ctx, crud.Created, ptr, Insert(r.Mapping, *ptr))
+}
+
+// insert executes the INSERT statement and reads back the row into the entity pointer.
+func (r Repository[ENT, ID]) insert(ctx context.Context, kind crud.ChangeKind, ptr *ENT, stmt InsertStatement) error {
+	cols, scan := r.Mapping.ToQuery(contextkit.WithoutValues(ctx))
+	if r.Dialect.Returning {
+		query, args, err := stmt.Returning(cols...).Build(r.Dialect)
+		if err != nil {
+			return err
+		}
+		logger.Debug(ctx, "flsql.Repository#insert", logging.Field("query", query))
+		if err := scan(ptr, r.Connection.QueryRowContext(ctx, query, args...)); err != nil {
+			return err
+		}
+		return r.changed(ctx, kind, *ptr)
+	}
+
+	query, args, err := stmt.Build(r.Dialect)
+	if err != nil {
+		return err
+	}
+	logger.Debug(ctx, "flsql.Repository#insert", logging.Field("query", query))
+	result, err := r.Connection.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	id, ok := r.Mapping.ID.Lookup(*ptr)
+	if !ok || zerokit.IsZero(id) {
+		if id, ok = lastInsertID[ID](result); !ok {
+			return fmt.Errorf("the dialect can't return the generated ID of %T, use Mapping.Prepare to set it", *ptr)
+		}
+	}
+	got, found, err := r.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("expected that the inserted %T is findable", *ptr)
+	}
+	*ptr = got
+	return r.changed(ctx, kind, got)
+}
+
+// lastInsertID returns the auto increment ID of the inserted row,
+// when the database driver reports it, and the ID is an integer.
+func lastInsertID[ID any](result Result) (ID, bool) {
+	var id ID
+	res, ok := result.(interface{ LastInsertId() (int64, error) })
+	if !ok {
+		return id, false
+	}
+	rid := reflect.ValueOf(&id).Elem()
+	switch rid.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+	default:
+		return id, false
+	}
+	n, err := res.LastInsertId()
+	if err != nil || n == 0 {
+		return id, false
+	}
+	rid.SetInt(n)
+	return id, true
+}
+
+func (r Repository[ENT, ID]) FindByID(ctx context.Context, id ID) (ENT, bool, error) {
+	sel := Select(ctx, r.Mapping).Where(MatchID(r.Mapping, id)).Limit(1)
+	query, args, err := sel.Build(r.Dialect)
+	if err != nil {
+		return *new(ENT), false, err
+	}
+
+	logger.Debug(ctx, "flsql.Repository#FindByID", logging.Field("query", query))
+
+	var v ENT
+	err = sel.scan(&v, r.Connection.QueryRowContext(ReadOnly(ctx), query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return *new(ENT), false, nil
+	}
+	if err != nil {
+		return *new(ENT), false, err
+	}
+	return v, true, nil
+}
+
+func (r Repository[ENT, ID]) FindAll(ctx context.Context) iterkit.SeqE[ENT] {
+	ctx = ReadOnly(ctx)
+	return QuerySelect(r.Connection, ctx, r.Dialect, Select(ctx, r.Mapping))
+}
+
+func (r Repository[ENT, ID]) FindByIDs(ctx context.Context, ids ...ID) iterkit.SeqE[ENT] {
+	if len(ids) == 0 {
+		return iterkit.Empty2[ENT, error]()
+	}
+	ctx = ReadOnly(ctx)
+	sel := Select(ctx, r.Mapping).Where(MatchIDs(r.Mapping, ids...))
+	query, args, err := sel.Build(r.Dialect)
+	if err != nil {
+		return iterkit.Error[ENT](err)
+	}
+	var count int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS %s", query, r.Dialect.Quote("src"))
+	if err := r.Connection.QueryRowContext(ctx, countQuery, args...).Scan(&count); err != nil {
+		return iterkit.Error[ENT](err)
+	}
+	if count != len(ids) {
+		return iterkit.Error[ENT](crud.ErrNotFound)
+	}
+	return QueryMany(r.Connection, ctx, sel.scan.Map, query, args...)
+}
+
+// Query implements crud.Querier by compiling the query into SQL.
+// Query fields are resolved against the Mapping's ToQuery columns, see crudquery.MatchField.
+func (r Repository[ENT, ID]) Query(ctx context.Context, q crudquery.Query) iterkit.SeqE[ENT] {
+	if err := q.Validate(); err != nil {
+		return iterkit.Error[ENT](err)
+	}
+	ctx = ReadOnly(ctx)
+	sel := Select(ctx, r.Mapping)
+	if q.Where != nil {
+		sel = sel.Where(FromQuery(*q.Where))
+	}
+	for _, o := range q.OrderBy {
+		col, ok := r.column(sel.columns, o.Field)
+		if !ok {
+			return iterkit.Error[ENT](crudquery.ErrInvalidQuery.F("unknown field: %q", o.Field))
+		}
+		sel = sel.OrderBy(col, o.Desc)
+	}
+	sel = sel.Limit(q.Limit).Offset(q.Offset)
+	return QuerySelect(r.Connection, ctx, r.Dialect, sel)
+}
+
+func (r Repository[ENT, ID]) column(cols []ColumnName, field string) (ColumnName, bool) {
+	for _, col := range cols {
+		if crudquery.MatchField(field, string(col)) {
+			return col, true
+		}
+	}
+	return "", false
+}
+
+// FindPage implements crud.PageFinder with keyset pagination over the ID columns of the table.
+func (r Repository[ENT, ID]) FindPage(ctx context.Context, cursor crud.Cursor, size int) (crud.Page[ENT], error) {
+	if size < 1 {
+		return crud.Page[ENT]{}, fmt.Errorf("invalid page size: %d", size)
+	}
+	ks, err := r.Mapping.Keyset(cursor)
+	if err != nil {
+		return crud.Page[ENT]{}, err
+	}
+	sel := Select(ctx, r.Mapping)
+	if where := ks.Where(r.Dialect.QuoteColumn, func() string { return "?" }); where != "" {
+		sel = sel.Where(Raw(where, ks.Args...))
+	}
+	for _, col := range ks.Columns {
+		sel = sel.OrderBy(col, false)
+	}
+	query, args, err := sel.Limit(size + 1).Build(r.Dialect)
+	if err != nil {
+		return crud.Page[ENT]{}, err
+	}
+
+	logger.Debug(ctx, "flsql.Repository#FindPage", logging.Field("query", query))
+
+	return QueryPage(r.Connection, ctx, r.Mapping, sel.scan, size, query, args...)
+}
+
+func (r Repository[ENT, ID]) Update(ctx context.Context, ptr *ENT) (rErr error) {
+	if ptr == nil {
+		return fmt.Errorf("nil entity pointer received in Update")
+	}
+	ctx, err := r.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer comproto.FinishOnePhaseCommit(&rErr, r, ctx)
+	return r.update(ctx, ptr)
+}
+
+func (r Repository[ENT, ID]) update(ctx context.Context, ptr *ENT) error {
+	if ptr == nil {
+		return fmt.Errorf("nil entity pointer received in Update")
+	}
+	id, ok := r.Mapping.ID.Lookup(*ptr)
+	if !ok {
+		return fmt.Errorf("missing entity ID for Update")
+	}
+
+	stmt := Update(r.Mapping, *ptr).Where(MatchID(r.Mapping, id))
+	var version int64
+	if r.Mapping.IsVersioned() {
+		version = r.Mapping.Version.Get(*ptr)
+		stmt = stmt.Set(r.Mapping.VersionColumn, version+1).Where(Eq(r.Mapping.VersionColumn, version))
+	}
+	if r.Mapping.IsSoftDeletable() {
+		stmt = stmt.Where(IsNull(r.Mapping.DeletedAtColumn))
+	}
+	if err := r.execOne(ctx, "Update", crud.Updated, stmt, func() error { return r.updateMissError(ctx, id, version) }); err != nil {
+		return err
+	}
+
+	got, found, err := r.FindByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error while looking up the entity: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("expected that updated entity is findable")
+	}
+	*ptr = got
+	return nil
+}
+
+// updateMissError tells why an Update didn't affect any row.
+// A versioned entity that still exists was modified since the received version was read.
+func (r Repository[ENT, ID]) updateMissError(ctx context.Context, id ID, version int64) error {
+	if !r.Mapping.IsVersioned() {
+		return crud.ErrNotFound
+	}
+	_, found, err := r.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return crud.ErrNotFound
+	}
+	err = crud.ErrConcurrentModification.F(`%T with id %v was modified concurrently (expected version %d)`, *new(ENT), id, version)
+	return errorkit.WithContext(err, ctx)
+}
+
+// Save implements crud.Saver.
+// An entity with an ID is upserted, which requires a Dialect with Upsert support,
+// while an entity without an ID is created.
+// The upsert can't check the version or the soft deletion of the stored row,
+// so a versioned or soft deletable entity is either created or updated with the checks of Create and Update.
+// Like Create, saving an entity with the ID of a soft deleted entity yields crud.ErrAlreadyExists.
+func (r Repository[ENT, ID]) Save(ctx context.Context, ptr *ENT) (rErr error) {
+	if ptr == nil {
+		return fmt.Errorf("nil entity pointer given to Save")
+	}
+	id, ok := r.Mapping.ID.Lookup(*ptr)
+	if !ok || zerokit.IsZero(id) {
+		return r.Create(ctx, ptr)
+	}
+	idArgs, err := r.Mapping.QueryID(id)
+	if err != nil {
+		return err
+	}
+	conflict, _ := SplitArgs(idArgs)
+
+	ctx, err = r.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer comproto.FinishOnePhaseCommit(&rErr, r, ctx)
+
+	if r.Mapping.IsVersioned() || r.Mapping.IsSoftDeletable() {
+		_, found, err := r.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if found {
+			return r.update(ctx, ptr)
+		}
+		return r.Create(ctx, ptr)
+	}
+
+	var kind = crud.Created
+	if r.OnChange != nil { // the upsert itself doesn't tell whether it inserted or updated the row
+		_, found, err := r.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if found {
+			kind = crud.Updated
+		}
+	}
+	return r.insert(ctx, kind, ptr, Insert(r.Mapping, *ptr).OnConflictUpdate(conflict...))
+}
+
+func (r Repository[ENT, ID]) DeleteByID(ctx context.Context, id ID) (rErr error) {
+	ctx, err := r.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer comproto.FinishOnePhaseCommit(&rErr, r, ctx)
+	n, err := r.delete(ctx, "DeleteByID", MatchID(r.Mapping, id))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return crud.ErrNotFound
+	}
+	return nil
+}
+
+func (r Repository[ENT, ID]) DeleteAll(ctx context.Context) (rErr error) {
+	ctx, err := r.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer comproto.FinishOnePhaseCommit(&rErr, r, ctx)
+	_, err = r.delete(ctx, "DeleteAll")
+	return err
+}
+
+// delete removes the matching rows, including the soft deleted ones.
+// The hard deletion of a soft deleted row is not reported to OnChange,
+// because its soft deletion was already reported as a deletion.
+func (r Repository[ENT, ID]) delete(ctx context.Context, method string, where ...Expr) (int64, error) {
+	stmt := Delete(ctx, r.Mapping).Where(where...)
+	if r.OnChange == nil || !r.Mapping.IsSoftDeletable() {
+		return r.execChange(ctx, method, crud.Deleted, stmt)
+	}
+	live, err := r.execChange(ctx, method, crud.Deleted, stmt.Where(IsNull(r.Mapping.DeletedAtColumn)))
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := r.exec(ctx, method, stmt.Where(IsNotNull(r.Mapping.DeletedAtColumn)))
+	return live + deleted, err
+}
+
+// SoftDeleteByID implements crud.SoftDeleter by setting the Mapping.DeletedAtColumn of the row.
+func (r Repository[ENT, ID]) SoftDeleteByID(ctx context.Context, id ID) error {
+	return r.setDeletedAt(ctx, id, true)
+}
+
+// Restore implements crud.SoftDeleter by clearing the Mapping.DeletedAtColumn of the row.
+func (r Repository[ENT, ID]) Restore(ctx context.Context, id ID) error {
+	return r.setDeletedAt(ctx, id, false)
+}
+
+func (r Repository[ENT, ID]) setDeletedAt(ctx context.Context, id ID, deleted bool) (rErr error) {
+	if !r.Mapping.IsSoftDeletable() {
+		return r.errNotSoftDeletable()
+	}
+	cols, _ := r.Mapping.ToQuery(ctx)
+	stmt := UpdateStatement{table: r.Mapping.TableName, known: knownColumns(r.Mapping, cols)}.
+		Where(MatchID(r.Mapping, id))
+	if deleted {
+		stmt = stmt.Set(r.Mapping.DeletedAtColumn, clock.Now().UTC()).Where(IsNull(r.Mapping.DeletedAtColumn))
+	} else {
+		stmt = stmt.Set(r.Mapping.DeletedAtColumn, nil).Where(IsNotNull(r.Mapping.DeletedAtColumn))
+	}
+
+	ctx, err := r.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer comproto.FinishOnePhaseCommit(&rErr, r, ctx)
+
+	// from the OnChange's point of view, a soft deleted entity is deleted, and a restored one is created again.
+	var kind = crud.Created
+	if deleted {
+		kind = crud.Deleted
+	}
+	return r.execOne(ctx, "setDeletedAt", kind, stmt, func() error { return crud.ErrNotFound })
+}
+
+// FindDeleted implements crud.SoftDeleter by listing the rows that has their Mapping.DeletedAtColumn set.
+func (r Repository[ENT, ID]) FindDeleted(ctx context.Context) iterkit.SeqE[ENT] {
+	if !r.Mapping.IsSoftDeletable() {
+		return iterkit.Error[ENT](r.errNotSoftDeletable())
+	}
+	sel := Select(ctx, r.Mapping)
+	sel.where = nil // Select filters out the soft deleted rows
+	return QuerySelect(r.Connection, ctx, r.Dialect, sel.Where(IsNotNull(r.Mapping.DeletedAtColumn)))
+}
+
+func (r Repository[ENT, ID]) errNotSoftDeletable() error {
+	return fmt.Errorf("soft delete requires flsql.Mapping.DeletedAtColumn for the %s table", r.Mapping.TableName)
+}
+
+// execOne executes a statement that is expected to affect a row,
+// and reports the miss error when it didn't.
+func (r Repository[ENT, ID]) execOne(ctx context.Context, method string, kind crud.ChangeKind, stmt returningStatement, miss func() error) error {
+	n, err := r.execChange(ctx, method, kind, stmt)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return miss()
+	}
+	return nil
+}
+
+// returningStatement is a mutating statement that can return the affected rows.
+type returningStatement interface {
+	Statement
+	withReturning(cols []ColumnName) Statement
+}
+
+func (s UpdateStatement) withReturning(cols []ColumnName) Statement { return s.Returning(cols...) }
+
+func (s DeleteStatement) withReturning(cols []ColumnName) Statement { return s.Returning(cols...) }
+
+// execChange executes a mutating statement, and reports the affected rows to OnChange.
+// It returns the number of the affected rows.
+func (r Repository[ENT, ID]) execChange(ctx context.Context, method string, kind crud.ChangeKind, stmt returningStatement) (int64, error) {
+	if r.OnChange == nil {
+		return r.exec(ctx, method, stmt)
+	}
+	cols, scan := r.Mapping.ToQuery(contextkit.WithoutValues(ctx))
+	query, args, err := stmt.withReturning(cols).Build(r.Dialect)
+	if err != nil {
+		return 0, err
+	}
+
+	logger.Debug(ctx, "flsql.Repository#"+method, logging.Field("query", query))
+
+	ents, err := iterkit.CollectE(QueryMany(r.Connection, ctx, scan.Map, query, args...))
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(ents)), r.changed(ctx, kind, ents...)
+}
+
+func (r Repository[ENT, ID]) exec(ctx context.Context, method string, stmt Statement) (int64, error) {
+	query, args, err := stmt.Build(r.Dialect)
+	if err != nil {
+		return 0, err
+	}
+
+	logger.Debug(ctx, "flsql.Repository#"+method, logging.Field("query", query))
+
+	result, err := r.Connection.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// changed reports the changed entities to OnChange.
+func (r Repository[ENT, ID]) changed(ctx context.Context, kind crud.ChangeKind, ents ...ENT) error {
+	if r.OnChange == nil || len(ents) == 0 {
+		return nil
+	}
+	return r.OnChange(ctx, kind, ents...)
+}
+
+func (r Repository[ENT, ID]) BeginTx(ctx context.Context) (context.Context, error) {
+	return r.Connection.BeginTx(ctx)
+}
+
+func (r Repository[ENT, ID]) CommitTx(ctx context.Context) error {
+	return r.Connection.CommitTx(ctx)
+}
+
+func (r Repository[ENT, ID]) RollbackTx(ctx context.Context) error {
+	return r.Connection.RollbackTx(ctx)
+}
@@ -0,0 +1,135 @@
+package flsql_test
+
+import (
+	"context"
+	"testing"
+
+	"go.llib.dev/frameless/pkg/flsql"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/crudquery"
+	"go.llib.dev/frameless/testing/testent"
+	"go.llib.dev/testcase/assert"
+)
+
+func TestRepository(t *testing.T) {
+	type DB struct{}
+	type TX struct{}
+
+	var (
+		ctx      = context.Background()
+		queries  []string
+		readOnly []bool
+		stored   testent.Foo
+	)
+	queryable := flsql.QueryableAdapter{
+		ExecFunc: func(ctx context.Context, query string, args ...any) (flsql.Result, error) {
+			queries = append(queries, query)
+			return &mockResult{StubRowsAffected: 0}, nil
+		},
+		QueryFunc: func(ctx context.Context, query string, args ...any) (flsql.Rows, error) {
+			queries = append(queries, query)
+			ro, _ := flsql.ContextReadOnly.Lookup(ctx)
+			readOnly = append(readOnly, ro)
+			return &mockRows{StubNext: func() bool { return false }}, nil
+		},
+		QueryRowFunc: func(ctx context.Context, query string, args ...any) flsql.Row {
+			queries = append(queries, query)
+			ro, _ := flsql.ContextReadOnly.Lookup(ctx)
+			readOnly = append(readOnly, ro)
+			return &mockRow{StubScan: func(dest ...any) error {
+				if _, ok := dest[0].(*bool); ok { // ExistsByIDs
+					for _, d := range dest {
+						*d.(*bool) = true
+					}
+					return nil
+				}
+				*dest[0].(*testent.FooID) = stored.ID
+				*dest[1].(*string) = stored.Foo
+				*dest[2].(*string) = stored.Bar
+				*dest[3].(*string) = stored.Baz
+				return nil
+			}}
+		},
+	}
+	conn := flsql.ConnectionAdapter[DB, TX]{
+		DB:        &DB{},
+		DBAdapter: func(db *DB) flsql.Queryable { return queryable },
+		TxAdapter: func(tx *TX) flsql.Queryable { return queryable },
+		Begin:     func(ctx context.Context, db *DB) (*TX, error) { return &TX{}, nil },
+		Commit:    func(ctx context.Context, tx *TX) error { return nil },
+		Rollback:  func(ctx context.Context, tx *TX) error { return nil },
+	}
+
+	t.Run("Save upserts with the dialect's syntax and reads back the row", func(t *testing.T) {
+		queries = nil
+		stored = testent.Foo{ID: "1", Foo: "a", Bar: "b", Baz: "c"}
+		subject := flsql.Repository[testent.Foo, testent.FooID]{Connection: conn, Mapping: fooMapping, Dialect: flsql.DialectPostgreSQL}
+
+		foo := testent.Foo{ID: "1", Foo: "a"}
+		assert.NoError(t, subject.Save(ctx, &foo))
+		assert.Equal(t, stored, foo)
+		assert.Equal(t, []string{`INSERT INTO "foos" ("bar", "baz", "foo", "id") VALUES ($1, $2, $3, $4) ` +
+			`ON CONFLICT ("id") DO UPDATE SET "bar" = EXCLUDED."bar", "baz" = EXCLUDED."baz", "foo" = EXCLUDED."foo", "id" = EXCLUDED."id" ` +
+			`RETURNING "id", "foo", "bar", "baz"`}, queries)
+	})
+
+	t.Run("DeleteByID reports a missing row as not found", func(t *testing.T) {
+		queries = nil
+		subject := flsql.Repository[testent.Foo, testent.FooID]{Connection: conn, Mapping: fooMapping, Dialect: flsql.DialectMySQL}
+
+		assert.ErrorIs(t, crud.ErrNotFound, subject.DeleteByID(ctx, "1"))
+		assert.Equal(t, []string{"DELETE FROM `foos` WHERE `id` = ?"}, queries)
+	})
+
+	t.Run("the read methods mark their queries as read-only, unlike the writes", func(t *testing.T) {
+		subject := flsql.Repository[testent.Foo, testent.FooID]{Connection: conn, Mapping: fooMapping, Dialect: flsql.DialectPostgreSQL}
+
+		readOnly = nil
+		_, _, err := subject.FindByID(ctx, "1")
+		assert.NoError(t, err)
+		_, err = iterkit.CollectE(subject.FindAll(ctx))
+		assert.NoError(t, err)
+		_, err = iterkit.CollectE(subject.Query(ctx, crudquery.Query{}))
+		assert.NoError(t, err)
+		assert.Equal(t, []bool{true, true, true}, readOnly)
+
+		readOnly = nil
+		foo := testent.Foo{ID: "1"}
+		assert.NoError(t, subject.Save(ctx, &foo))
+		assert.NotEmpty(t, readOnly)
+		assert.NotContains(t, readOnly, true)
+	})
+
+	t.Run("UpdateMany updates the entities with a single UPDATE FROM statement", func(t *testing.T) {
+		queries = nil
+		subject := flsql.Repository[testent.Foo, testent.FooID]{Connection: conn, Mapping: fooMapping, Dialect: flsql.DialectPostgreSQL}
+
+		foo1 := testent.Foo{ID: "1", Foo: "a"}
+		foo2 := testent.Foo{ID: "2", Foo: "b"}
+		err := subject.UpdateMany(ctx, &foo1, &foo2)
+		assert.ErrorIs(t, err, crud.ErrNotFound, "the stub returns no updated row")
+		assert.Equal(t, []string{`UPDATE "foos" SET "bar" = "src"."bar", "baz" = "src"."baz", "foo" = "src"."foo" ` +
+			`FROM (SELECT "bar", "baz", "foo", "id" FROM "foos" WHERE 1 = 0 UNION ALL SELECT $1, $2, $3, $4 UNION ALL SELECT $5, $6, $7, $8) AS "src" ` +
+			`WHERE "foos"."id" = "src"."id" ` +
+			`RETURNING "foos"."id", "foos"."foo", "foos"."bar", "foos"."baz"`}, queries)
+	})
+
+	t.Run("UpdateMany upserts the present entities with a single statement, when the dialect lacks UPDATE FROM", func(t *testing.T) {
+		queries = nil
+		dialect := flsql.DialectMySQL
+		dialect.Returning = true
+		subject := flsql.Repository[testent.Foo, testent.FooID]{Connection: conn, Mapping: fooMapping, Dialect: dialect}
+
+		foo1 := testent.Foo{ID: "1", Foo: "a"}
+		foo2 := testent.Foo{ID: "2", Foo: "b"}
+		err := subject.UpdateMany(ctx, &foo1, &foo2)
+		assert.Error(t, err, "the stub returns no upserted row")
+		assert.Equal(t, []string{
+			"SELECT EXISTS (SELECT 1 FROM `foos` WHERE `id` = ?), EXISTS (SELECT 1 FROM `foos` WHERE `id` = ?)",
+			"INSERT INTO `foos` (`bar`, `baz`, `foo`, `id`) VALUES (?, ?, ?, ?), (?, ?, ?, ?) " +
+				"ON DUPLICATE KEY UPDATE `bar` = VALUES(`bar`), `baz` = VALUES(`baz`), `foo` = VALUES(`foo`), `id` = VALUES(`id`) " +
+				"RETURNING `id`, `foo`, `bar`, `baz`",
+		}, queries)
+	})
+}
@@ -44,6 +44,11 @@ var ErrInvalidRequestBody = errorkit.UserError{
 	Message: "The request body is invalid.",
 }
 
+var ErrInvalidPageRequest = errorkit.UserError{
+	Code:    "invalid-page-request",
+	Message: "The requested page is invalid, check the cursor and limit query parameters.",
+}
+
 var ErrInternalServerError = errorkit.UserError{
 	Code:    "internal-server-error",
 	Message: "An unexpected internal server error occurred.",
@@ -86,7 +91,8 @@ func ErrorMapping(ctx context.Context, err error, dto *rfc7807.DTO) {
 		dto.Status = http.StatusForbidden
 		dto.Detail = ErrForbidden.Message.String()
 	case errors.Is(err, ErrMalformedID),
-		errors.Is(err, ErrInvalidRequestBody):
+		errors.Is(err, ErrInvalidRequestBody),
+		errors.Is(err, ErrInvalidPageRequest):
 		dto.Status = http.StatusBadRequest
 	}
 }
@@ -11,9 +11,12 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"go.llib.dev/frameless/pkg/errorkit"
 	"go.llib.dev/frameless/pkg/httpkit/mediatype"
 	"go.llib.dev/frameless/pkg/iokit"
 	"go.llib.dev/frameless/pkg/iterkit"
@@ -81,6 +84,11 @@ type RESTClient[ENT, ID any] struct {
 	//
 	// default: false
 	DisableStreaming bool
+	// PageSize [optional] asks the server to paginate the results of FindAll with the given page size.
+	// FindAll follows the next page links of a paginated server response either way.
+	//
+	// default: the server decides about the pagination
+	PageSize int
 	// KeepAliveInterval is the interval in between the response body guaranteed to be read, to avoid read timeout.
 	//
 	// default: 5s
@@ -138,11 +146,11 @@ func (r RESTClient[ENT, ID]) Create(ctx context.Context, ptr *ENT) error {
 	return codec.Unmarshal(responseBody, ptr)
 }
 
+// FindAll lists the entities of the RESTful resource.
+// When the server paginates its Index endpoint, FindAll follows the Link header of each page until the last one.
 func (r RESTClient[ENT, ID]) FindAll(ctx context.Context) iter.Seq2[ENT, error] {
 	return func(yield func(ENT, error) bool) {
 		ctx = r.withContext(ctx)
-		var details []logging.Detail
-		defer func() { logger.Debug(ctx, "find all entity with a rest client http request", details...) }()
 
 		baseURL, err := r.getBaseURL(ctx)
 		if err != nil {
@@ -152,71 +160,163 @@ func (r RESTClient[ENT, ID]) FindAll(ctx context.Context) iter.Seq2[ENT, error]
 		}
 
 		reqURL := pathkit.Join(baseURL, "/")
-		details = append(details, logging.Field("url", reqURL))
+		if 0 < r.PageSize {
+			// the empty cursor requests the first page
+			reqURL += "?" + url.Values{queryKeyCursor: {""}, queryKeyLimit: {strconv.Itoa(r.PageSize)}}.Encode()
+		}
+
+		for reqURL != "" {
+			next, ok := r.findAllPage(ctx, reqURL, yield)
+			if !ok {
+				return
+			}
+			reqURL = next
+		}
+	}
+}
+
+// FindPage implements crud.PageFinder by requesting a single page from the Index endpoint of the RESTful resource.
+func (r RESTClient[ENT, ID]) FindPage(ctx context.Context, cursor crud.Cursor, size int) (crud.Page[ENT], error) {
+	ctx = r.withContext(ctx)
+
+	if size < 1 {
+		return crud.Page[ENT]{}, fmt.Errorf("invalid page size: %d", size)
+	}
+
+	baseURL, err := r.getBaseURL(ctx)
+	if err != nil {
+		return crud.Page[ENT]{}, err
+	}
+
+	query := url.Values{
+		queryKeyCursor: {string(cursor)},
+		queryKeyLimit:  {strconv.Itoa(size)},
+	}
 
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	var (
+		page    crud.Page[ENT]
+		pageErr error
+	)
+	next, _ := r.findAllPage(ctx, pathkit.Join(baseURL, "/")+"?"+query.Encode(), func(ent ENT, err error) bool {
 		if err != nil {
-			var zero ENT
-			yield(zero, err)
-			return
+			pageErr = err
+			return false
 		}
+		page.Entries = append(page.Entries, ent)
+		return true
+	})
+	if pageErr != nil {
+		if errResp, ok := errorkit.As[ClientErrUnexpectedResponse](pageErr); ok && errResp.StatusCode == http.StatusBadRequest {
+			return crud.Page[ENT]{}, crud.ErrInvalidCursor.F("%s", errResp.Error())
+		}
+		return crud.Page[ENT]{}, pageErr
+	}
+	if next != "" {
+		nextURL, err := url.Parse(next)
+		if err != nil {
+			return crud.Page[ENT]{}, err
+		}
+		page.Next = crud.Cursor(nextURL.Query().Get(queryKeyCursor))
+	}
+	return page, nil
+}
+
+// findAllPage requests a single page from the Index endpoint, and yields its entities.
+// It returns the URL of the next page, if the response has one,
+// and reports whether the iteration can continue.
+func (r RESTClient[ENT, ID]) findAllPage(ctx context.Context, reqURL string, yield func(ENT, error) bool) (string, bool) {
+	var details []logging.Detail
+	defer func() { logger.Debug(ctx, "find all entity with a rest client http request", details...) }()
+
+	details = append(details, logging.Field("url", reqURL))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		var zero ENT
+		yield(zero, err)
+		return "", false
+	}
 
-		reqMediaType := r.getMediaType()
-		req.Header.Set(headerKeyContentType, reqMediaType)
-		req.Header.Set(headerKeyAccept, reqMediaType)
-		details = append(details, logging.Field("request content type", reqMediaType))
-		details = append(details, logging.Field("request accept media type", reqMediaType))
+	reqMediaType := r.getMediaType()
+	req.Header.Set(headerKeyContentType, reqMediaType)
+	req.Header.Set(headerKeyAccept, reqMediaType)
+	details = append(details, logging.Field("request content type", reqMediaType))
+	details = append(details, logging.Field("request accept media type", reqMediaType))
 
-		resp, err := r.httpClient().Do(req)
+	resp, err := r.httpClient().Do(req)
+	if err != nil {
+		var zero ENT
+		yield(zero, err)
+		return "", false
+	}
+
+	details = append(details, logging.Field("status code", resp.StatusCode))
+
+	if !statusOK(resp) {
+		body, err := r.bodyReadAll(resp.Body)
 		if err != nil {
 			var zero ENT
 			yield(zero, err)
-			return
+			return "", false
 		}
+		var zero ENT
+		yield(zero, makeClientErrUnexpectedResponse(req, resp, body))
+		return "", false
+	}
 
-		details = append(details, logging.Field("status code", resp.StatusCode))
-		c, respMediaType, ok := r.contentTypeBasedCodec(resp)
-		if !ok {
-			err := fmt.Errorf("no codec configured for response content type: %s", respMediaType)
+	var next string
+	if link, ok := lookupNextLink(resp.Header); ok {
+		nextURL, err := req.URL.Parse(link)
+		if err != nil {
 			var zero ENT
 			yield(zero, err)
-			return
+			return "", false
 		}
+		next = nextURL.String()
+		details = append(details, logging.Field("next page url", next))
+	}
 
-		details = append(details, logging.Field("response content type", respMediaType))
+	c, respMediaType, ok := r.contentTypeBasedCodec(resp)
+	if !ok {
+		err := fmt.Errorf("no codec configured for response content type: %s", respMediaType)
+		var zero ENT
+		yield(zero, err)
+		return "", false
+	}
 
-		if sc, ok := c.(codec.StreamConsumer); ok {
-			r.streamFindAll(ctx, sc, resp.Body, yield)
-			return
-		}
-		r.findAll(ctx, c, resp.Body, yield)
+	details = append(details, logging.Field("response content type", respMediaType))
+
+	if sc, ok := c.(codec.StreamConsumer); ok {
+		return next, r.streamFindAll(ctx, sc, resp.Body, yield)
 	}
+	return next, r.findAll(ctx, c, resp.Body, yield)
 }
 
-func (r RESTClient[ENT, ID]) findAll(ctx context.Context, c codec.Codec, body io.ReadCloser, yield func(ENT, error) bool) {
+func (r RESTClient[ENT, ID]) findAll(ctx context.Context, c codec.Codec, body io.ReadCloser, yield func(ENT, error) bool) bool {
 	defer body.Close()
 	data, err := r.bodyReadAll(body)
 	if err != nil {
 		var zero ENT
 		yield(zero, err)
-		return
+		return false
 	}
 
 	var vs []ENT
 	if err := c.Unmarshal(data, &vs); err != nil {
 		var zero ENT
 		yield(zero, err)
-		return
+		return false
 	}
 
 	for _, v := range vs {
 		if !yield(v, nil) {
-			return
+			return false
 		}
 	}
+	return true
 }
 
-func (r RESTClient[ENT, ID]) streamFindAll(_ context.Context, sc codec.StreamConsumer, body io.ReadCloser, yield func(ENT, error) bool) {
+func (r RESTClient[ENT, ID]) streamFindAll(_ context.Context, sc codec.StreamConsumer, body io.ReadCloser, yield func(ENT, error) bool) bool {
 	var src io.Reader = body
 
 	if r.DisableStreaming {
@@ -225,7 +325,7 @@ func (r RESTClient[ENT, ID]) streamFindAll(_ context.Context, sc codec.StreamCon
 		if err != nil {
 			var zero ENT
 			yield(zero, err)
-			return
+			return false
 		}
 		src = io.NopCloser(bytes.NewReader(data))
 	} else {
@@ -239,18 +339,19 @@ func (r RESTClient[ENT, ID]) streamFindAll(_ context.Context, sc codec.StreamCon
 		var ent ENT
 		if err != nil {
 			yield(ent, err)
-			return
+			return false
 		}
 		if err := elem.Decode(&ent); err != nil {
 			if !yield(ent, err) {
-				return
+				return false
 			}
 			continue
 		}
 		if !yield(ent, nil) {
-			return
+			return false
 		}
 	}
+	return true
 }
 
 func (r RESTClient[ENT, ID]) FindByID(ctx context.Context, id ID) (ent ENT, found bool, err error) {
@@ -643,6 +744,32 @@ func pathsubst(ctx context.Context, uri string) (string, error) {
 	return pathkit.Join(pathParts...), nil
 }
 
+// lookupNextLink looks up the target of the "next" relation in the Link header, as defined in RFC 8288.
+func lookupNextLink(header http.Header) (string, bool) {
+	for _, value := range header.Values(headerKeyLink) {
+		for _, link := range strings.Split(value, ",") {
+			target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+			if !ok {
+				continue
+			}
+			target = strings.TrimSpace(target)
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range strings.Split(params, ";") {
+				key, val, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+				if slices.Contains(strings.Fields(strings.Trim(val, `"`)), "next") {
+					return strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">"), true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
 func intWithin(got, min, max int) bool {
 	return min <= got && got <= max
 }
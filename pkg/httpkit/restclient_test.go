@@ -111,6 +111,7 @@ func TestRESTClient_crud(t *testing.T) {
 	crudcontract.ByIDsFinder[testent.Foo, testent.FooID](fooClient, crudcontractsConfig).Spec(s)
 	crudcontract.Updater[testent.Foo, testent.FooID](fooClient, crudcontractsConfig).Spec(s)
 	crudcontract.Deleter[testent.Foo, testent.FooID](fooClient, crudcontractsConfig).Spec(s)
+	crudcontract.PageFinder[testent.Foo, testent.FooID](fooClient, crudcontractsConfig).Spec(s)
 }
 
 func TestRESTClient_FindAll_withDisableStreaming(t *testing.T) {
@@ -133,6 +134,56 @@ func TestRESTClient_FindAll_withDisableStreaming(t *testing.T) {
 	crudcontract.AllFinder[testent.Foo, testent.FooID](fooClient, crudcontractsConfig).Test(t)
 }
 
+func TestRESTClient_FindAll_withPagination(t *testing.T) {
+	mem := memory.NewMemory()
+	fooRepo := memory.NewRepository[testent.Foo, testent.FooID](mem)
+	fooAPI := httpkit.RESTHandlerFromCRUD[testent.Foo, testent.FooID](fooRepo, func(h *httpkit.RESTHandler[testent.Foo, testent.FooID]) {
+		h.IndexPageSize = 2
+	})
+	srv := httptest.NewServer(fooAPI)
+	t.Cleanup(srv.Close)
+
+	var foos []testent.Foo
+	for range 5 {
+		foo := testent.MakeFoo(t)
+		crudtest.Create[testent.Foo, testent.FooID](t, fooRepo, t.Context(), &foo)
+		foos = append(foos, foo)
+	}
+
+	t.Run("client side PageSize requests pagination", func(t *testing.T) {
+		fooClient := httpkit.RESTClient[testent.Foo, testent.FooID]{
+			HTTPClient: srv.Client(),
+			BaseURL:    srv.URL,
+			PageSize:   1,
+		}
+
+		got, err := iterkit.CollectE(fooClient.FindAll(t.Context()))
+		assert.NoError(t, err)
+		assert.ContainsExactly(t, foos, got)
+
+		page, err := fooClient.FindPage(t.Context(), "", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(page.Entries))
+		assert.True(t, page.HasNext())
+	})
+
+	t.Run("server side pagination is followed", func(t *testing.T) {
+		pagedAPI := fooAPI
+		pagedAPI.Index = nil
+		pagedSrv := httptest.NewServer(pagedAPI)
+		t.Cleanup(pagedSrv.Close)
+
+		fooClient := httpkit.RESTClient[testent.Foo, testent.FooID]{
+			HTTPClient: pagedSrv.Client(),
+			BaseURL:    pagedSrv.URL,
+		}
+
+		got, err := iterkit.CollectE(fooClient.FindAll(t.Context()))
+		assert.NoError(t, err)
+		assert.ContainsExactly(t, foos, got)
+	})
+}
+
 func TestRESTClient_subresource(t *testing.T) {
 	logger.Testing(t)
 
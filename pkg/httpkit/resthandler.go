@@ -8,6 +8,7 @@ import (
 	"iter"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"go.llib.dev/frameless/pkg/httpkit/internal"
@@ -76,6 +77,30 @@ type RESTHandler[ENT, ID any] struct {
 	// Index is a collection endpoint.
 	//		GET /
 	Index func(ctx context.Context) iter.Seq2[ENT, error]
+	// IndexPage will return a page of the entities that comes after the cursor.
+	// IndexPage is a collection endpoint, which takes over the Index endpoint
+	// when the request asks for a page with the "cursor" query parameter, or when Index is not supplied.
+	// An empty cursor requests the first page.
+	// While there are more pages left, the response has a Link header that points to the next page.
+	//		GET /?cursor=:cursor&limit=:size
+	//
+	// IndexPage is unaware of the Filters and the parent resource scope,
+	// thus when Filters are defined, or when the RESTHandler is used as a subresource without being ScopeAware,
+	// the listing is not paginated, and it is served by Index, or by walking through every page of IndexPage.
+	IndexPage func(ctx context.Context, cursor crud.Cursor, size int) (crud.Page[ENT], error)
+	// IndexPageSize [optional] is both the default and the maximum page size for the IndexPage endpoint.
+	//
+	// default: DefaultIndexPageSize
+	IndexPageSize int
+	// Count [optional] will return the total number of entities in the resource.
+	// When Count is supplied, the Index responses tell the total with the X-Total-Count header,
+	// regardless of the requested page.
+	//		GET / -> X-Total-Count: :count
+	//
+	// Count is unaware of the Filters and the parent resource scope,
+	// thus the header is omitted when Filters are defined,
+	// or when the RESTHandler is used as a subresource without being ScopeAware.
+	Count func(ctx context.Context) (int, error)
 	// Show will return a single entity, looked up by its ID.
 	// Show is a resource endpoint.
 	// 		GET /:id
@@ -311,14 +336,24 @@ func (h RESTHandler[ENT, ID]) getBodyReadLimit() int {
 }
 
 func (h RESTHandler[ENT, ID]) index(w http.ResponseWriter, r *http.Request) {
-	if h.Index == nil {
+	if h.Index == nil && h.IndexPage == nil {
 		h.errMethodNotAllowed(w, r)
 		return
 	}
 
 	ctx := r.Context()
 
-	index := h.indexIter(ctx)
+	var index iter.Seq2[ENT, error]
+	if h.isIndexPageRequest(r) {
+		page, err := h.indexPage(w, r)
+		if err != nil {
+			h.getErrorHandler().HandleError(w, r, err)
+			return
+		}
+		index = h.scopedIndex(ctx, iterkit.SliceE(page.Entries))
+	} else {
+		index = h.scopedIndex(ctx, h.indexAll(ctx))
+	}
 
 	if len(h.Filters) != 0 {
 		index = iterkit.OnSeqEValue(index, func(i iter.Seq[ENT]) iter.Seq[ENT] {
@@ -333,6 +368,15 @@ func (h RESTHandler[ENT, ID]) index(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	if h.Count != nil && !h.isNarrowedIndex(ctx) {
+		total, err := h.Count(ctx)
+		if err != nil {
+			h.getErrorHandler().HandleError(w, r, err)
+			return
+		}
+		w.Header().Set(headerKeyTotalCount, strconv.Itoa(total))
+	}
+
 	c, responseMediaType := h.responseBodyCodec(r)
 	w.Header().Set(headerKeyContentType, responseMediaType)
 
@@ -344,6 +388,15 @@ func (h RESTHandler[ENT, ID]) index(w http.ResponseWriter, r *http.Request) {
 	h.indexReply(ctx, w, r, index, c)
 }
 
+// isNarrowedIndex reports whether the Index listing is narrowed down by the Filters or by the parent resource scope,
+// which neither Count nor IndexPage is aware of.
+func (h RESTHandler[ENT, ID]) isNarrowedIndex(ctx context.Context) bool {
+	if len(h.Filters) != 0 {
+		return true
+	}
+	return !h.ScopeAware && h.isSubResourceContext(ctx)
+}
+
 func (h RESTHandler[ENT, ID]) indexReply(ctx context.Context, w http.ResponseWriter, r *http.Request, index iter.Seq2[ENT, error], c codec.Codec) {
 	vs, err := iterkit.CollectE(index)
 	if err != nil {
@@ -405,8 +458,88 @@ func (h RESTHandler[ENT, ID]) indexStreamReply(ctx context.Context, w http.Respo
 	}
 }
 
-func (h RESTHandler[ENT, ID]) indexIter(ctx context.Context) iter.Seq2[ENT, error] {
-	index := h.Index(ctx)
+// DefaultIndexPageSize is the page size of the RESTHandler's IndexPage endpoint,
+// if the RESTHandler.IndexPageSize is not provided.
+var DefaultIndexPageSize int = 100
+
+func (h RESTHandler[ENT, ID]) getIndexPageSize() int {
+	if 0 < h.IndexPageSize {
+		return h.IndexPageSize
+	}
+	return DefaultIndexPageSize
+}
+
+func (h RESTHandler[ENT, ID]) isIndexPageRequest(r *http.Request) bool {
+	if h.IndexPage == nil || h.isNarrowedIndex(r.Context()) {
+		return false
+	}
+	if h.Index == nil {
+		return true
+	}
+	return r.URL.Query().Has(queryKeyCursor)
+}
+
+// indexAll lists every entity with Index,
+// or when Index is not supplied, by walking through every page of IndexPage.
+func (h RESTHandler[ENT, ID]) indexAll(ctx context.Context) iter.Seq2[ENT, error] {
+	if h.Index != nil {
+		return h.Index(ctx)
+	}
+	return func(yield func(ENT, error) bool) {
+		var cursor crud.Cursor
+		for {
+			page, err := h.IndexPage(ctx, cursor, h.getIndexPageSize())
+			if err != nil {
+				var zero ENT
+				yield(zero, err)
+				return
+			}
+			for _, ent := range page.Entries {
+				if !yield(ent, nil) {
+					return
+				}
+			}
+			if !page.HasNext() {
+				return
+			}
+			cursor = page.Next
+		}
+	}
+}
+
+// indexPage fetches the requested page with IndexPage,
+// and sets the Link header to the next page when there is one.
+func (h RESTHandler[ENT, ID]) indexPage(w http.ResponseWriter, r *http.Request) (crud.Page[ENT], error) {
+	var (
+		query  = r.URL.Query()
+		size   = h.getIndexPageSize()
+		cursor = crud.Cursor(query.Get(queryKeyCursor))
+	)
+	if raw := query.Get(queryKeyLimit); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return crud.Page[ENT]{}, ErrInvalidPageRequest.F("invalid %s query parameter: %q", queryKeyLimit, raw)
+		}
+		size = min(limit, size)
+	}
+	page, err := h.IndexPage(r.Context(), cursor, size)
+	if errors.Is(err, crud.ErrInvalidCursor) {
+		return crud.Page[ENT]{}, ErrInvalidPageRequest.Wrap(err)
+	}
+	if err != nil {
+		return crud.Page[ENT]{}, err
+	}
+	if page.HasNext() {
+		// the Link target is a relative reference, resolved against the request URL,
+		// so it stays correct regardless of where the RESTHandler is mounted.
+		query.Set(queryKeyCursor, string(page.Next))
+		query.Set(queryKeyLimit, strconv.Itoa(size))
+		w.Header().Set(headerKeyLink, fmt.Sprintf(`<?%s>; rel="next"`, query.Encode()))
+	}
+	return page, nil
+}
+
+func (h RESTHandler[ENT, ID]) scopedIndex(ctx context.Context, index iter.Seq2[ENT, error]) iter.Seq2[ENT, error] {
 	if _, ok := internal.ContextRESTParentResourceValuePointer.Lookup(ctx); ok {
 		index = iterkit.OnSeqEValue(index, func(i iter.Seq[ENT]) iter.Seq[ENT] {
 			return iterkit.Filter(i, func(v ENT) bool {
@@ -774,6 +907,12 @@ func RESTHandlerFromCRUD[ENT, ID any](repo crud.ByIDFinder[ENT, ID], conf ...fun
 	if repo, ok := repo.(crud.AllFinder[ENT]); ok && h.Index == nil {
 		h.Index = repo.FindAll // TODO: handle query
 	}
+	if repo, ok := repo.(crud.PageFinder[ENT]); ok && h.IndexPage == nil {
+		h.IndexPage = repo.FindPage
+	}
+	if repo, ok := repo.(crud.Counter); ok && h.Count == nil {
+		h.Count = repo.Count
+	}
 	if repo, ok := repo.(crud.Updater[ENT]); ok && h.Update == nil {
 		h.Update = repo.Update
 	}
@@ -802,6 +941,13 @@ func bodyReadAll(body io.ReadCloser, bodyReadLimit iokit.ByteSize) (_ []byte, re
 const (
 	headerKeyContentType = "Content-Type"
 	headerKeyAccept      = "Accept"
+	headerKeyLink        = "Link"
+	headerKeyTotalCount  = "X-Total-Count"
+)
+
+const (
+	queryKeyCursor = "cursor"
+	queryKeyLimit  = "limit"
 )
 
 func (h RESTHandler[ENT, ID]) getIDParser(rawID string) (ID, error) {
@@ -875,7 +1021,7 @@ func (h RESTHandler[ENT, ID]) RouteInfo() RouteInfo {
 	if h.Create != nil {
 		routes = append(routes, PathInfo{Method: http.MethodPost, Path: "/", Desc: "#Create"})
 	}
-	if h.Index != nil {
+	if h.Index != nil || h.IndexPage != nil {
 		routes = append(routes, PathInfo{Method: http.MethodGet, Path: "/", Desc: "#Index"})
 	}
 	if h.DestroyAll != nil {
@@ -336,15 +336,239 @@ func TestRESTHandler_ServeHTTP(t *testing.T) {
 				})
 			})
 
+			s.When("Count is set", func(s *testcase.Spec) {
+				GivenWeHaveStoredValue(s)
+				GivenWeHaveStoredValue(s)
+
+				s.Then("the total number of entities is in the X-Total-Count header", func(t *testcase.T) {
+					rr := act(t)
+					assert.Equal(t, http.StatusOK, rr.Code)
+					assert.Equal(t, "2", rr.Header().Get("X-Total-Count"))
+				})
+
+				s.Then("a page response also tells the total number of entities", func(t *testcase.T) {
+					path.Set(t, "/?cursor=&limit=1")
+					rr := act(t)
+					assert.Equal(t, http.StatusOK, rr.Code)
+					assert.Equal(t, 1, len(respondsWithJSON[[]X](t, rr)))
+					assert.Equal(t, "2", rr.Header().Get("X-Total-Count"))
+				})
+
+				s.And("Filters are defined", func(s *testcase.Spec) {
+					subject.Let(s, func(t *testcase.T) httpkit.RESTHandler[X, XID] {
+						h := subject.Super(t)
+						h.Filters = append(h.Filters, func(ctx context.Context, x X) bool { return false })
+						return h
+					})
+
+					s.Then("the X-Total-Count header is absent, since Count can't tell the total of the filtered listing", func(t *testcase.T) {
+						rr := act(t)
+						assert.Equal(t, http.StatusOK, rr.Code)
+						assert.Empty(t, respondsWithJSON[[]X](t, rr))
+						assert.Empty(t, rr.Header().Get("X-Total-Count"))
+					})
+				})
+
+				s.And("the handler is a subresource", func(s *testcase.Spec) {
+					Context.Let(s, func(t *testcase.T) context.Context {
+						return internal.ContextRESTParentResourceValuePointer.ContextWith(Context.Super(t), o.Get(t))
+					})
+
+					s.Then("the X-Total-Count header is absent, since Count is unaware of the parent scope", func(t *testcase.T) {
+						rr := act(t)
+						assert.Equal(t, http.StatusOK, rr.Code)
+						assert.Empty(t, rr.Header().Get("X-Total-Count"))
+					})
+
+					s.And("the handler is ScopeAware", func(s *testcase.Spec) {
+						subject.Let(s, func(t *testcase.T) httpkit.RESTHandler[X, XID] {
+							h := subject.Super(t)
+							h.ScopeAware = true
+							return h
+						})
+
+						s.Then("the total number of entities is in the X-Total-Count header", func(t *testcase.T) {
+							rr := act(t)
+							assert.Equal(t, http.StatusOK, rr.Code)
+							assert.Equal(t, "2", rr.Header().Get("X-Total-Count"))
+						})
+					})
+				})
+
+				s.And("counting fails", func(s *testcase.Spec) {
+					expectedErr := let.Error(s)
+
+					subject.Let(s, func(t *testcase.T) httpkit.RESTHandler[X, XID] {
+						h := subject.Super(t)
+						h.Count = func(ctx context.Context) (int, error) { return 0, expectedErr.Get(t) }
+						return h
+					})
+
+					s.Then("the error is propagated back", func(t *testcase.T) {
+						rr := act(t)
+						assert.Equal(t, http.StatusInternalServerError, rr.Code)
+						assert.Empty(t, rr.Header().Get("X-Total-Count"))
+					})
+				})
+			})
+
+			s.When("Count is not set", func(s *testcase.Spec) {
+				subject.Let(s, func(t *testcase.T) httpkit.RESTHandler[X, XID] {
+					h := subject.Super(t)
+					h.Count = nil
+					return h
+				})
+
+				s.Then("the X-Total-Count header is absent", func(t *testcase.T) {
+					assert.Empty(t, act(t).Header().Get("X-Total-Count"))
+				})
+			})
+
 			s.When("Index is not set", func(s *testcase.Spec) {
 				subject.Let(s, func(t *testcase.T) httpkit.RESTHandler[X, XID] {
 					rapi := subject.Super(t)
 					rapi.Index = nil
+					rapi.IndexPage = nil
 					return rapi
 				})
 
 				ThenNotAllowed(s)
 			})
+
+			s.When("IndexPage is set", func(s *testcase.Spec) {
+				ent1 := GivenWeHaveStoredValue(s)
+				ent2 := GivenWeHaveStoredValue(s)
+				ent3 := GivenWeHaveStoredValue(s)
+
+				subject.Let(s, func(t *testcase.T) httpkit.RESTHandler[X, XID] {
+					h := subject.Super(t)
+					h.IndexPage = mdb.Get(t).FindPage
+					return h
+				})
+
+				fetchAll := func(t *testcase.T) []X {
+					var got []X
+					for i := 0; ; i++ {
+						assert.True(t, i < 10, "too many pages")
+						rr := act(t)
+						assert.Equal(t, http.StatusOK, rr.Code)
+						got = append(got, respondsWithJSON[[]X](t, rr)...)
+						link := rr.Header().Get("Link")
+						if link == "" {
+							return got
+						}
+						assert.True(t, strings.HasSuffix(link, `>; rel="next"`))
+						next, err := url.Parse(strings.TrimPrefix(strings.TrimSuffix(link, `>; rel="next"`), "<"))
+						assert.NoError(t, err)
+						path.Set(t, (&url.URL{Path: "/"}).ResolveReference(next).String())
+					}
+				}
+
+				s.Then("without paging query parameters, the Index endpoint is used", func(t *testcase.T) {
+					rr := act(t)
+					assert.Equal(t, http.StatusOK, rr.Code)
+					assert.Empty(t, rr.Header().Get("Link"))
+					assert.ContainsExactly(t, []X{ent1.Get(t), ent2.Get(t), ent3.Get(t)}, respondsWithJSON[[]X](t, rr))
+				})
+
+				s.Then("the limit query parameter alone doesn't request a page from IndexPage", func(t *testcase.T) {
+					path.Set(t, "/?limit=1")
+					rr := act(t)
+					assert.Equal(t, http.StatusOK, rr.Code)
+					assert.Empty(t, rr.Header().Get("Link"))
+					assert.ContainsExactly(t, []X{ent1.Get(t), ent2.Get(t), ent3.Get(t)}, respondsWithJSON[[]X](t, rr))
+				})
+
+				s.Then("the cursor query parameter requests a page, and the Link header points to the next page", func(t *testcase.T) {
+					path.Set(t, "/?cursor=&limit=1")
+					rr := act(t)
+					assert.Equal(t, http.StatusOK, rr.Code)
+					assert.Equal(t, 1, len(respondsWithJSON[[]X](t, rr)))
+					assert.NotEmpty(t, rr.Header().Get("Link"))
+				})
+
+				s.Then("following the Link headers yields every entity", func(t *testcase.T) {
+					path.Set(t, "/?cursor=&limit=2")
+					assert.ContainsExactly(t, []X{ent1.Get(t), ent2.Get(t), ent3.Get(t)}, fetchAll(t))
+				})
+
+				s.Then("other query parameters are kept in the Link of the next page", func(t *testcase.T) {
+					path.Set(t, "/?cursor=&limit=1&foo=bar")
+					rr := act(t)
+					assert.Contains(t, rr.Header().Get("Link"), "foo=bar")
+				})
+
+				s.Then("an invalid limit is a bad request", func(t *testcase.T) {
+					path.Set(t, "/?cursor=&limit=zero")
+					rr := act(t)
+					assert.Equal(t, http.StatusBadRequest, rr.Code)
+					errDTO := respondsWithJSON[rfc7807.DTO](t, rr)
+					assert.Equal(t, httpkit.ErrInvalidPageRequest.Code.String(), errDTO.Type.ID)
+				})
+
+				s.Then("an invalid cursor is a bad request", func(t *testcase.T) {
+					path.Set(t, "/?cursor=!not-a-cursor!")
+					rr := act(t)
+					assert.Equal(t, http.StatusBadRequest, rr.Code)
+				})
+
+				s.And("Filters are defined", func(s *testcase.Spec) {
+					subject.Let(s, func(t *testcase.T) httpkit.RESTHandler[X, XID] {
+						h := subject.Super(t)
+						h.Filters = append(h.Filters, func(ctx context.Context, x X) bool {
+							return x.ID != ent1.Get(t).ID
+						})
+						return h
+					})
+
+					s.Then("the listing is not paginated, since the pages would come back short", func(t *testcase.T) {
+						path.Set(t, "/?cursor=&limit=1")
+						rr := act(t)
+						assert.Equal(t, http.StatusOK, rr.Code)
+						assert.Empty(t, rr.Header().Get("Link"))
+						assert.ContainsExactly(t, []X{ent2.Get(t), ent3.Get(t)}, respondsWithJSON[[]X](t, rr))
+					})
+				})
+
+				s.And("Index is not set", func(s *testcase.Spec) {
+					subject.Let(s, func(t *testcase.T) httpkit.RESTHandler[X, XID] {
+						h := subject.Super(t)
+						h.Index = nil
+						h.IndexPageSize = 2
+						return h
+					})
+
+					s.And("the handler is a subresource", func(s *testcase.Spec) {
+						Context.Let(s, func(t *testcase.T) context.Context {
+							return internal.ContextRESTParentResourceValuePointer.ContextWith(Context.Super(t), o.Get(t))
+						})
+
+						s.Then("every page of IndexPage is listed in a single response", func(t *testcase.T) {
+							rr := act(t)
+							assert.Equal(t, http.StatusOK, rr.Code)
+							assert.Empty(t, rr.Header().Get("Link"))
+							assert.ContainsExactly(t, []X{ent1.Get(t), ent2.Get(t), ent3.Get(t)}, respondsWithJSON[[]X](t, rr))
+						})
+					})
+
+					s.Then("the endpoint is paginated by default with the IndexPageSize", func(t *testcase.T) {
+						rr := act(t)
+						assert.Equal(t, http.StatusOK, rr.Code)
+						assert.Equal(t, 2, len(respondsWithJSON[[]X](t, rr)))
+						assert.NotEmpty(t, rr.Header().Get("Link"))
+					})
+
+					s.Then("the requested limit can't exceed the IndexPageSize", func(t *testcase.T) {
+						path.Set(t, "/?limit=100")
+						rr := act(t)
+						assert.Equal(t, 2, len(respondsWithJSON[[]X](t, rr)))
+					})
+
+					s.Then("following the Link headers yields every entity", func(t *testcase.T) {
+						assert.ContainsExactly(t, []X{ent1.Get(t), ent2.Get(t), ent3.Get(t)}, fetchAll(t))
+					})
+				})
+			})
 		})
 
 		s.Describe(`#create`, func(s *testcase.Spec) {
@@ -1439,6 +1663,72 @@ func TestRESTHandler_nestedOwnershipConstraint(t *testing.T) {
 	})
 }
 
+func TestRESTHandler_nestedTotalCount(t *testing.T) {
+	type User struct {
+		ID string
+	}
+	type Note struct {
+		ID     string
+		UserID string
+	}
+	var (
+		userRepo = &memory.Repository[User, string]{}
+		noteRepo = &memory.Repository[Note, string]{}
+	)
+	var (
+		noteResource = httpkit.RESTHandler[Note, string]{
+			Index: noteRepo.FindAll,
+			Show:  noteRepo.FindByID,
+			Count: noteRepo.Count,
+		}
+		userResource = httpkit.RESTHandler[User, string]{
+			Index: userRepo.FindAll,
+			Show:  userRepo.FindByID,
+			Count: userRepo.Count,
+
+			ResourceRoutes: httpkit.NewRouter(func(r *httpkit.Router) {
+				r.Resource("notes", noteResource)
+			}),
+		}
+		router = httpkit.NewRouter(func(r *httpkit.Router) {
+			r.Resource("users", userResource)
+		})
+	)
+
+	var ctx = context.Background()
+
+	t.Log("given we have two users")
+	user1 := User{}
+	crudtest.Create[User, string](t, userRepo, ctx, &user1)
+	user2 := User{}
+	crudtest.Create[User, string](t, userRepo, ctx, &user2)
+
+	t.Log("and the first user has one note, while the second has two")
+	note1 := Note{UserID: user1.ID}
+	crudtest.Create[Note, string](t, noteRepo, ctx, &note1)
+	for range 2 {
+		note := Note{UserID: user2.ID}
+		crudtest.Create[Note, string](t, noteRepo, ctx, &note)
+	}
+
+	t.Run("the top level resource tells its total", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/users", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "2", rr.Header().Get("X-Total-Count"))
+	})
+
+	t.Run("the nested resource doesn't tell the unscoped total of every note", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, pathkit.Join("users", user1.ID, "notes"), nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var got []Note
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+		assert.Equal(t, []Note{note1}, got)
+		assert.Empty(t, rr.Header().Get("X-Total-Count"))
+	})
+}
+
 func TestRESTHandler_idWithEscapedChars(tt *testing.T) {
 	t := testcase.NewT(tt)
 	exp := randomPathPart(t)
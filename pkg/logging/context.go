@@ -47,3 +47,16 @@ func lookupValue(ctx context.Context) (*ctxValue, bool) {
 	}
 	return nil, false
 }
+
+// ContextFields evaluates the logging details of the context into Fields.
+// The keys are formatted with the default key format of the Logger.
+func ContextFields(ctx context.Context) Fields {
+	var (
+		l = &Logger{}
+		e = make(entry)
+	)
+	for _, d := range getLoggingDetailsFromContext(ctx) {
+		d.addTo(ctx, l, e)
+	}
+	return Fields(e)
+}
@@ -64,3 +64,15 @@ func TestContextWith(t *testing.T) {
 		assert.Contains(t, buf.String(), `"bar":42`)
 	})
 }
+
+func TestContextFields(t *testing.T) {
+	t.Run("without details, no field is returned", func(t *testing.T) {
+		assert.Empty(t, logging.ContextFields(context.Background()))
+	})
+
+	t.Run("the details of the context are evaluated into fields", func(t *testing.T) {
+		ctx := logging.ContextWith(context.Background(), logging.Fields{"foo": "bar"})
+		ctx = logging.ContextWith(ctx, logging.Field("userID", 42))
+		assert.Equal(t, logging.Fields{"foo": "bar", "user_id": 42}, logging.ContextFields(ctx))
+	})
+}
@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"go.llib.dev/frameless/port/pubsub"
+)
+
+// PubsubHeaderKey is the message header in which the logging details of a context travel across a pubsub queue.
+const PubsubHeaderKey = "logging-details"
+
+// PubsubPropagator is a pubsub.ContextPropagator for the logging details of a context.
+// The details of the publisher's context are evaluated into fields at the time of the publishing,
+// and the subscriber receives them as Fields in the context of the message.
+//
+// The PubsubPropagator is registered by default, see DisablePubsubPropagation to opt out.
+type PubsubPropagator struct{}
+
+var _ pubsub.ContextPropagator = PubsubPropagator{}
+
+func (PubsubPropagator) Inject(ctx context.Context, headers map[string]string) {
+	fields := ContextFields(ctx)
+	if len(fields) == 0 {
+		return
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return
+	}
+	headers[PubsubHeaderKey] = string(data)
+}
+
+func (PubsubPropagator) Extract(ctx context.Context, headers map[string]string) context.Context {
+	data, ok := headers[PubsubHeaderKey]
+	if !ok {
+		return ctx
+	}
+	var fields Fields
+	if err := json.Unmarshal([]byte(data), &fields); err != nil {
+		return ctx
+	}
+	return ContextWith(ctx, fields)
+}
+
+var pubsubPropagation = struct {
+	m          sync.Mutex
+	unregister func()
+}{unregister: pubsub.RegisterContextPropagator(PubsubPropagator{})}
+
+// DisablePubsubPropagation stops the logging details from travelling across the pubsub queues.
+// The logging details often hold personal or otherwise sensitive data,
+// which is stored in the message headers of every published message.
+// Call it from your main, when your logging details must not leave the process.
+// It returns a function that enables the propagation again.
+func DisablePubsubPropagation() func() {
+	pubsubPropagation.m.Lock()
+	defer pubsubPropagation.m.Unlock()
+	if pubsubPropagation.unregister != nil {
+		pubsubPropagation.unregister()
+		pubsubPropagation.unregister = nil
+	}
+	return func() {
+		pubsubPropagation.m.Lock()
+		defer pubsubPropagation.m.Unlock()
+		if pubsubPropagation.unregister == nil {
+			pubsubPropagation.unregister = pubsub.RegisterContextPropagator(PubsubPropagator{})
+		}
+	}
+}
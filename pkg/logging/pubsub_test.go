@@ -0,0 +1,64 @@
+package logging_test
+
+import (
+	"context"
+	"testing"
+
+	"go.llib.dev/frameless/adapter/memory"
+	"go.llib.dev/frameless/pkg/logging"
+	"go.llib.dev/frameless/port/pubsub"
+	"go.llib.dev/testcase/assert"
+)
+
+func TestPubsubPropagator(t *testing.T) {
+	t.Run("logging details of the publisher travel to the received message", func(t *testing.T) {
+		l, buf := logging.Stub(t)
+		q := &memory.Queue[string]{}
+
+		ctx := logging.ContextWith(context.Background(), logging.Fields{"foo": "bar", "bar": 42})
+		ctx = logging.ContextWith(ctx, logging.Field("baz", "qux"))
+		assert.NoError(t, q.Publish(ctx, "data"))
+
+		subCTX, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		for msg, err := range q.Subscribe(subCTX) {
+			assert.NoError(t, err)
+			l.Info(msg.Context(), "received")
+			assert.NoError(t, msg.ACK())
+			break
+		}
+
+		assert.Contains(t, buf.String(), `"message":"received"`)
+		assert.Contains(t, buf.String(), `"foo":"bar"`)
+		assert.Contains(t, buf.String(), `"bar":42`)
+		assert.Contains(t, buf.String(), `"baz":"qux"`)
+	})
+
+	t.Run("logging details are published as a message header", func(t *testing.T) {
+		ctx := logging.ContextWith(context.Background(), logging.Fields{"foo": "bar"})
+		headers := pubsub.MakeHeaders(ctx)
+		assert.Equal(t, `{"foo":"bar"}`, headers[logging.PubsubHeaderKey])
+	})
+
+	t.Run("without logging details, no header is published", func(t *testing.T) {
+		headers := pubsub.MakeHeaders(context.Background())
+		_, ok := headers[logging.PubsubHeaderKey]
+		assert.False(t, ok)
+	})
+
+	t.Run("when the propagation is disabled, the logging details are not published", func(t *testing.T) {
+		t.Cleanup(logging.DisablePubsubPropagation())
+		ctx := logging.ContextWith(context.Background(), logging.Fields{"foo": "bar"})
+		_, ok := pubsub.MakeHeaders(ctx)[logging.PubsubHeaderKey]
+		assert.False(t, ok)
+	})
+
+	t.Run("disabling the propagation more than once is safe", func(t *testing.T) {
+		enable := logging.DisablePubsubPropagation()
+		logging.DisablePubsubPropagation()
+		enable()
+		enable()
+		ctx := logging.ContextWith(context.Background(), logging.Fields{"foo": "bar"})
+		assert.Equal(t, `{"foo":"bar"}`, pubsub.MakeHeaders(ctx)[logging.PubsubHeaderKey])
+	})
+}
@@ -0,0 +1,205 @@
+// Package outbox implements the transactional outbox pattern on top of the pubsub port.
+//
+// Publishing to a message broker within a database transaction is unsafe,
+// when the broker is not the same resource as the database.
+// The transaction might roll back after the message is already published,
+// or the publishing might fail after the transaction is already committed.
+//
+// The Outbox solves this by storing the message in a Repository, within the caller's transaction,
+// and the Relay delivers the stored messages to the downstream Publisher, once they are committed.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"go.llib.dev/frameless/pkg/contextkit"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/logger"
+	"go.llib.dev/frameless/pkg/logging"
+	"go.llib.dev/frameless/pkg/tasker"
+	"go.llib.dev/frameless/pkg/uuid"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/crudquery"
+	"go.llib.dev/frameless/port/pubsub"
+	"go.llib.dev/testcase/clock"
+)
+
+// Record is a message stored in the outbox, waiting to be relayed.
+type Record[Data any] struct {
+	// ID is the dedupe key of the message.
+	// When a message is relayed more than once, each delivery carries the same dedupe key.
+	ID        RecordID `ext:"id"`
+	Data      Data
+	CreatedAt time.Time
+}
+
+type RecordID string
+
+// Repository is the storage of the outbox records.
+//
+// To make the Outbox transactional, the Repository must take part in the transaction of the caller's context.
+// For example, it should use the same database connection as the rest of the domain repositories.
+//
+// When the Repository implements crud.Querier, the Relay fetches the pending records in batches,
+// ordered by their CreatedAt and ID fields.
+// Otherwise every pending record is loaded with FindAll in each relay round.
+type Repository[Data any] interface {
+	crud.Creator[Record[Data]]
+	crud.AllFinder[Record[Data]]
+	crud.ByIDDeleter[RecordID]
+}
+
+// Outbox is a pubsub.Publisher that stores the published messages in its Repository,
+// within the transaction of the given context.
+// The stored messages are delivered by the Relay.
+type Outbox[Data any] struct {
+	Repository Repository[Data]
+	// DedupeKey [optional] tells the dedupe key of the published data.
+	// Publishing a data, while another data with the same dedupe key is still pending in the outbox, is a no-op.
+	//
+	// By default, every published message receives a unique dedupe key.
+	DedupeKey func(Data) string
+}
+
+var _ pubsub.Publisher[any] = Outbox[any]{}
+
+func (o Outbox[Data]) Publish(ctx context.Context, data Data) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	rec := Record[Data]{
+		Data:      data,
+		CreatedAt: clock.Now().UTC(),
+	}
+	if o.DedupeKey != nil {
+		rec.ID = RecordID(o.DedupeKey(data))
+	}
+	if rec.ID == "" {
+		id, err := uuid.MakeV7()
+		if err != nil {
+			return err
+		}
+		rec.ID = RecordID(id.String())
+	}
+	err := o.Repository.Create(ctx, &rec)
+	if errors.Is(err, crud.ErrAlreadyExists) {
+		return nil
+	}
+	return err
+}
+
+// DedupeKey holds the dedupe key of a relayed message in its publishing context.
+// A downstream Publisher can use it to recognise a message that is relayed again.
+var DedupeKey contextkit.ValueHandler[ctxKeyDedupeKey, RecordID]
+
+type ctxKeyDedupeKey struct{}
+
+// Relay delivers the pending messages of an outbox Repository to a downstream Publisher.
+//
+// The delivery has at-least-once semantics.
+// A record is only removed from the Repository after the downstream Publisher accepted it,
+// so a failure in between causes the message to be relayed again.
+// Running multiple Relay on the same Repository also can cause duplicate deliveries,
+// use tasker.WithNoOverlap to avoid it.
+type Relay[Data any] struct {
+	Repository Repository[Data]
+	Downstream pubsub.Publisher[Data]
+	// Interval [optional] is the time between two relay rounds when the Relay is Run.
+	//
+	// default: 1 second
+	Interval tasker.Interval
+	// BatchSize [optional] is the maximum number of records that Drain fetches at once,
+	// when the Repository implements crud.Querier.
+	//
+	// default: 100
+	BatchSize int
+}
+
+// Drain relays every pending record to the Downstream in the order of their creation.
+// The first failure stops the draining, to keep the ordering of the messages.
+func (r Relay[Data]) Drain(ctx context.Context) error {
+	querier, ok := r.Repository.(crud.Querier[Record[Data]])
+	if !ok {
+		return r.drainAll(ctx)
+	}
+	q := crudquery.Query{}.Asc("CreatedAt").Asc("ID").WithLimit(r.getBatchSize())
+	for {
+		// the relayed records are deleted, so the next batch starts from the beginning again
+		recs, err := iterkit.CollectE(querier.Query(ctx, q))
+		if err != nil {
+			return err
+		}
+		for _, rec := range recs {
+			if err := r.relay(ctx, rec); err != nil {
+				return err
+			}
+		}
+		if len(recs) < q.Limit {
+			return nil
+		}
+	}
+}
+
+func (r Relay[Data]) drainAll(ctx context.Context) error {
+	recs, err := iterkit.CollectE(r.Repository.FindAll(ctx))
+	if err != nil {
+		return err
+	}
+	slices.SortStableFunc(recs, func(a, b Record[Data]) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		if a.ID < b.ID {
+			return -1
+		}
+		if a.ID > b.ID {
+			return 1
+		}
+		return 0
+	})
+	for _, rec := range recs {
+		if err := r.relay(ctx, rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r Relay[Data]) relay(ctx context.Context, rec Record[Data]) error {
+	if err := r.Downstream.Publish(DedupeKey.ContextWith(ctx, rec.ID), rec.Data); err != nil {
+		return err
+	}
+	err := r.Repository.DeleteByID(ctx, rec.ID)
+	if errors.Is(err, crud.ErrNotFound) { // already relayed by someone else
+		return nil
+	}
+	return err
+}
+
+// Run implements tasker.Runnable, and drains the outbox repeatedly, until the context is cancelled.
+// A failed relay round doesn't stop the Relay, the pending messages are retried in the next round.
+func (r Relay[Data]) Run(ctx context.Context) error {
+	return tasker.WithRepeat(r.getInterval(), func(ctx context.Context) error {
+		if err := r.Drain(ctx); err != nil && ctx.Err() == nil {
+			logger.Warn(ctx, "outbox Relay failed to drain the outbox", logging.ErrField(err))
+		}
+		return nil
+	})(ctx)
+}
+
+func (r Relay[Data]) getBatchSize() int {
+	if r.BatchSize <= 0 {
+		return 100
+	}
+	return r.BatchSize
+}
+
+func (r Relay[Data]) getInterval() tasker.Interval {
+	if r.Interval == nil {
+		return tasker.Every(time.Second)
+	}
+	return r.Interval
+}
@@ -0,0 +1,118 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"testing"
+	"time"
+
+	"go.llib.dev/frameless/adapter/memory"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/outbox"
+	"go.llib.dev/frameless/pkg/tasker"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/crudquery"
+	"go.llib.dev/frameless/port/pubsub"
+	"go.llib.dev/frameless/port/pubsub/pubsubtest"
+	"go.llib.dev/frameless/testing/testent"
+	"go.llib.dev/testcase/assert"
+)
+
+var _ pubsub.Publisher[testent.Foo] = outbox.Outbox[testent.Foo]{}
+
+func TestRelay_Run(t *testing.T) {
+	var (
+		ctx        = context.Background()
+		m          = memory.NewMemory()
+		repo       = memory.NewOutboxRepository[testent.Foo](m)
+		downstream = &memory.Queue[testent.Foo]{}
+		sub        = pubsubtest.Subscribe[testent.Foo](t, downstream, ctx)
+		ob         = outbox.Outbox[testent.Foo]{Repository: repo}
+		relay      = outbox.Relay[testent.Foo]{
+			Repository: repo,
+			Downstream: downstream,
+			Interval:   tasker.Every(time.Millisecond),
+		}
+	)
+
+	rctx, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() { done <- relay.Run(rctx) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+
+	foo1 := testent.MakeFoo(t)
+	assert.NoError(t, ob.Publish(ctx, foo1))
+
+	tx, err := m.BeginTx(ctx)
+	assert.NoError(t, err)
+	foo2 := testent.MakeFoo(t)
+	assert.NoError(t, ob.Publish(tx, foo2))
+
+	sub.Eventually(t, func(tb testing.TB, got []testent.Foo) {
+		assert.Equal(tb, []testent.Foo{foo1}, got)
+	})
+
+	assert.NoError(t, m.CommitTx(tx))
+	sub.Eventually(t, func(tb testing.TB, got []testent.Foo) {
+		assert.Equal(tb, []testent.Foo{foo1, foo2}, got)
+	})
+}
+
+type queryOnlyRepository[Data any] struct {
+	outbox.Repository[Data]
+	Querier crud.Querier[outbox.Record[Data]]
+	Limits  []int
+}
+
+func (r *queryOnlyRepository[Data]) FindAll(ctx context.Context) iter.Seq2[outbox.Record[Data], error] {
+	return iterkit.Error[outbox.Record[Data]](errors.New("FindAll is not expected"))
+}
+
+func (r *queryOnlyRepository[Data]) Query(ctx context.Context, q crudquery.Query) iter.Seq2[outbox.Record[Data], error] {
+	r.Limits = append(r.Limits, q.Limit)
+	return r.Querier.Query(ctx, q)
+}
+
+func TestRelay_Drain_batches(t *testing.T) {
+	var (
+		ctx        = context.Background()
+		m          = memory.NewMemory()
+		repo       = memory.NewOutboxRepository[testent.Foo](m)
+		subject    = &queryOnlyRepository[testent.Foo]{Repository: repo, Querier: repo}
+		downstream = &memory.Queue[testent.Foo]{}
+		sub        = pubsubtest.Subscribe[testent.Foo](t, downstream, ctx)
+		ob         = outbox.Outbox[testent.Foo]{Repository: subject}
+		relay      = outbox.Relay[testent.Foo]{
+			Repository: subject,
+			Downstream: downstream,
+			BatchSize:  2,
+		}
+	)
+
+	var exp []testent.Foo
+	for range 5 {
+		foo := testent.MakeFoo(t)
+		exp = append(exp, foo)
+		assert.NoError(t, ob.Publish(ctx, foo))
+	}
+
+	assert.NoError(t, relay.Drain(ctx))
+	assert.Equal(t, []int{2, 2, 2}, subject.Limits)
+	sub.Eventually(t, func(tb testing.TB, got []testent.Foo) {
+		assert.Equal(tb, exp, got)
+	})
+}
+
+func TestDedupeKey(t *testing.T) {
+	ctx := context.Background()
+	_, ok := outbox.DedupeKey.Lookup(ctx)
+	assert.False(t, ok)
+
+	key, ok := outbox.DedupeKey.Lookup(outbox.DedupeKey.ContextWith(ctx, "42"))
+	assert.True(t, ok)
+	assert.Equal(t, outbox.RecordID("42"), key)
+}
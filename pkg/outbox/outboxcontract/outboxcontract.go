@@ -0,0 +1,224 @@
+package outboxcontract
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"go.llib.dev/frameless/internal/spechelper"
+	"go.llib.dev/frameless/pkg/outbox"
+	"go.llib.dev/frameless/pkg/reflectkit"
+	"go.llib.dev/frameless/pkg/slicekit"
+	"go.llib.dev/frameless/port/comproto"
+	"go.llib.dev/frameless/port/contract"
+	"go.llib.dev/frameless/port/option"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/let"
+)
+
+type Option[Data any] option.Option[Config[Data]]
+
+type Config[Data any] struct {
+	MakeContext func(testing.TB) context.Context
+	MakeData    func(testing.TB) Data
+	// OnePhaseCommit [optional] is the transaction manager, which transactions the Repository takes part in.
+	OnePhaseCommit comproto.OnePhaseCommitProtocol
+}
+
+func (c *Config[Data]) Init() {
+	c.MakeContext = func(testing.TB) context.Context {
+		return context.Background()
+	}
+	c.MakeData = spechelper.MakeValue[Data]
+}
+
+func (c Config[Data]) Configure(t *Config[Data]) {
+	*t = reflectkit.MergeStruct(*t, c)
+}
+
+// Repository verifies that the subject can be used as the storage of an outbox.Outbox,
+// and its records are delivered by the outbox.Relay.
+func Repository[Data any](subject outbox.Repository[Data], opts ...Option[Data]) contract.Contract {
+	c := option.ToConfig[Config[Data]](opts)
+	s := testcase.NewSpec(nil)
+	s.HasSideEffect()
+
+	var (
+		ctx        = let.With[context.Context](s, c.MakeContext)
+		downstream = let.Var(s, func(t *testcase.T) *publisher[Data] {
+			return &publisher[Data]{}
+		})
+		relay = let.Var(s, func(t *testcase.T) outbox.Relay[Data] {
+			return outbox.Relay[Data]{
+				Repository: subject,
+				Downstream: downstream.Get(t),
+			}
+		})
+		ob = let.Var(s, func(t *testcase.T) outbox.Outbox[Data] {
+			return outbox.Outbox[Data]{Repository: subject}
+		})
+	)
+
+	s.Before(func(t *testcase.T) {
+		// drain the leftovers of other tests
+		assert.NoError(t, outbox.Relay[Data]{Repository: subject, Downstream: &publisher[Data]{}}.Drain(ctx.Get(t)))
+	})
+
+	s.Test("a published message is relayed to the downstream publisher", func(t *testcase.T) {
+		data := c.MakeData(t)
+		assert.NoError(t, ob.Get(t).Publish(ctx.Get(t), data))
+		assert.Empty(t, downstream.Get(t).Values(), "message should not be published before the relay")
+
+		assert.NoError(t, relay.Get(t).Drain(ctx.Get(t)))
+		assert.Equal(t, []Data{data}, downstream.Get(t).Values())
+	})
+
+	s.Test("a relayed message is removed from the outbox", func(t *testcase.T) {
+		assert.NoError(t, ob.Get(t).Publish(ctx.Get(t), c.MakeData(t)))
+		assert.NoError(t, relay.Get(t).Drain(ctx.Get(t)))
+		assert.NoError(t, relay.Get(t).Drain(ctx.Get(t)))
+		assert.Equal(t, 1, len(downstream.Get(t).Values()))
+	})
+
+	s.Test("messages are relayed in the order of their publishing", func(t *testcase.T) {
+		var exp []Data
+		t.Random.Repeat(2, 5, func() {
+			data := c.MakeData(t)
+			exp = append(exp, data)
+			assert.NoError(t, ob.Get(t).Publish(ctx.Get(t), data))
+		})
+		assert.NoError(t, relay.Get(t).Drain(ctx.Get(t)))
+		assert.Equal(t, exp, downstream.Get(t).Values())
+	})
+
+	s.Test("messages beyond a single batch are relayed as well, in the order of their publishing", func(t *testcase.T) {
+		var exp []Data
+		t.Random.Repeat(3, 7, func() {
+			data := c.MakeData(t)
+			exp = append(exp, data)
+			assert.NoError(t, ob.Get(t).Publish(ctx.Get(t), data))
+		})
+		r := relay.Get(t)
+		r.BatchSize = 2
+		assert.NoError(t, r.Drain(ctx.Get(t)))
+		assert.Equal(t, exp, downstream.Get(t).Values())
+	})
+
+	s.Test("a message that failed to be relayed, is relayed again with the same dedupe key", func(t *testcase.T) {
+		data := c.MakeData(t)
+		assert.NoError(t, ob.Get(t).Publish(ctx.Get(t), data))
+
+		expErr := t.Random.Error()
+		downstream.Get(t).Err = expErr
+		assert.ErrorIs(t, relay.Get(t).Drain(ctx.Get(t)), expErr)
+
+		downstream.Get(t).Err = nil
+		assert.NoError(t, relay.Get(t).Drain(ctx.Get(t)))
+		assert.Equal(t, []Data{data, data}, downstream.Get(t).Values())
+
+		keys := downstream.Get(t).Keys()
+		assert.Equal(t, 2, len(keys))
+		assert.NotEmpty(t, keys[0])
+		assert.Equal(t, keys[0], keys[1])
+	})
+
+	s.Test("relayed messages have a unique dedupe key", func(t *testcase.T) {
+		t.Random.Repeat(2, 5, func() {
+			assert.NoError(t, ob.Get(t).Publish(ctx.Get(t), c.MakeData(t)))
+		})
+		assert.NoError(t, relay.Get(t).Drain(ctx.Get(t)))
+		keys := downstream.Get(t).Keys()
+		assert.Equal(t, len(keys), len(slicekit.Unique(keys)))
+	})
+
+	s.Test("publishing with a still pending dedupe key is a no-op", func(t *testcase.T) {
+		key := t.Random.UUID()
+		o := ob.Get(t)
+		o.DedupeKey = func(Data) string { return key }
+		data := c.MakeData(t)
+		assert.NoError(t, o.Publish(ctx.Get(t), data))
+		assert.NoError(t, o.Publish(ctx.Get(t), c.MakeData(t)))
+
+		assert.NoError(t, relay.Get(t).Drain(ctx.Get(t)))
+		assert.Equal(t, []Data{data}, downstream.Get(t).Values())
+		assert.Equal(t, []outbox.RecordID{outbox.RecordID(key)}, downstream.Get(t).Keys())
+	})
+
+	s.Test("canceled context yields the context error", func(t *testcase.T) {
+		cctx, cancel := context.WithCancel(ctx.Get(t))
+		cancel()
+		assert.ErrorIs(t, ob.Get(t).Publish(cctx, c.MakeData(t)), context.Canceled)
+	})
+
+	s.Context("transaction", func(s *testcase.Spec) {
+		s.Before(func(t *testcase.T) {
+			if c.OnePhaseCommit == nil {
+				t.Skip("Config.OnePhaseCommit is not supplied")
+			}
+		})
+
+		s.Test("a message published within a committed transaction is relayed", func(t *testcase.T) {
+			tx, err := c.OnePhaseCommit.BeginTx(ctx.Get(t))
+			assert.NoError(t, err)
+			data := c.MakeData(t)
+			assert.NoError(t, ob.Get(t).Publish(tx, data))
+			assert.NoError(t, c.OnePhaseCommit.CommitTx(tx))
+
+			assert.NoError(t, relay.Get(t).Drain(ctx.Get(t)))
+			assert.Equal(t, []Data{data}, downstream.Get(t).Values())
+		})
+
+		s.Test("a message published within a rolled back transaction is never relayed", func(t *testcase.T) {
+			tx, err := c.OnePhaseCommit.BeginTx(ctx.Get(t))
+			assert.NoError(t, err)
+			assert.NoError(t, ob.Get(t).Publish(tx, c.MakeData(t)))
+			assert.NoError(t, c.OnePhaseCommit.RollbackTx(tx))
+
+			assert.NoError(t, relay.Get(t).Drain(ctx.Get(t)))
+			assert.Empty(t, downstream.Get(t).Values())
+		})
+
+		s.Test("a message published within an ongoing transaction is not relayed", func(t *testcase.T) {
+			tx, err := c.OnePhaseCommit.BeginTx(ctx.Get(t))
+			assert.NoError(t, err)
+			defer func() { _ = c.OnePhaseCommit.RollbackTx(tx) }()
+			assert.NoError(t, ob.Get(t).Publish(tx, c.MakeData(t)))
+
+			assert.NoError(t, relay.Get(t).Drain(ctx.Get(t)))
+			assert.Empty(t, downstream.Get(t).Values())
+		})
+	})
+
+	return s.AsSuite("OutboxRepository")
+}
+
+// publisher is a downstream pubsub.Publisher that records the relayed messages.
+type publisher[Data any] struct {
+	Err error
+
+	mutex  sync.Mutex
+	values []Data
+	keys   []outbox.RecordID
+}
+
+func (p *publisher[Data]) Publish(ctx context.Context, data Data) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	key, _ := outbox.DedupeKey.Lookup(ctx)
+	p.values = append(p.values, data)
+	p.keys = append(p.keys, key)
+	return p.Err
+}
+
+func (p *publisher[Data]) Values() []Data {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]Data{}, p.values...)
+}
+
+func (p *publisher[Data]) Keys() []outbox.RecordID {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]outbox.RecordID{}, p.keys...)
+}
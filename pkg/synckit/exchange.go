@@ -3,17 +3,20 @@ package synckit
 import (
 	"context"
 	"io"
+	"maps"
 	"sync"
 	"sync/atomic"
 
 	"go.llib.dev/frameless/pkg/slicekit"
+	"go.llib.dev/frameless/pkg/uuid"
 	"go.llib.dev/frameless/port/ds"
 	"go.llib.dev/frameless/port/pubsub"
+	"go.llib.dev/testcase/clock"
 )
 
 type Fan[T any] struct {
 	exchangeBase[T]
-	channel chan T
+	channel chan exchangeItem[T]
 
 	o sync.Once
 }
@@ -25,14 +28,16 @@ var _ io.Closer = (*Fan[struct{}])(nil)
 
 func (fan *Fan[T]) init() {
 	fan.o.Do(func() {
-		fan.channel = make(chan T)
+		fan.channel = make(chan exchangeItem[T])
 		fan.exchangeBase.init()
 	})
 }
 
+// Publish will publish the data to one of the subscribers.
+// The received message implements pubsub.MetadataProvider.
 func (fan *Fan[T]) Publish(ctx context.Context, v T) error {
 	fan.init()
-	return fan.exchangeBase.publish(ctx, fan.channel, v)
+	return fan.exchangeBase.publish(ctx, fan.channel, makeExchangeItem(ctx, v))
 }
 
 func (fan *Fan[T]) Subscribe(ctx context.Context) pubsub.Subscription[T] {
@@ -46,16 +51,20 @@ type Broadcast[T any] struct {
 	exchangeBase[T]
 
 	m sync.RWMutex
-	s map[int]chan T
+	s map[int]chan exchangeItem[T]
 }
 
 var _ pubsub.Publisher[string] = (*Broadcast[string])(nil)
 
+// Publish will publish the data to every subscriber.
+// The received messages implement pubsub.MetadataProvider,
+// and the copies of the message share the same message ID.
 func (b *Broadcast[T]) Publish(ctx context.Context, data T) error {
 	b.m.RLock()
 	defer b.m.RUnlock()
+	item := makeExchangeItem(ctx, data)
 	for _, ch := range b.s {
-		if err := b.exchangeBase.publish(ctx, ch, data); err != nil {
+		if err := b.exchangeBase.publish(ctx, ch, item); err != nil {
 			return err
 		}
 	}
@@ -66,7 +75,7 @@ var _ pubsub.Subscriber[string] = (*Broadcast[string])(nil)
 
 func (b *Broadcast[T]) Subscribe(ctx context.Context) pubsub.Subscription[T] {
 	return func(yield func(pubsub.Message[T], error) bool) {
-		var ch = make(chan T)
+		var ch = make(chan exchangeItem[T])
 		defer close(ch)
 		defer register(&b.m, &b.s, ch)()
 		for v, err := range b.exchangeBase.subscribe(ctx, ch) {
@@ -87,16 +96,43 @@ type exchangeBase[T any] struct {
 	cancels map[int]func()
 	closed  chan struct{}
 
-	buffer []T
+	buffer []exchangeItem[T]
 }
 
-func (ex *exchangeBase[T]) bufferShift() (T, bool) {
-	ex.mutex.RLock()
-	if len(ex.buffer) == 0 {
-		ex.mutex.RUnlock()
-		var zero T
-		return zero, false
+// exchangeItem is a published message along with its metadata.
+type exchangeItem[T any] struct {
+	data T
+	// meta is the metadata of the message, where the DeliveryCount is the count of the previous deliveries.
+	meta pubsub.Metadata
+}
+
+func makeExchangeItem[T any](ctx context.Context, data T) exchangeItem[T] {
+	return exchangeItem[T]{
+		data: data,
+		meta: pubsub.Metadata{
+			ID:          uuid.Must(uuid.MakeV7).String(),
+			Headers:     pubsub.MakeHeaders(ctx),
+			PublishedAt: clock.Now(),
+		},
 	}
+}
+
+// exchangeMessage is a received message of an exchange.
+type exchangeMessage[T any] struct {
+	pubsub.Message[T]
+	meta pubsub.Metadata
+}
+
+var _ pubsub.MetadataProvider = exchangeMessage[any]{}
+
+// Metadata implements the pubsub.MetadataProvider.
+func (msg exchangeMessage[T]) Metadata() pubsub.Metadata {
+	meta := msg.meta
+	meta.Headers = maps.Clone(meta.Headers)
+	return meta
+}
+
+func (ex *exchangeBase[T]) bufferShift() (exchangeItem[T], bool) {
 	ex.mutex.Lock()
 	defer ex.mutex.Unlock()
 	return slicekit.Shift(&ex.buffer)
@@ -109,7 +145,7 @@ func (ex *exchangeBase[T]) init() {
 	})
 }
 
-func (ex *exchangeBase[T]) publish(ctx context.Context, ch chan<- T, data T) error {
+func (ex *exchangeBase[T]) publish(ctx context.Context, ch chan<- exchangeItem[T], item exchangeItem[T]) error {
 	ex.init()
 	if ctx == nil {
 		ctx = context.Background()
@@ -122,14 +158,14 @@ func (ex *exchangeBase[T]) publish(ctx context.Context, ch chan<- T, data T) err
 		return ctx.Err()
 	case <-ex.closed:
 		return context.Canceled
-	case ch <- data:
+	case ch <- item:
 	}
 	return nil
 }
 
 var _ pubsub.Subscriber[struct{}] = (*Fan[struct{}])(nil)
 
-func (ex *exchangeBase[T]) subscribe(ctx context.Context, ch chan T) pubsub.Subscription[T] {
+func (ex *exchangeBase[T]) subscribe(ctx context.Context, ch chan exchangeItem[T]) pubsub.Subscription[T] {
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -137,10 +173,15 @@ func (ex *exchangeBase[T]) subscribe(ctx context.Context, ch chan T) pubsub.Subs
 		ex.init()
 		atomic.AddInt64(&ex.len, 1)
 		defer atomic.AddInt64(&ex.len, -1)
-		var handle = func(ctx context.Context, data T) bool {
+		var handle = func(ctx context.Context, item exchangeItem[T]) bool {
 			ctx, cancel := context.WithCancel(ctx)
 			defer ex.regCancel(cancel)()
-			var msg = pubsub.MakeMessage(ctx, data, ex.ack, ex.defaultNack(ch))
+			item.meta.DeliveryCount++
+			ctx = pubsub.ExtractContext(ctx, item.meta.Headers)
+			var msg = exchangeMessage[T]{
+				Message: pubsub.MakeMessage(ctx, item.data, ex.ack, ex.defaultNack(ch, item)),
+				meta:    item.meta,
+			}
 			defer msg.NACK()
 			return yield(msg, nil)
 		}
@@ -172,16 +213,16 @@ func (ex *exchangeBase[T]) ack(pubsub.Message[T]) error {
 	return nil
 }
 
-func (ex *exchangeBase[T]) defaultNack(ch chan T) func(msg pubsub.Message[T]) error {
+func (ex *exchangeBase[T]) defaultNack(ch chan exchangeItem[T], item exchangeItem[T]) func(msg pubsub.Message[T]) error {
 	return func(msg pubsub.Message[T]) error {
 		select {
 		case <-msg.Context().Done():
 			return msg.Context().Err()
-		case ch <- msg.Data():
+		case ch <- item:
 			return nil
 		default:
 			ex.mutex.Lock()
-			ex.buffer = append(ex.buffer, msg.Data())
+			ex.buffer = append(ex.buffer, item)
 			ex.mutex.Unlock()
 			return nil
 		}
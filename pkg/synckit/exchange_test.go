@@ -15,6 +15,8 @@ import (
 	"go.llib.dev/frameless/port/pubsub/pubsubtest"
 	"go.llib.dev/testcase"
 	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/clock"
+	"go.llib.dev/testcase/clock/timecop"
 	"go.llib.dev/testcase/let"
 	"go.llib.dev/testcase/random"
 )
@@ -621,6 +623,64 @@ func TestFan(t *testing.T) {
 		})
 	})
 
+	s.Test("a NACK-ed message is redelivered to the only subscriber", func(t *testcase.T) {
+		f := subject.Get(t)
+		val := t.Random.UUID()
+		received := make(chan string, 2)
+		job := t.Go(func(ctx context.Context) {
+			var n int
+			for msg, err := range f.Subscribe(ctx) {
+				assert.NoError(t, err)
+				received <- msg.Data()
+				if n++; n == 1 {
+					assert.NoError(t, msg.NACK()) // the subscriber is busy, so the message goes to the buffer
+					continue
+				}
+				assert.NoError(t, msg.ACK())
+				return
+			}
+		})
+
+		assert.NoError(t, f.Publish(t.Context(), val))
+		assert.Within(t, 5*time.Second, func(ctx context.Context) { job.Wait() })
+		assert.Equal(t, val, <-received)
+		assert.Equal(t, val, <-received)
+	})
+
+	s.Test("the received message has metadata", func(t *testcase.T) {
+		timecop.Travel(t, clock.Now(), timecop.Freeze)
+		f := subject.Get(t)
+		metas := make(chan pubsub.Metadata, 2)
+		job := t.Go(func(ctx context.Context) {
+			for msg, err := range f.Subscribe(ctx) {
+				assert.NoError(t, err)
+				meta, ok := pubsub.LookupMetadata(msg)
+				assert.True(t, ok)
+				metas <- meta
+				if meta.DeliveryCount == 1 {
+					assert.NoError(t, msg.NACK()) // nack so the message is delivered again
+					continue
+				}
+				assert.NoError(t, msg.ACK())
+				return
+			}
+		})
+
+		headers := map[string]string{"correlation-id": t.Random.UUID()}
+		publishedAt := clock.Now()
+		assert.NoError(t, f.Publish(pubsub.ContextWithHeaders(t.Context(), headers), t.Random.UUID()))
+		job.Wait()
+
+		first, second := <-metas, <-metas
+		assert.NotEmpty(t, first.ID)
+		assert.Equal(t, headers, first.Headers)
+		assert.True(t, first.PublishedAt.Equal(publishedAt))
+		assert.Equal(t, 1, first.DeliveryCount)
+		assert.Equal(t, first.ID, second.ID)
+		assert.Equal(t, headers, second.Headers)
+		assert.Equal(t, 2, second.DeliveryCount)
+	})
+
 	s.Test("implements pubsub Queue contract", func(t *testcase.T) {
 		conf := pubsubcontract.Config[string]{
 			MakeData: func(tb testing.TB) string {
@@ -1408,6 +1468,37 @@ func TestBroadcast(t *testing.T) {
 		})
 	})
 
+	s.Test("the copies of a broadcast message share the message ID", func(t *testcase.T) {
+		var b synckit.Broadcast[int]
+		metas := make(chan pubsub.Metadata, 2)
+		for range 2 {
+			t.Go(func(ctx context.Context) {
+				for msg, err := range b.Subscribe(ctx) {
+					assert.NoError(t, err)
+					meta, ok := pubsub.LookupMetadata(msg)
+					assert.True(t, ok)
+					assert.NoError(t, msg.ACK())
+					metas <- meta
+					return
+				}
+			})
+		}
+		t.Eventually(func(t *testcase.T) {
+			assert.Equal(t, b.Len(), 2)
+		})
+
+		headers := map[string]string{"correlation-id": t.Random.UUID()}
+		assert.NoError(t, b.Publish(pubsub.ContextWithHeaders(t.Context(), headers), 42))
+
+		first, second := <-metas, <-metas
+		assert.NotEmpty(t, first.ID)
+		assert.Equal(t, first.ID, second.ID)
+		assert.Equal(t, headers, first.Headers)
+		assert.Equal(t, headers, second.Headers)
+		assert.Equal(t, 1, first.DeliveryCount)
+		assert.Equal(t, 1, second.DeliveryCount)
+	})
+
 	s.Test("nack w cancel should not result in any issue", func(t *testcase.T) {
 		var b synckit.Broadcast[int]
 		job := t.Go(func(ctx context.Context) {
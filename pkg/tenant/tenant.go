@@ -0,0 +1,237 @@
+// Package tenant scopes crud repositories to the tenant of the current request,
+// so many tenants can share the same backing resource without threading the tenant ID through every query.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"iter"
+
+	"go.llib.dev/frameless/pkg/errorkit"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/port/comproto"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/crudquery"
+	"go.llib.dev/frameless/port/crud/extid"
+)
+
+const (
+	// ErrMissingTenant is returned when the context doesn't tell the tenant of the operation.
+	ErrMissingTenant errorkit.Error = "ErrMissingTenant"
+	// ErrCrossTenantAccess is returned when an entity belongs to a different tenant than the one in the context.
+	ErrCrossTenantAccess errorkit.Error = "ErrCrossTenantAccess"
+	// ErrCreateRefused is returned when the Source refuses to create an entity,
+	// because its ID is already taken by an entity that the tenant can't see.
+	// Unlike crud.ErrAlreadyExists, it doesn't tell whether the ID belongs to an entity of another tenant.
+	ErrCreateRefused errorkit.Error = "ErrCreateRefused"
+)
+
+// Repository decorates a crud repository and scopes every operation to the tenant in the context.
+//
+// Create stamps the tenant on the new entities, while the finders and the deleters
+// only see the entities of the tenant.
+// The entities of other tenants look absent, so their existence is not revealed.
+type Repository[ENT, ID any, TID comparable] struct {
+	// Source is the decorated repository, which holds the entities of every tenant.
+	Source Source[ENT, ID]
+	// Context tells the tenant of the current operation.
+	// Usually it is a contextkit.ValueHandler, which is set up by a middleware.
+	Context Context[TID]
+	// TenantA is the accessor of the ENT's tenant field.
+	TenantA Accessor[ENT, TID]
+	// TenantField [optional] is the name of the tenant field, as the Source's crud.Querier understands it.
+	// When supplied, and the Source implements crud.Querier,
+	// FindAll lets the Source filter by the tenant, instead of filtering the entities of every tenant.
+	TenantField string
+	// IDA [optional] is the ENT's ID accessor.
+	//
+	// default: extid Lookup/Set
+	IDA extid.Accessor[ENT, ID]
+}
+
+// Source is the repository that a Repository decorates.
+type Source[ENT, ID any] interface {
+	crud.Creator[ENT]
+	crud.ByIDFinder[ENT, ID]
+	crud.AllFinder[ENT]
+	crud.Updater[ENT]
+	crud.ByIDDeleter[ID]
+}
+
+// Context stores the tenant in a context, such as a contextkit.ValueHandler.
+type Context[TID any] interface {
+	Lookup(context.Context) (TID, bool)
+	ContextWith(context.Context, TID) context.Context
+}
+
+// Accessor describes how to access the tenant field of an ENT.
+//
+//	tenant.Accessor[Note, TenantID](func(v *Note) *TenantID { return &v.TenantID })
+type Accessor[ENT, TID any] func(*ENT) *TID
+
+// Create creates an entity for the tenant.
+// crud.ErrAlreadyExists is only reported for the entities of the tenant,
+// while an ID taken by an entity of another tenant is refused with ErrCreateRefused.
+func (r Repository[ENT, ID, TID]) Create(ctx context.Context, ptr *ENT) error {
+	if ptr == nil {
+		return r.Source.Create(ctx, ptr)
+	}
+	if err := r.stamp(ctx, ptr); err != nil {
+		return err
+	}
+	err := r.Source.Create(ctx, ptr)
+	if errors.Is(err, crud.ErrAlreadyExists) {
+		return r.alreadyExists(ctx, ptr, err)
+	}
+	return err
+}
+
+func (r Repository[ENT, ID, TID]) FindByID(ctx context.Context, id ID) (ENT, bool, error) {
+	tid, err := r.tenant(ctx)
+	if err != nil {
+		return *new(ENT), false, err
+	}
+	ent, found, err := r.Source.FindByID(ctx, id)
+	if err != nil || !found {
+		return ent, found, err
+	}
+	if *r.TenantA(&ent) != tid {
+		return *new(ENT), false, nil
+	}
+	return ent, true, nil
+}
+
+func (r Repository[ENT, ID, TID]) FindAll(ctx context.Context) iter.Seq2[ENT, error] {
+	tid, err := r.tenant(ctx)
+	if err != nil {
+		return iterkit.Error[ENT](err)
+	}
+	if querier, ok := r.Source.(crud.Querier[ENT]); ok && r.TenantField != "" {
+		return querier.Query(ctx, crudquery.Where(crudquery.Eq(r.TenantField, tid)))
+	}
+	return iterkit.OnSeqEValue(r.Source.FindAll(ctx), func(i iter.Seq[ENT]) iter.Seq[ENT] {
+		return iterkit.Filter(i, func(v ENT) bool {
+			return *r.TenantA(&v) == tid
+		})
+	})
+}
+
+// Update updates an entity of the tenant.
+// Moving an entity to another tenant is refused with ErrCrossTenantAccess.
+func (r Repository[ENT, ID, TID]) Update(ctx context.Context, ptr *ENT) error {
+	if ptr == nil {
+		return r.Source.Update(ctx, ptr)
+	}
+	if err := r.stamp(ctx, ptr); err != nil {
+		return err
+	}
+	if err := r.shouldBePresent(ctx, r.IDA.Get(*ptr)); err != nil {
+		return err
+	}
+	return r.Source.Update(ctx, ptr)
+}
+
+func (r Repository[ENT, ID, TID]) DeleteByID(ctx context.Context, id ID) error {
+	if err := r.shouldBePresent(ctx, id); err != nil {
+		return err
+	}
+	return r.Source.DeleteByID(ctx, id)
+}
+
+// DeleteAll deletes every entity of the tenant, while the entities of other tenants are kept.
+func (r Repository[ENT, ID, TID]) DeleteAll(ctx context.Context) (rErr error) {
+	if c, ok := r.Source.(comproto.OnePhaseCommitProtocol); ok {
+		tx, err := c.BeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer comproto.FinishOnePhaseCommit(&rErr, c, tx)
+		ctx = tx
+	}
+	// the IDs are collected first, as deleting while iterating is not supported by every Source
+	ents, err := iterkit.CollectE(r.FindAll(ctx))
+	if err != nil {
+		return err
+	}
+	for _, ent := range ents {
+		// an entity that is deleted meanwhile is already where DeleteAll wants it to be
+		if err := r.Source.DeleteByID(ctx, r.IDA.Get(ent)); err != nil && !errors.Is(err, crud.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r Repository[ENT, ID, TID]) BeginTx(ctx context.Context) (context.Context, error) {
+	if c, ok := r.Source.(comproto.OnePhaseCommitProtocol); ok {
+		return c.BeginTx(ctx)
+	}
+	return ctx, nil
+}
+
+func (r Repository[ENT, ID, TID]) CommitTx(ctx context.Context) error {
+	if c, ok := r.Source.(comproto.OnePhaseCommitProtocol); ok {
+		return c.CommitTx(ctx)
+	}
+	return nil
+}
+
+func (r Repository[ENT, ID, TID]) RollbackTx(ctx context.Context) error {
+	if c, ok := r.Source.(comproto.OnePhaseCommitProtocol); ok {
+		return c.RollbackTx(ctx)
+	}
+	return nil
+}
+
+func (r Repository[ENT, ID, TID]) tenant(ctx context.Context) (TID, error) {
+	if r.Context == nil {
+		return *new(TID), ErrMissingTenant.F("%T has no Context to look up the tenant", r)
+	}
+	tid, ok := r.Context.Lookup(ctx)
+	if !ok {
+		return *new(TID), ErrMissingTenant
+	}
+	return tid, nil
+}
+
+// stamp sets the tenant of the entity, unless it already belongs to a different tenant.
+func (r Repository[ENT, ID, TID]) stamp(ctx context.Context, ptr *ENT) error {
+	tid, err := r.tenant(ctx)
+	if err != nil {
+		return err
+	}
+	var (
+		field = r.TenantA(ptr)
+		zero  TID
+	)
+	if *field != zero && *field != tid {
+		return ErrCrossTenantAccess.F("%T belongs to tenant %v, not to %v", *ptr, *field, tid)
+	}
+	*field = tid
+	return nil
+}
+
+// alreadyExists scopes the crud.ErrAlreadyExists of the Source to the tenant,
+// so the existence of the other tenants' entities is not revealed.
+func (r Repository[ENT, ID, TID]) alreadyExists(ctx context.Context, ptr *ENT, err error) error {
+	id := r.IDA.Get(*ptr)
+	_, found, ferr := r.FindByID(ctx, id)
+	if ferr != nil {
+		return ferr
+	}
+	if found {
+		return err
+	}
+	return ErrCreateRefused.F("%T can't be created with id: %v", *ptr, id)
+}
+
+func (r Repository[ENT, ID, TID]) shouldBePresent(ctx context.Context, id ID) error {
+	_, found, err := r.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return crud.ErrNotFound.F("%T is not found with id: %v", *new(ENT), id)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package tenant_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go.llib.dev/frameless/adapter/memory"
+	"go.llib.dev/frameless/pkg/contextkit"
+	"go.llib.dev/frameless/pkg/tenant"
+	"go.llib.dev/frameless/pkg/tenant/tenantcontract"
+	"go.llib.dev/frameless/port/crud/crudcontract"
+	"go.llib.dev/frameless/testing/testent"
+	"go.llib.dev/testcase"
+)
+
+type TenantKey struct{}
+
+// TenantOf is the context handler of the tenant, which is usually set by an HTTP middleware.
+var TenantOf contextkit.ValueHandler[TenantKey, string]
+
+func ExampleRepository() {
+	m := memory.NewMemory()
+	repo := tenant.Repository[testent.Foo, testent.FooID, string]{
+		Source:  memory.NewRepository[testent.Foo, testent.FooID](m),
+		Context: TenantOf,
+		TenantA: func(v *testent.Foo) *string { return &v.Bar },
+	}
+
+	ctx := TenantOf.ContextWith(context.Background(), "tenant-a")
+
+	foo := testent.Foo{Foo: "foo"}
+	_ = repo.Create(ctx, &foo) // foo.Bar == "tenant-a"
+
+	// other tenants don't see foo
+	_, found, _ := repo.FindByID(TenantOf.ContextWith(context.Background(), "tenant-b"), foo.ID)
+	fmt.Println(foo.Bar, found)
+	// Output: tenant-a false
+}
+
+func TestRepository(t *testing.T) {
+	m := memory.NewMemory()
+	subject := tenant.Repository[testent.Foo, testent.FooID, string]{
+		Source:  memory.NewRepository[testent.Foo, testent.FooID](m),
+		Context: TenantOf,
+		TenantA: func(v *testent.Foo) *string { return &v.Bar },
+	}
+
+	conf := crudcontract.Config[testent.Foo, testent.FooID]{
+		MakeContext: func(tb testing.TB) context.Context {
+			return TenantOf.ContextWith(context.Background(), "42")
+		},
+		MakeEntity: func(tb testing.TB) testent.Foo {
+			foo := testent.MakeFoo(tb)
+			foo.Bar = ""
+			return foo
+		},
+		OnePhaseCommit: m,
+	}
+
+	testcase.RunSuite(t,
+		crudcontract.Creator[testent.Foo, testent.FooID](subject, conf),
+		crudcontract.Finder[testent.Foo, testent.FooID](subject, conf),
+		crudcontract.Updater[testent.Foo, testent.FooID](subject, conf),
+		crudcontract.Deleter[testent.Foo, testent.FooID](subject, conf),
+		tenantcontract.Repository(subject, tenantcontract.Config[testent.Foo, string]{
+			MakeEntity: testent.MakeFoo,
+		}),
+	)
+}
@@ -0,0 +1,168 @@
+package tenantcontract
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.llib.dev/frameless/internal/spechelper"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/reflectkit"
+	"go.llib.dev/frameless/pkg/tenant"
+	"go.llib.dev/frameless/port/contract"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/option"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/let"
+)
+
+type Option[ENT any, TID comparable] option.Option[Config[ENT, TID]]
+
+type Config[ENT any, TID comparable] struct {
+	// MakeContext makes the base context, which the contract extends with the tenant.
+	MakeContext func(testing.TB) context.Context
+	MakeEntity  func(testing.TB) ENT
+	MakeTenant  func(testing.TB) TID
+}
+
+func (c *Config[ENT, TID]) Init() {
+	c.MakeContext = func(testing.TB) context.Context {
+		return context.Background()
+	}
+	c.MakeEntity = spechelper.MakeValue[ENT]
+	c.MakeTenant = spechelper.MakeValue[TID]
+}
+
+func (c Config[ENT, TID]) Configure(t *Config[ENT, TID]) {
+	*t = reflectkit.MergeStruct(*t, c)
+}
+
+// Repository verifies that the subject isolates the entities of the tenants from each other.
+func Repository[ENT, ID any, TID comparable](subject tenant.Repository[ENT, ID, TID], opts ...Option[ENT, TID]) contract.Contract {
+	c := option.ToConfig[Config[ENT, TID]](opts)
+	s := testcase.NewSpec(nil)
+
+	var (
+		tenantOf = func(t *testcase.T) context.Context {
+			return subject.Context.ContextWith(c.MakeContext(t), c.MakeTenant(t))
+		}
+		ctx   = let.Var(s, tenantOf)
+		other = let.Var(s, tenantOf)
+		// makeEntity makes an entity that doesn't belong to any tenant yet.
+		makeEntity = func(t *testcase.T) *ENT {
+			ent := c.MakeEntity(t)
+			*subject.TenantA(&ent) = *new(TID)
+			return &ent
+		}
+		create = func(t *testcase.T, ctx context.Context) *ENT {
+			ptr := makeEntity(t)
+			assert.NoError(t, subject.Create(ctx, ptr))
+			t.Cleanup(func() { _ = subject.DeleteByID(ctx, subject.IDA.Get(*ptr)) })
+			return ptr
+		}
+	)
+
+	s.Test("Create stamps the tenant of the context on the entity", func(t *testcase.T) {
+		ptr := create(t, ctx.Get(t))
+		tid, _ := subject.Context.Lookup(ctx.Get(t))
+		assert.Equal(t, tid, *subject.TenantA(ptr))
+
+		got, found, err := subject.FindByID(ctx.Get(t), subject.IDA.Get(*ptr))
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, *ptr, got)
+	})
+
+	s.Test("Create refuses an entity of another tenant", func(t *testcase.T) {
+		ptr := makeEntity(t)
+		tid, _ := subject.Context.Lookup(other.Get(t))
+		*subject.TenantA(ptr) = tid
+		assert.ErrorIs(t, subject.Create(ctx.Get(t), ptr), tenant.ErrCrossTenantAccess)
+	})
+
+	s.Test("Create doesn't reveal that the ID is taken by an entity of another tenant", func(t *testcase.T) {
+		theirs := create(t, other.Get(t))
+		ptr := makeEntity(t)
+		assert.NoError(t, subject.IDA.Set(ptr, subject.IDA.Get(*theirs)))
+
+		err := subject.Create(ctx.Get(t), ptr)
+		assert.ErrorIs(t, err, tenant.ErrCreateRefused)
+		assert.False(t, errors.Is(err, crud.ErrAlreadyExists))
+
+		got, found, err := subject.FindByID(other.Get(t), subject.IDA.Get(*theirs))
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, *theirs, got)
+	})
+
+	s.Test("without a tenant in the context, the operations fail with ErrMissingTenant", func(t *testcase.T) {
+		ptr := create(t, ctx.Get(t))
+		noTenant := c.MakeContext(t)
+		assert.ErrorIs(t, subject.Create(noTenant, makeEntity(t)), tenant.ErrMissingTenant)
+		_, _, err := subject.FindByID(noTenant, subject.IDA.Get(*ptr))
+		assert.ErrorIs(t, err, tenant.ErrMissingTenant)
+		_, err = iterkit.CollectE(subject.FindAll(noTenant))
+		assert.ErrorIs(t, err, tenant.ErrMissingTenant)
+		assert.ErrorIs(t, subject.DeleteByID(noTenant, subject.IDA.Get(*ptr)), tenant.ErrMissingTenant)
+	})
+
+	s.Test("the entity of another tenant is not found", func(t *testcase.T) {
+		ptr := create(t, other.Get(t))
+		_, found, err := subject.FindByID(ctx.Get(t), subject.IDA.Get(*ptr))
+		assert.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	s.Test("FindAll only yields the entities of the tenant", func(t *testcase.T) {
+		mine := create(t, ctx.Get(t))
+		theirs := create(t, other.Get(t))
+		vs, err := iterkit.CollectE(subject.FindAll(ctx.Get(t)))
+		assert.NoError(t, err)
+		assert.Contains(t, vs, *mine)
+		assert.NotContains(t, vs, *theirs)
+	})
+
+	s.Test("the entity of another tenant can't be updated", func(t *testcase.T) {
+		ptr := create(t, other.Get(t))
+		update := *ptr
+		*subject.TenantA(&update) = *new(TID)
+		assert.ErrorIs(t, subject.Update(ctx.Get(t), &update), crud.ErrNotFound)
+
+		got, found, err := subject.FindByID(other.Get(t), subject.IDA.Get(*ptr))
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, *ptr, got)
+	})
+
+	s.Test("an entity can't be moved to another tenant", func(t *testcase.T) {
+		ptr := create(t, ctx.Get(t))
+		tid, _ := subject.Context.Lookup(other.Get(t))
+		*subject.TenantA(ptr) = tid
+		assert.ErrorIs(t, subject.Update(ctx.Get(t), ptr), tenant.ErrCrossTenantAccess)
+	})
+
+	s.Test("the entity of another tenant can't be deleted", func(t *testcase.T) {
+		ptr := create(t, other.Get(t))
+		assert.ErrorIs(t, subject.DeleteByID(ctx.Get(t), subject.IDA.Get(*ptr)), crud.ErrNotFound)
+
+		_, found, err := subject.FindByID(other.Get(t), subject.IDA.Get(*ptr))
+		assert.NoError(t, err)
+		assert.True(t, found)
+	})
+
+	s.Test("DeleteAll keeps the entities of other tenants", func(t *testcase.T) {
+		mine := create(t, ctx.Get(t))
+		theirs := create(t, other.Get(t))
+		assert.NoError(t, subject.DeleteAll(ctx.Get(t)))
+
+		_, found, err := subject.FindByID(ctx.Get(t), subject.IDA.Get(*mine))
+		assert.NoError(t, err)
+		assert.False(t, found)
+		_, found, err = subject.FindByID(other.Get(t), subject.IDA.Get(*theirs))
+		assert.NoError(t, err)
+		assert.True(t, found)
+	})
+
+	return s.AsSuite("tenant.Repository")
+}
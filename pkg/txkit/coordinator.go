@@ -0,0 +1,301 @@
+package txkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"go.llib.dev/frameless/pkg/contextkit"
+	"go.llib.dev/frameless/pkg/errorkit"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/uuid"
+	"go.llib.dev/frameless/port/comproto"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/testcase/clock"
+)
+
+// Coordinator is a transaction manager that coordinates a global transaction
+// across multiple resources with the two-phase commit protocol.
+//
+// BeginTx enlists every participant into the transaction.
+// CommitTx prepares the transaction on each participant,
+// and when all of them succeeded, it records the commit decision in the DecisionLog,
+// then commits the participants.
+// When any of the participants fails to prepare, the whole transaction is rolled back.
+//
+// Within a Coordinator transaction, the txkit.Manager based participants keep their transactions apart by their DB,
+// even when they share the same TX type.
+//
+// When the process stops between the two phases, the prepared transactions of the participants stay in doubt.
+// Recover finishes them according to the decisions in the DecisionLog.
+type Coordinator struct {
+	// Participants are the resources that take part in the coordinated transactions.
+	// The name of a participant must be stable across restarts,
+	// as Recover uses it to identify the prepared transactions of the participant.
+	Participants map[string]comproto.TwoPhaseCommitProtocol
+	// DecisionLog is the durable storage of the transaction decisions.
+	// It must not take part in the transactions of the Participants.
+	DecisionLog DecisionLog
+}
+
+var _ comproto.OnePhaseCommitProtocol = Coordinator{}
+
+// DecisionLog is the durable storage of the Coordinator's transaction decisions.
+type DecisionLog interface {
+	crud.Creator[Decision]
+	crud.Updater[Decision]
+	crud.AllFinder[Decision]
+	crud.ByIDDeleter[string]
+}
+
+// Decision is the state of a coordinated transaction.
+type Decision struct {
+	TxID         string `ext:"id"`
+	Outcome      DecisionOutcome
+	Participants []string
+	CreatedAt    time.Time
+}
+
+type DecisionOutcome string
+
+const (
+	// DecisionPending means that the participants are being prepared.
+	// A pending transaction is presumed to be aborted during recovery.
+	DecisionPending DecisionOutcome = "pending"
+	// DecisionCommit means that every participant is prepared and the transaction must be committed.
+	DecisionCommit DecisionOutcome = "commit"
+)
+
+const ErrInDoubt errorkit.Error = "the transaction is committed, but some of the participants failed to finish it"
+
+type ctxKeyCoordinatorTx struct{}
+
+type coordinatorTx struct {
+	id     string
+	parent *coordinatorTx
+	// base is the context before the transaction, and it is used to access the DecisionLog.
+	base   context.Context
+	done   bool
+	cancel func()
+}
+
+func (c Coordinator) BeginTx(ctx context.Context) (_ context.Context, rErr error) {
+	if err := ctx.Err(); err != nil {
+		return ctx, err
+	}
+	tx := &coordinatorTx{base: contextkit.WithoutCancel(ctx)}
+	if ptx, ok := c.lookupTx(ctx); ok {
+		tx.parent = ptx
+		tx.id = ptx.id
+	} else {
+		id, err := uuid.MakeV7()
+		if err != nil {
+			return ctx, err
+		}
+		tx.id = id.String()
+	}
+	// the participants of the same TX type must not join each other's transactions
+	ctx = contextWithDBScopedTx(ctx)
+	var enlisted []comproto.TwoPhaseCommitProtocol
+	defer func() {
+		if rErr == nil {
+			return
+		}
+		for _, p := range enlisted {
+			rErr = errorkit.Merge(rErr, p.RollbackTx(contextkit.WithoutCancel(ctx)))
+		}
+	}()
+	for _, name := range c.names() {
+		p := c.Participants[name]
+		pctx, err := p.BeginTx(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%s participant failed to begin the transaction: %w", name, err)
+		}
+		ctx = pctx
+		enlisted = append(enlisted, p)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	tx.cancel = cancel
+	return context.WithValue(ctx, ctxKeyCoordinatorTx{}, tx), nil
+}
+
+// CommitTx commits the transaction.
+//
+// A nested transaction is committed on the participants directly,
+// while the outermost transaction is committed with the two-phase commit protocol.
+// When a participant fails to commit after the commit decision is made,
+// CommitTx returns with ErrInDoubt, and the decision is kept in the DecisionLog,
+// so Recover can finish the transaction later.
+func (c Coordinator) CommitTx(ctx context.Context) error {
+	tx, ok := c.lookupTx(ctx)
+	if !ok {
+		return ErrNoTx
+	}
+	if tx.done {
+		return fmt.Errorf("CommitTx: %w", ErrTxDone)
+	}
+	tx.done = true
+	defer tx.cancel()
+	if err := ctx.Err(); err != nil {
+		return errorkit.Merge(err, c.rollback(ctx))
+	}
+	// The participants begin their transactions on top of each other's context,
+	// thus finishing one of them cancels the context of the others.
+	ctx = contextkit.WithoutCancel(ctx)
+	if tx.parent != nil {
+		var errs []error
+		for _, name := range c.names() {
+			errs = append(errs, c.Participants[name].CommitTx(ctx))
+		}
+		return errorkit.Merge(errs...)
+	}
+	return c.commit(ctx, tx)
+}
+
+func (c Coordinator) commit(ctx context.Context, tx *coordinatorTx) error {
+	decision := Decision{
+		TxID:         tx.id,
+		Outcome:      DecisionPending,
+		Participants: c.names(),
+		CreatedAt:    clock.Now().UTC(),
+	}
+	if err := c.DecisionLog.Create(tx.base, &decision); err != nil {
+		return errorkit.Merge(err, c.rollback(ctx))
+	}
+
+	// first phase
+	prepared := make(map[string]context.Context)
+	for _, name := range decision.Participants {
+		pctx, err := c.Participants[name].PrepareTx(comproto.ContextWithTxID(ctx, participantTxID(tx.id, name)))
+		if err != nil {
+			err = fmt.Errorf("%s participant failed to prepare the transaction: %w", name, err)
+			return c.abort(ctx, tx, prepared, err)
+		}
+		prepared[name] = pctx
+	}
+
+	// the commit decision
+	decision.Outcome = DecisionCommit
+	if err := c.DecisionLog.Update(tx.base, &decision); err != nil {
+		return c.abort(ctx, tx, prepared, err)
+	}
+
+	// second phase
+	var errs []error
+	for _, name := range c.names() {
+		if err := c.Participants[name].CommitTx(prepared[name]); err != nil {
+			errs = append(errs, fmt.Errorf("%s participant failed to commit the transaction: %w", name, err))
+		}
+	}
+	if err := errorkit.Merge(errs...); err != nil {
+		return errorkit.Merge(ErrInDoubt, err)
+	}
+	return c.DecisionLog.DeleteByID(tx.base, tx.id)
+}
+
+func (c Coordinator) abort(ctx context.Context, tx *coordinatorTx, prepared map[string]context.Context, cause error) error {
+	var errs = []error{cause}
+	for _, name := range c.names() {
+		pctx, ok := prepared[name]
+		if !ok {
+			pctx = ctx
+		}
+		errs = append(errs, c.Participants[name].RollbackTx(contextkit.WithoutCancel(pctx)))
+	}
+	errs = append(errs, c.DecisionLog.DeleteByID(tx.base, tx.id))
+	return errorkit.Merge(errs...)
+}
+
+func (c Coordinator) RollbackTx(ctx context.Context) error {
+	tx, ok := c.lookupTx(ctx)
+	if !ok {
+		return ErrNoTx
+	}
+	if tx.done {
+		return ErrTxDone
+	}
+	tx.done = true
+	defer tx.cancel()
+	for ptx := tx.parent; ptx != nil; ptx = ptx.parent {
+		ptx.done = true
+		defer ptx.cancel()
+	}
+	ctxErr := ctx.Err() // the participants' rollback cancels the context
+	return errorkit.Merge(c.rollback(ctx), ctxErr)
+}
+
+func (c Coordinator) rollback(ctx context.Context) error {
+	ctx = contextkit.WithoutCancel(ctx)
+	var errs []error
+	for _, name := range c.names() {
+		errs = append(errs, c.Participants[name].RollbackTx(ctx))
+	}
+	return errorkit.Merge(errs...)
+}
+
+// Recover finishes the in-doubt transactions, based on the decisions in the DecisionLog.
+// Transactions with a commit decision are committed, while pending transactions are rolled back,
+// on every participant that implements comproto.TwoPhaseCommitRecoverer.
+//
+// Recover must be called before the Coordinator begins new transactions, ideally during the application start.
+// When multiple application instances share a DecisionLog,
+// Recover could roll back a pending transaction of another instance.
+func (c Coordinator) Recover(ctx context.Context) error {
+	decisions, err := iterkit.CollectE(c.DecisionLog.FindAll(ctx))
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, decision := range decisions {
+		errs = append(errs, c.recover(ctx, decision))
+	}
+	return errorkit.Merge(errs...)
+}
+
+func (c Coordinator) recover(ctx context.Context, decision Decision) error {
+	var errs []error
+	for _, name := range decision.Participants {
+		p, ok := c.Participants[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown participant in the %s transaction: %s", decision.TxID, name))
+			continue
+		}
+		recoverer, ok := p.(comproto.TwoPhaseCommitRecoverer)
+		if !ok { // the participant's prepared transactions didn't outlive the process
+			continue
+		}
+		txID := participantTxID(decision.TxID, name)
+		var err error
+		switch decision.Outcome {
+		case DecisionCommit:
+			err = recoverer.CommitPreparedTx(ctx, txID)
+		default:
+			err = recoverer.RollbackPreparedTx(ctx, txID)
+		}
+		if err != nil && !errors.Is(err, comproto.ErrPreparedTxNotFound) {
+			errs = append(errs, fmt.Errorf("%s participant failed to recover the %s transaction: %w", name, decision.TxID, err))
+		}
+	}
+	if err := errorkit.Merge(errs...); err != nil {
+		return err
+	}
+	return c.DecisionLog.DeleteByID(ctx, decision.TxID)
+}
+
+func (c Coordinator) lookupTx(ctx context.Context) (*coordinatorTx, bool) {
+	tx, ok := ctx.Value(ctxKeyCoordinatorTx{}).(*coordinatorTx)
+	return tx, ok
+}
+
+func (c Coordinator) names() []string {
+	return slices.Sorted(maps.Keys(c.Participants))
+}
+
+// participantTxID makes the transaction ID of a participant unique,
+// even when multiple participants use the same underlying resource.
+func participantTxID(txID, participant string) string {
+	return txID + ":" + participant
+}
@@ -0,0 +1,318 @@
+package txkit_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"go.llib.dev/frameless/adapter/memory"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/txkit"
+	"go.llib.dev/frameless/port/comproto"
+	"go.llib.dev/frameless/port/comproto/comprotocontract"
+	"go.llib.dev/frameless/port/pubsub/pubsubtest"
+	"go.llib.dev/frameless/testing/testent"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+)
+
+func ExampleCoordinator() {
+	var (
+		db    comproto.TwoPhaseCommitProtocol // e.g.: postgresql.Connection
+		queue comproto.TwoPhaseCommitProtocol // e.g.: *memory.Queue[Data]
+		log   txkit.DecisionLog               // e.g.: postgresql.DecisionLogRepository
+	)
+
+	coordinator := txkit.Coordinator{
+		Participants: map[string]comproto.TwoPhaseCommitProtocol{
+			"db":    db,
+			"queue": queue,
+		},
+		DecisionLog: log,
+	}
+
+	ctx := context.Background()
+	// finish the transactions that were left in doubt by the previous run
+	if err := coordinator.Recover(ctx); err != nil {
+		panic(err)
+	}
+
+	myUseCase := func(ctx context.Context) (rErr error) {
+		ctx, err := coordinator.BeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer comproto.FinishOnePhaseCommit(&rErr, coordinator, ctx)
+		// use the db and the queue with the transaction context
+		return nil
+	}
+
+	_ = myUseCase(ctx)
+}
+
+func TestCoordinator(t *testing.T) {
+	s := testcase.NewSpec(t)
+	s.HasSideEffect()
+
+	var (
+		p1 = testcase.Let(s, func(t *testcase.T) *Participant { return &Participant{} })
+		p2 = testcase.Let(s, func(t *testcase.T) *Participant { return &Participant{} })
+		dl = testcase.Let(s, func(t *testcase.T) *memory.Repository[txkit.Decision, string] {
+			return memory.NewRepository[txkit.Decision, string](memory.NewMemory())
+		})
+		subject = testcase.Let(s, func(t *testcase.T) txkit.Coordinator {
+			return txkit.Coordinator{
+				Participants: map[string]comproto.TwoPhaseCommitProtocol{
+					"p1": p1.Get(t),
+					"p2": p2.Get(t),
+				},
+				DecisionLog: dl.Get(t),
+			}
+		})
+		decisions = func(t *testcase.T) []txkit.Decision {
+			vs, err := iterkit.CollectE(dl.Get(t).FindAll(context.Background()))
+			assert.NoError(t, err)
+			return vs
+		}
+	)
+
+	s.Context("implements OnePhaseCommitProtocol", func(s *testcase.Spec) {
+		comprotocontract.OnePhaseCommitProtocol(txkit.Coordinator{
+			Participants: map[string]comproto.TwoPhaseCommitProtocol{
+				"p1": &Participant{},
+				"p2": &Participant{},
+			},
+			DecisionLog: memory.NewRepository[txkit.Decision, string](memory.NewMemory()),
+		}).Spec(s)
+	})
+
+	s.Test("on commit, every participant is prepared and committed", func(t *testcase.T) {
+		ctx, err := subject.Get(t).BeginTx(context.Background())
+		assert.NoError(t, err)
+		assert.NoError(t, subject.Get(t).CommitTx(ctx))
+
+		assert.Equal(t, 1, p1.Get(t).Committed())
+		assert.Equal(t, 1, p2.Get(t).Committed())
+		assert.Empty(t, p1.Get(t).Prepared())
+		assert.Empty(t, p2.Get(t).Prepared())
+		assert.Empty(t, decisions(t), "decision should be removed from the log after a successful commit")
+	})
+
+	s.Test("on rollback, every participant is rolled back", func(t *testcase.T) {
+		ctx, err := subject.Get(t).BeginTx(context.Background())
+		assert.NoError(t, err)
+		assert.NoError(t, subject.Get(t).RollbackTx(ctx))
+
+		assert.Equal(t, 1, p1.Get(t).RolledBack())
+		assert.Equal(t, 1, p2.Get(t).RolledBack())
+		assert.Equal(t, 0, p1.Get(t).Committed())
+		assert.Equal(t, 0, p2.Get(t).Committed())
+	})
+
+	s.Test("when a participant fails to prepare, every participant is rolled back", func(t *testcase.T) {
+		expErr := t.Random.Error()
+		p2.Get(t).PrepareErr = expErr
+
+		ctx, err := subject.Get(t).BeginTx(context.Background())
+		assert.NoError(t, err)
+		assert.ErrorIs(t, subject.Get(t).CommitTx(ctx), expErr)
+
+		assert.Equal(t, 0, p1.Get(t).Committed())
+		assert.Equal(t, 0, p2.Get(t).Committed())
+		assert.Equal(t, 1, p1.Get(t).RolledBack())
+		assert.Equal(t, 1, p2.Get(t).RolledBack())
+		assert.Empty(t, p1.Get(t).Prepared())
+		assert.Empty(t, decisions(t))
+	})
+
+	s.Test("when a participant fails to commit after the decision, the transaction is finished by Recover", func(t *testcase.T) {
+		p2.Get(t).CommitErr = t.Random.Error()
+
+		ctx, err := subject.Get(t).BeginTx(context.Background())
+		assert.NoError(t, err)
+		assert.ErrorIs(t, subject.Get(t).CommitTx(ctx), txkit.ErrInDoubt)
+
+		assert.Equal(t, 1, p1.Get(t).Committed())
+		assert.Equal(t, 0, p2.Get(t).Committed())
+		assert.Equal(t, 1, len(p2.Get(t).Prepared()))
+		ds := decisions(t)
+		assert.Equal(t, 1, len(ds))
+		assert.Equal(t, txkit.DecisionCommit, ds[0].Outcome)
+
+		p2.Get(t).CommitErr = nil
+		assert.NoError(t, subject.Get(t).Recover(context.Background()))
+		assert.Equal(t, 1, p2.Get(t).Committed())
+		assert.Empty(t, p2.Get(t).Prepared())
+		assert.Empty(t, decisions(t))
+	})
+
+	s.Test("Recover rolls back the prepared transactions of a pending decision", func(t *testcase.T) {
+		ctx, err := p1.Get(t).BeginTx(context.Background())
+		assert.NoError(t, err)
+		txID := t.Random.UUID()
+		_, err = p1.Get(t).PrepareTx(comproto.ContextWithTxID(ctx, txID+":p1"))
+		assert.NoError(t, err)
+		assert.NoError(t, dl.Get(t).Create(context.Background(), &txkit.Decision{
+			TxID:         txID,
+			Outcome:      txkit.DecisionPending,
+			Participants: []string{"p1", "p2"},
+		}))
+
+		assert.NoError(t, subject.Get(t).Recover(context.Background()))
+		assert.Empty(t, p1.Get(t).Prepared())
+		assert.Equal(t, 0, p1.Get(t).Committed())
+		assert.Empty(t, decisions(t))
+	})
+
+	s.Test("Recover with an unknown participant keeps the decision", func(t *testcase.T) {
+		assert.NoError(t, dl.Get(t).Create(context.Background(), &txkit.Decision{
+			TxID:         t.Random.UUID(),
+			Outcome:      txkit.DecisionCommit,
+			Participants: []string{"p3"},
+		}))
+		assert.Error(t, subject.Get(t).Recover(context.Background()))
+		assert.Equal(t, 1, len(decisions(t)))
+	})
+
+	s.Test("a memory Queue participant publishes its messages only on commit", func(t *testcase.T) {
+		q := &memory.Queue[testent.Foo]{}
+		c := subject.Get(t)
+		c.Participants["queue"] = q
+		sub := pubsubtest.Subscribe[testent.Foo](t, q, context.Background())
+
+		ctx, err := c.BeginTx(context.Background())
+		assert.NoError(t, err)
+		foo := testent.MakeFoo(t)
+		assert.NoError(t, q.Publish(ctx, foo))
+		assert.NoError(t, c.CommitTx(ctx))
+
+		sub.Eventually(t, func(tb testing.TB, got []testent.Foo) {
+			assert.Equal(tb, []testent.Foo{foo}, got)
+		})
+	})
+}
+
+func TestParticipant(t *testing.T) {
+	comprotocontract.TwoPhaseCommitProtocol(&Participant{}).Test(t)
+}
+
+// Participant is a fake two-phase commit resource, which keeps its prepared transactions
+// until they are finished, even through the recovery API.
+type Participant struct {
+	PrepareErr error
+	CommitErr  error
+
+	mutex      sync.Mutex
+	committed  int
+	rolledBack int
+	prepared   map[string]struct{}
+}
+
+var _ interface {
+	comproto.TwoPhaseCommitProtocol
+	comproto.TwoPhaseCommitRecoverer
+} = &Participant{}
+
+type ParticipantTx struct{}
+
+func (p *Participant) txm() txkit.Manager[Participant, ParticipantTx, any] {
+	return txkit.Manager[Participant, ParticipantTx, any]{
+		DB: p,
+		Begin: func(ctx context.Context, db *Participant) (*ParticipantTx, error) {
+			return &ParticipantTx{}, nil
+		},
+		Commit: func(ctx context.Context, tx *ParticipantTx) error {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			p.committed++
+			return nil
+		},
+		Rollback: func(ctx context.Context, tx *ParticipantTx) error {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			p.rolledBack++
+			return nil
+		},
+		Prepare: func(ctx context.Context, tx *ParticipantTx, txID string) error {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			if p.PrepareErr != nil {
+				return p.PrepareErr
+			}
+			if p.prepared == nil {
+				p.prepared = make(map[string]struct{})
+			}
+			p.prepared[txID] = struct{}{}
+			return nil
+		},
+		CommitPrepared: func(ctx context.Context, tx *ParticipantTx, txID string) error {
+			if p.CommitErr != nil {
+				return p.CommitErr
+			}
+			return p.CommitPreparedTx(ctx, txID)
+		},
+		RollbackPrepared: func(ctx context.Context, tx *ParticipantTx, txID string) error {
+			return p.RollbackPreparedTx(ctx, txID)
+		},
+	}
+}
+
+func (p *Participant) BeginTx(ctx context.Context) (context.Context, error) {
+	return p.txm().BeginTx(ctx)
+}
+
+func (p *Participant) PrepareTx(ctx context.Context) (context.Context, error) {
+	return p.txm().PrepareTx(ctx)
+}
+
+func (p *Participant) CommitTx(ctx context.Context) error {
+	return p.txm().CommitTx(ctx)
+}
+
+func (p *Participant) RollbackTx(ctx context.Context) error {
+	return p.txm().RollbackTx(ctx)
+}
+
+func (p *Participant) CommitPreparedTx(ctx context.Context, txID string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, ok := p.prepared[txID]; !ok {
+		return comproto.ErrPreparedTxNotFound
+	}
+	delete(p.prepared, txID)
+	p.committed++
+	return nil
+}
+
+func (p *Participant) RollbackPreparedTx(ctx context.Context, txID string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, ok := p.prepared[txID]; !ok {
+		return comproto.ErrPreparedTxNotFound
+	}
+	delete(p.prepared, txID)
+	p.rolledBack++
+	return nil
+}
+
+func (p *Participant) Committed() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.committed
+}
+
+func (p *Participant) RolledBack() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.rolledBack
+}
+
+func (p *Participant) Prepared() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	var ids []string
+	for id := range p.prepared {
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -9,12 +9,18 @@ import (
 	"go.llib.dev/frameless/pkg/contextkit"
 	"go.llib.dev/frameless/pkg/errorkit"
 	"go.llib.dev/frameless/pkg/teardown"
+	"go.llib.dev/frameless/pkg/uuid"
+	"go.llib.dev/frameless/port/comproto"
 )
 
 const (
 	ErrTxDone errorkit.Error = "transaction is already finished"
 	ErrNoCtx  errorkit.Error = "context.Context not given"
 	ErrNoTx   errorkit.Error = "no transaction present in the current context"
+
+	ErrTxPrepared          errorkit.Error = "transaction is already prepared"
+	ErrNestedTxPrepare     errorkit.Error = "a nested transaction can't be prepared"
+	ErrPrepareNotSupported errorkit.Error = "two-phase commit is not supported"
 )
 
 type contextTransaction struct {
@@ -129,12 +135,18 @@ func OnRollback[StepFn onRollbackStepFn](ctx context.Context, step StepFn) error
 //
 // Type Arguments:
 // - DB: the main connection that
+//
+// Managers with the same TX type share the transaction of a context.
+// Within a Coordinator transaction, the transaction of a context belongs to the DB of the Manager instead,
+// so Managers with different DB pointers are isolated from each other's transactions,
+// even when their TX type is the same.
 type Manager[DB, TX, Queryable any] struct {
 	// DB  is the underlying Database type to access.
 	// It is ideal if ConnectionAdapter used as the Connection type implementation, but you need access to not exposed functionalities.
 	//
 	// 		type Connection txkit.Manager[*sql.DB, *sql.Tx]
 	//
+	// Within a Coordinator transaction, DB also identifies the transactions of the Manager in the context.
 	DB *DB
 	// TxAdapter provides the mapping for a native driver specific TX type to be usable as a Queryable.
 	TxAdapter func(tx *TX) Queryable
@@ -146,6 +158,19 @@ type Manager[DB, TX, Queryable any] struct {
 	Commit func(ctx context.Context, tx *TX) error
 	// Rollback is a function that must rollback a given transaction.
 	Rollback func(ctx context.Context, tx *TX) error
+	// Prepare [optional] is a function that prepares a given transaction for a later commit,
+	// as the first phase of a two-phase commit.
+	// The txID identifies the prepared transaction.
+	// Without Prepare, the Manager doesn't support PrepareTx.
+	Prepare func(ctx context.Context, tx *TX, txID string) error
+	// CommitPrepared [optional] is a function that commits a given prepared transaction.
+	//
+	// default: Commit
+	CommitPrepared func(ctx context.Context, tx *TX, txID string) error
+	// RollbackPrepared [optional] is a function that rolls back a given prepared transaction.
+	//
+	// default: Rollback
+	RollbackPrepared func(ctx context.Context, tx *TX, txID string) error
 	// ErrTxDone is the error returned when the transaction is already finished.
 	// ErrTxDone is an optional field.
 	//
@@ -174,6 +199,8 @@ type txInContext[TX any] struct {
 	tx     *TX
 	done   bool
 	cancel func()
+	// preparedTxID is the ID of the prepared transaction, when PrepareTx was called.
+	preparedTxID string
 }
 
 func (m Manager[DB, TX, Queryable]) BeginTx(ctx context.Context) (context.Context, error) {
@@ -184,6 +211,9 @@ func (m Manager[DB, TX, Queryable]) BeginTx(ctx context.Context) (context.Contex
 	tx := &txInContext[TX]{}
 
 	if ptx, ok := m.lookupTx(ctx); ok {
+		if rtx, ok := m.lookupRootTx(ctx); ok && rtx.preparedTxID != "" {
+			return ctx, ErrTxPrepared
+		}
 		tx.parent = ptx
 	}
 
@@ -198,7 +228,7 @@ func (m Manager[DB, TX, Queryable]) BeginTx(ctx context.Context) (context.Contex
 	ctx, cancel := context.WithCancel(ctx)
 	tx.cancel = cancel
 
-	return context.WithValue(ctx, ctxKeyForContextTxHandler[TX]{}, tx), nil
+	return context.WithValue(ctx, m.ctxKey(ctx), tx), nil
 }
 
 func (m Manager[DB, TX, Queryable]) CommitTx(ctx context.Context) error {
@@ -215,7 +245,10 @@ func (m Manager[DB, TX, Queryable]) CommitTx(ctx context.Context) error {
 		return ctx.Err()
 	}
 	if err := ctx.Err(); err != nil {
-		return errorkit.Merge(err, m.Rollback(ctx, tx.tx))
+		return errorkit.Merge(err, m.rollback(ctx, tx))
+	}
+	if tx.preparedTxID != "" && m.CommitPrepared != nil {
+		return m.CommitPrepared(ctx, tx.tx, tx.preparedTxID)
 	}
 	return m.Commit(ctx, tx.tx)
 }
@@ -234,7 +267,7 @@ func (m Manager[DB, TX, Queryable]) RollbackTx(ctx context.Context) error {
 		// defer context cancellation after all the rollback call is executed
 		defer tx.cancel()
 		if tx.tx != nil {
-			rberr := m.Rollback(contextkit.WithoutCancel(ctx), tx.tx)
+			rberr := m.rollback(contextkit.WithoutCancel(ctx), tx)
 			ctxErr := ctx.Err()
 			return errorkit.Merge(rberr, ctxErr)
 		}
@@ -244,6 +277,53 @@ func (m Manager[DB, TX, Queryable]) RollbackTx(ctx context.Context) error {
 	}
 }
 
+func (m Manager[DB, TX, Queryable]) rollback(ctx context.Context, tx *txInContext[TX]) error {
+	if tx.preparedTxID != "" && m.RollbackPrepared != nil {
+		return m.RollbackPrepared(ctx, tx.tx, tx.preparedTxID)
+	}
+	return m.Rollback(ctx, tx.tx)
+}
+
+// PrepareTx prepares the transaction of the context for a later commit, as the first phase of a two-phase commit.
+// The prepared transaction is identified by the comproto.LookupTxID of the context,
+// or by a random ID when the context has none.
+//
+// After a successful PrepareTx, the transaction can only be finished with CommitTx or RollbackTx.
+func (m Manager[DB, TX, Queryable]) PrepareTx(ctx context.Context) (context.Context, error) {
+	if err := ctx.Err(); err != nil {
+		return ctx, err
+	}
+	if m.Prepare == nil {
+		return ctx, ErrPrepareNotSupported
+	}
+	tx, ok := m.lookupTx(ctx)
+	if !ok {
+		return ctx, ErrNoTx
+	}
+	if tx.done {
+		return ctx, fmt.Errorf("PrepareTx: %w", m.txDoneErr())
+	}
+	if tx.tx == nil {
+		return ctx, ErrNestedTxPrepare
+	}
+	if tx.preparedTxID != "" {
+		return ctx, ErrTxPrepared
+	}
+	txID, ok := comproto.LookupTxID(ctx)
+	if !ok {
+		id, err := uuid.MakeV7()
+		if err != nil {
+			return ctx, err
+		}
+		txID = id.String()
+	}
+	if err := m.Prepare(ctx, tx.tx, txID); err != nil {
+		return ctx, err
+	}
+	tx.preparedTxID = txID
+	return ctx, nil
+}
+
 func (m Manager[DB, TX, Queryable]) LookupTx(ctx context.Context) (*TX, bool) {
 	tx, ok := m.lookupRootTx(ctx)
 	if !ok {
@@ -279,10 +359,28 @@ func (m Manager[DB, TX, Queryable]) txDoneErr() error {
 	return ErrTxDone
 }
 
-type ctxKeyForContextTxHandler[T any] struct{}
+// ctxKeyForContextTxHandler is shared by the Managers of the same TX type.
+// In a context with DB scoped transactions, it is bound to the DB as well,
+// so transactions of different DB instances with the same TX type can live in the same context,
+// e.g.: when they are coordinated in a two-phase commit.
+type ctxKeyForContextTxHandler[T any] struct{ db any }
+
+type ctxKeyDBScopedTx struct{}
+
+// contextWithDBScopedTx makes the Managers keep their transactions in the context under a key bound to their DB.
+func contextWithDBScopedTx(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxKeyDBScopedTx{}, true)
+}
+
+func (m Manager[DB, TX, Queryable]) ctxKey(ctx context.Context) ctxKeyForContextTxHandler[TX] {
+	if scoped, _ := ctx.Value(ctxKeyDBScopedTx{}).(bool); scoped {
+		return ctxKeyForContextTxHandler[TX]{db: m.DB}
+	}
+	return ctxKeyForContextTxHandler[TX]{}
+}
 
 func (m Manager[DB, TX, Queryable]) lookupTx(ctx context.Context) (*txInContext[TX], bool) {
-	tx, ok := ctx.Value(ctxKeyForContextTxHandler[TX]{}).(*txInContext[TX])
+	tx, ok := ctx.Value(m.ctxKey(ctx)).(*txInContext[TX])
 	return tx, ok
 }
 
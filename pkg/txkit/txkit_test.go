@@ -387,6 +387,38 @@ func TestManager(t *testing.T) {
 		assert.Equal[any](t, q.F, tx)
 	})
 
+	t.Run("managers of the same DB share the transaction of the context", func(t *testing.T) {
+		var other txkit.Manager[DB, TX, Queryable] = subject // pass by value copy
+
+		ctx, err := subject.BeginTx(context.Background())
+		assert.NoError(t, err)
+		tx, ok := subject.LookupTx(ctx)
+		assert.True(t, ok)
+
+		otx, ok := other.LookupTx(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, tx, otx)
+		assert.Equal[any](t, tx, other.Q(ctx).F)
+		assert.NoError(t, other.CommitTx(ctx))
+		assert.ErrorIs(t, txkit.ErrTxDone, subject.CommitTx(ctx))
+	})
+
+	t.Run("managers of different DBs with the same TX type share the transaction of the context", func(t *testing.T) {
+		var other txkit.Manager[DB, TX, Queryable] = subject // pass by value copy
+		other.DB = &DB{N: rnd.Domain(), V: db.V}
+
+		ctx, err := subject.BeginTx(context.Background())
+		assert.NoError(t, err)
+		tx, ok := subject.LookupTx(ctx)
+		assert.True(t, ok)
+
+		otx, ok := other.LookupTx(ctx)
+		assert.True(t, ok, "outside of a Coordinator transaction, the transaction is shared by the TX type")
+		assert.Equal(t, tx, otx)
+		assert.NoError(t, other.CommitTx(ctx))
+		assert.ErrorIs(t, txkit.ErrTxDone, subject.CommitTx(ctx))
+	})
+
 	t.Run("cancel will not cannel the context of the Rollback call", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 
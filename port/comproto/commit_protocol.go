@@ -2,6 +2,8 @@ package comproto
 
 import (
 	"context"
+
+	"go.llib.dev/frameless/internal/errorkitlite"
 )
 
 type OnePhaseCommitProtocol interface {
@@ -39,3 +41,33 @@ type TwoPhaseCommitProtocol interface {
 	// must be interpreted as Two Phase Commit Protocol's Commit or Rollback action.
 	PrepareTx(context.Context) (context.Context, error)
 }
+
+// TwoPhaseCommitRecoverer is implemented by a TwoPhaseCommitProtocol resource,
+// whose prepared transactions outlive the process that prepared them.
+//
+// A transaction manager uses it to finish the in-doubt transactions,
+// after it restarted between the prepare and the commit phase.
+type TwoPhaseCommitRecoverer interface {
+	// CommitPreparedTx commits a prepared transaction by its transaction ID.
+	// When no prepared transaction is found with the given ID, it returns ErrPreparedTxNotFound.
+	CommitPreparedTx(ctx context.Context, txID string) error
+	// RollbackPreparedTx rolls back a prepared transaction by its transaction ID.
+	// When no prepared transaction is found with the given ID, it returns ErrPreparedTxNotFound.
+	RollbackPreparedTx(ctx context.Context, txID string) error
+}
+
+const ErrPreparedTxNotFound errorkitlite.Error = "prepared transaction not found"
+
+type ctxKeyTxID struct{}
+
+// ContextWithTxID sets the global transaction ID in the context,
+// which a TwoPhaseCommitProtocol should use to identify the transaction during PrepareTx.
+func ContextWithTxID(ctx context.Context, txID string) context.Context {
+	return context.WithValue(ctx, ctxKeyTxID{}, txID)
+}
+
+// LookupTxID looks up the global transaction ID from the context.
+func LookupTxID(ctx context.Context) (string, bool) {
+	txID, ok := ctx.Value(ctxKeyTxID{}).(string)
+	return txID, ok && txID != ""
+}
@@ -0,0 +1,135 @@
+package comprotocontract
+
+import (
+	"context"
+
+	"go.llib.dev/frameless/port/comproto"
+	"go.llib.dev/frameless/port/contract"
+	"go.llib.dev/frameless/port/option"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+)
+
+// TwoPhaseCommitProtocol verifies that the subject can take part in a two-phase commit,
+// coordinated by a transaction manager.
+//
+// When the subject also implements comproto.TwoPhaseCommitRecoverer,
+// then the recovery of the prepared transactions is verified as well.
+func TwoPhaseCommitProtocol(subject comproto.TwoPhaseCommitProtocol, opts ...Option) contract.Contract {
+	c := option.ToConfig[Config](opts)
+	s := testcase.NewSpec(nil)
+
+	OnePhaseCommitProtocol(subject, opts...).Spec(s)
+
+	s.Context("supplies TwoPhaseCommitProtocol", func(s *testcase.Spec) {
+		s.HasSideEffect()
+
+		s.Test(`BeginTx + PrepareTx + CommitTx, no error`, func(t *testcase.T) {
+			tx, err := subject.BeginTx(c.MakeContext(t))
+			assert.Must(t).NoError(err)
+			tx, err = subject.PrepareTx(tx)
+			assert.Must(t).NoError(err)
+			assert.Must(t).NoError(subject.CommitTx(tx))
+			assert.Must(t).ErrorIs(tx.Err(), context.Canceled)
+		})
+
+		s.Test(`BeginTx + PrepareTx + RollbackTx, no error`, func(t *testcase.T) {
+			tx, err := subject.BeginTx(c.MakeContext(t))
+			assert.Must(t).NoError(err)
+			tx, err = subject.PrepareTx(tx)
+			assert.Must(t).NoError(err)
+			assert.Must(t).NoError(subject.RollbackTx(tx))
+			assert.Must(t).ErrorIs(tx.Err(), context.Canceled)
+		})
+
+		s.Test(`BeginTx + PrepareTx + CommitTx + CommitTx, yields error`, func(t *testcase.T) {
+			tx, err := subject.BeginTx(c.MakeContext(t))
+			assert.Must(t).NoError(err)
+			tx, err = subject.PrepareTx(tx)
+			assert.Must(t).NoError(err)
+			assert.Must(t).NoError(subject.CommitTx(tx))
+			assert.Must(t).Error(subject.CommitTx(tx))
+		})
+
+		s.Test(`BeginTx + multiple PrepareTx, yields error`, func(t *testcase.T) {
+			tx, err := subject.BeginTx(c.MakeContext(t))
+			assert.Must(t).NoError(err)
+			defer func() { _ = subject.RollbackTx(tx) }()
+			tx, err = subject.PrepareTx(tx)
+			assert.Must(t).NoError(err)
+			_, err = subject.PrepareTx(tx)
+			assert.Must(t).Error(err)
+		})
+
+		s.Test(`BeginTx + CommitTx + PrepareTx, yields error`, func(t *testcase.T) {
+			tx, err := subject.BeginTx(c.MakeContext(t))
+			assert.Must(t).NoError(err)
+			assert.Must(t).NoError(subject.CommitTx(tx))
+			_, err = subject.PrepareTx(tx)
+			assert.Must(t).Error(err)
+		})
+
+		s.Test(`PrepareTx without a transaction, yields error`, func(t *testcase.T) {
+			_, err := subject.PrepareTx(c.MakeContext(t))
+			assert.Must(t).Error(err)
+		})
+
+		s.Test(`PrepareTx with a global transaction ID in the context`, func(t *testcase.T) {
+			tx, err := subject.BeginTx(c.MakeContext(t))
+			assert.Must(t).NoError(err)
+			tx, err = subject.PrepareTx(comproto.ContextWithTxID(tx, t.Random.UUID()))
+			assert.Must(t).NoError(err)
+			assert.Must(t).NoError(subject.CommitTx(tx))
+		})
+
+		s.Test(`PrepareTx with a cancelled context, yields error`, func(t *testcase.T) {
+			ctx, cancel := context.WithCancel(c.MakeContext(t))
+			tx, err := subject.BeginTx(ctx)
+			assert.Must(t).NoError(err)
+			cancel()
+			_, err = subject.PrepareTx(tx)
+			assert.Must(t).ErrorIs(context.Canceled, err)
+			_ = subject.RollbackTx(tx)
+		})
+	})
+
+	if recoverer, ok := subject.(comproto.TwoPhaseCommitRecoverer); ok {
+		s.Context("supplies TwoPhaseCommitRecoverer", func(s *testcase.Spec) {
+			s.HasSideEffect()
+
+			txID := testcase.Let(s, func(t *testcase.T) string {
+				return t.Random.UUID()
+			})
+			prepare := func(t *testcase.T) {
+				tx, err := subject.BeginTx(c.MakeContext(t))
+				assert.Must(t).NoError(err)
+				t.Defer(func() { _ = subject.RollbackTx(tx) }) // release the in-process transaction
+				_, err = subject.PrepareTx(comproto.ContextWithTxID(tx, txID.Get(t)))
+				assert.Must(t).NoError(err)
+			}
+
+			s.Test(`CommitPreparedTx commits a prepared transaction by its ID`, func(t *testcase.T) {
+				prepare(t)
+				assert.Must(t).NoError(recoverer.CommitPreparedTx(c.MakeContext(t), txID.Get(t)))
+				assert.Must(t).ErrorIs(comproto.ErrPreparedTxNotFound,
+					recoverer.CommitPreparedTx(c.MakeContext(t), txID.Get(t)))
+			})
+
+			s.Test(`RollbackPreparedTx rolls back a prepared transaction by its ID`, func(t *testcase.T) {
+				prepare(t)
+				assert.Must(t).NoError(recoverer.RollbackPreparedTx(c.MakeContext(t), txID.Get(t)))
+				assert.Must(t).ErrorIs(comproto.ErrPreparedTxNotFound,
+					recoverer.RollbackPreparedTx(c.MakeContext(t), txID.Get(t)))
+			})
+
+			s.Test(`unknown transaction ID yields ErrPreparedTxNotFound`, func(t *testcase.T) {
+				assert.Must(t).ErrorIs(comproto.ErrPreparedTxNotFound,
+					recoverer.CommitPreparedTx(c.MakeContext(t), txID.Get(t)))
+				assert.Must(t).ErrorIs(comproto.ErrPreparedTxNotFound,
+					recoverer.RollbackPreparedTx(c.MakeContext(t), txID.Get(t)))
+			})
+		})
+	}
+
+	return s.AsSuite("TwoPhaseCommitProtocol")
+}
@@ -6,6 +6,10 @@ import (
 	"context"
 	"io"
 	"iter"
+	"time"
+
+	"go.llib.dev/frameless/port/crud/crudquery"
+	"go.llib.dev/frameless/port/pubsub"
 )
 
 type Creator[ENT any] interface {
@@ -63,12 +67,115 @@ type AllFinder[ENT any] interface {
 	FindAll(context.Context) iter.Seq2[ENT, error]
 }
 
+// Querier finds the entities that match a portable crudquery.Query.
+type Querier[ENT any] interface {
+	// Query returns the entities that match the given crudquery.Query.
+	//
+	// Unlike QueryManyMethodSignature based methods, the query is expressed as a portable data structure,
+	// so the same search can be executed against any adapter that implements Querier.
+	// An invalid query, such as a reference to an unknown field, should yield crudquery.ErrInvalidQuery.
+	Query(ctx context.Context, q crudquery.Query) iter.Seq2[ENT, error]
+}
+
+// PageFinder pages through the entities of a resource with cursor based pagination.
+type PageFinder[ENT any] interface {
+	// FindPage returns at most size entities, starting right after the position that the cursor points to.
+	// An empty cursor requests the first page, and the size must be a positive number.
+	//
+	// Unlike offset based pagination, the cursor remembers where the previous page ended,
+	// so entities created or deleted while a client pages through the resource won't cause skipped or repeated entries.
+	// The cursor is opaque for the caller, and only the PageFinder that issued it can interpret it.
+	// An unrecognisable cursor should yield ErrInvalidCursor.
+	FindPage(ctx context.Context, cursor Cursor, size int) (Page[ENT], error)
+}
+
+// Cursor is an opaque continuation token that points to a position in a resource.
+type Cursor string
+
+// Page is a consecutive part of the entities of a resource.
+type Page[ENT any] struct {
+	// Entries are the entities on the page.
+	Entries []ENT
+	// Next is the cursor that points to the next page.
+	// Next is empty when there are no more pages left.
+	Next Cursor
+}
+
+// HasNext reports whether there is a page after this one.
+func (p Page[ENT]) HasNext() bool {
+	return p.Next != ""
+}
+
+// Counter counts the entities of a resource, without fetching them.
+type Counter interface {
+	// Count returns the number of entities in the resource.
+	// Soft deleted entities are not counted.
+	Count(ctx context.Context) (int, error)
+}
+
+// QueryCounter counts the entities that match a crudquery.Query, without fetching them.
+type QueryCounter interface {
+	// CountQuery returns the number of entities that match the query's Where expression.
+	// Ordering, Limit and Offset don't affect the result,
+	// so the count tells the total number of matching entities, regardless of the requested page.
+	// An invalid query should yield crudquery.ErrInvalidQuery.
+	CountQuery(ctx context.Context, q crudquery.Query) (int, error)
+}
+
+// GroupCounter counts the entities per distinct value of a field.
+type GroupCounter interface {
+	// CountGroups returns the number of entities for each distinct value of the given field,
+	// among the entities that match the query's Where expression.
+	// The field is resolved the same way as the fields of a crudquery.Query, see crudquery.MatchField.
+	// Values without any entity are not part of the result, and the order of the groups is unspecified.
+	CountGroups(ctx context.Context, field string, q crudquery.Query) ([]GroupCount, error)
+}
+
+// GroupCount is the number of entities that share the same field value.
+type GroupCount struct {
+	Value any
+	Count int
+}
+
+// Exister tells whether an entity exists, without fetching it.
+type Exister[ID any] interface {
+	// ExistsByID reports whether an entity is present with the given ID.
+	// A soft deleted entity is considered absent.
+	ExistsByID(ctx context.Context, id ID) (bool, error)
+}
+
 type Updater[ENT any] interface {
 	// Update will take a pointer to an entity and update the stored entity data by the values in received entity.
 	// The ENT must have a valid ID field, which referencing an existing entity in the external resource.
+	//
+	// When ENT has a version field (see extver), Update only succeeds if the received version matches the stored one.
+	// On success, the version is incremented both in the resource and in the received entity,
+	// otherwise ErrConcurrentModification is returned.
 	Update(ctx context.Context, ptr *ENT) error
 }
 
+// ManyCreator creates multiple entities in one go.
+type ManyCreator[ENT any] interface {
+	// CreateMany creates every given entity, following the behaviour of Creator.Create for each of them.
+	//
+	// The entities are created independently from each other,
+	// so a failing entity doesn't prevent the creation of the rest.
+	// When some of the entities couldn't be created, CreateMany returns a *BulkError,
+	// which tells which entities failed and why.
+	// Any other error means that none of the entities were created.
+	CreateMany(ctx context.Context, ptrs ...*ENT) error
+}
+
+// ManyUpdater updates multiple entities in one go.
+type ManyUpdater[ENT any] interface {
+	// UpdateMany updates every given entity, following the behaviour of Updater.Update for each of them.
+	//
+	// When some of the entities couldn't be updated, UpdateMany returns a *BulkError,
+	// which tells which entities failed and why, while the rest of the entities are updated.
+	// Any other error means that none of the entities were updated.
+	UpdateMany(ctx context.Context, ptrs ...*ENT) error
+}
+
 // Deleter request to destroy a business entity in the Resource that implement it's test.
 type Deleter[ID any] interface {
 	ByIDDeleter[ID]
@@ -80,11 +187,75 @@ type ByIDDeleter[ID any] interface {
 	DeleteByID(ctx context.Context, id ID) error
 }
 
+type ByIDsDeleter[ID any] interface {
+	// DeleteByIDs removes the entities that belong to the given IDs.
+	//
+	// When some of the IDs don't point to an existing entity, DeleteByIDs returns a *BulkError
+	// that reports ErrNotFound for them, while the rest of the entities are deleted.
+	// Any other error means that none of the entities were deleted.
+	DeleteByIDs(ctx context.Context, ids ...ID) error
+}
+
 type AllDeleter interface {
 	// DeleteAll will erase all entity from the resource that has <V> type
 	DeleteAll(context.Context) error
 }
 
+// SoftDeleter marks entities as deleted, while it keeps them around to allow their restoration.
+// A soft deleted entity is hidden from the finder methods, such as FindByID and FindAll.
+// ByIDDeleter.DeleteByID on the other hand, permanently removes an entity, even if it was soft deleted.
+type SoftDeleter[ENT, ID any] interface {
+	// SoftDeleteByID marks the entity as deleted.
+	// It returns ErrNotFound when there is no live entity with the given ID.
+	SoftDeleteByID(ctx context.Context, id ID) error
+	// Restore brings back a soft deleted entity.
+	// It returns ErrNotFound when there is no soft deleted entity with the given ID.
+	Restore(ctx context.Context, id ID) error
+	// FindDeleted returns the soft deleted entities.
+	FindDeleted(ctx context.Context) iter.Seq2[ENT, error]
+}
+
+// TemporalFinder looks into the past of a resource, based on when its changes were made.
+type TemporalFinder[ENT, ID any] interface {
+	// AsOf returns a read-only Finder, which reflects the state of the resource at the given point in time.
+	// An entity created after that time is absent, while an entity changed or deleted after it
+	// is found in the state it had at that time.
+	AsOf(ctx context.Context, at time.Time) (Finder[ENT, ID], error)
+}
+
+// ChangeSubscriber exposes the changes of a resource as a change feed.
+//
+// Only committed changes are delivered.
+// A mutation made within a transaction that was rolled back never appears in the feed,
+// and a mutation made within a transaction only appears after the transaction is committed.
+type ChangeSubscriber[ENT, ID any] interface {
+	// SubscribeChanges returns a subscription that yields the changes committed after the subscription is made.
+	// The changes of a transaction are delivered in the order they were made,
+	// but the changes of concurrent transactions are not guaranteed to follow their commit order.
+	// Every committed change is delivered, even if it was committed after a change that was already delivered.
+	// An ACK-ed change is considered delivered, a NACK-ed change is delivered again.
+	SubscribeChanges(ctx context.Context) pubsub.Subscription[Change[ENT, ID]]
+}
+
+// Change describes a committed mutation of an entity in a resource.
+type Change[ENT, ID any] struct {
+	Kind ChangeKind
+	// ID is the identifier of the changed entity.
+	ID ID
+	// Entity is the state of the entity after the change.
+	// In case of a Deleted change, it is the last known state of the entity before its deletion.
+	Entity ENT
+}
+
+// ChangeKind tells how an entity was changed.
+type ChangeKind string
+
+const (
+	Created ChangeKind = "created"
+	Updated ChangeKind = "updated"
+	Deleted ChangeKind = "deleted"
+)
+
 // Purger supplies functionality to purge a resource completely.
 // On high level this looks similar to what AllDeleter do,
 // but in case of an event logged resource, this will purge all the events.
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 
+	"go.llib.dev/frameless/pkg/errorkit"
 	"go.llib.dev/frameless/port/crud"
 )
 
@@ -36,3 +37,16 @@ func ExampleBatcher() {
 	_ = batchCreation.Add(DomainEntity{V: "baz"})
 	_ = batchCreation.Close()
 }
+
+func ExampleManyCreator() {
+	ctx := context.Background()
+	var repo crud.ManyCreator[DomainEntity]
+
+	ents := []*DomainEntity{{V: "foo"}, {V: "bar"}, {V: "baz"}}
+	err := repo.CreateMany(ctx, ents...)
+	if bulkErr, ok := errorkit.As[*crud.BulkError](err); ok {
+		for _, f := range bulkErr.Failures {
+			_ = ents[f.Index] // the entity that failed with f.Err
+		}
+	}
+}
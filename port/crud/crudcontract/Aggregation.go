@@ -0,0 +1,198 @@
+package crudcontract
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go.llib.dev/frameless/pkg/mapkit"
+	"go.llib.dev/frameless/pkg/pointer"
+	"go.llib.dev/frameless/pkg/slicekit"
+	"go.llib.dev/frameless/port/contract"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/crudquery"
+	"go.llib.dev/frameless/port/option"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/let"
+)
+
+// Counter verifies that the subject's Count follows the creation and deletion of the entities.
+func Counter[ENT, ID any](subject crud.Counter, opts ...Option[ENT, ID]) contract.Contract {
+	c := option.ToConfig[Config[ENT, ID]](opts)
+	s := testcase.NewSpec(nil)
+
+	ctx := let.With[context.Context](s, c.MakeContext)
+
+	count := func(t *testcase.T) int {
+		t.Helper()
+		n, err := subject.Count(ctx.Get(t))
+		assert.NoError(t, err)
+		return n
+	}
+
+	s.Test("created entities are counted", func(t *testcase.T) {
+		before := count(t)
+		n := t.Random.IntBetween(1, 3)
+		for range n {
+			ptr := pointer.Of(c.MakeEntity(t))
+			shouldStore(t, c, subject, ptr)
+			t.Cleanup(func() { tryDelete(t, c, subject, *ptr) })
+		}
+		assert.Equal(t, before+n, count(t))
+	})
+
+	s.Test("deleted entities are no longer counted", func(t *testcase.T) {
+		ptr := pointer.Of(c.MakeEntity(t))
+		shouldStore(t, c, subject, ptr)
+		t.Cleanup(func() { tryDelete(t, c, subject, *ptr) })
+		before := count(t)
+
+		shouldDelete(t, c, subject, ctx.Get(t), *ptr)
+		assert.Equal(t, before-1, count(t))
+	})
+
+	return s.AsSuite("Counter")
+}
+
+// QueryCounter verifies that the subject counts the entities that match a crudquery.Query,
+// the same way as a crud.Querier would find them.
+func QueryCounter[ENT, ID any](subject crud.QueryCounter, field QuerierField[ENT], opts ...Option[ENT, ID]) contract.Contract {
+	c := option.ToConfig[Config[ENT, ID]](opts)
+	s := testcase.NewSpec(nil)
+
+	var (
+		ctx    = let.With[context.Context](s, c.MakeContext)
+		values = let.Var(s, func(t *testcase.T) []any {
+			prefix := t.Random.StringNC(8, "abcdefghijklmnopqrstuvwxyz")
+			var vs []any
+			for i := range t.Random.IntBetween(3, 5) {
+				v := prefix + strconv.Itoa(i+1)
+				ptr := pointer.Of(c.MakeEntity(t))
+				field.Set(ptr, v)
+				shouldStore(t, c, subject, ptr)
+				t.Cleanup(func() { tryDelete(t, c, subject, *ptr) })
+				vs = append(vs, v)
+			}
+			return vs
+		}).EagerLoading(s)
+	)
+
+	count := func(t *testcase.T, q crudquery.Query) int {
+		t.Helper()
+		n, err := subject.CountQuery(ctx.Get(t), q)
+		assert.NoError(t, err)
+		return n
+	}
+
+	s.Test("the matching entities are counted", func(t *testcase.T) {
+		vs := values.Get(t)
+		assert.Equal(t, len(vs), count(t, crudquery.Where(crudquery.In(field.Name, vs...))))
+		assert.Equal(t, 1, count(t, crudquery.Where(crudquery.Eq(field.Name, vs[0]))))
+	})
+
+	s.Test("an empty query counts every entity", func(t *testcase.T) {
+		assert.True(t, len(values.Get(t)) <= count(t, crudquery.Query{}))
+	})
+
+	s.Test("ordering and pagination don't affect the count", func(t *testcase.T) {
+		vs := values.Get(t)
+		q := crudquery.Where(crudquery.In(field.Name, vs...)).Asc(field.Name).WithLimit(1).WithOffset(1)
+		assert.Equal(t, len(vs), count(t, q))
+	})
+
+	s.Test("an unknown field yields ErrInvalidQuery", func(t *testcase.T) {
+		_, err := subject.CountQuery(ctx.Get(t), crudquery.Where(crudquery.Eq("unknown_field_"+t.Random.StringNC(5, "abcdef"), 1)))
+		assert.ErrorIs(t, err, crudquery.ErrInvalidQuery)
+	})
+
+	return s.AsSuite("QueryCounter")
+}
+
+// GroupCounter verifies that the subject counts the entities per distinct field value.
+func GroupCounter[ENT, ID any](subject crud.GroupCounter, field QuerierField[ENT], opts ...Option[ENT, ID]) contract.Contract {
+	c := option.ToConfig[Config[ENT, ID]](opts)
+	s := testcase.NewSpec(nil)
+
+	var (
+		ctx = let.With[context.Context](s, c.MakeContext)
+		// expected is the number of stored entities per field value.
+		expected = let.Var(s, func(t *testcase.T) map[string]int {
+			prefix := t.Random.StringNC(8, "abcdefghijklmnopqrstuvwxyz")
+			var counts = make(map[string]int)
+			for i := range t.Random.IntBetween(2, 4) {
+				v := prefix + strconv.Itoa(i+1)
+				counts[v] = t.Random.IntBetween(1, 3)
+				for range counts[v] {
+					ptr := pointer.Of(c.MakeEntity(t))
+					field.Set(ptr, v)
+					shouldStore(t, c, subject, ptr)
+					t.Cleanup(func() { tryDelete(t, c, subject, *ptr) })
+				}
+			}
+			return counts
+		}).EagerLoading(s)
+	)
+
+	s.Test("entities are counted per field value", func(t *testcase.T) {
+		vs := slicekit.Map(mapkit.Keys(expected.Get(t)), func(v string) any { return v })
+		groups, err := subject.CountGroups(ctx.Get(t), field.Name, crudquery.Where(crudquery.In(field.Name, vs...)))
+		assert.NoError(t, err)
+
+		got := make(map[string]int)
+		for _, g := range groups {
+			got[fmt.Sprint(g.Value)] = g.Count
+		}
+		assert.Equal(t, expected.Get(t), got)
+	})
+
+	s.Test("the query narrows down the counted entities", func(t *testcase.T) {
+		vs := mapkit.Keys(expected.Get(t))
+		v := vs[t.Random.IntN(len(vs))]
+		groups, err := subject.CountGroups(ctx.Get(t), field.Name, crudquery.Where(crudquery.Eq(field.Name, v)))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(groups))
+		assert.Equal(t, v, fmt.Sprint(groups[0].Value))
+		assert.Equal(t, expected.Get(t)[v], groups[0].Count)
+	})
+
+	s.Test("an unknown field yields ErrInvalidQuery", func(t *testcase.T) {
+		_, err := subject.CountGroups(ctx.Get(t), "unknown_field_"+t.Random.StringNC(5, "abcdef"), crudquery.Query{})
+		assert.ErrorIs(t, err, crudquery.ErrInvalidQuery)
+	})
+
+	return s.AsSuite("GroupCounter")
+}
+
+// Exister verifies that the subject tells apart the present entities from the absent ones.
+func Exister[ENT, ID any](subject crud.Exister[ID], opts ...Option[ENT, ID]) contract.Contract {
+	c := option.ToConfig[Config[ENT, ID]](opts)
+	s := testcase.NewSpec(nil)
+
+	var (
+		ctx = let.With[context.Context](s, c.MakeContext)
+		ptr = let.Var(s, func(t *testcase.T) *ENT {
+			ptr := pointer.Of(c.MakeEntity(t))
+			shouldStore(t, c, subject, ptr)
+			t.Cleanup(func() { tryDelete(t, c, subject, *ptr) })
+			return ptr
+		})
+	)
+
+	s.Test("a stored entity exists", func(t *testcase.T) {
+		id := c.Helper().HasID(t, ptr.Get(t))
+		ok, err := subject.ExistsByID(ctx.Get(t), id)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	s.Test("a deleted entity doesn't exist", func(t *testcase.T) {
+		id := c.Helper().HasID(t, ptr.Get(t))
+		shouldDelete(t, c, subject, ctx.Get(t), *ptr.Get(t))
+		ok, err := subject.ExistsByID(ctx.Get(t), id)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	return s.AsSuite("Exister")
+}
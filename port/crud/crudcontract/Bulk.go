@@ -0,0 +1,218 @@
+package crudcontract
+
+import (
+	"context"
+
+	"go.llib.dev/frameless/pkg/errorkit"
+	"go.llib.dev/frameless/pkg/pointer"
+	"go.llib.dev/frameless/port/contract"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/option"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/let"
+)
+
+// ManyCreator verifies that the subject creates every given entity,
+// and when some of them can't be created, it reports them with a *crud.BulkError, while it creates the rest.
+func ManyCreator[ENT, ID any](subject crud.ManyCreator[ENT], opts ...Option[ENT, ID]) contract.Contract {
+	c := option.ToConfig[Config[ENT, ID]](opts)
+	s := testcase.NewSpec(nil)
+
+	var (
+		ctx  = let.With[context.Context](s, c.MakeContext)
+		ptrs = let.Var(s, func(t *testcase.T) []*ENT {
+			var ptrs []*ENT
+			t.Random.Repeat(2, 5, func() {
+				ptrs = append(ptrs, pointer.Of(c.MakeEntity(t)))
+			})
+			return ptrs
+		})
+	)
+	act := func(t *testcase.T) error {
+		err := subject.CreateMany(ctx.Get(t), ptrs.Get(t)...)
+		t.Cleanup(func() {
+			for _, ptr := range ptrs.Get(t) {
+				tryDelete(t, c, subject, *ptr)
+			}
+		})
+		return err
+	}
+
+	s.Test("every entity is created", func(t *testcase.T) {
+		assert.NoError(t, act(t))
+		for _, ptr := range ptrs.Get(t) {
+			id := c.Helper().HasID(t, ptr)
+			got := shouldPresent[ENT, ID](t, c, subject, ctx.Get(t), id)
+			assert.Equal(t, *ptr, *got)
+		}
+	})
+
+	s.Test("no entity is a no-op", func(t *testcase.T) {
+		assert.NoError(t, subject.CreateMany(ctx.Get(t)))
+	})
+
+	s.Test("an already existing entity is reported, while the rest is created", func(t *testcase.T) {
+		existing := pointer.Of(c.MakeEntity(t))
+		shouldStore(t, c, subject, existing)
+		t.Cleanup(func() { tryDelete(t, c, subject, *existing) })
+
+		index := t.Random.IntN(len(ptrs.Get(t)))
+		ptrs.Get(t)[index] = pointer.Of(*existing)
+
+		err := act(t)
+		assert.ErrorIs(t, err, crud.ErrAlreadyExists)
+		bulkErr, ok := errorkit.As[*crud.BulkError](err)
+		assert.True(t, ok, "*crud.BulkError was expected")
+		assert.Equal(t, 1, len(bulkErr.Failures))
+		assert.Equal(t, index, bulkErr.Failures[0].Index)
+
+		for i, ptr := range ptrs.Get(t) {
+			if i == index {
+				continue
+			}
+			shouldPresent[ENT, ID](t, c, subject, ctx.Get(t), c.Helper().HasID(t, ptr))
+		}
+	})
+
+	s.Test("canceled context yields the context error", func(t *testcase.T) {
+		cctx, cancel := context.WithCancel(ctx.Get(t))
+		cancel()
+		ctx.Set(t, cctx)
+		assert.ErrorIs(t, act(t), context.Canceled)
+	})
+
+	return s.AsSuite("ManyCreator")
+}
+
+// ManyUpdater verifies that the subject updates every given entity,
+// and when some of them can't be updated, it reports them with a *crud.BulkError, while it updates the rest.
+func ManyUpdater[ENT, ID any](subject crud.ManyUpdater[ENT], opts ...Option[ENT, ID]) contract.Contract {
+	c := option.ToConfig[Config[ENT, ID]](opts)
+	s := testcase.NewSpec(nil)
+
+	var (
+		ctx  = let.With[context.Context](s, c.MakeContext)
+		ptrs = let.Var(s, func(t *testcase.T) []*ENT {
+			var ptrs []*ENT
+			t.Random.Repeat(2, 5, func() {
+				ptr := pointer.Of(c.MakeEntity(t))
+				shouldStore(t, c, subject, ptr)
+				t.Cleanup(func() { tryDelete(t, c, subject, *ptr) })
+				ptrs = append(ptrs, ptr)
+			})
+			return ptrs
+		}).EagerLoading(s)
+	)
+	act := func(t *testcase.T) error {
+		for _, ptr := range ptrs.Get(t) {
+			c.ModifyEntity(t, ptr)
+		}
+		return subject.UpdateMany(ctx.Get(t), ptrs.Get(t)...)
+	}
+
+	s.Test("every entity is updated", func(t *testcase.T) {
+		assert.NoError(t, act(t))
+		for _, ptr := range ptrs.Get(t) {
+			got := shouldPresent[ENT, ID](t, c, subject, ctx.Get(t), c.Helper().HasID(t, ptr))
+			assert.Equal(t, *ptr, *got)
+		}
+	})
+
+	s.Test("no entity is a no-op", func(t *testcase.T) {
+		assert.NoError(t, subject.UpdateMany(ctx.Get(t)))
+	})
+
+	s.Test("an absent entity is reported with ErrNotFound, while the rest is updated", func(t *testcase.T) {
+		index := t.Random.IntN(len(ptrs.Get(t)))
+		shouldDelete(t, c, subject, ctx.Get(t), *ptrs.Get(t)[index])
+
+		err := act(t)
+		assert.ErrorIs(t, err, crud.ErrNotFound)
+		bulkErr, ok := errorkit.As[*crud.BulkError](err)
+		assert.True(t, ok, "*crud.BulkError was expected")
+		assert.Equal(t, 1, len(bulkErr.Failures))
+		assert.Equal(t, index, bulkErr.Failures[0].Index)
+
+		for i, ptr := range ptrs.Get(t) {
+			id := c.Helper().HasID(t, ptr)
+			if i == index {
+				shouldAbsent[ENT, ID](t, c, subject, ctx.Get(t), id)
+				continue
+			}
+			got := shouldPresent[ENT, ID](t, c, subject, ctx.Get(t), id)
+			assert.Equal(t, *ptr, *got)
+		}
+	})
+
+	s.Test("canceled context yields the context error", func(t *testcase.T) {
+		cctx, cancel := context.WithCancel(ctx.Get(t))
+		cancel()
+		ctx.Set(t, cctx)
+		assert.ErrorIs(t, act(t), context.Canceled)
+	})
+
+	return s.AsSuite("ManyUpdater")
+}
+
+// ByIDsDeleter verifies that the subject deletes the entities of every given ID,
+// and when some of the IDs are absent, it reports them with a *crud.BulkError, while it deletes the rest.
+func ByIDsDeleter[ENT, ID any](subject crud.ByIDsDeleter[ID], opts ...Option[ENT, ID]) contract.Contract {
+	c := option.ToConfig[Config[ENT, ID]](opts)
+	s := testcase.NewSpec(nil)
+
+	var (
+		ctx = let.With[context.Context](s, c.MakeContext)
+		ids = let.Var(s, func(t *testcase.T) []ID {
+			var ids []ID
+			t.Random.Repeat(2, 5, func() {
+				ptr := pointer.Of(c.MakeEntity(t))
+				shouldStore(t, c, subject, ptr)
+				t.Cleanup(func() { tryDelete(t, c, subject, *ptr) })
+				ids = append(ids, c.Helper().HasID(t, ptr))
+			})
+			return ids
+		}).EagerLoading(s)
+	)
+	act := func(t *testcase.T) error {
+		return subject.DeleteByIDs(ctx.Get(t), ids.Get(t)...)
+	}
+
+	s.Test("every entity is deleted", func(t *testcase.T) {
+		assert.NoError(t, act(t))
+		for _, id := range ids.Get(t) {
+			shouldAbsent[ENT, ID](t, c, subject, ctx.Get(t), id)
+		}
+	})
+
+	s.Test("no ID is a no-op", func(t *testcase.T) {
+		assert.NoError(t, subject.DeleteByIDs(ctx.Get(t)))
+	})
+
+	s.Test("an absent ID is reported with ErrNotFound, while the rest is deleted", func(t *testcase.T) {
+		index := t.Random.IntN(len(ids.Get(t)))
+		assert.NoError(t, subject.DeleteByIDs(ctx.Get(t), ids.Get(t)[index]))
+
+		err := act(t)
+		assert.ErrorIs(t, err, crud.ErrNotFound)
+		bulkErr, ok := errorkit.As[*crud.BulkError](err)
+		assert.True(t, ok, "*crud.BulkError was expected")
+		assert.Equal(t, 1, len(bulkErr.Failures))
+		assert.Equal(t, index, bulkErr.Failures[0].Index)
+
+		for _, id := range ids.Get(t) {
+			shouldAbsent[ENT, ID](t, c, subject, ctx.Get(t), id)
+		}
+	})
+
+	s.Test("canceled context yields the context error", func(t *testcase.T) {
+		cctx, cancel := context.WithCancel(ctx.Get(t))
+		cancel()
+		assert.ErrorIs(t, subject.DeleteByIDs(cctx, ids.Get(t)...), context.Canceled)
+		for _, id := range ids.Get(t) {
+			shouldPresent[ENT, ID](t, c, subject, ctx.Get(t), id)
+		}
+	})
+
+	return s.AsSuite("ByIDsDeleter")
+}
@@ -0,0 +1,238 @@
+package crudcontract
+
+import (
+	"context"
+	"testing"
+
+	"go.llib.dev/frameless/pkg/pointer"
+	"go.llib.dev/frameless/pkg/reflectkit"
+	"go.llib.dev/frameless/pkg/slicekit"
+	"go.llib.dev/frameless/port/comproto"
+	"go.llib.dev/frameless/port/contract"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/option"
+	"go.llib.dev/frameless/port/pubsub"
+	"go.llib.dev/frameless/port/pubsub/pubsubtest"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/let"
+)
+
+// ChangeSubscriber verifies that the mutations made through the subject
+// are delivered as crud.Change events through its change feed,
+// and that only the committed mutations are delivered.
+func ChangeSubscriber[ENT, ID any](subject crud.ChangeSubscriber[ENT, ID], opts ...Option[ENT, ID]) contract.Contract {
+	c := option.ToConfig[Config[ENT, ID]](opts)
+	s := testcase.NewSpec(nil)
+
+	var (
+		ctx = let.With[context.Context](s, c.MakeContext)
+		sub = let.Var(s, func(t *testcase.T) *pubsubtest.AsyncResults[crud.Change[ENT, ID]] {
+			return pubsubtest.Subscribe[crud.Change[ENT, ID]](t, changeSubscriber[ENT, ID]{S: subject}, ctx.Get(t))
+		}).EagerLoading(s)
+		ptr = let.Var(s, func(t *testcase.T) *ENT {
+			ptr := pointer.Of(c.MakeEntity(t))
+			shouldStore(t, c, subject, ptr)
+			t.Cleanup(func() { tryDelete(t, c, subject, *ptr) })
+			return ptr
+		})
+	)
+
+	changesOf := func(changes []crud.Change[ENT, ID], id ID) []crud.Change[ENT, ID] {
+		return slicekit.Filter(changes, func(change crud.Change[ENT, ID]) bool {
+			return reflectkit.Equal(change.ID, id)
+		})
+	}
+
+	kindsOf := func(changes []crud.Change[ENT, ID]) []crud.ChangeKind {
+		return slicekit.Map(changes, func(change crud.Change[ENT, ID]) crud.ChangeKind {
+			return change.Kind
+		})
+	}
+
+	s.Test("creating an entity is delivered as a Created change", func(t *testcase.T) {
+		id := c.Helper().HasID(t, ptr.Get(t))
+
+		sub.Get(t).Eventually(t, func(tb testing.TB, changes []crud.Change[ENT, ID]) {
+			assert.Equal(tb, []crud.Change[ENT, ID]{
+				{Kind: crud.Created, ID: id, Entity: *ptr.Get(t)},
+			}, changesOf(changes, id))
+		})
+	})
+
+	s.Test("updating an entity is delivered as an Updated change", func(t *testcase.T) {
+		updater, ok := subject.(crud.Updater[ENT])
+		if !ok {
+			t.Skipf("%T doesn't implement crud.Updater", subject)
+		}
+		id := c.Helper().HasID(t, ptr.Get(t))
+		ent := *ptr.Get(t)
+		c.ModifyEntity(t, &ent)
+		assert.NoError(t, updater.Update(ctx.Get(t), &ent))
+
+		sub.Get(t).Eventually(t, func(tb testing.TB, changes []crud.Change[ENT, ID]) {
+			assert.Equal(tb, []crud.Change[ENT, ID]{
+				{Kind: crud.Created, ID: id, Entity: *ptr.Get(t)},
+				{Kind: crud.Updated, ID: id, Entity: ent},
+			}, changesOf(changes, id))
+		})
+	})
+
+	s.Test("deleting an entity is delivered as a Deleted change with the last state of the entity", func(t *testcase.T) {
+		id := c.Helper().HasID(t, ptr.Get(t))
+		shouldDelete(t, c, subject, ctx.Get(t), *ptr.Get(t))
+
+		sub.Get(t).Eventually(t, func(tb testing.TB, changes []crud.Change[ENT, ID]) {
+			assert.Equal(tb, []crud.Change[ENT, ID]{
+				{Kind: crud.Created, ID: id, Entity: *ptr.Get(t)},
+				{Kind: crud.Deleted, ID: id, Entity: *ptr.Get(t)},
+			}, changesOf(changes, id))
+		})
+	})
+
+	s.Test("changes of an entity are delivered in the order of their mutation", func(t *testcase.T) {
+		updater, ok := subject.(crud.Updater[ENT])
+		if !ok {
+			t.Skipf("%T doesn't implement crud.Updater", subject)
+		}
+		id := c.Helper().HasID(t, ptr.Get(t))
+		exp := []crud.ChangeKind{crud.Created}
+		t.Random.Repeat(1, 3, func() {
+			ent := *shouldPresent[ENT, ID](t, c, subject, ctx.Get(t), id)
+			c.ModifyEntity(t, &ent)
+			assert.NoError(t, updater.Update(ctx.Get(t), &ent))
+			exp = append(exp, crud.Updated)
+		})
+		shouldDelete(t, c, subject, ctx.Get(t), *ptr.Get(t))
+		exp = append(exp, crud.Deleted)
+
+		sub.Get(t).Eventually(t, func(tb testing.TB, changes []crud.Change[ENT, ID]) {
+			assert.Equal(tb, exp, kindsOf(changesOf(changes, id)))
+		})
+	})
+
+	s.Context("transaction", func(s *testcase.Spec) {
+		tm := let.Var(s, func(t *testcase.T) comproto.OnePhaseCommitProtocol {
+			if c.OnePhaseCommit != nil {
+				return c.OnePhaseCommit
+			}
+			tm, ok := any(subject).(comproto.OnePhaseCommitProtocol)
+			if !ok {
+				t.Skipf("%T doesn't implement comproto.OnePhaseCommitProtocol", subject)
+			}
+			return tm
+		})
+
+		create := func(t *testcase.T, ctx context.Context) *ENT {
+			creator, ok := subject.(crud.Creator[ENT])
+			if !ok {
+				t.Skipf("%T doesn't implement crud.Creator", subject)
+			}
+			ptr := pointer.Of(c.MakeEntity(t))
+			assert.NoError(t, creator.Create(ctx, ptr))
+			t.Cleanup(func() { tryDelete(t, c, subject, *ptr) })
+			return ptr
+		}
+
+		s.Test("a change made within a transaction is delivered only after the commit", func(t *testcase.T) {
+			tx, err := tm.Get(t).BeginTx(ctx.Get(t))
+			assert.NoError(t, err)
+			ptr := create(t, tx)
+			id := c.Helper().HasID(t, ptr)
+
+			pubsubtest.Waiter.Wait()
+			assert.Empty(t, changesOf(sub.Get(t).Values(), id),
+				"uncommitted change should not be delivered")
+
+			assert.NoError(t, tm.Get(t).CommitTx(tx))
+			sub.Get(t).Eventually(t, func(tb testing.TB, changes []crud.Change[ENT, ID]) {
+				assert.Equal(tb, []crud.ChangeKind{crud.Created}, kindsOf(changesOf(changes, id)))
+			})
+		})
+
+		s.Test("the changes of overlapping transactions are all delivered, regardless of their commit order", func(t *testcase.T) {
+			tx1, err := tm.Get(t).BeginTx(ctx.Get(t))
+			assert.NoError(t, err)
+			ptr1 := create(t, tx1)
+			id1 := c.Helper().HasID(t, ptr1)
+
+			tx2, err := tm.Get(t).BeginTx(ctx.Get(t))
+			assert.NoError(t, err)
+			ptr2 := create(t, tx2)
+			id2 := c.Helper().HasID(t, ptr2)
+
+			// the transaction that started later commits first
+			assert.NoError(t, tm.Get(t).CommitTx(tx2))
+			assert.NoError(t, tm.Get(t).CommitTx(tx1))
+
+			sub.Get(t).Eventually(t, func(tb testing.TB, changes []crud.Change[ENT, ID]) {
+				assert.Equal(tb, []crud.ChangeKind{crud.Created}, kindsOf(changesOf(changes, id1)))
+				assert.Equal(tb, []crud.ChangeKind{crud.Created}, kindsOf(changesOf(changes, id2)))
+			})
+		})
+
+		s.Test("a change made within a rolled back transaction is never delivered", func(t *testcase.T) {
+			tx, err := tm.Get(t).BeginTx(ctx.Get(t))
+			assert.NoError(t, err)
+			rolledBack := create(t, tx)
+			assert.NoError(t, tm.Get(t).RollbackTx(tx))
+
+			// a later committed change marks that the feed has already progressed past the rolled back one.
+			id := c.Helper().HasID(t, ptr.Get(t))
+			sub.Get(t).Eventually(t, func(tb testing.TB, changes []crud.Change[ENT, ID]) {
+				assert.NotEmpty(tb, changesOf(changes, id))
+			})
+
+			rolledBackID, ok := lookupNonZeroID(c, *rolledBack)
+			if !ok {
+				return // without an ID, the rolled back entity can't leave a trace in the feed.
+			}
+			assert.Empty(t, changesOf(sub.Get(t).Values(), rolledBackID),
+				"rolled back change should not be delivered")
+		})
+	})
+
+	s.Test("a NACK-ed change is delivered again", func(t *testcase.T) {
+		sctx, cancel := context.WithTimeout(ctx.Get(t), pubsubtest.Waiter.Timeout)
+		defer cancel()
+		var (
+			subscription = subject.SubscribeChanges(sctx)
+			id           = c.Helper().HasID(t, ptr.Get(t))
+		)
+		var nacked bool
+		for msg, err := range subscription {
+			assert.NoError(t, err)
+			if !reflectkit.Equal(msg.Data().ID, id) {
+				assert.NoError(t, msg.ACK())
+				continue
+			}
+			if !nacked {
+				nacked = true
+				assert.NoError(t, msg.NACK())
+				continue
+			}
+			assert.Equal(t, crud.Change[ENT, ID]{Kind: crud.Created, ID: id, Entity: *ptr.Get(t)}, msg.Data())
+			assert.NoError(t, msg.ACK())
+			break
+		}
+		assert.True(t, nacked)
+	})
+
+	s.Test("canceled context ends the subscription with the context error", func(t *testcase.T) {
+		cctx, cancel := context.WithCancel(ctx.Get(t))
+		cancel()
+		for _, err := range subject.SubscribeChanges(cctx) {
+			assert.ErrorIs(t, err, context.Canceled)
+		}
+	})
+
+	return s.AsSuite("ChangeSubscriber")
+}
+
+type changeSubscriber[ENT, ID any] struct {
+	S crud.ChangeSubscriber[ENT, ID]
+}
+
+func (s changeSubscriber[ENT, ID]) Subscribe(ctx context.Context) pubsub.Subscription[crud.Change[ENT, ID]] {
+	return s.S.SubscribeChanges(ctx)
+}
@@ -0,0 +1,119 @@
+package crudcontract
+
+import (
+	"context"
+
+	"go.llib.dev/frameless/pkg/pointer"
+	"go.llib.dev/frameless/port/contract"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/option"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/let"
+)
+
+// PageFinder verifies that paging through the subject with crud.PageFinder visits every entity exactly once,
+// even if the resource changes between two page requests.
+func PageFinder[ENT, ID any](subject crud.PageFinder[ENT], opts ...Option[ENT, ID]) contract.Contract {
+	c := option.ToConfig[Config[ENT, ID]](opts)
+	s := testcase.NewSpec(nil)
+
+	var (
+		ctx  = let.With[context.Context](s, c.MakeContext)
+		size = let.IntB(s, 1, 3)
+		ents = let.Var(s, func(t *testcase.T) []ENT {
+			var ents []ENT
+			for range t.Random.IntBetween(3, 7) {
+				ptr := pointer.Of(c.MakeEntity(t))
+				shouldStore(t, c, subject, ptr)
+				t.Cleanup(func() { tryDelete(t, c, subject, *ptr) })
+				ents = append(ents, *ptr)
+			}
+			return ents
+		}).EagerLoading(s)
+	)
+
+	// pageThrough collects the entities of every page that comes after the cursor.
+	pageThrough := func(t *testcase.T, cursor crud.Cursor) []ENT {
+		t.Helper()
+		var got []ENT
+		for {
+			page, err := subject.FindPage(ctx.Get(t), cursor, size.Get(t))
+			assert.NoError(t, err)
+			assert.True(t, len(page.Entries) <= size.Get(t),
+				"the page should not have more entries than the requested size")
+			got = append(got, page.Entries...)
+			if !page.HasNext() {
+				return got
+			}
+			assert.NotEqual(t, cursor, page.Next, "the next cursor should move forward")
+			cursor = page.Next
+		}
+	}
+
+	countByID := func(vs []ENT) map[any]int {
+		var m = make(map[any]int)
+		for _, v := range vs {
+			m[c.IDA.Get(v)]++
+		}
+		return m
+	}
+
+	s.Test("paging through the resource yields every entity exactly once", func(t *testcase.T) {
+		got := countByID(pageThrough(t, ""))
+		for _, ent := range ents.Get(t) {
+			assert.Equal(t, 1, got[c.IDA.Get(ent)], "the entity should be present exactly once")
+		}
+	})
+
+	s.Test("the first page is not empty when the resource has entities", func(t *testcase.T) {
+		page, err := subject.FindPage(ctx.Get(t), "", size.Get(t))
+		assert.NoError(t, err)
+		assert.NotEmpty(t, page.Entries)
+	})
+
+	s.Test("entities are neither skipped nor repeated when the resource changes between two pages", func(t *testcase.T) {
+		first, err := subject.FindPage(ctx.Get(t), "", size.Get(t))
+		assert.NoError(t, err)
+
+		seen := countByID(first.Entries)
+		var remaining []ENT
+		for _, ent := range ents.Get(t) {
+			if seen[c.IDA.Get(ent)] == 0 {
+				remaining = append(remaining, ent)
+			}
+		}
+		if !first.HasNext() || len(remaining) == 0 {
+			t.Skip("the stored entities fit on the first page")
+		}
+
+		// a deleted entity from the already visited page would shift an offset based pagination
+		deleter, ok := subject.(crud.ByIDDeleter[ID])
+		if !ok {
+			t.Skipf("%T doesn't implement crud.ByIDDeleter", subject)
+		}
+		assert.NoError(t, deleter.DeleteByID(ctx.Get(t), c.IDA.Get(first.Entries[0])))
+
+		got := countByID(pageThrough(t, first.Next))
+		for _, ent := range remaining {
+			assert.Equal(t, 1, got[c.IDA.Get(ent)], "the entity should be present exactly once")
+		}
+		for _, ent := range first.Entries {
+			assert.Equal(t, 0, got[c.IDA.Get(ent)], "entities from the previous page should not be repeated")
+		}
+	})
+
+	s.Test("an unknown cursor yields ErrInvalidCursor", func(t *testcase.T) {
+		_, err := subject.FindPage(ctx.Get(t), "!not a cursor!", size.Get(t))
+		assert.ErrorIs(t, err, crud.ErrInvalidCursor)
+	})
+
+	s.Test("canceled context yields the context error", func(t *testcase.T) {
+		cctx, cancel := context.WithCancel(ctx.Get(t))
+		cancel()
+		_, err := subject.FindPage(cctx, "", size.Get(t))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	return s.AsSuite("PageFinder")
+}
@@ -0,0 +1,188 @@
+package crudcontract
+
+import (
+	"context"
+	"slices"
+	"strconv"
+
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/pointer"
+	"go.llib.dev/frameless/pkg/slicekit"
+	"go.llib.dev/frameless/port/contract"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/crudquery"
+	"go.llib.dev/frameless/port/option"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/let"
+)
+
+// QuerierField describes a string field of ENT which the Querier contract can use for its queries.
+type QuerierField[ENT any] struct {
+	// Name is the field name as the subject's crud.Querier understands it.
+	Name string
+	// Set assigns a value to the field that Name refers to.
+	Set func(ptr *ENT, value string)
+}
+
+// Querier verifies that the subject interprets a crudquery.Query the same way as every other crud.Querier.
+//
+// The contract stores entities with controlled values in the field described by QuerierField,
+// then checks filtering, ordering and pagination against them.
+// The stored field values are never NULL/nil, as the ordering of nil values is adapter specific.
+func Querier[ENT, ID any](subject crud.Querier[ENT], field QuerierField[ENT], opts ...Option[ENT, ID]) contract.Contract {
+	c := option.ToConfig[Config[ENT, ID]](opts)
+	s := testcase.NewSpec(nil)
+
+	var (
+		ctx = let.With[context.Context](s, c.MakeContext)
+		// values are the field values of the stored entities, in ascending order.
+		values = let.Var(s, func(t *testcase.T) []string {
+			prefix := t.Random.StringNC(8, "abcdefghijklmnopqrstuvwxyz")
+			var vs []string
+			for i := range t.Random.IntBetween(4, 6) {
+				vs = append(vs, prefix+strconv.Itoa(i+1))
+			}
+			return vs
+		})
+		// ents are the stored entities, ordered the same way as values.
+		ents = let.Var(s, func(t *testcase.T) []ENT {
+			var ents []ENT
+			for _, v := range values.Get(t) {
+				ptr := pointer.Of(c.MakeEntity(t))
+				field.Set(ptr, v)
+				shouldStore(t, c, subject, ptr)
+				t.Cleanup(func() { tryDelete(t, c, subject, *ptr) })
+				ents = append(ents, *ptr)
+			}
+			return ents
+		}).EagerLoading(s)
+		// scope limits the query to the entities that the contract stored.
+		scope = func(t *testcase.T) crudquery.Expr {
+			return crudquery.In(field.Name, slicekit.Map(values.Get(t), func(v string) any { return v })...)
+		}
+	)
+
+	query := func(t *testcase.T, q crudquery.Query) []ENT {
+		t.Helper()
+		vs, err := iterkit.CollectE(subject.Query(ctx.Get(t), q))
+		assert.NoError(t, err)
+		return vs
+	}
+
+	s.Test("an empty query matches every entity", func(t *testcase.T) {
+		assert.Contains(t, query(t, crudquery.Query{}), ents.Get(t))
+	})
+
+	s.Test("eq matches only the entity with the equal value", func(t *testcase.T) {
+		got := query(t, crudquery.Where(crudquery.And(scope(t), crudquery.Eq(field.Name, values.Get(t)[0]))))
+		assert.ContainsExactly(t, got, ents.Get(t)[:1])
+	})
+
+	s.Test("ne matches every entity except the one with the equal value", func(t *testcase.T) {
+		got := query(t, crudquery.Where(crudquery.And(scope(t), crudquery.Ne(field.Name, values.Get(t)[0]))))
+		assert.ContainsExactly(t, got, ents.Get(t)[1:])
+	})
+
+	s.Test("in matches entities with any of the listed values", func(t *testcase.T) {
+		vs := values.Get(t)
+		got := query(t, crudquery.Where(crudquery.In(field.Name, vs[0], vs[2])))
+		assert.ContainsExactly(t, got, []ENT{ents.Get(t)[0], ents.Get(t)[2]})
+	})
+
+	s.Test("in without values matches nothing", func(t *testcase.T) {
+		assert.Empty(t, query(t, crudquery.Where(crudquery.In(field.Name))))
+	})
+
+	s.Test("lt, lte, gt and gte compare the field value", func(t *testcase.T) {
+		var (
+			vs  = values.Get(t)
+			es  = ents.Get(t)
+			pin = vs[1]
+		)
+		assert.ContainsExactly(t, query(t, crudquery.Where(crudquery.And(scope(t), crudquery.Lt(field.Name, pin)))), es[:1])
+		assert.ContainsExactly(t, query(t, crudquery.Where(crudquery.And(scope(t), crudquery.Lte(field.Name, pin)))), es[:2])
+		assert.ContainsExactly(t, query(t, crudquery.Where(crudquery.And(scope(t), crudquery.Gt(field.Name, pin)))), es[2:])
+		assert.ContainsExactly(t, query(t, crudquery.Where(crudquery.And(scope(t), crudquery.Gte(field.Name, pin)))), es[1:])
+	})
+
+	s.Test("or matches entities that satisfy any of the expressions", func(t *testcase.T) {
+		vs := values.Get(t)
+		got := query(t, crudquery.Where(crudquery.Or(
+			crudquery.Eq(field.Name, vs[0]),
+			crudquery.Eq(field.Name, vs[1]),
+		)))
+		assert.ContainsExactly(t, got, ents.Get(t)[:2])
+	})
+
+	s.Test("not negates the expression", func(t *testcase.T) {
+		vs := values.Get(t)
+		got := query(t, crudquery.Where(crudquery.And(scope(t),
+			crudquery.Not(crudquery.In(field.Name, vs[0], vs[1])))))
+		assert.ContainsExactly(t, got, ents.Get(t)[2:])
+	})
+
+	s.Test("order by ascending", func(t *testcase.T) {
+		got := query(t, crudquery.Where(scope(t)).Asc(field.Name))
+		assert.Equal(t, ents.Get(t), got)
+	})
+
+	s.Test("order by descending", func(t *testcase.T) {
+		exp := slices.Clone(ents.Get(t))
+		slices.Reverse(exp)
+		got := query(t, crudquery.Where(scope(t)).Desc(field.Name))
+		assert.Equal(t, exp, got)
+	})
+
+	s.Test("limit and offset paginate the ordered result", func(t *testcase.T) {
+		got := query(t, crudquery.Where(scope(t)).Asc(field.Name).WithOffset(1).WithLimit(2))
+		assert.Equal(t, ents.Get(t)[1:3], got)
+	})
+
+	s.Test("offset beyond the result yields nothing", func(t *testcase.T) {
+		got := query(t, crudquery.Where(scope(t)).Asc(field.Name).WithOffset(len(ents.Get(t))))
+		assert.Empty(t, got)
+	})
+
+	s.Test("unknown field yields an invalid query error", func(t *testcase.T) {
+		q := crudquery.Where(crudquery.Eq("unknown_"+t.Random.StringNC(8, "abcdefghijklmnopqrstuvwxyz"), "value"))
+		_, err := iterkit.CollectE(subject.Query(ctx.Get(t), q))
+		assert.ErrorIs(t, err, crudquery.ErrInvalidQuery)
+	})
+
+	s.Test("malformed expression yields an invalid query error", func(t *testcase.T) {
+		q := crudquery.Where(crudquery.Expr{Op: "unknown"})
+		_, err := iterkit.CollectE(subject.Query(ctx.Get(t), q))
+		assert.ErrorIs(t, err, crudquery.ErrInvalidQuery)
+	})
+
+	s.Test("canceled context yields the context error", func(t *testcase.T) {
+		cctx, cancel := context.WithCancel(ctx.Get(t))
+		cancel()
+		_, err := iterkit.CollectE(subject.Query(cctx, crudquery.Where(scope(t))))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	if c.OnePhaseCommit != nil {
+		s.Test("entities created within a transaction are visible to queries in the same transaction", func(t *testcase.T) {
+			tx, err := c.OnePhaseCommit.BeginTx(ctx.Get(t))
+			assert.NoError(t, err)
+			defer c.OnePhaseCommit.RollbackTx(tx)
+
+			value := values.Get(t)[0] + "0"
+			ptr := pointer.Of(c.MakeEntity(t))
+			field.Set(ptr, value)
+			creator, ok := subject.(crud.Creator[ENT])
+			if !ok {
+				t.Skipf("%T doesn't implement crud.Creator", subject)
+			}
+			assert.NoError(t, creator.Create(tx, ptr))
+
+			got, err := iterkit.CollectE(subject.Query(tx, crudquery.Where(crudquery.Eq(field.Name, value))))
+			assert.NoError(t, err)
+			assert.ContainsExactly(t, got, []ENT{*ptr})
+		})
+	}
+
+	return s.AsSuite("Querier")
+}
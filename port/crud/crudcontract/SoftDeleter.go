@@ -0,0 +1,170 @@
+package crudcontract
+
+import (
+	"context"
+	"errors"
+
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/pointer"
+	"go.llib.dev/frameless/pkg/reflectkit"
+	"go.llib.dev/frameless/port/contract"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/option"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/let"
+)
+
+// SoftDeleter verifies that a soft deleted entity is hidden from the finder methods of the subject,
+// while it can be still listed with FindDeleted, and brought back with Restore.
+func SoftDeleter[ENT, ID any](subject crud.SoftDeleter[ENT, ID], opts ...Option[ENT, ID]) contract.Contract {
+	c := option.ToConfig[Config[ENT, ID]](opts)
+	s := testcase.NewSpec(nil)
+
+	var (
+		ctx = let.With[context.Context](s, c.MakeContext)
+		ptr = let.Var(s, func(t *testcase.T) *ENT {
+			ptr := pointer.Of(c.MakeEntity(t))
+			shouldStore(t, c, subject, ptr)
+			t.Cleanup(func() { tryPermanentDelete(t, c, subject, *ptr) })
+			return ptr
+		}).EagerLoading(s)
+		id = let.Var(s, func(t *testcase.T) ID {
+			return c.Helper().HasID(t, ptr.Get(t))
+		})
+	)
+
+	isDeleted := func(t *testcase.T, id ID) bool {
+		t.Helper()
+		deleted, err := iterkit.CollectE(subject.FindDeleted(ctx.Get(t)))
+		assert.NoError(t, err)
+		for _, ent := range deleted {
+			if got, ok := c.IDA.Lookup(ent); ok && reflectkit.Equal(got, id) {
+				return true
+			}
+		}
+		return false
+	}
+
+	s.Test("a live entity is not listed among the deleted entities", func(t *testcase.T) {
+		assert.False(t, isDeleted(t, id.Get(t)))
+	})
+
+	s.When("the entity is soft deleted", func(s *testcase.Spec) {
+		s.Before(func(t *testcase.T) {
+			assert.NoError(t, subject.SoftDeleteByID(ctx.Get(t), id.Get(t)))
+		})
+
+		s.Then("it is hidden from FindByID", func(t *testcase.T) {
+			shouldAbsent(t, c, subject, ctx.Get(t), id.Get(t))
+		})
+
+		s.Then("it is hidden from FindAll", func(t *testcase.T) {
+			finder, ok := subject.(crud.AllFinder[ENT])
+			if !ok {
+				t.Skipf("%T doesn't implement crud.AllFinder", subject)
+			}
+			all, err := iterkit.CollectE(finder.FindAll(ctx.Get(t)))
+			assert.NoError(t, err)
+			for _, ent := range all {
+				assert.NotEqual(t, id.Get(t), c.IDA.Get(ent),
+					"soft deleted entity should not be returned by FindAll")
+			}
+		})
+
+		s.Then("it is listed by FindDeleted", func(t *testcase.T) {
+			assert.True(t, isDeleted(t, id.Get(t)))
+		})
+
+		s.Then("soft deleting it again yields ErrNotFound", func(t *testcase.T) {
+			assert.ErrorIs(t, subject.SoftDeleteByID(ctx.Get(t), id.Get(t)), crud.ErrNotFound)
+		})
+
+		s.Then("it can be restored with its original content", func(t *testcase.T) {
+			assert.NoError(t, subject.Restore(ctx.Get(t), id.Get(t)))
+			got := shouldPresent[ENT, ID](t, c, subject, ctx.Get(t), id.Get(t))
+			assert.Equal(t, *ptr.Get(t), *got)
+			assert.False(t, isDeleted(t, id.Get(t)))
+		})
+
+		s.Then("restoring it twice yields ErrNotFound", func(t *testcase.T) {
+			assert.NoError(t, subject.Restore(ctx.Get(t), id.Get(t)))
+			assert.ErrorIs(t, subject.Restore(ctx.Get(t), id.Get(t)), crud.ErrNotFound)
+		})
+
+		s.Then("creating an entity with its ID yields ErrAlreadyExists, while the soft deleted entity is kept", func(t *testcase.T) {
+			creator, ok := subject.(crud.Creator[ENT])
+			if !ok {
+				t.Skipf("%T doesn't implement crud.Creator", subject)
+			}
+			ent := c.MakeEntity(t)
+			assert.NoError(t, c.IDA.Set(&ent, id.Get(t)))
+			assert.ErrorIs(t, creator.Create(ctx.Get(t), &ent), crud.ErrAlreadyExists)
+			assert.True(t, isDeleted(t, id.Get(t)))
+		})
+
+		s.Then("saving an entity with its ID yields ErrAlreadyExists, while the soft deleted entity is kept", func(t *testcase.T) {
+			saver, ok := subject.(crud.Saver[ENT])
+			if !ok {
+				t.Skipf("%T doesn't implement crud.Saver", subject)
+			}
+			ent := c.MakeEntity(t)
+			assert.NoError(t, c.IDA.Set(&ent, id.Get(t)))
+			assert.ErrorIs(t, saver.Save(ctx.Get(t), &ent), crud.ErrAlreadyExists)
+			assert.True(t, isDeleted(t, id.Get(t)))
+			shouldAbsent(t, c, subject, ctx.Get(t), id.Get(t))
+		})
+
+		s.Then("DeleteByID removes it permanently", func(t *testcase.T) {
+			deleter, ok := subject.(crud.ByIDDeleter[ID])
+			if !ok {
+				t.Skipf("%T doesn't implement crud.ByIDDeleter", subject)
+			}
+			assert.NoError(t, deleter.DeleteByID(ctx.Get(t), id.Get(t)))
+			assert.False(t, isDeleted(t, id.Get(t)))
+			assert.ErrorIs(t, subject.Restore(ctx.Get(t), id.Get(t)), crud.ErrNotFound)
+		})
+	})
+
+	s.Test("restoring a live entity yields ErrNotFound", func(t *testcase.T) {
+		assert.ErrorIs(t, subject.Restore(ctx.Get(t), id.Get(t)), crud.ErrNotFound)
+		shouldPresent[ENT, ID](t, c, subject, ctx.Get(t), id.Get(t))
+	})
+
+	s.Test("soft deleting an absent entity yields ErrNotFound", func(t *testcase.T) {
+		deleter, ok := subject.(crud.ByIDDeleter[ID])
+		if !ok {
+			t.Skipf("%T doesn't implement crud.ByIDDeleter", subject)
+		}
+		assert.NoError(t, deleter.DeleteByID(ctx.Get(t), id.Get(t)))
+		assert.ErrorIs(t, subject.SoftDeleteByID(ctx.Get(t), id.Get(t)), crud.ErrNotFound)
+	})
+
+	s.Test("canceled context yields the context error", func(t *testcase.T) {
+		cctx, cancel := context.WithCancel(ctx.Get(t))
+		cancel()
+		assert.ErrorIs(t, subject.SoftDeleteByID(cctx, id.Get(t)), context.Canceled)
+		assert.ErrorIs(t, subject.Restore(cctx, id.Get(t)), context.Canceled)
+		_, err := iterkit.CollectE(subject.FindDeleted(cctx))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	return s.AsSuite("SoftDeleter")
+}
+
+// tryPermanentDelete removes the entity, even if it was soft deleted and therefore hidden from FindByID.
+func tryPermanentDelete[ENT, ID any](t *testcase.T, c Config[ENT, ID], resource any, v ENT) {
+	id, ok := lookupNonZeroID(c, v)
+	if !ok {
+		return
+	}
+	deleter, ok := resource.(crud.ByIDDeleter[ID])
+	if !ok {
+		return
+	}
+	err := deleter.DeleteByID(c.MakeContext(t), id)
+	if errors.Is(err, crud.ErrNotFound) {
+		return
+	}
+	assert.NoError(t, err)
+}
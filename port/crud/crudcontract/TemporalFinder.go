@@ -0,0 +1,144 @@
+package crudcontract
+
+import (
+	"context"
+	"time"
+
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/pointer"
+	"go.llib.dev/frameless/pkg/reflectkit"
+	"go.llib.dev/frameless/port/contract"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/option"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/clock"
+	"go.llib.dev/testcase/clock/timecop"
+	"go.llib.dev/testcase/let"
+)
+
+// TemporalFinder verifies that the Finder returned by the subject's AsOf
+// reflects the state of the subject at the requested point in time.
+//
+// The contract moves the clock with timecop, to separate the moments of the changes.
+func TemporalFinder[ENT, ID any](subject crud.TemporalFinder[ENT, ID], opts ...Option[ENT, ID]) contract.Contract {
+	c := option.ToConfig[Config[ENT, ID]](opts)
+	s := testcase.NewSpec(nil)
+	s.HasSideEffect()
+
+	var (
+		ctx = let.With[context.Context](s, c.MakeContext)
+		ptr = let.Var(s, func(t *testcase.T) *ENT {
+			ptr := pointer.Of(c.MakeEntity(t))
+			shouldStore(t, c, subject, ptr)
+			t.Cleanup(func() { tryDelete(t, c, subject, *ptr) })
+			return ptr
+		})
+	)
+
+	// moment returns the current time, and moves the clock ahead,
+	// so the changes made afterwards happen after the returned time.
+	moment := func(t *testcase.T) time.Time {
+		now := clock.Now()
+		timecop.Travel(t, time.Minute)
+		return now
+	}
+
+	asOf := func(t *testcase.T, at time.Time) crud.Finder[ENT, ID] {
+		t.Helper()
+		finder, err := subject.AsOf(ctx.Get(t), at)
+		assert.NoError(t, err)
+		return finder
+	}
+
+	findByID := func(t *testcase.T, finder crud.Finder[ENT, ID], id ID) (ENT, bool) {
+		t.Helper()
+		ent, found, err := finder.FindByID(ctx.Get(t), id)
+		assert.NoError(t, err)
+		return ent, found
+	}
+
+	isListed := func(t *testcase.T, finder crud.Finder[ENT, ID], id ID) bool {
+		t.Helper()
+		all, err := iterkit.CollectE(finder.FindAll(ctx.Get(t)))
+		assert.NoError(t, err)
+		for _, ent := range all {
+			if got, ok := c.IDA.Lookup(ent); ok && reflectkit.Equal(got, id) {
+				return true
+			}
+		}
+		return false
+	}
+
+	s.Test("an entity created after the point in time is absent", func(t *testcase.T) {
+		at := moment(t)
+		id := c.Helper().HasID(t, ptr.Get(t))
+
+		finder := asOf(t, at)
+		_, found := findByID(t, finder, id)
+		assert.False(t, found)
+		assert.False(t, isListed(t, finder, id))
+	})
+
+	s.Test("an entity created before the point in time is present", func(t *testcase.T) {
+		id := c.Helper().HasID(t, ptr.Get(t))
+		timecop.Travel(t, time.Minute)
+		at := moment(t)
+
+		finder := asOf(t, at)
+		got, found := findByID(t, finder, id)
+		assert.True(t, found)
+		assert.Equal(t, *ptr.Get(t), got)
+		assert.True(t, isListed(t, finder, id))
+	})
+
+	s.Test("an entity updated after the point in time is found in its earlier state", func(t *testcase.T) {
+		updater, ok := subject.(crud.Updater[ENT])
+		if !ok {
+			t.Skipf("%T doesn't implement crud.Updater", subject)
+		}
+		id := c.Helper().HasID(t, ptr.Get(t))
+		timecop.Travel(t, time.Minute)
+		at := moment(t)
+
+		ent := *ptr.Get(t)
+		c.ModifyEntity(t, &ent)
+		assert.NoError(t, updater.Update(ctx.Get(t), &ent))
+
+		got, found := findByID(t, asOf(t, at), id)
+		assert.True(t, found)
+		assert.Equal(t, *ptr.Get(t), got)
+
+		got, found = findByID(t, asOf(t, clock.Now()), id)
+		assert.True(t, found)
+		assert.Equal(t, ent, got)
+	})
+
+	s.Test("an entity deleted after the point in time is present", func(t *testcase.T) {
+		id := c.Helper().HasID(t, ptr.Get(t))
+		timecop.Travel(t, time.Minute)
+		at := moment(t)
+
+		shouldDelete(t, c, subject, ctx.Get(t), *ptr.Get(t))
+
+		finder := asOf(t, at)
+		got, found := findByID(t, finder, id)
+		assert.True(t, found)
+		assert.Equal(t, *ptr.Get(t), got)
+		assert.True(t, isListed(t, finder, id))
+	})
+
+	s.Test("an entity deleted before the point in time is absent", func(t *testcase.T) {
+		id := c.Helper().HasID(t, ptr.Get(t))
+		timecop.Travel(t, time.Minute)
+		shouldDelete(t, c, subject, ctx.Get(t), *ptr.Get(t))
+		timecop.Travel(t, time.Minute)
+
+		finder := asOf(t, moment(t))
+		_, found := findByID(t, finder, id)
+		assert.False(t, found)
+		assert.False(t, isListed(t, finder, id))
+	})
+
+	return s.AsSuite("TemporalFinder")
+}
@@ -0,0 +1,236 @@
+package crudcontract
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.llib.dev/frameless/pkg/pointer"
+	"go.llib.dev/frameless/port/contract"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/option"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/let"
+)
+
+// VersionedUpdater verifies that the subject applies optimistic concurrency control on a versioned entity.
+// An update is only accepted when it is based on the latest stored version of the entity,
+// otherwise the subject must reject it with crud.ErrConcurrentModification.
+//
+// The ENT type must have a version field, either tagged with `ext:"version"` or configured with Config.VersionA.
+func VersionedUpdater[ENT, ID any](subject crud.Updater[ENT], opts ...Option[ENT, ID]) contract.Contract {
+	c := option.ToConfig[Config[ENT, ID]](opts)
+	s := testcase.NewSpec(nil)
+
+	var (
+		ctx = let.With[context.Context](s, c.MakeContext)
+		ptr = let.Var(s, func(t *testcase.T) *ENT {
+			ptr := pointer.Of(c.MakeEntity(t))
+			shouldStore(t, c, subject, ptr)
+			t.Cleanup(func() { tryDelete(t, c, subject, *ptr) })
+			return ptr
+		}).EagerLoading(s)
+	)
+
+	s.Before(func(t *testcase.T) {
+		if !c.VersionA.IsVersioned() {
+			t.Skip("ENT is not a versioned entity")
+		}
+	})
+
+	versionOf := func(t *testcase.T, ent ENT) int64 {
+		t.Helper()
+		version, ok := c.VersionA.Lookup(ent)
+		assert.True(t, ok, "the entity was expected to have a version field")
+		return version
+	}
+
+	stored := func(t *testcase.T) ENT {
+		t.Helper()
+		return *shouldPresent[ENT, ID](t, c, subject, ctx.Get(t), c.Helper().HasID(t, ptr.Get(t)))
+	}
+
+	s.Test("a stored entity has a version", func(t *testcase.T) {
+		assert.NotEqual(t, int64(0), versionOf(t, *ptr.Get(t)))
+		assert.Equal(t, versionOf(t, *ptr.Get(t)), versionOf(t, stored(t)))
+	})
+
+	s.Test("updating the latest version succeeds and increments the version", func(t *testcase.T) {
+		ent := *ptr.Get(t)
+		before := versionOf(t, ent)
+		c.ModifyEntity(t, &ent)
+
+		assert.NoError(t, subject.Update(ctx.Get(t), &ent))
+		assert.True(t, before < versionOf(t, ent), "the version of the received entity should be incremented")
+
+		got := stored(t)
+		assert.Equal(t, versionOf(t, ent), versionOf(t, got))
+		assert.Equal(t, ent, got)
+	})
+
+	s.Test("subsequent updates based on the returned version succeed", func(t *testcase.T) {
+		ent := *ptr.Get(t)
+		t.Random.Repeat(2, 5, func() {
+			c.ModifyEntity(t, &ent)
+			assert.NoError(t, subject.Update(ctx.Get(t), &ent))
+		})
+		assert.Equal(t, ent, stored(t))
+	})
+
+	s.Test("updating a stale version yields ErrConcurrentModification and keeps the stored entity", func(t *testcase.T) {
+		var (
+			fresh = *ptr.Get(t)
+			stale = *ptr.Get(t)
+		)
+		c.ModifyEntity(t, &fresh)
+		assert.NoError(t, subject.Update(ctx.Get(t), &fresh))
+
+		c.ModifyEntity(t, &stale)
+		staleVersion := versionOf(t, stale)
+		assert.ErrorIs(t, subject.Update(ctx.Get(t), &stale), crud.ErrConcurrentModification)
+		assert.Equal(t, staleVersion, versionOf(t, stale), "the version of a rejected entity should be left as is")
+		assert.Equal(t, fresh, stored(t))
+	})
+
+	s.Test("a rejected update succeeds after it is based on the latest version", func(t *testcase.T) {
+		var (
+			fresh = *ptr.Get(t)
+			stale = *ptr.Get(t)
+		)
+		c.ModifyEntity(t, &fresh)
+		assert.NoError(t, subject.Update(ctx.Get(t), &fresh))
+		c.ModifyEntity(t, &stale)
+		assert.ErrorIs(t, subject.Update(ctx.Get(t), &stale), crud.ErrConcurrentModification)
+
+		latest := stored(t)
+		c.ModifyEntity(t, &latest)
+		assert.NoError(t, subject.Update(ctx.Get(t), &latest))
+		assert.Equal(t, latest, stored(t))
+	})
+
+	s.Test("only one of the concurrent updates of the same version succeeds", func(t *testcase.T) {
+		var (
+			ctx       = ctx.Get(t)
+			succeeded int32
+			ops       []func()
+		)
+		t.Random.Repeat(2, 5, func() {
+			ent := *ptr.Get(t)
+			c.ModifyEntity(t, &ent)
+			ops = append(ops, func() {
+				err := subject.Update(ctx, &ent)
+				if err == nil {
+					atomic.AddInt32(&succeeded, 1)
+					return
+				}
+				assert.ErrorIs(t, err, crud.ErrConcurrentModification)
+			})
+		})
+		raceConcurrently(ops)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&succeeded))
+	})
+
+	s.Test("only one of two concurrent transactions that update the same version can commit", func(t *testcase.T) {
+		if c.OnePhaseCommit == nil {
+			t.Skip("OnePhaseCommit is not supplied")
+		}
+		var (
+			first  = *ptr.Get(t)
+			second = *ptr.Get(t)
+		)
+		c.ModifyEntity(t, &first)
+		c.ModifyEntity(t, &second)
+
+		tx1, err := c.OnePhaseCommit.BeginTx(ctx.Get(t))
+		assert.NoError(t, err)
+		assert.NoError(t, subject.Update(tx1, &first))
+
+		// the second transaction may fail right away, wait for the first one to finish, or fail at its commit.
+		result := make(chan error, 1)
+		go func() {
+			result <- func() error {
+				tx2, err := c.OnePhaseCommit.BeginTx(ctx.Get(t))
+				if err != nil {
+					return err
+				}
+				if err := subject.Update(tx2, &second); err != nil {
+					_ = c.OnePhaseCommit.RollbackTx(tx2)
+					return err
+				}
+				return c.OnePhaseCommit.CommitTx(tx2)
+			}()
+		}()
+		var (
+			err2     error
+			finished bool
+		)
+		select {
+		case err2 = <-result:
+			finished = true
+		case <-time.After(250 * time.Millisecond):
+		}
+
+		err1 := c.OnePhaseCommit.CommitTx(tx1)
+		if !finished {
+			select {
+			case err2 = <-result:
+			case <-time.After(time.Minute):
+				t.Fatal("the second transaction didn't finish")
+			}
+		}
+
+		switch {
+		case err1 == nil:
+			assert.ErrorIs(t, err2, crud.ErrConcurrentModification)
+			assert.Equal(t, first, stored(t))
+		case err2 == nil:
+			assert.ErrorIs(t, err1, crud.ErrConcurrentModification)
+			assert.Equal(t, second, stored(t))
+		default:
+			t.Fatalf("one of the transactions was expected to commit: %v, %v", err1, err2)
+		}
+	})
+
+	s.Context("Save", func(s *testcase.Spec) {
+		saver := let.Var(s, func(t *testcase.T) crud.Saver[ENT] {
+			saver, ok := subject.(crud.Saver[ENT])
+			if !ok {
+				t.Skipf("%T doesn't implement crud.Saver", subject)
+			}
+			return saver
+		})
+
+		s.Test("saving the latest version succeeds and increments the version", func(t *testcase.T) {
+			ent := *ptr.Get(t)
+			before := versionOf(t, ent)
+			c.ModifyEntity(t, &ent)
+
+			assert.NoError(t, saver.Get(t).Save(ctx.Get(t), &ent))
+			assert.True(t, before < versionOf(t, ent), "the version of the received entity should be incremented")
+			assert.Equal(t, ent, stored(t))
+		})
+
+		s.Test("saving a stale version yields ErrConcurrentModification and keeps the stored entity", func(t *testcase.T) {
+			var (
+				fresh = *ptr.Get(t)
+				stale = *ptr.Get(t)
+			)
+			c.ModifyEntity(t, &fresh)
+			assert.NoError(t, subject.Update(ctx.Get(t), &fresh))
+
+			c.ModifyEntity(t, &stale)
+			assert.ErrorIs(t, saver.Get(t).Save(ctx.Get(t), &stale), crud.ErrConcurrentModification)
+			assert.Equal(t, fresh, stored(t))
+		})
+	})
+
+	s.Test("updating an absent entity yields ErrNotFound", func(t *testcase.T) {
+		ent := *ptr.Get(t)
+		shouldDelete(t, c, subject, ctx.Get(t), ent)
+		c.ModifyEntity(t, &ent)
+		assert.ErrorIs(t, subject.Update(ctx.Get(t), &ent), crud.ErrNotFound)
+	})
+
+	return s.AsSuite("VersionedUpdater")
+}
@@ -9,6 +9,7 @@ import (
 	"go.llib.dev/frameless/port/comproto"
 	"go.llib.dev/frameless/port/crud/crudtest"
 	"go.llib.dev/frameless/port/crud/extid"
+	"go.llib.dev/frameless/port/crud/extver"
 	"go.llib.dev/frameless/port/option"
 )
 
@@ -39,6 +40,9 @@ type Config[ENT, ID any] struct {
 	// Configure this if you don't use the ext:"id" tag in your entity.
 	// TODO: add support for this accessor in crudtest
 	IDA extid.Accessor[ENT, ID]
+	// VersionA [optional] is the version Accessor.
+	// Configure this if you don't use the ext:"version" tag in your versioned entity.
+	VersionA extver.Accessor[ENT]
 	// LazyNotFoundError will allow QueryMany methods to return crud.ErrNotFound during the iteration,
 	// instead during the method call.
 	// For e.g.: crud.ByIDsFinder[ENT, ID]
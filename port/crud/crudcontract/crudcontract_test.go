@@ -35,6 +35,18 @@ var _ = []contract.Contract{
 	crudcontract.QueryOne[EntType, IDType](nil, "", nil),
 	crudcontract.QueryMany[EntType, IDType](nil, "", nil),
 	crudcontract.Batcher[EntType, IDType, crud.Batch[EntType]](nil),
+	crudcontract.Querier[EntType, IDType](nil, crudcontract.QuerierField[EntType]{}),
+	crudcontract.PageFinder[EntType, IDType](nil),
+	crudcontract.VersionedUpdater[EntType, IDType](nil),
+	crudcontract.SoftDeleter[EntType, IDType](nil),
+	crudcontract.ChangeSubscriber[EntType, IDType](nil),
+	crudcontract.ManyCreator[EntType, IDType](nil),
+	crudcontract.ManyUpdater[EntType, IDType](nil),
+	crudcontract.ByIDsDeleter[EntType, IDType](nil),
+	crudcontract.Counter[EntType, IDType](nil),
+	crudcontract.QueryCounter[EntType, IDType](nil, crudcontract.QuerierField[EntType]{}),
+	crudcontract.GroupCounter[EntType, IDType](nil, crudcontract.QuerierField[EntType]{}),
+	crudcontract.Exister[EntType, IDType](nil),
 }
 
 func contracts[ENT, ID any](resource Resource[ENT, ID], cm comproto.OnePhaseCommitProtocol, opts ...crudcontract.Option[ENT, ID]) []contract.Contract {
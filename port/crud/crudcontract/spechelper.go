@@ -251,8 +251,12 @@ func (c Config[ENT, ID]) ModifyEntity(tb testing.TB, ptr *ENT) {
 		return
 	}
 	id, _ := lookupNonZeroID[ID](c, *ptr)
+	version, versioned := c.VersionA.Lookup(*ptr)
 	*ptr = random.Unique(func() ENT { return c.MakeEntity(tb) }, *ptr)
 	setID(tb, c, ptr, id)
+	if versioned {
+		assert.NoError(tb, c.VersionA.Set(ptr, version))
+	}
 }
 
 func shouldPresent[ENT, ID any](t *testcase.T, c Config[ENT, ID], resource any, ctx context.Context, id ID) *ENT {
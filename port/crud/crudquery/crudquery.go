@@ -0,0 +1,218 @@
+// Package crudquery defines a portable, serialisable query expression
+// that crud adapters can translate into their native query language.
+//
+// A Query is plain data, it can be encoded as JSON, logged, or passed between services,
+// and every adapter which implements crud.Querier is expected to interpret it the same way.
+package crudquery
+
+import (
+	"fmt"
+	"strings"
+
+	"go.llib.dev/frameless/internal/errorkitlite"
+)
+
+const ErrInvalidQuery errorkitlite.Error = "ErrInvalidQuery"
+
+// Query describes a search against a repository.
+//
+// The zero Query matches every entity, without any ordering or pagination.
+//
+// NULL/nil fields follow the SQL semantics:
+// the comparison of a nil field with a non-nil value is neither true nor false,
+// so the entity doesn't match, not even when the comparison is negated with OpNot.
+// Use a nil Value with OpEq or OpNe to match the nil fields.
+type Query struct {
+	// Where is an optional filter expression.
+	// When it is nil, every entity is matched.
+	Where *Expr `json:"where,omitempty"`
+	// OrderBy defines the sort order of the result.
+	// When it is empty, the result ordering is undefined.
+	OrderBy []Order `json:"order_by,omitempty"`
+	// Limit restricts the number of returned entities.
+	// Zero means no limit.
+	Limit int `json:"limit,omitempty"`
+	// Offset skips the given number of entities before returning results.
+	Offset int `json:"offset,omitempty"`
+}
+
+// Order describes the sorting by a given field.
+//
+// The position of the NULL/nil values is adapter specific, as SQL databases differ in it.
+// Apply, SQLite and MySQL order them before every other value, while PostgreSQL orders them after in ascending order.
+type Order struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc,omitempty"`
+}
+
+// Operator is the kind of an Expr node.
+type Operator string
+
+const (
+	// OpEq matches when Field is equal to Value.
+	// A nil Value matches a NULL/nil field.
+	OpEq Operator = "eq"
+	// OpNe matches when Field is not equal to Value.
+	OpNe Operator = "ne"
+	// OpLt matches when Field is less than Value.
+	OpLt Operator = "lt"
+	// OpLte matches when Field is less than or equal to Value.
+	OpLte Operator = "lte"
+	// OpGt matches when Field is greater than Value.
+	OpGt Operator = "gt"
+	// OpGte matches when Field is greater than or equal to Value.
+	OpGte Operator = "gte"
+	// OpIn matches when Field is equal to any of the Values.
+	OpIn Operator = "in"
+	// OpAnd matches when every Exprs matches.
+	// An empty OpAnd matches everything.
+	OpAnd Operator = "and"
+	// OpOr matches when any of the Exprs matches.
+	// An empty OpOr matches nothing.
+	OpOr Operator = "or"
+	// OpNot matches when its single Exprs element doesn't match.
+	OpNot Operator = "not"
+)
+
+// IsComparison reports whether the operator compares a Field against a Value.
+func (op Operator) IsComparison() bool {
+	switch op {
+	case OpEq, OpNe, OpLt, OpLte, OpGt, OpGte:
+		return true
+	default:
+		return false
+	}
+}
+
+// Expr is a node in the query expression tree.
+// Which properties are used depends on the Op value.
+type Expr struct {
+	Op     Operator `json:"op"`
+	Field  string   `json:"field,omitempty"`
+	Value  any      `json:"value,omitempty"`
+	Values []any    `json:"values,omitempty"`
+	Exprs  []Expr   `json:"exprs,omitempty"`
+}
+
+func Eq(field string, value any) Expr  { return Expr{Op: OpEq, Field: field, Value: value} }
+func Ne(field string, value any) Expr  { return Expr{Op: OpNe, Field: field, Value: value} }
+func Lt(field string, value any) Expr  { return Expr{Op: OpLt, Field: field, Value: value} }
+func Lte(field string, value any) Expr { return Expr{Op: OpLte, Field: field, Value: value} }
+func Gt(field string, value any) Expr  { return Expr{Op: OpGt, Field: field, Value: value} }
+func Gte(field string, value any) Expr { return Expr{Op: OpGte, Field: field, Value: value} }
+
+func In(field string, values ...any) Expr { return Expr{Op: OpIn, Field: field, Values: values} }
+
+func And(exprs ...Expr) Expr { return Expr{Op: OpAnd, Exprs: exprs} }
+func Or(exprs ...Expr) Expr  { return Expr{Op: OpOr, Exprs: exprs} }
+func Not(expr Expr) Expr     { return Expr{Op: OpNot, Exprs: []Expr{expr}} }
+
+// Where returns a Query filtered by the given expression.
+func Where(expr Expr) Query {
+	return Query{Where: &expr}
+}
+
+// Asc adds an ascending ordering by field.
+func (q Query) Asc(field string) Query {
+	q.OrderBy = append(append([]Order{}, q.OrderBy...), Order{Field: field})
+	return q
+}
+
+// Desc adds a descending ordering by field.
+func (q Query) Desc(field string) Query {
+	q.OrderBy = append(append([]Order{}, q.OrderBy...), Order{Field: field, Desc: true})
+	return q
+}
+
+// WithLimit returns a copy of the Query with the limit set.
+func (q Query) WithLimit(n int) Query {
+	q.Limit = n
+	return q
+}
+
+// WithOffset returns a copy of the Query with the offset set.
+func (q Query) WithOffset(n int) Query {
+	q.Offset = n
+	return q
+}
+
+// Validate checks whether the Query is well-formed.
+func (q Query) Validate() error {
+	if q.Limit < 0 {
+		return ErrInvalidQuery.F("negative limit: %d", q.Limit)
+	}
+	if q.Offset < 0 {
+		return ErrInvalidQuery.F("negative offset: %d", q.Offset)
+	}
+	for _, o := range q.OrderBy {
+		if o.Field == "" {
+			return ErrInvalidQuery.F("order by is missing the field name")
+		}
+	}
+	if q.Where != nil {
+		return q.Where.Validate()
+	}
+	return nil
+}
+
+// Validate checks whether the expression tree is well-formed.
+func (e Expr) Validate() error {
+	switch {
+	case e.Op.IsComparison():
+		if e.Field == "" {
+			return ErrInvalidQuery.F("%s expression is missing the field name", e.Op)
+		}
+		if e.Value == nil && e.Op != OpEq && e.Op != OpNe {
+			return ErrInvalidQuery.F("%s expression can't compare %s to nil", e.Op, e.Field)
+		}
+	case e.Op == OpIn:
+		if e.Field == "" {
+			return ErrInvalidQuery.F("%s expression is missing the field name", e.Op)
+		}
+	case e.Op == OpAnd, e.Op == OpOr:
+		for _, sub := range e.Exprs {
+			if err := sub.Validate(); err != nil {
+				return err
+			}
+		}
+	case e.Op == OpNot:
+		if len(e.Exprs) != 1 {
+			return ErrInvalidQuery.F("%s expression expects exactly one sub expression, got %d", e.Op, len(e.Exprs))
+		}
+		return e.Exprs[0].Validate()
+	default:
+		return ErrInvalidQuery.F("unknown operator: %q", e.Op)
+	}
+	return nil
+}
+
+func (e Expr) String() string {
+	switch {
+	case e.Op.IsComparison():
+		return fmt.Sprintf("%s %s %#v", e.Field, e.Op, e.Value)
+	case e.Op == OpIn:
+		return fmt.Sprintf("%s %s %#v", e.Field, e.Op, e.Values)
+	case e.Op == OpNot && len(e.Exprs) == 1:
+		return fmt.Sprintf("not (%s)", e.Exprs[0].String())
+	default:
+		var parts []string
+		for _, sub := range e.Exprs {
+			parts = append(parts, "("+sub.String()+")")
+		}
+		return strings.Join(parts, " "+string(e.Op)+" ")
+	}
+}
+
+// MatchField reports whether a query field name refers to the given name of a struct field or table column.
+//
+// The comparison is case-insensitive and ignores underscores,
+// so "created_at", "CreatedAt" and "createdAt" all refer to the same field.
+// This keeps a Query portable between adapters where fields are Go struct fields
+// and adapters where they are table columns.
+func MatchField(field, name string) bool {
+	return normaliseField(field) == normaliseField(name)
+}
+
+func normaliseField(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
@@ -0,0 +1,194 @@
+package crudquery_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/port/crud/crudquery"
+	"go.llib.dev/testcase/assert"
+)
+
+type Order struct {
+	ID        OrderID
+	Status    string
+	Total     int
+	Paid      bool
+	Note      *string
+	CreatedAt time.Time
+}
+
+type OrderID string
+
+func ExampleWhere() {
+	_ = crudquery.Where(crudquery.And(
+		crudquery.Eq("status", "shipped"),
+		crudquery.Gte("total", 100),
+	)).Desc("created_at").WithLimit(10)
+}
+
+func TestQuery_Validate(t *testing.T) {
+	assert.NoError(t, crudquery.Query{}.Validate())
+	assert.NoError(t, crudquery.Where(crudquery.And()).Validate())
+	assert.NoError(t, crudquery.Where(crudquery.Eq("note", nil)).Validate())
+
+	for _, q := range []crudquery.Query{
+		{Limit: -1},
+		{Offset: -1},
+		{OrderBy: []crudquery.Order{{}}},
+		crudquery.Where(crudquery.Expr{Op: "unknown"}),
+		crudquery.Where(crudquery.Eq("", "x")),
+		crudquery.Where(crudquery.In("")),
+		crudquery.Where(crudquery.Lt("total", nil)),
+		crudquery.Where(crudquery.Expr{Op: crudquery.OpNot}),
+		crudquery.Where(crudquery.Or(crudquery.Expr{Op: "unknown"})),
+	} {
+		assert.ErrorIs(t, q.Validate(), crudquery.ErrInvalidQuery)
+	}
+}
+
+func TestQuery_json(t *testing.T) {
+	q := crudquery.Where(crudquery.Or(
+		crudquery.Eq("status", "new"),
+		crudquery.Not(crudquery.In("total", 1, 2)),
+	)).Asc("created_at").WithLimit(3).WithOffset(1)
+
+	data, err := json.Marshal(q)
+	assert.NoError(t, err)
+
+	var got crudquery.Query
+	assert.NoError(t, json.Unmarshal(data, &got))
+	assert.NoError(t, got.Validate())
+
+	order := Order{Status: "paid", Total: 1}
+	ok, err := crudquery.Match(*got.Where, order)
+	assert.NoError(t, err)
+	assert.False(t, ok, "total decoded as float64 should still be comparable with the int field")
+}
+
+func TestMatchField(t *testing.T) {
+	assert.True(t, crudquery.MatchField("created_at", "CreatedAt"))
+	assert.True(t, crudquery.MatchField("createdAt", "created_at"))
+	assert.True(t, crudquery.MatchField("ID", "id"))
+	assert.False(t, crudquery.MatchField("created", "CreatedAt"))
+}
+
+func TestFieldValue(t *testing.T) {
+	order := Order{ID: "42", Status: "shipped"}
+
+	v, err := crudquery.FieldValue(order, "status")
+	assert.NoError(t, err)
+	assert.Equal[any](t, "shipped", v)
+
+	v, err = crudquery.FieldValue(order, "id")
+	assert.NoError(t, err)
+	assert.Equal[any](t, OrderID("42"), v)
+
+	_, err = crudquery.FieldValue(order, "unknown")
+	assert.ErrorIs(t, err, crudquery.ErrInvalidQuery)
+}
+
+func TestMatch(t *testing.T) {
+	note := "fragile"
+	order := Order{
+		ID:        "42",
+		Status:    "paid",
+		Total:     250,
+		Paid:      true,
+		Note:      &note,
+		CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	for _, tc := range []struct {
+		Expr crudquery.Expr
+		OK   bool
+	}{
+		{Expr: crudquery.Eq("status", "paid"), OK: true},
+		{Expr: crudquery.Eq("status", "new"), OK: false},
+		{Expr: crudquery.Eq("id", "42"), OK: true},
+		{Expr: crudquery.Eq("paid", true), OK: true},
+		{Expr: crudquery.Ne("paid", true), OK: false},
+		{Expr: crudquery.Eq("note", "fragile"), OK: true},
+		{Expr: crudquery.Eq("note", nil), OK: false},
+		{Expr: crudquery.Ne("note", nil), OK: true},
+		{Expr: crudquery.Gt("total", 100), OK: true},
+		{Expr: crudquery.Lte("total", 250.0), OK: true},
+		{Expr: crudquery.Lt("total", 250.5), OK: true},
+		{Expr: crudquery.Lt("created_at", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)), OK: true},
+		{Expr: crudquery.In("status", "new", "paid"), OK: true},
+		{Expr: crudquery.In("status"), OK: false},
+		{Expr: crudquery.And(), OK: true},
+		{Expr: crudquery.Or(), OK: false},
+		{Expr: crudquery.And(crudquery.Eq("status", "paid"), crudquery.Gt("total", 1000)), OK: false},
+		{Expr: crudquery.Or(crudquery.Eq("status", "new"), crudquery.Gt("total", 100)), OK: true},
+		{Expr: crudquery.Not(crudquery.Eq("status", "paid")), OK: false},
+	} {
+		got, err := crudquery.Match(tc.Expr, order)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.OK, got, assert.MessageF("%s", tc.Expr.String()))
+	}
+
+	_, err := crudquery.Match(crudquery.Eq("unknown", 1), order)
+	assert.ErrorIs(t, err, crudquery.ErrInvalidQuery)
+
+	_, err = crudquery.Match(crudquery.Gt("paid", false), order)
+	assert.ErrorIs(t, err, crudquery.ErrInvalidQuery, "bool values have no ordering")
+}
+
+func TestMatch_nullSemantics(t *testing.T) {
+	order := Order{ID: "42", Status: "paid", Total: 250}
+
+	for _, tc := range []struct {
+		Expr crudquery.Expr
+		OK   bool
+	}{
+		{Expr: crudquery.Eq("note", nil), OK: true},
+		{Expr: crudquery.Ne("note", nil), OK: false},
+		{Expr: crudquery.Eq("note", "fragile"), OK: false},
+		{Expr: crudquery.Ne("note", "fragile"), OK: false},
+		{Expr: crudquery.Lt("note", "fragile"), OK: false},
+		{Expr: crudquery.Gte("note", "fragile"), OK: false},
+		{Expr: crudquery.In("note", "fragile"), OK: false},
+		{Expr: crudquery.Not(crudquery.Eq("note", "fragile")), OK: false},
+		{Expr: crudquery.Not(crudquery.In("status", "new", nil)), OK: false},
+		{Expr: crudquery.In("status", "paid", nil), OK: true},
+		{Expr: crudquery.Or(crudquery.Eq("note", "fragile"), crudquery.Eq("status", "paid")), OK: true},
+		{Expr: crudquery.Not(crudquery.And(crudquery.Eq("note", "fragile"), crudquery.Eq("status", "new"))), OK: true},
+		{Expr: crudquery.Not(crudquery.And(crudquery.Eq("note", "fragile"), crudquery.Eq("status", "paid"))), OK: false},
+	} {
+		got, err := crudquery.Match(tc.Expr, order)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.OK, got, assert.MessageF("%s", tc.Expr.String()))
+	}
+}
+
+func TestApply(t *testing.T) {
+	orders := []Order{
+		{ID: "1", Status: "new", Total: 30},
+		{ID: "2", Status: "paid", Total: 10},
+		{ID: "3", Status: "paid", Total: 20},
+		{ID: "4", Status: "new", Total: 40},
+	}
+
+	got, err := iterkit.CollectE(crudquery.Apply(crudquery.Query{}, iterkit.SliceE(orders)))
+	assert.NoError(t, err)
+	assert.Equal(t, orders, got)
+
+	got, err = iterkit.CollectE(crudquery.Apply(crudquery.Where(crudquery.Eq("status", "paid")).Desc("total"), iterkit.SliceE(orders)))
+	assert.NoError(t, err)
+	assert.Equal(t, []Order{orders[2], orders[1]}, got)
+
+	got, err = iterkit.CollectE(crudquery.Apply(crudquery.Query{}.Asc("status").Asc("total").WithOffset(1).WithLimit(2), iterkit.SliceE(orders)))
+	assert.NoError(t, err)
+	assert.Equal(t, []Order{orders[3], orders[1]}, got)
+
+	_, err = iterkit.CollectE(crudquery.Apply(crudquery.Query{}.Asc("unknown"), iterkit.SliceE(orders)))
+	assert.ErrorIs(t, err, crudquery.ErrInvalidQuery)
+
+	a, b := "a", "b"
+	notes := []Order{{ID: "1", Note: &b}, {ID: "2"}, {ID: "3", Note: &a}, {ID: "4"}}
+	got, err = iterkit.CollectE(crudquery.Apply(crudquery.Query{}.Asc("note"), iterkit.SliceE(notes)))
+	assert.NoError(t, err)
+	assert.Equal(t, []Order{notes[1], notes[3], notes[2], notes[0]}, got, "nil values are ordered first")
+}
@@ -0,0 +1,365 @@
+package crudquery
+
+import (
+	"errors"
+	"iter"
+	"reflect"
+	"slices"
+
+	"go.llib.dev/frameless/pkg/reflectkit"
+)
+
+// Match reports whether an entity satisfies the expression.
+//
+// Match is the in-memory interpretation of an Expr,
+// where expression fields are resolved against the struct fields of ENT using MatchField.
+// Adapters without a native query language can use it to implement crud.Querier.
+func Match[ENT any](expr Expr, ent ENT) (bool, error) {
+	if err := expr.Validate(); err != nil {
+		return false, err
+	}
+	return match(expr, reflect.ValueOf(&ent).Elem())
+}
+
+// FieldValue returns the value of an entity's struct field, resolved with MatchField.
+// An unknown field yields ErrInvalidQuery.
+func FieldValue[ENT any](ent ENT, field string) (any, error) {
+	v, err := lookupField(reflect.ValueOf(&ent).Elem(), field)
+	if err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+// Apply evaluates the Query in memory over the entities of the given iterator.
+//
+// Filtering happens while iterating, but ordering requires Apply to consume the whole input before yielding.
+func Apply[ENT any](q Query, vs iter.Seq2[ENT, error]) iter.Seq2[ENT, error] {
+	return func(yield func(ENT, error) bool) {
+		var zero ENT
+		if err := q.Validate(); err != nil {
+			yield(zero, err)
+			return
+		}
+		var (
+			offset  = q.Offset
+			limit   = q.Limit
+			matches = func(yield func(ENT, error) bool) {
+				for v, err := range vs {
+					if err != nil {
+						yield(v, err)
+						return
+					}
+					if q.Where != nil {
+						ok, err := match(*q.Where, reflect.ValueOf(&v).Elem())
+						if err != nil {
+							yield(zero, err)
+							return
+						}
+						if !ok {
+							continue
+						}
+					}
+					if !yield(v, nil) {
+						return
+					}
+				}
+			}
+		)
+		if 0 < len(q.OrderBy) {
+			var ents []ENT
+			for v, err := range matches {
+				if err != nil {
+					yield(zero, err)
+					return
+				}
+				ents = append(ents, v)
+			}
+			if err := sortBy(ents, q.OrderBy); err != nil {
+				yield(zero, err)
+				return
+			}
+			matches = func(yield func(ENT, error) bool) {
+				for _, v := range ents {
+					if !yield(v, nil) {
+						return
+					}
+				}
+			}
+		}
+		var n int
+		for v, err := range matches {
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if 0 < offset {
+				offset--
+				continue
+			}
+			if 0 < limit && limit <= n {
+				return
+			}
+			n++
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+func sortBy[ENT any](ents []ENT, orders []Order) error {
+	var errs []error
+	slices.SortStableFunc(ents, func(a, b ENT) int {
+		for _, o := range orders {
+			av, err := lookupField(reflect.ValueOf(&a).Elem(), o.Field)
+			if err != nil {
+				errs = append(errs, err)
+				return 0
+			}
+			bv, err := lookupField(reflect.ValueOf(&b).Elem(), o.Field)
+			if err != nil {
+				errs = append(errs, err)
+				return 0
+			}
+			cmp, err := compareOrder(av, bv)
+			if err != nil {
+				errs = append(errs, err)
+				return 0
+			}
+			if o.Desc {
+				cmp *= -1
+			}
+			if cmp != 0 {
+				return cmp
+			}
+		}
+		return 0
+	})
+	return errors.Join(errs...)
+}
+
+// match reports whether the expression is true for the entity.
+// An expression that is unknown, due to a nil field, doesn't match, just like in SQL.
+func match(expr Expr, ent reflect.Value) (bool, error) {
+	t, err := eval(expr, ent)
+	return t == isTrue, err
+}
+
+// truth is a value of the three-valued logic of SQL,
+// where the comparison of a NULL/nil field with a non-nil value is unknown.
+// The values are ordered, so AND is the minimum, OR is the maximum of the operands,
+// and NOT turns true and false into each other, while it keeps unknown.
+type truth int8
+
+const (
+	isFalse truth = iota
+	isUnknown
+	isTrue
+)
+
+func truthOf(ok bool) truth {
+	if ok {
+		return isTrue
+	}
+	return isFalse
+}
+
+func eval(expr Expr, ent reflect.Value) (truth, error) {
+	switch expr.Op {
+	case OpAnd:
+		var t = isTrue
+		for _, sub := range expr.Exprs {
+			st, err := eval(sub, ent)
+			if err != nil {
+				return isFalse, err
+			}
+			t = min(t, st)
+			if t == isFalse {
+				return isFalse, nil
+			}
+		}
+		return t, nil
+
+	case OpOr:
+		var t = isFalse
+		for _, sub := range expr.Exprs {
+			st, err := eval(sub, ent)
+			if err != nil {
+				return isFalse, err
+			}
+			t = max(t, st)
+			if t == isTrue {
+				return isTrue, nil
+			}
+		}
+		return t, nil
+
+	case OpNot:
+		if len(expr.Exprs) != 1 {
+			return isFalse, expr.Validate()
+		}
+		t, err := eval(expr.Exprs[0], ent)
+		return isTrue - t, err
+
+	case OpIn:
+		field, err := lookupField(ent, expr.Field)
+		if err != nil {
+			return isFalse, err
+		}
+		if reflectkit.IsNil(field) {
+			return isUnknown, nil
+		}
+		var t = isFalse
+		for _, value := range expr.Values {
+			if value == nil {
+				t = isUnknown
+				continue
+			}
+			cmp, err := compareTo(field, value, true)
+			if err != nil {
+				return isFalse, err
+			}
+			if cmp == 0 {
+				return isTrue, nil
+			}
+		}
+		return t, nil
+
+	case OpEq, OpNe, OpLt, OpLte, OpGt, OpGte:
+		field, err := lookupField(ent, expr.Field)
+		if err != nil {
+			return isFalse, err
+		}
+		if expr.Value == nil {
+			isNil := reflectkit.IsNil(field)
+			if expr.Op == OpNe {
+				return truthOf(!isNil), nil
+			}
+			return truthOf(isNil), nil
+		}
+		if reflectkit.IsNil(field) {
+			return isUnknown, nil
+		}
+		cmp, err := compareTo(field, expr.Value, expr.Op == OpEq || expr.Op == OpNe)
+		if err != nil {
+			return isFalse, err
+		}
+		switch expr.Op {
+		case OpEq:
+			return truthOf(cmp == 0), nil
+		case OpNe:
+			return truthOf(cmp != 0), nil
+		case OpLt:
+			return truthOf(cmp < 0), nil
+		case OpLte:
+			return truthOf(cmp <= 0), nil
+		case OpGt:
+			return truthOf(0 < cmp), nil
+		default: // OpGte
+			return truthOf(0 <= cmp), nil
+		}
+
+	default:
+		return isFalse, expr.Validate()
+	}
+}
+
+func lookupField(ent reflect.Value, name string) (reflect.Value, error) {
+	base := reflectkit.BaseValue(ent)
+	if base.Kind() != reflect.Struct {
+		return reflect.Value{}, ErrInvalidQuery.F("%s is not a struct type, field %q can't be looked up", ent.Type().String(), name)
+	}
+	sf, ok := base.Type().FieldByNameFunc(func(fieldName string) bool {
+		return MatchField(name, fieldName)
+	})
+	if !ok {
+		return reflect.Value{}, ErrInvalidQuery.F("unknown field for %s: %q", base.Type().String(), name)
+	}
+	field, err := base.FieldByIndexErr(sf.Index)
+	if err != nil { // nil embedded struct pointer
+		return reflect.Zero(sf.Type), nil
+	}
+	return field, nil
+}
+
+func compareTo(field reflect.Value, value any, equality bool) (int, error) {
+	return compareValues(field, reflect.ValueOf(value), equality)
+}
+
+// compareOrder compares the field values of two entities for ordering.
+// A nil value is ordered before every other value, as SQLite and MySQL do.
+func compareOrder(a, b reflect.Value) (int, error) {
+	switch aNil, bNil := reflectkit.IsNil(a), reflectkit.IsNil(b); {
+	case aNil && bNil:
+		return 0, nil
+	case aNil:
+		return -1, nil
+	case bNil:
+		return 1, nil
+	}
+	return compareValues(a, b, false)
+}
+
+// compareValues compares a field value with a query value.
+// The query value is converted into the field's type when it is safe to do so,
+// which allows comparing named types (e.g. type UserID string) with plain literals,
+// or numeric fields with numbers decoded from JSON.
+//
+// When only equality is needed, types without ordering (e.g. bool) are compared for equality.
+func compareValues(field, value reflect.Value, equality bool) (int, error) {
+	field = reflectkit.BaseValue(field)
+	if reflectkit.IsNil(field) {
+		return -1, nil
+	}
+	value = reflectkit.BaseValue(value)
+	if value.Type() != field.Type() {
+		switch {
+		case isNumber(field.Kind()) && isNumber(value.Kind()) && (isFloat(field.Kind()) || isFloat(value.Kind())):
+			float64Type := reflect.TypeOf(float64(0))
+			field, value = field.Convert(float64Type), value.Convert(float64Type)
+		case convertible(value, field.Type()):
+			value = value.Convert(field.Type())
+		}
+	}
+	cmp, err := reflectkit.Compare(field, value)
+	if err == nil {
+		return cmp, nil
+	}
+	if equality && errors.Is(err, reflectkit.ErrNotComparable) && value.Type() == field.Type() {
+		if reflectkit.Equal(field, value) {
+			return 0, nil
+		}
+		return 1, nil
+	}
+	return 0, ErrInvalidQuery.F("%s", err.Error())
+}
+
+func convertible(value reflect.Value, typ reflect.Type) bool {
+	if !value.Type().ConvertibleTo(typ) {
+		return false
+	}
+	switch {
+	case isNumber(value.Kind()) && isNumber(typ.Kind()):
+		return true
+	case value.Kind() == typ.Kind():
+		return true
+	default: // avoid conversions like int -> string, which yields a rune
+		return false
+	}
+}
+
+func isFloat(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
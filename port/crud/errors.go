@@ -1,8 +1,78 @@
 package crud
 
-import "go.llib.dev/frameless/internal/errorkitlite"
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.llib.dev/frameless/internal/errorkitlite"
+)
 
 const (
 	ErrAlreadyExists errorkitlite.Error = "err-already-exists"
 	ErrNotFound      errorkitlite.Error = "err-not-found"
+	ErrInvalidCursor errorkitlite.Error = "err-invalid-cursor"
+	// ErrConcurrentModification is returned by crud.Updater when a versioned entity was changed
+	// by someone else since it was read, and the update would overwrite those changes.
+	ErrConcurrentModification errorkitlite.Error = "err-concurrent-modification"
 )
+
+// BulkError is returned by the bulk operations, such as ManyCreator.CreateMany,
+// when some of the items failed while the rest of them succeeded.
+//
+// errors.Is and errors.As look through the errors of the failed items,
+// thus errors.Is(err, ErrAlreadyExists) tells if any of the items failed because it already existed.
+type BulkError struct {
+	// Failures are the failed items, in the order they were given to the bulk operation.
+	Failures []BulkFailure
+}
+
+// BulkFailure describes a single failed item of a bulk operation.
+type BulkFailure struct {
+	// Index is the position of the failed item in the arguments of the bulk operation.
+	Index int
+	// Err is the reason of the failure.
+	Err error
+}
+
+func (err *BulkError) Error() string {
+	var msgs []string
+	for _, f := range err.Failures {
+		msgs = append(msgs, fmt.Sprintf("[%d] %s", f.Index, f.Err.Error()))
+	}
+	return fmt.Sprintf("%d bulk item(s) failed: %s", len(err.Failures), strings.Join(msgs, "; "))
+}
+
+func (err *BulkError) Unwrap() []error {
+	var errs []error
+	for _, f := range err.Failures {
+		errs = append(errs, f.Err)
+	}
+	return errs
+}
+
+// LookupFailure looks up the failure of the item with the given index.
+func (err *BulkError) LookupFailure(index int) (BulkFailure, bool) {
+	for _, f := range err.Failures {
+		if f.Index == index {
+			return f, true
+		}
+	}
+	return BulkFailure{}, false
+}
+
+// Add records the failure of an item.
+func (err *BulkError) Add(index int, cause error) {
+	err.Failures = append(err.Failures, BulkFailure{Index: index, Err: cause})
+}
+
+// ErrOrNil returns the BulkError when it has any failure, otherwise it returns nil.
+func (err *BulkError) ErrOrNil() error {
+	if err == nil || len(err.Failures) == 0 {
+		return nil
+	}
+	sort.Slice(err.Failures, func(i, j int) bool {
+		return err.Failures[i].Index < err.Failures[j].Index
+	})
+	return err
+}
@@ -72,6 +72,12 @@ func Set[ID any](ptr any, id ID) error {
 			return nil
 		}
 
+		if c := lookupCompositeIdentifier(idByTypeKey{ENT: concreteType, ID: reflectkit.TypeOf[ID]()}); c.OK() {
+			c.Set(newStruct, reflect.ValueOf(id))
+			ifaceVal.Set(newStruct)
+			return nil
+		}
+
 		if _, val, ok := extractIdentifierField(concreteType, newStruct); ok {
 			val.Set(reflect.ValueOf(id))
 			ifaceVal.Set(newStruct)
@@ -101,6 +107,11 @@ func Set[ID any](ptr any, id ID) error {
 		return nil
 	}
 
+	if c := lookupCompositeIdentifier(idByTypeKey{ENT: rt.Elem(), ID: reflectkit.TypeOf[ID]()}); c.OK() {
+		c.Set(r.Elem(), reflect.ValueOf(id))
+		return nil
+	}
+
 	if _, val, ok := ExtractIdentifierField(ptr); ok {
 		val.Set(reflect.ValueOf(id))
 		return nil
@@ -123,6 +134,8 @@ func Get[ID, ENT any](ent ENT) ID {
 // The function prioritises the following when selecting an ID field:
 // - Any field of type ID.
 // - If multiple fields of type ID exist, the one tagged as 'ext:"id"' is preferred.
+// - If multiple fields are tagged as 'ext:"id"', and ID is a struct with a field for each of them by name and type,
+// then ID is treated as a composite ID, which is assembled from the tagged fields.
 //
 // This function helps identify the primary ID field in ENT structs consistently.
 func Lookup[ID, ENT any](ent ENT) (id ID, ok bool) {
@@ -156,6 +169,10 @@ func Lookup[ID, ENT any](ent ENT) (id ID, ok bool) {
 		id, ok = value.Interface().(ID)
 		return id, ok
 	}
+	if c := lookupCompositeIdentifier(idByTypeKey{ENT: typ, ID: reflectkit.TypeOf[ID]()}); c.OK() {
+		id, ok = c.Get(str).Interface().(ID)
+		return id, ok
+	}
 	if _, value, ok := extractIdentifierField(typ, str); ok {
 		id, ok = value.Interface().(ID)
 		return id, ok
@@ -163,6 +180,71 @@ func Lookup[ID, ENT any](ent ENT) (id ID, ok bool) {
 	return id, false
 }
 
+var cacheCompositeIdentifier synckit.Map[idByTypeKey, compositeIdentifier]
+
+// compositeIdentifier describes a composite ID,
+// where the ENT has multiple fields tagged with `ext:"id"`,
+// and the ID is a struct, which has a field with the same name and type for each of them.
+//
+//	type Entity struct {
+//		TenantID string `ext:"id"`
+//		LocalID  int    `ext:"id"`
+//	}
+//
+//	type EntityID struct {
+//		TenantID string
+//		LocalID  int
+//	}
+type compositeIdentifier struct {
+	ID reflect.Type
+	// Fields pairs the ENT field indexes with the ID field indexes.
+	Fields [][2]int
+}
+
+func lookupCompositeIdentifier(key idByTypeKey) compositeIdentifier {
+	return cacheCompositeIdentifier.GetOrInit(key, func() compositeIdentifier {
+		if key.ENT.Kind() != reflect.Struct || key.ID.Kind() != reflect.Struct {
+			return compositeIdentifier{}
+		}
+		var fields [][2]int
+		for i := range key.ENT.NumField() {
+			entField := key.ENT.Field(i)
+			tag, ok, err := extTag.Lookup(entField)
+			if err != nil || !ok || !tag.IsID {
+				continue
+			}
+			idField, ok := key.ID.FieldByName(entField.Name)
+			if !ok || len(idField.Index) != 1 || idField.Type != entField.Type || !entField.IsExported() || !idField.IsExported() {
+				return compositeIdentifier{}
+			}
+			fields = append(fields, [2]int{i, idField.Index[0]})
+		}
+		if len(fields) < 2 || len(fields) != key.ID.NumField() {
+			return compositeIdentifier{}
+		}
+		return compositeIdentifier{ID: key.ID, Fields: fields}
+	})
+}
+
+func (c compositeIdentifier) OK() bool {
+	return 0 < len(c.Fields)
+}
+
+func (c compositeIdentifier) Get(ent reflect.Value) reflect.Value {
+	id := reflect.New(c.ID).Elem()
+	for _, f := range c.Fields {
+		id.Field(f[1]).Set(ent.Field(f[0]))
+	}
+	return id
+}
+
+// Set expects an addressable ENT value.
+func (c compositeIdentifier) Set(ent reflect.Value, id reflect.Value) {
+	for _, f := range c.Fields {
+		ent.Field(f[0]).Set(id.Field(f[1]))
+	}
+}
+
 type idByTypeKey struct {
 	ENT reflect.Type
 	ID  reflect.Type
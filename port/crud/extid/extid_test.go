@@ -1228,3 +1228,62 @@ func TestGet(t *testing.T) {
 		assert.Equal(t, extid.Get[MyIDType](v), v.DI)
 	})
 }
+
+func TestLookup_compositeID(t *testing.T) {
+	type ID struct {
+		TenantID string
+		LocalID  int
+	}
+	type ENT struct {
+		TenantID string `ext:"id"`
+		LocalID  int    `ext:"id"`
+		Value    string
+	}
+
+	id, ok := extid.Lookup[ID](ENT{TenantID: "t", LocalID: 42, Value: "v"})
+	assert.True(t, ok)
+	assert.Equal(t, ID{TenantID: "t", LocalID: 42}, id)
+
+	id, ok = extid.Lookup[ID](&ENT{TenantID: "t"})
+	assert.True(t, ok, "zero value parts should be OK, since the fields exist")
+	assert.Equal(t, ID{TenantID: "t"}, id)
+
+	t.Run("when the ID struct doesn't match the tagged fields", func(t *testing.T) {
+		type OtherID struct {
+			TenantID string
+			LocalID  string
+		}
+		_, ok := extid.Lookup[OtherID](ENT{TenantID: "t", LocalID: 42})
+		assert.False(t, ok)
+	})
+
+	t.Run("when the ID struct is the type of a field", func(t *testing.T) {
+		type ENT struct {
+			ID    ID `ext:"id"`
+			Value string
+		}
+		id, ok := extid.Lookup[ID](ENT{ID: ID{TenantID: "t", LocalID: 42}})
+		assert.True(t, ok)
+		assert.Equal(t, ID{TenantID: "t", LocalID: 42}, id)
+	})
+}
+
+func TestSet_compositeID(t *testing.T) {
+	type ID struct {
+		TenantID string
+		LocalID  int
+	}
+	type ENT struct {
+		TenantID string `ext:"id"`
+		LocalID  int    `ext:"id"`
+		Value    string
+	}
+
+	ent := ENT{Value: "v"}
+	assert.NoError(t, extid.Set(&ent, ID{TenantID: "t", LocalID: 42}))
+	assert.Equal(t, ENT{TenantID: "t", LocalID: 42, Value: "v"}, ent)
+
+	var accessor extid.Accessor[ENT, ID]
+	assert.NoError(t, accessor.Set(&ent, ID{TenantID: "o", LocalID: 24}))
+	assert.Equal(t, ID{TenantID: "o", LocalID: 24}, accessor.Get(ent))
+}
@@ -0,0 +1,226 @@
+// Package extver locates the version field of an entity.
+//
+// A versioned entity opts into optimistic concurrency control:
+// crud.Updater implementations compare the version of the received entity with the stored one,
+// and reject the update with crud.ErrConcurrentModification when they differ.
+package extver
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.llib.dev/frameless/internal/errorkitlite"
+	"go.llib.dev/frameless/pkg/reflectkit"
+	"go.llib.dev/frameless/pkg/synckit"
+)
+
+const ErrVersionFieldNotFound errorkitlite.Error = "ErrVersionFieldNotFound"
+
+// Initial is the version of a newly created entity.
+const Initial int64 = 1
+
+// Lookup returns the version of the entity.
+//
+// The version field is an integer field, tagged with `ext:"version"`.
+// Unlike extid, there is no naming convention for the version field,
+// because optimistic concurrency control is an opt-in behaviour.
+//
+// ok reports whether the entity is versioned.
+func Lookup[ENT any](ent ENT) (version int64, ok bool) {
+	val := reflectkit.BaseValue(reflect.ValueOf(ent))
+	if !val.IsValid() || val.Kind() != reflect.Struct {
+		return 0, false
+	}
+	field, ok := versionField(val)
+	if !ok {
+		return 0, false
+	}
+	if field.CanInt() {
+		return field.Int(), true
+	}
+	return int64(field.Uint()), true
+}
+
+// Get returns the version of the entity, or zero if the entity is not versioned.
+func Get[ENT any](ent ENT) int64 {
+	version, _ := Lookup(ent)
+	return version
+}
+
+// Set assigns the version to the version field of the entity.
+func Set[ENT any](ptr *ENT, version int64) error {
+	if ptr == nil {
+		return fmt.Errorf("nil %s pointer given to extver.Set", reflectkit.TypeOf[ENT]().String())
+	}
+	val := reflectkit.BaseValue(reflect.ValueOf(ptr))
+	if !val.IsValid() || val.Kind() != reflect.Struct {
+		return ErrVersionFieldNotFound
+	}
+	if !val.CanAddr() {
+		// ENT is an interface type, the boxed struct needs to be copied to be able to change it.
+		box := reflect.ValueOf(ptr).Elem()
+		cpy := reflect.New(val.Type()).Elem()
+		cpy.Set(val)
+		if err := setVersion(cpy, version); err != nil {
+			return err
+		}
+		box.Set(cpy)
+		return nil
+	}
+	return setVersion(val, version)
+}
+
+func setVersion(val reflect.Value, version int64) error {
+	field, ok := versionField(val)
+	if !ok {
+		return ErrVersionFieldNotFound
+	}
+	if field.CanInt() {
+		field.SetInt(version)
+	} else {
+		field.SetUint(uint64(version))
+	}
+	return nil
+}
+
+func versionField(val reflect.Value) (reflect.Value, bool) {
+	index, ok := lookupVersionFieldIndex(val.Type())
+	if !ok {
+		return reflect.Value{}, false
+	}
+	return val.FieldByIndex(index), true
+}
+
+var cacheVersionFieldIndex synckit.Map[reflect.Type, []int]
+
+func lookupVersionFieldIndex(typ reflect.Type) ([]int, bool) {
+	index := cacheVersionFieldIndex.GetOrInit(typ, func() []int {
+		return findVersionFieldIndex(typ)
+	})
+	return index, index != nil
+}
+
+func findVersionFieldIndex(typ reflect.Type) []int {
+	{ // lookup by ext:"version" tag
+		for i := range typ.NumField() {
+			field := typ.Field(i)
+			tag, ok, err := extTag.Lookup(field)
+			if err != nil || !ok || !tag.IsVersion {
+				continue
+			}
+			if !isInteger(field.Type) {
+				panic(fmt.Sprintf(`%s.%s is tagged with ext:"version", but it is not an integer type`,
+					typ.String(), field.Name))
+			}
+			return field.Index
+		}
+	}
+	{ // lookup the version in the embedded fields
+		for i := range typ.NumField() {
+			field := typ.Field(i)
+			if !field.Anonymous || field.Type.Kind() != reflect.Struct {
+				continue
+			}
+			if sub := findVersionFieldIndex(field.Type); sub != nil {
+				return append([]int{i}, sub...)
+			}
+		}
+	}
+	return nil
+}
+
+func isInteger(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+type extTagField struct {
+	IsVersion bool
+}
+
+var extTag = reflectkit.TagHandler[extTagField]{
+	Name: "ext",
+
+	Parse: func(field reflect.StructField, tagName, tagValue string) (extTagField, error) {
+		const isVersionFlag = "version"
+		var tag extTagField
+		for _, field := range strings.Fields(tagValue) {
+			if strings.EqualFold(field, isVersionFlag) {
+				tag.IsVersion = true
+			}
+		}
+		return tag, nil
+	},
+}
+
+//--------------------------------------------------------------------------------------------------------------------//
+
+// Accessor is a function that allows describing how to access the version field in an ENT type.
+// The returned version pointer will be used to Lookup its value, or to set new value to this version pointer.
+// Its functions will panic if func is provided, but it returns a nil pointer, as it is considered as implementation error.
+//
+// Example implementation:
+//
+//	extver.Accessor[Foo](func(v *Foo) *int64 { return &v.Version })
+//
+// default: extver.Lookup, extver.Set, which will use the `ext:"version"` tag.
+type Accessor[ENT any] func(*ENT) *int64
+
+func (fn Accessor[ENT]) Get(ent ENT) int64 {
+	version, _ := fn.Lookup(ent)
+	return version
+}
+
+func (fn Accessor[ENT]) Lookup(ent ENT) (int64, bool) {
+	if fn == nil {
+		return Lookup(ent)
+	}
+	return *fn.ptr(&ent), true
+}
+
+func (fn Accessor[ENT]) Set(ptr *ENT, version int64) error {
+	if fn == nil {
+		return Set(ptr, version)
+	}
+	if ptr == nil {
+		return fmt.Errorf("nil %T pointer given for set version", *new(ENT))
+	}
+	*fn.ptr(ptr) = version
+	return nil
+}
+
+// IsVersioned reports whether ENT has a version field that the Accessor can access.
+func (fn Accessor[ENT]) IsVersioned() bool {
+	if fn != nil {
+		return true
+	}
+	typ := reflectkit.TypeOf[ENT]()
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return false
+	}
+	_, ok := lookupVersionFieldIndex(typ)
+	return ok
+}
+
+func (fn Accessor[ENT]) ptr(ent *ENT) *int64 {
+	if ent == nil {
+		panic(fmt.Sprintf("nil %T error (%T)", *new(ENT), fn))
+	}
+	version := fn(ent)
+	if version == nil {
+		var format string
+		format = "implementation error: %T is provided, but it returned a nil pointer."
+		format += "\nExample implementation: fn = func(v *Foo) *int64 { return &v.Version }"
+		panic(fmt.Sprintf(format, fn))
+	}
+	return version
+}
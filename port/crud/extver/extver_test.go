@@ -0,0 +1,103 @@
+package extver_test
+
+import (
+	"testing"
+
+	"go.llib.dev/frameless/port/crud/extver"
+	"go.llib.dev/frameless/testing/testent"
+	"go.llib.dev/testcase/assert"
+)
+
+func TestLookup(t *testing.T) {
+	t.Run("tagged field", func(t *testing.T) {
+		version, ok := extver.Lookup(testent.Doc{Version: 42})
+		assert.True(t, ok)
+		assert.Equal(t, int64(42), version)
+	})
+	t.Run("unsigned tagged field", func(t *testing.T) {
+		type Ent struct {
+			Rev uint32 `ext:"version"`
+		}
+		version, ok := extver.Lookup(Ent{Rev: 7})
+		assert.True(t, ok)
+		assert.Equal(t, int64(7), version)
+	})
+	t.Run("embedded field", func(t *testing.T) {
+		type Ent struct {
+			testent.Doc
+		}
+		version, ok := extver.Lookup(Ent{Doc: testent.Doc{Version: 3}})
+		assert.True(t, ok)
+		assert.Equal(t, int64(3), version)
+	})
+	t.Run("pointer", func(t *testing.T) {
+		version, ok := extver.Lookup(&testent.Doc{Version: 42})
+		assert.True(t, ok)
+		assert.Equal(t, int64(42), version)
+
+		_, ok = extver.Lookup[*testent.Doc](nil)
+		assert.False(t, ok)
+	})
+	t.Run("a field named Version is not versioning by convention", func(t *testing.T) {
+		type Ent struct {
+			Version int
+		}
+		_, ok := extver.Lookup(Ent{Version: 1})
+		assert.False(t, ok)
+	})
+	t.Run("non integer tagged field", func(t *testing.T) {
+		type Ent struct {
+			Version string `ext:"version"`
+		}
+		assert.Panic(t, func() { extver.Lookup(Ent{}) })
+	})
+}
+
+func TestSet(t *testing.T) {
+	t.Run("tagged field", func(t *testing.T) {
+		var doc testent.Doc
+		assert.NoError(t, extver.Set(&doc, 42))
+		assert.Equal(t, int64(42), doc.Version)
+	})
+	t.Run("interface", func(t *testing.T) {
+		var v any = testent.Doc{Title: "foo"}
+		assert.NoError(t, extver.Set(&v, 42))
+		assert.Equal(t, any(testent.Doc{Title: "foo", Version: 42}), v)
+	})
+	t.Run("not versioned", func(t *testing.T) {
+		var foo testent.Foo
+		assert.ErrorIs(t, extver.Set(&foo, 42), extver.ErrVersionFieldNotFound)
+	})
+	t.Run("nil pointer", func(t *testing.T) {
+		assert.Error(t, extver.Set[testent.Doc](nil, 42))
+	})
+}
+
+func TestAccessor(t *testing.T) {
+	type Ent struct {
+		Rev int64
+	}
+	t.Run("nil Accessor falls back to the ext tag", func(t *testing.T) {
+		var va extver.Accessor[testent.Doc]
+		assert.True(t, va.IsVersioned())
+
+		var doc testent.Doc
+		assert.NoError(t, va.Set(&doc, 2))
+		assert.Equal(t, int64(2), va.Get(doc))
+
+		assert.False(t, extver.Accessor[Ent](nil).IsVersioned())
+		assert.False(t, extver.Accessor[testent.Foo](nil).IsVersioned())
+	})
+	t.Run("Accessor func", func(t *testing.T) {
+		var va extver.Accessor[Ent] = func(v *Ent) *int64 { return &v.Rev }
+		assert.True(t, va.IsVersioned())
+
+		var ent Ent
+		assert.NoError(t, va.Set(&ent, 2))
+		assert.Equal(t, int64(2), ent.Rev)
+
+		version, ok := va.Lookup(ent)
+		assert.True(t, ok)
+		assert.Equal(t, int64(2), version)
+	})
+}
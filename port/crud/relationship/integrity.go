@@ -0,0 +1,298 @@
+package relationship
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+
+	"go.llib.dev/frameless/pkg/errorkit"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/pkg/reflectkit"
+	"go.llib.dev/frameless/port/comproto"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/crudquery"
+	"go.llib.dev/frameless/port/crud/extid"
+)
+
+// ErrRestricted is returned when an entity can't be deleted, because other entities still refer to it.
+const ErrRestricted errorkit.Error = "ErrRestricted"
+
+// Policy tells what happens with the referring entities, when the entity they refer to is deleted.
+type Policy int
+
+const (
+	// Restrict refuses to delete an entity while other entities refer to it.
+	Restrict Policy = iota
+	// Cascade deletes the referring entities together with the entity they refer to.
+	Cascade
+	// SetNull removes the reference from the referring entities, and keeps them.
+	SetNull
+)
+
+// Repository decorates a crud repository and keeps the referential integrity of its entities,
+// based on the registered relationships, see BelongsTo and ReferencesMany.
+//
+// Create and Update refuse an entity that refers to an absent entity.
+// DeleteByID and DeleteAll apply the Policy of the Dependents on the entities that refer to the deleted entity.
+type Repository[ENT, ID any] struct {
+	// Source is the decorated repository.
+	Source Source[ENT, ID]
+	// Targets [optional] are the repositories of the entities that ENT refers to.
+	Targets []Target
+	// Dependents [optional] are the repositories of the entities that refer to ENT.
+	Dependents []Dependent
+	// IDA [optional] is the ENT's ID accessor.
+	//
+	// default: extid Lookup/Set
+	IDA extid.Accessor[ENT, ID]
+}
+
+// Source is the repository that a Repository decorates.
+type Source[ENT, ID any] interface {
+	crud.Creator[ENT]
+	crud.ByIDFinder[ENT, ID]
+	crud.AllFinder[ENT]
+	crud.Updater[ENT]
+	crud.ByIDDeleter[ID]
+}
+
+// Target is a repository of entities that other entities refer to.
+type Target interface {
+	targetType() reflect.Type
+	exists(ctx context.Context, id reflect.Value) (bool, error)
+}
+
+// Dependent is a repository of entities that refer to other entities.
+type Dependent interface {
+	policy() Policy
+	onDelete(ctx context.Context, referenced reflect.Type, id any) error
+}
+
+// Referenced is a Target, where the existence of the ENT entities is checked with the Repository.
+// When the Repository implements crud.Exister, it is preferred over FindByID.
+type Referenced[ENT, ID any] struct {
+	Repository crud.ByIDFinder[ENT, ID]
+}
+
+func (t Referenced[ENT, ID]) targetType() reflect.Type {
+	return reflectkit.TypeOf[ENT]()
+}
+
+func (t Referenced[ENT, ID]) exists(ctx context.Context, rID reflect.Value) (bool, error) {
+	id, ok := rID.Interface().(ID)
+	if !ok {
+		return false, fmt.Errorf("%w: %s ID was expected, but got %s",
+			reflectkit.ErrTypeMismatch, reflectkit.TypeOf[ID](), rID.Type())
+	}
+	if exister, ok := t.Repository.(crud.Exister[ID]); ok {
+		return exister.ExistsByID(ctx, id)
+	}
+	_, found, err := t.Repository.FindByID(ctx, id)
+	return found, err
+}
+
+// Referrer is a Dependent, where the ENT entities refer to the deleted entity,
+// and the Policy is applied on them through the Repository.
+type Referrer[ENT, ID any] struct {
+	Repository ReferrerRepository[ENT, ID]
+	Policy     Policy
+	// Field [optional] is the name of the BelongsTo reference field, as the Repository's crud.Querier understands it.
+	// When supplied, and the Repository implements crud.Querier,
+	// the referring entities are looked up by the field, instead of filtering every entity of FindAll.
+	// ReferencesMany relationships are always looked up with FindAll.
+	Field string
+	// IDA [optional] is the ENT's ID accessor.
+	//
+	// default: extid Lookup/Set
+	IDA extid.Accessor[ENT, ID]
+}
+
+// ReferrerRepository is the repository of a Referrer.
+type ReferrerRepository[ENT, ID any] interface {
+	crud.AllFinder[ENT]
+	crud.Updater[ENT]
+	crud.ByIDDeleter[ID]
+}
+
+func (d Referrer[ENT, ID]) policy() Policy {
+	return d.Policy
+}
+
+func (d Referrer[ENT, ID]) onDelete(ctx context.Context, referenced reflect.Type, id any) error {
+	typ := reflectkit.TypeOf[ENT]()
+	ref, ok := lookupReference(typ, referenced)
+	if !ok {
+		return fmt.Errorf("%s has no relationship with %s", typ, referenced)
+	}
+	// the referrers are collected first, as changing them while iterating is not supported by every Repository
+	ents, err := iterkit.CollectE(iterkit.OnSeqEValue(d.referrers(ctx, ref, id), func(i iter.Seq[ENT]) iter.Seq[ENT] {
+		return iterkit.Filter(i, func(ent ENT) bool {
+			return ref.Has(reflect.ValueOf(ent), id)
+		})
+	}))
+	if err != nil {
+		return err
+	}
+	for _, ent := range ents {
+		switch d.Policy {
+		case Restrict:
+			return ErrRestricted.F("%s with id %v is referred by %s with id %v", referenced, id, typ, d.IDA.Get(ent))
+		case Cascade:
+			// a referrer that is deleted meanwhile is already where Cascade wants it to be
+			if err := d.Repository.DeleteByID(ctx, d.IDA.Get(ent)); err != nil && !errors.Is(err, crud.ErrNotFound) {
+				return err
+			}
+		case SetNull:
+			if err := ref.Unset(reflect.ValueOf(&ent), id); err != nil {
+				return err
+			}
+			if err := d.Repository.Update(ctx, &ent); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown relationship.Policy: %d", d.Policy)
+		}
+	}
+	return nil
+}
+
+func (d Referrer[ENT, ID]) referrers(ctx context.Context, ref reference, id any) iter.Seq2[ENT, error] {
+	if querier, ok := d.Repository.(crud.Querier[ENT]); ok && d.Field != "" && ref.referencesMany == nil {
+		q := crudquery.Where(crudquery.Eq(d.Field, id))
+		if d.Policy == Restrict { // a single referrer is enough to refuse the delete
+			q = q.WithLimit(1)
+		}
+		return querier.Query(ctx, q)
+	}
+	return d.Repository.FindAll(ctx)
+}
+
+func (r Repository[ENT, ID]) Create(ctx context.Context, ptr *ENT) error {
+	if ptr == nil {
+		return r.Source.Create(ctx, ptr)
+	}
+	if err := r.checkReferences(ctx, *ptr); err != nil {
+		return err
+	}
+	return r.Source.Create(ctx, ptr)
+}
+
+func (r Repository[ENT, ID]) FindByID(ctx context.Context, id ID) (ENT, bool, error) {
+	return r.Source.FindByID(ctx, id)
+}
+
+func (r Repository[ENT, ID]) FindAll(ctx context.Context) iter.Seq2[ENT, error] {
+	return r.Source.FindAll(ctx)
+}
+
+func (r Repository[ENT, ID]) Update(ctx context.Context, ptr *ENT) error {
+	if ptr == nil {
+		return r.Source.Update(ctx, ptr)
+	}
+	if err := r.checkReferences(ctx, *ptr); err != nil {
+		return err
+	}
+	return r.Source.Update(ctx, ptr)
+}
+
+// DeleteByID deletes the entity, after the Dependents' Policy is applied on the entities that refer to it.
+//
+// When the Source supports transactions, the whole delete runs in one,
+// so a refused delete doesn't leave the referring entities half-processed.
+func (r Repository[ENT, ID]) DeleteByID(ctx context.Context, id ID) (rErr error) {
+	if c, ok := r.Source.(comproto.OnePhaseCommitProtocol); ok {
+		tx, err := c.BeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer comproto.FinishOnePhaseCommit(&rErr, c, tx)
+		ctx = tx
+	}
+	return r.deleteByID(ctx, id)
+}
+
+// DeleteAll deletes every entity, after the Dependents' Policy is applied on the entities that refer to them.
+func (r Repository[ENT, ID]) DeleteAll(ctx context.Context) (rErr error) {
+	if c, ok := r.Source.(comproto.OnePhaseCommitProtocol); ok {
+		tx, err := c.BeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer comproto.FinishOnePhaseCommit(&rErr, c, tx)
+		ctx = tx
+	}
+	// the IDs are collected first, as deleting while iterating is not supported by every Source
+	ents, err := iterkit.CollectE(r.Source.FindAll(ctx))
+	if err != nil {
+		return err
+	}
+	for _, ent := range ents {
+		// an entity that is deleted meanwhile, for example by a cascade, is already where DeleteAll wants it to be
+		if err := r.deleteByID(ctx, r.IDA.Get(ent)); err != nil && !errors.Is(err, crud.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r Repository[ENT, ID]) BeginTx(ctx context.Context) (context.Context, error) {
+	if c, ok := r.Source.(comproto.OnePhaseCommitProtocol); ok {
+		return c.BeginTx(ctx)
+	}
+	return ctx, nil
+}
+
+func (r Repository[ENT, ID]) CommitTx(ctx context.Context) error {
+	if c, ok := r.Source.(comproto.OnePhaseCommitProtocol); ok {
+		return c.CommitTx(ctx)
+	}
+	return nil
+}
+
+func (r Repository[ENT, ID]) RollbackTx(ctx context.Context) error {
+	if c, ok := r.Source.(comproto.OnePhaseCommitProtocol); ok {
+		return c.RollbackTx(ctx)
+	}
+	return nil
+}
+
+func (r Repository[ENT, ID]) deleteByID(ctx context.Context, id ID) error {
+	var (
+		typ = reflectkit.TypeOf[ENT]()
+		key = any(id)
+	)
+	// restrictions are checked first, so nothing is cascaded when the delete is refused anyway
+	for _, restrict := range []bool{true, false} {
+		for _, dep := range r.Dependents {
+			if (dep.policy() == Restrict) != restrict {
+				continue
+			}
+			if err := dep.onDelete(ctx, typ, key); err != nil {
+				return err
+			}
+		}
+	}
+	return r.Source.DeleteByID(ctx, id)
+}
+
+func (r Repository[ENT, ID]) checkReferences(ctx context.Context, ent ENT) error {
+	typ := reflectkit.TypeOf[ENT]()
+	for _, target := range r.Targets {
+		ref, ok := lookupReference(typ, target.targetType())
+		if !ok {
+			return fmt.Errorf("%s has no relationship with %s", typ, target.targetType())
+		}
+		for _, id := range ref.IDs(reflect.ValueOf(ent)) {
+			found, err := target.exists(ctx, id)
+			if err != nil {
+				return err
+			}
+			if !found {
+				return ErrMissingReference.F("%s refers to an absent %s with id: %v", typ, target.targetType(), id.Interface())
+			}
+		}
+	}
+	return nil
+}
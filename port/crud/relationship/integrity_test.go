@@ -0,0 +1,245 @@
+package relationship_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"testing"
+
+	"go.llib.dev/frameless/adapter/memory"
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/port/crud/relationship"
+	"go.llib.dev/frameless/port/crud/relationship/relationshipcontract"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+)
+
+func ExampleRepository() {
+	type UserID string
+	type User struct {
+		ID UserID
+	}
+
+	type NoteID string
+	type Note struct {
+		ID      NoteID
+		OwnerID UserID
+	}
+
+	var (
+		m     = memory.NewMemory()
+		users = memory.NewRepository[User, UserID](m)
+		notes = memory.NewRepository[Note, NoteID](m)
+	)
+
+	// notes can only refer to existing users
+	_ = relationship.Repository[Note, NoteID]{
+		Source: notes,
+		Targets: []relationship.Target{
+			relationship.Referenced[User, UserID]{Repository: users},
+		},
+	}
+
+	// deleting a user deletes the user's notes as well
+	_ = relationship.Repository[User, UserID]{
+		Source: users,
+		Dependents: []relationship.Dependent{
+			relationship.Referrer[Note, NoteID]{Repository: notes, Policy: relationship.Cascade},
+		},
+	}
+}
+
+type IntegrityUserID string
+
+type IntegrityUser struct {
+	ID   IntegrityUserID
+	Name string
+}
+
+type IntegrityNoteID string
+
+type IntegrityNote struct {
+	ID      IntegrityNoteID
+	OwnerID IntegrityUserID
+	Text    string
+}
+
+type IntegrityGroupID string
+
+type IntegrityGroup struct {
+	ID      IntegrityGroupID
+	Members []IntegrityUserID
+}
+
+func TestRepository(t *testing.T) {
+	type (
+		User    = IntegrityUser
+		UserID  = IntegrityUserID
+		Note    = IntegrityNote
+		NoteID  = IntegrityNoteID
+		Group   = IntegrityGroup
+		GroupID = IntegrityGroupID
+	)
+
+	var (
+		makeUser = func(tb testing.TB) User {
+			return User{Name: testcase.ToT(&tb).Random.String()}
+		}
+		makeNote = func(tb testing.TB) Note {
+			return Note{Text: testcase.ToT(&tb).Random.String()}
+		}
+		makeGroup = func(tb testing.TB) Group {
+			return Group{}
+		}
+	)
+
+	subjects := func(policy relationship.Policy) (relationship.Repository[User, UserID], relationship.Repository[Note, NoteID], relationship.Repository[Group, GroupID]) {
+		var (
+			m      = memory.NewMemory()
+			users  = memory.NewRepository[User, UserID](m)
+			notes  = memory.NewRepository[Note, NoteID](m)
+			groups = memory.NewRepository[Group, GroupID](m)
+		)
+		return relationship.Repository[User, UserID]{
+				Source: users,
+				Dependents: []relationship.Dependent{
+					relationship.Referrer[Note, NoteID]{Repository: notes, Policy: policy, Field: "OwnerID"},
+					relationship.Referrer[Group, GroupID]{Repository: groups, Policy: policy},
+				},
+			},
+			relationship.Repository[Note, NoteID]{
+				Source:  notes,
+				Targets: []relationship.Target{relationship.Referenced[User, UserID]{Repository: users}},
+			},
+			relationship.Repository[Group, GroupID]{
+				Source:  groups,
+				Targets: []relationship.Target{relationship.Referenced[User, UserID]{Repository: users}},
+			}
+	}
+
+	var (
+		noteConf = relationshipcontract.Config[User, Note]{MakeEntity: makeUser, MakeReferrer: makeNote}
+		grpConf  = relationshipcontract.Config[User, Group]{MakeEntity: makeUser, MakeReferrer: makeGroup}
+	)
+
+	restrictUsers, restrictNotes, restrictGroups := subjects(relationship.Restrict)
+	cascadeUsers, cascadeNotes, cascadeGroups := subjects(relationship.Cascade)
+	setNullUsers, setNullNotes, setNullGroups := subjects(relationship.SetNull)
+
+	testcase.RunSuite(t,
+		relationshipcontract.Restrict[User, UserID, Note, NoteID](restrictUsers, restrictNotes, noteConf),
+		relationshipcontract.Restrict[User, UserID, Group, GroupID](restrictUsers, restrictGroups, grpConf),
+		relationshipcontract.Cascade[User, UserID, Note, NoteID](cascadeUsers, cascadeNotes, noteConf),
+		relationshipcontract.Cascade[User, UserID, Group, GroupID](cascadeUsers, cascadeGroups, grpConf),
+		relationshipcontract.SetNull[User, UserID, Note, NoteID](setNullUsers, setNullNotes, noteConf),
+		relationshipcontract.SetNull[User, UserID, Group, GroupID](setNullUsers, setNullGroups, grpConf),
+		relationshipcontract.References[Note, NoteID, User, UserID](restrictNotes, restrictUsers, noteConf),
+		relationshipcontract.References[Group, GroupID, User, UserID](restrictGroups, restrictUsers, grpConf),
+	)
+}
+
+func TestRepository_restrictIsCheckedBeforeCascade(t *testing.T) {
+	type (
+		User   = IntegrityUser
+		UserID = IntegrityUserID
+		Note   = IntegrityNote
+		NoteID = IntegrityNoteID
+	)
+
+	var (
+		ctx    = context.Background()
+		m      = memory.NewMemory()
+		notes  = memory.NewRepository[Note, NoteID](m)
+		groups = memory.NewRepository[IntegrityGroup, IntegrityGroupID](m)
+		users  = relationship.Repository[User, UserID]{
+			Source: memory.NewRepository[User, UserID](m),
+			Dependents: []relationship.Dependent{
+				relationship.Referrer[Note, NoteID]{Repository: notes, Policy: relationship.Cascade},
+				relationship.Referrer[IntegrityGroup, IntegrityGroupID]{Repository: groups, Policy: relationship.Restrict},
+			},
+		}
+	)
+
+	usr := User{Name: "usr"}
+	assert.NoError(t, users.Create(ctx, &usr))
+	note := Note{OwnerID: usr.ID}
+	assert.NoError(t, notes.Create(ctx, &note))
+	grp := IntegrityGroup{Members: []UserID{usr.ID}}
+	assert.NoError(t, groups.Create(ctx, &grp))
+
+	assert.ErrorIs(t, users.DeleteByID(ctx, usr.ID), relationship.ErrRestricted)
+
+	_, found, err := notes.FindByID(ctx, note.ID)
+	assert.NoError(t, err)
+	assert.True(t, found, "the note should have been kept, as the user is not deleted")
+}
+
+// queryOnlyRepository fails on FindAll, so only queries can find its entities.
+type queryOnlyRepository[ENT, ID any] struct {
+	*memory.Repository[ENT, ID]
+}
+
+func (r queryOnlyRepository[ENT, ID]) FindAll(ctx context.Context) iter.Seq2[ENT, error] {
+	return iterkit.Error[ENT](errors.New("FindAll is not expected"))
+}
+
+func TestReferrer_Field(t *testing.T) {
+	type (
+		User   = IntegrityUser
+		UserID = IntegrityUserID
+		Note   = IntegrityNote
+		NoteID = IntegrityNoteID
+	)
+
+	var (
+		ctx   = context.Background()
+		m     = memory.NewMemory()
+		notes = queryOnlyRepository[Note, NoteID]{Repository: memory.NewRepository[Note, NoteID](m)}
+		users = func(policy relationship.Policy) relationship.Repository[User, UserID] {
+			return relationship.Repository[User, UserID]{
+				Source: memory.NewRepository[User, UserID](m),
+				Dependents: []relationship.Dependent{
+					relationship.Referrer[Note, NoteID]{Repository: notes, Policy: policy, Field: "OwnerID"},
+				},
+			}
+		}
+		setup = func(t *testing.T) (User, Note, Note) {
+			usr := User{Name: "usr"}
+			assert.NoError(t, users(relationship.Restrict).Create(ctx, &usr))
+			note := Note{OwnerID: usr.ID}
+			assert.NoError(t, notes.Create(ctx, &note))
+			other := Note{OwnerID: "other"}
+			assert.NoError(t, notes.Create(ctx, &other))
+			return usr, note, other
+		}
+	)
+
+	t.Run("Restrict", func(t *testing.T) {
+		usr, _, _ := setup(t)
+		assert.ErrorIs(t, users(relationship.Restrict).DeleteByID(ctx, usr.ID), relationship.ErrRestricted)
+	})
+
+	t.Run("Cascade", func(t *testing.T) {
+		usr, note, other := setup(t)
+		assert.NoError(t, users(relationship.Cascade).DeleteByID(ctx, usr.ID))
+		_, found, err := notes.FindByID(ctx, note.ID)
+		assert.NoError(t, err)
+		assert.False(t, found)
+		_, found, err = notes.FindByID(ctx, other.ID)
+		assert.NoError(t, err)
+		assert.True(t, found)
+	})
+
+	t.Run("SetNull", func(t *testing.T) {
+		usr, note, other := setup(t)
+		assert.NoError(t, users(relationship.SetNull).DeleteByID(ctx, usr.ID))
+		got, found, err := notes.FindByID(ctx, note.ID)
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Empty(t, got.OwnerID)
+		got, found, err = notes.FindByID(ctx, other.ID)
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, other, got)
+	})
+}
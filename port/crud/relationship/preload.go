@@ -0,0 +1,259 @@
+package relationship
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.llib.dev/frameless/pkg/errorkit"
+	"go.llib.dev/frameless/pkg/reflectkit"
+	"go.llib.dev/frameless/pkg/slicekit"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/extid"
+)
+
+const (
+	// ErrMissingReference is returned when an entity references a related entity which doesn't exist.
+	ErrMissingReference errorkit.Error = "ErrMissingReference"
+	// ErrCycle is returned when preloading would get back to an entity type which is already being preloaded.
+	ErrCycle errorkit.Error = "ErrCycle"
+)
+
+// DefaultPreloadBatchSize is the maximum number of IDs in a single FindByIDs call,
+// if the Preloader.BatchSize is not provided.
+var DefaultPreloadBatchSize = 100
+
+// Preloader loads the related entities through the registered relationships, see BelongsTo and ReferencesMany.
+//
+// Instead of looking up the related entities one by one for each entity (N+1),
+// the Preloader collects the referenced IDs of every entity, and loads them with batched FindByIDs calls.
+// The loaded entities are preloaded as well, so a whole chain of relationships can be loaded in one go.
+type Preloader struct {
+	// BatchSize [optional] limits the number of IDs in a single FindByIDs call.
+	//
+	// default: DefaultPreloadBatchSize
+	BatchSize int
+
+	finders map[reflect.Type]preloadFinder
+}
+
+type preloadFinder func(ctx context.Context, ids []reflect.Value) (map[any]reflect.Value, error)
+
+// WithFinder registers the repository, from which the Preloader loads the ENT entities.
+// Only the relationships that point to an entity type with a registered finder are preloaded.
+func WithFinder[ENT any, ID comparable](p *Preloader, finder crud.ByIDsFinder[ENT, ID]) {
+	if p.finders == nil {
+		p.finders = make(map[reflect.Type]preloadFinder)
+	}
+	p.finders[reflectkit.TypeOf[ENT]()] = func(ctx context.Context, rIDs []reflect.Value) (map[any]reflect.Value, error) {
+		ids := slicekit.Map(rIDs, func(id reflect.Value) ID { return id.Interface().(ID) })
+		var ents = make(map[any]reflect.Value)
+		for ent, err := range finder.FindByIDs(ctx, ids...) {
+			if err != nil {
+				return nil, err
+			}
+			id, ok := extid.Lookup[ID](ent)
+			if !ok {
+				return nil, fmt.Errorf("%T has no ID", ent)
+			}
+			ents[id] = reflect.ValueOf(ent)
+		}
+		return ents, nil
+	}
+}
+
+// Preloaded holds the entities that Preload loaded.
+type Preloaded struct {
+	entities map[reflect.Type]map[any]reflect.Value
+}
+
+// Preload loads the related entities of the given entities, and the related entities of those, and so on.
+// A reference to an absent entity yields ErrMissingReference,
+// and a chain of relationships that leads back to an entity type which is already on the chain yields ErrCycle.
+func Preload[ENT any](ctx context.Context, p Preloader, ents []ENT) (Preloaded, error) {
+	pl := Preloaded{entities: make(map[reflect.Type]map[any]reflect.Value)}
+	typ := reflectkit.TypeOf[ENT]()
+	vs := slicekit.Map(ents, func(v ENT) reflect.Value { return reflect.ValueOf(v) })
+	if err := p.preload(ctx, &pl, typ, vs, []reflect.Type{typ}); err != nil {
+		return Preloaded{}, err
+	}
+	return pl, nil
+}
+
+func (p Preloader) preload(ctx context.Context, pl *Preloaded, typ reflect.Type, ents []reflect.Value, path []reflect.Type) error {
+	if len(ents) == 0 {
+		return nil
+	}
+	for related, find := range p.finders {
+		r, ok := lookupReference(typ, related)
+		if !ok {
+			continue
+		}
+		var (
+			ids  []reflect.Value
+			seen = make(map[any]struct{})
+		)
+		for _, ent := range ents {
+			for _, id := range r.IDs(ent) {
+				key := id.Interface()
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+				if _, ok := pl.entities[related][key]; ok {
+					continue
+				}
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		for _, t := range path {
+			if t == related {
+				return ErrCycle.F("%s", strings.Join(slicekit.Map(append(path, related), reflect.Type.String), " -> "))
+			}
+		}
+		loaded, err := p.load(ctx, typ, related, find, ids)
+		if err != nil {
+			return err
+		}
+		if pl.entities[related] == nil {
+			pl.entities[related] = make(map[any]reflect.Value)
+		}
+		var next []reflect.Value
+		for id, ent := range loaded {
+			pl.entities[related][id] = ent
+			next = append(next, ent)
+		}
+		if err := p.preload(ctx, pl, related, next, append(path[:len(path):len(path)], related)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p Preloader) load(ctx context.Context, typ, related reflect.Type, find preloadFinder, ids []reflect.Value) (map[any]reflect.Value, error) {
+	var loaded = make(map[any]reflect.Value, len(ids))
+	for _, batch := range slicekit.Batch(ids, p.getBatchSize()) {
+		ents, err := find(ctx, batch)
+		if errors.Is(err, crud.ErrNotFound) {
+			return nil, ErrMissingReference.Wrap(fmt.Errorf("%s references absent %s entities: %w", typ, related, err))
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range batch {
+			ent, ok := ents[id.Interface()]
+			if !ok {
+				return nil, ErrMissingReference.F("%s references an absent %s with id: %v", typ, related, id.Interface())
+			}
+			loaded[id.Interface()] = ent
+		}
+	}
+	return loaded, nil
+}
+
+func (p Preloader) getBatchSize() int {
+	if 0 < p.BatchSize {
+		return p.BatchSize
+	}
+	return DefaultPreloadBatchSize
+}
+
+// Associated returns the preloaded B entities, that the given entity references.
+// The entity can be any of the entities that Preload received or loaded.
+func Associated[B any](pl Preloaded, ent any) []B {
+	var (
+		v       = reflectkit.BaseValueOf(ent)
+		related = reflectkit.TypeOf[B]()
+	)
+	r, ok := lookupReference(v.Type(), related)
+	if !ok {
+		return nil
+	}
+	var bs []B
+	for _, id := range r.IDs(v) {
+		if b, ok := pl.entities[related][id.Interface()]; ok {
+			bs = append(bs, b.Interface().(B))
+		}
+	}
+	return bs
+}
+
+// reference is a relationship, where an entity refers to the related entities by their IDs.
+type reference struct {
+	belongsTo      accessor
+	referencesMany accessor
+}
+
+// lookupReference finds the relationship in which the typ entities hold the IDs of the related entities.
+// Self references are not followed, as by convention an entity's own ID field would count as one.
+func lookupReference(typ, related reflect.Type) (reference, bool) {
+	if typ == related || typ.Kind() != reflect.Struct || related.Kind() != reflect.Struct {
+		return reference{}, false
+	}
+	var r reference
+	// BelongsTo[Who, ToWhom] is registered under {A: ToWhom, B: Who}
+	r.belongsTo = getRecord(typeMatch{A: related, B: typ}).BelongsTo
+	r.referencesMany = getRecord(typeMatch{A: typ, B: related}).ReferencesMany
+	return r, r.belongsTo != nil || r.referencesMany != nil
+}
+
+// IDs returns the non-zero IDs that the entity refers to.
+func (r reference) IDs(ent reflect.Value) []reflect.Value {
+	var ids []reflect.Value
+	if r.belongsTo != nil {
+		if id, ok := r.belongsTo.ReflectLookup(ent); ok && !id.IsZero() {
+			ids = append(ids, id)
+		}
+	}
+	if r.referencesMany != nil {
+		if refs, ok := r.referencesMany.ReflectLookup(ent); ok && refs.Kind() == reflect.Slice {
+			for i := 0; i < refs.Len(); i++ {
+				if id := refs.Index(i); !id.IsZero() {
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// Has tells if the entity refers to the id.
+func (r reference) Has(ent reflect.Value, id any) bool {
+	for _, got := range r.IDs(ent) {
+		if reflectkit.Equal(got.Interface(), id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unset removes the references to the id from the entity that ptr points to.
+func (r reference) Unset(ptr reflect.Value, id any) error {
+	ent := ptr.Elem()
+	if r.belongsTo != nil {
+		if got, ok := r.belongsTo.ReflectLookup(ent); ok && reflectkit.Equal(got.Interface(), id) {
+			if err := r.belongsTo.ReflectSet(ptr, reflect.Zero(got.Type())); err != nil {
+				return err
+			}
+		}
+	}
+	if r.referencesMany != nil {
+		if refs, ok := r.referencesMany.ReflectLookup(ent); ok && refs.Kind() == reflect.Slice {
+			kept := reflect.MakeSlice(refs.Type(), 0, refs.Len())
+			for i := 0; i < refs.Len(); i++ {
+				if !reflectkit.Equal(refs.Index(i).Interface(), id) {
+					kept = reflect.Append(kept, refs.Index(i))
+				}
+			}
+			if err := r.referencesMany.ReflectSet(ptr, kept); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
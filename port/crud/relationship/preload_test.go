@@ -0,0 +1,221 @@
+package relationship_test
+
+import (
+	"context"
+	"iter"
+	"testing"
+
+	"go.llib.dev/frameless/adapter/memory"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/relationship"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+)
+
+func ExamplePreload() {
+	type UserID string
+	type User struct {
+		ID UserID
+	}
+
+	type NoteID string
+	type Note struct {
+		ID      NoteID
+		OwnerID UserID
+	}
+
+	var (
+		ctx   = context.Background()
+		m     = memory.NewMemory()
+		users = memory.NewRepository[User, UserID](m)
+	)
+
+	var p relationship.Preloader
+	relationship.WithFinder[User, UserID](&p, users)
+
+	var notes []Note // e.g.: from notes.FindAll(ctx)
+	preloaded, err := relationship.Preload(ctx, p, notes)
+	if err != nil {
+		return
+	}
+
+	for _, note := range notes {
+		_ = relationship.Associated[User](preloaded, note) // the owner of the note
+	}
+}
+
+type spyByIDsFinder[ENT, ID any] struct {
+	crud.ByIDsFinder[ENT, ID]
+	Calls [][]ID
+}
+
+func (spy *spyByIDsFinder[ENT, ID]) FindByIDs(ctx context.Context, ids ...ID) iter.Seq2[ENT, error] {
+	spy.Calls = append(spy.Calls, ids)
+	return spy.ByIDsFinder.FindByIDs(ctx, ids...)
+}
+
+func TestPreload(t *testing.T) {
+	s := testcase.NewSpec(t)
+
+	type AuthorID string
+	type Author struct {
+		ID   AuthorID
+		Name string
+	}
+
+	type TagID string
+	type Tag struct {
+		ID   TagID
+		Name string
+	}
+
+	type BookID string
+	type Book struct {
+		ID       BookID
+		AuthorID AuthorID
+		TagIDs   []TagID
+	}
+
+	type ReviewID string
+	type Review struct {
+		ID     ReviewID
+		BookID BookID
+	}
+
+	var (
+		m       = testcase.Let(s, func(t *testcase.T) *memory.Memory { return memory.NewMemory() })
+		authors = testcase.Let(s, func(t *testcase.T) *spyByIDsFinder[Author, AuthorID] {
+			return &spyByIDsFinder[Author, AuthorID]{ByIDsFinder: memory.NewRepository[Author, AuthorID](m.Get(t))}
+		})
+		tags = testcase.Let(s, func(t *testcase.T) *spyByIDsFinder[Tag, TagID] {
+			return &spyByIDsFinder[Tag, TagID]{ByIDsFinder: memory.NewRepository[Tag, TagID](m.Get(t))}
+		})
+		books = testcase.Let(s, func(t *testcase.T) *memory.Repository[Book, BookID] {
+			return memory.NewRepository[Book, BookID](m.Get(t))
+		})
+	)
+
+	createAuthor := func(t *testcase.T) Author {
+		v := Author{Name: t.Random.String()}
+		assert.NoError(t, memory.NewRepository[Author, AuthorID](m.Get(t)).Create(t.Context(), &v))
+		return v
+	}
+	createTag := func(t *testcase.T) Tag {
+		v := Tag{Name: t.Random.String()}
+		assert.NoError(t, memory.NewRepository[Tag, TagID](m.Get(t)).Create(t.Context(), &v))
+		return v
+	}
+
+	preloader := testcase.Let(s, func(t *testcase.T) relationship.Preloader {
+		var p relationship.Preloader
+		relationship.WithFinder[Author, AuthorID](&p, authors.Get(t))
+		relationship.WithFinder[Tag, TagID](&p, tags.Get(t))
+		return p
+	})
+
+	s.Test("BelongsTo relationships are loaded with a single FindByIDs call", func(t *testcase.T) {
+		a1, a2 := createAuthor(t), createAuthor(t)
+		bs := []Book{
+			{ID: "1", AuthorID: a1.ID},
+			{ID: "2", AuthorID: a2.ID},
+			{ID: "3", AuthorID: a1.ID},
+		}
+
+		pl, err := relationship.Preload(t.Context(), preloader.Get(t), bs)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []Author{a1}, relationship.Associated[Author](pl, bs[0]))
+		assert.Equal(t, []Author{a2}, relationship.Associated[Author](pl, bs[1]))
+		assert.Equal(t, []Author{a1}, relationship.Associated[Author](pl, bs[2]))
+		assert.Equal(t, 1, len(authors.Get(t).Calls))
+		assert.ContainsExactly(t, []AuthorID{a1.ID, a2.ID}, authors.Get(t).Calls[0])
+	})
+
+	s.Test("ReferencesMany relationships are loaded in the order of the references", func(t *testcase.T) {
+		t1, t2, t3 := createTag(t), createTag(t), createTag(t)
+		bs := []Book{
+			{ID: "1", TagIDs: []TagID{t2.ID, t1.ID}},
+			{ID: "2", TagIDs: []TagID{t3.ID}},
+			{ID: "3"},
+		}
+
+		pl, err := relationship.Preload(t.Context(), preloader.Get(t), bs)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []Tag{t2, t1}, relationship.Associated[Tag](pl, bs[0]))
+		assert.Equal(t, []Tag{t3}, relationship.Associated[Tag](pl, bs[1]))
+		assert.Empty(t, relationship.Associated[Tag](pl, bs[2]))
+		assert.Empty(t, relationship.Associated[Author](pl, bs[2]))
+		assert.Equal(t, 1, len(tags.Get(t).Calls))
+	})
+
+	s.Test("the FindByIDs calls are batched by the BatchSize", func(t *testcase.T) {
+		var bs []Book
+		for i := 0; i < 5; i++ {
+			bs = append(bs, Book{ID: BookID(t.Random.UUID()), AuthorID: createAuthor(t).ID})
+		}
+
+		p := preloader.Get(t)
+		p.BatchSize = 2
+		pl, err := relationship.Preload(t.Context(), p, bs)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 3, len(authors.Get(t).Calls))
+		for _, b := range bs {
+			assert.Equal(t, 1, len(relationship.Associated[Author](pl, b)))
+		}
+	})
+
+	s.Test("the relationships of the loaded entities are preloaded as well", func(t *testcase.T) {
+		a := createAuthor(t)
+		b := Book{AuthorID: a.ID}
+		assert.NoError(t, books.Get(t).Create(t.Context(), &b))
+		rs := []Review{{ID: "1", BookID: b.ID}}
+
+		var p = preloader.Get(t)
+		relationship.WithFinder[Book, BookID](&p, books.Get(t))
+
+		pl, err := relationship.Preload(t.Context(), p, rs)
+		assert.NoError(t, err)
+
+		gotBooks := relationship.Associated[Book](pl, rs[0])
+		assert.Equal(t, []Book{b}, gotBooks)
+		assert.Equal(t, []Author{a}, relationship.Associated[Author](pl, gotBooks[0]))
+	})
+
+	s.Test("a reference to an absent entity is reported", func(t *testcase.T) {
+		bs := []Book{{ID: "1", AuthorID: createAuthor(t).ID}, {ID: "2", AuthorID: "absent"}}
+
+		_, err := relationship.Preload(t.Context(), preloader.Get(t), bs)
+		assert.ErrorIs(t, relationship.ErrMissingReference, err)
+		assert.ErrorIs(t, crud.ErrNotFound, err)
+	})
+
+	s.Test("a relationship cycle is reported", func(t *testcase.T) {
+		type XID string
+		type YID string
+		type X struct {
+			ID    XID
+			RefID YID
+		}
+		type Y struct {
+			ID    YID
+			RefID XID
+		}
+
+		xs := memory.NewRepository[X, XID](m.Get(t))
+		ys := memory.NewRepository[Y, YID](m.Get(t))
+		x := X{ID: "x"}
+		y := Y{ID: "y", RefID: x.ID}
+		x.RefID = y.ID
+		assert.NoError(t, xs.Create(t.Context(), &x))
+		assert.NoError(t, ys.Create(t.Context(), &y))
+
+		var p relationship.Preloader
+		relationship.WithFinder[X, XID](&p, xs)
+		relationship.WithFinder[Y, YID](&p, ys)
+
+		_, err := relationship.Preload(t.Context(), p, []X{x})
+		assert.ErrorIs(t, relationship.ErrCycle, err)
+	})
+}
@@ -0,0 +1,229 @@
+package relationshipcontract
+
+import (
+	"context"
+	"testing"
+
+	"go.llib.dev/frameless/internal/spechelper"
+	"go.llib.dev/frameless/pkg/reflectkit"
+	"go.llib.dev/frameless/port/contract"
+	"go.llib.dev/frameless/port/crud"
+	"go.llib.dev/frameless/port/crud/crudtest"
+	"go.llib.dev/frameless/port/crud/extid"
+	"go.llib.dev/frameless/port/crud/relationship"
+	"go.llib.dev/frameless/port/option"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/let"
+)
+
+type Option[ENT, REF any] option.Option[Config[ENT, REF]]
+
+// Config is the configuration of the contracts,
+// where REF is the entity type that refers to the ENT entity type.
+type Config[ENT, REF any] struct {
+	MakeContext func(testing.TB) context.Context
+	MakeEntity  func(testing.TB) ENT
+	// MakeReferrer makes a REF entity, which doesn't refer to any ENT entity yet.
+	MakeReferrer func(testing.TB) REF
+}
+
+func (c *Config[ENT, REF]) Init() {
+	c.MakeContext = func(testing.TB) context.Context {
+		return context.Background()
+	}
+	c.MakeEntity = spechelper.MakeValue[ENT]
+	c.MakeReferrer = spechelper.MakeValue[REF]
+}
+
+func (c Config[ENT, REF]) Configure(t *Config[ENT, REF]) {
+	*t = reflectkit.MergeStruct(*t, c)
+}
+
+// Subject is the repository of a contract.
+type Subject[ENT, ID any] interface {
+	crud.Creator[ENT]
+	crud.ByIDFinder[ENT, ID]
+	crud.Updater[ENT]
+	crud.ByIDDeleter[ID]
+	crud.AllDeleter
+}
+
+// Restrict verifies that the subject refuses to delete an ENT while a REF in the referrers refers to it.
+func Restrict[ENT, ID, REF, REFID any](subject Subject[ENT, ID], referrers Subject[REF, REFID], opts ...Option[ENT, REF]) contract.Contract {
+	c := option.ToConfig[Config[ENT, REF]](opts)
+	s := testcase.NewSpec(nil)
+
+	var (
+		ctx = let.Var(s, func(t *testcase.T) context.Context { return c.MakeContext(t) })
+		ent = let.Var(s, func(t *testcase.T) *ENT { return create[ENT, ID](t, subject, ctx.Get(t), c.MakeEntity(t)) })
+		ref = let.Var(s, func(t *testcase.T) *REF {
+			return createReferrer[ENT, REF, REFID](t, c, referrers, ctx.Get(t), ent.Get(t))
+		}).EagerLoading(s)
+	)
+
+	s.Test("DeleteByID is refused while the entity is referred", func(t *testcase.T) {
+		err := subject.DeleteByID(ctx.Get(t), idOf[ID](t, ent.Get(t)))
+		assert.ErrorIs(t, err, relationship.ErrRestricted)
+
+		crudtest.IsPresent(t, subject, ctx.Get(t), idOf[ID](t, ent.Get(t)))
+		crudtest.IsPresent(t, referrers, ctx.Get(t), idOf[REFID](t, ref.Get(t)))
+	})
+
+	s.Test("DeleteAll is refused while an entity is referred", func(t *testcase.T) {
+		assert.ErrorIs(t, subject.DeleteAll(ctx.Get(t)), relationship.ErrRestricted)
+
+		crudtest.IsPresent(t, subject, ctx.Get(t), idOf[ID](t, ent.Get(t)))
+	})
+
+	s.Test("DeleteByID succeeds once the referrers are gone", func(t *testcase.T) {
+		assert.NoError(t, referrers.DeleteByID(ctx.Get(t), idOf[REFID](t, ref.Get(t))))
+		assert.NoError(t, subject.DeleteByID(ctx.Get(t), idOf[ID](t, ent.Get(t))))
+
+		crudtest.IsAbsent(t, subject, ctx.Get(t), idOf[ID](t, ent.Get(t)))
+	})
+
+	s.Test("DeleteByID doesn't mind the referrers of other entities", func(t *testcase.T) {
+		other := create[ENT, ID](t, subject, ctx.Get(t), c.MakeEntity(t))
+		assert.NoError(t, subject.DeleteByID(ctx.Get(t), idOf[ID](t, other)))
+
+		crudtest.IsAbsent(t, subject, ctx.Get(t), idOf[ID](t, other))
+	})
+
+	return s.AsSuite("relationship.Restrict")
+}
+
+// Cascade verifies that the subject deletes the REF entities in the referrers, together with the ENT they refer to.
+func Cascade[ENT, ID, REF, REFID any](subject Subject[ENT, ID], referrers Subject[REF, REFID], opts ...Option[ENT, REF]) contract.Contract {
+	c := option.ToConfig[Config[ENT, REF]](opts)
+	s := testcase.NewSpec(nil)
+
+	var (
+		ctx = let.Var(s, func(t *testcase.T) context.Context { return c.MakeContext(t) })
+		ent = let.Var(s, func(t *testcase.T) *ENT { return create[ENT, ID](t, subject, ctx.Get(t), c.MakeEntity(t)) })
+		ref = let.Var(s, func(t *testcase.T) *REF {
+			return createReferrer[ENT, REF, REFID](t, c, referrers, ctx.Get(t), ent.Get(t))
+		}).EagerLoading(s)
+	)
+
+	s.Test("DeleteByID deletes the referrers of the entity", func(t *testcase.T) {
+		other := create[ENT, ID](t, subject, ctx.Get(t), c.MakeEntity(t))
+		otherRef := createReferrer[ENT, REF, REFID](t, c, referrers, ctx.Get(t), other)
+
+		assert.NoError(t, subject.DeleteByID(ctx.Get(t), idOf[ID](t, ent.Get(t))))
+
+		crudtest.IsAbsent(t, subject, ctx.Get(t), idOf[ID](t, ent.Get(t)))
+		crudtest.IsAbsent(t, referrers, ctx.Get(t), idOf[REFID](t, ref.Get(t)))
+		crudtest.IsPresent(t, referrers, ctx.Get(t), idOf[REFID](t, otherRef))
+	})
+
+	s.Test("DeleteAll deletes the referrers of every entity", func(t *testcase.T) {
+		other := create[ENT, ID](t, subject, ctx.Get(t), c.MakeEntity(t))
+		otherRef := createReferrer[ENT, REF, REFID](t, c, referrers, ctx.Get(t), other)
+
+		assert.NoError(t, subject.DeleteAll(ctx.Get(t)))
+
+		crudtest.IsAbsent(t, referrers, ctx.Get(t), idOf[REFID](t, ref.Get(t)))
+		crudtest.IsAbsent(t, referrers, ctx.Get(t), idOf[REFID](t, otherRef))
+	})
+
+	return s.AsSuite("relationship.Cascade")
+}
+
+// SetNull verifies that the subject removes the reference from the REF entities in the referrers,
+// when the ENT they refer to is deleted.
+func SetNull[ENT, ID, REF, REFID any](subject Subject[ENT, ID], referrers Subject[REF, REFID], opts ...Option[ENT, REF]) contract.Contract {
+	c := option.ToConfig[Config[ENT, REF]](opts)
+	s := testcase.NewSpec(nil)
+
+	var (
+		ctx = let.Var(s, func(t *testcase.T) context.Context { return c.MakeContext(t) })
+		ent = let.Var(s, func(t *testcase.T) *ENT { return create[ENT, ID](t, subject, ctx.Get(t), c.MakeEntity(t)) })
+		ref = let.Var(s, func(t *testcase.T) *REF {
+			return createReferrer[ENT, REF, REFID](t, c, referrers, ctx.Get(t), ent.Get(t))
+		}).EagerLoading(s)
+	)
+
+	s.Test("DeleteByID keeps the referrers, but without the reference", func(t *testcase.T) {
+		assert.NoError(t, subject.DeleteByID(ctx.Get(t), idOf[ID](t, ent.Get(t))))
+
+		crudtest.IsAbsent(t, subject, ctx.Get(t), idOf[ID](t, ent.Get(t)))
+		got := crudtest.IsPresent(t, referrers, ctx.Get(t), idOf[REFID](t, ref.Get(t)))
+		assert.False(t, relationship.Related(*ent.Get(t), *got))
+	})
+
+	s.Test("DeleteAll keeps the referrers, but without the reference", func(t *testcase.T) {
+		assert.NoError(t, subject.DeleteAll(ctx.Get(t)))
+
+		got := crudtest.IsPresent(t, referrers, ctx.Get(t), idOf[REFID](t, ref.Get(t)))
+		assert.False(t, relationship.Related(*ent.Get(t), *got))
+	})
+
+	return s.AsSuite("relationship.SetNull")
+}
+
+// References verifies that the subject refuses the REF entities, which refer to an ENT that is absent from the referenced.
+func References[REF, REFID, ENT, ID any](subject Subject[REF, REFID], referenced Subject[ENT, ID], opts ...Option[ENT, REF]) contract.Contract {
+	c := option.ToConfig[Config[ENT, REF]](opts)
+	s := testcase.NewSpec(nil)
+
+	var (
+		ctx = let.Var(s, func(t *testcase.T) context.Context { return c.MakeContext(t) })
+		ent = let.Var(s, func(t *testcase.T) *ENT { return create[ENT, ID](t, referenced, ctx.Get(t), c.MakeEntity(t)) })
+		// absent is an entity with an ID, which is not present in the referenced.
+		absent = let.Var(s, func(t *testcase.T) *ENT {
+			ptr := create[ENT, ID](t, referenced, ctx.Get(t), c.MakeEntity(t))
+			assert.NoError(t, referenced.DeleteByID(ctx.Get(t), idOf[ID](t, ptr)))
+			return ptr
+		})
+	)
+
+	s.Test("Create accepts a reference to a present entity", func(t *testcase.T) {
+		ref := createReferrer[ENT, REF, REFID](t, c, subject, ctx.Get(t), ent.Get(t))
+		crudtest.IsPresent(t, subject, ctx.Get(t), idOf[REFID](t, ref))
+	})
+
+	s.Test("Create accepts an entity without a reference", func(t *testcase.T) {
+		ref := c.MakeReferrer(t)
+		assert.NoError(t, subject.Create(ctx.Get(t), &ref))
+		t.Defer(subject.DeleteByID, ctx.Get(t), idOf[REFID](t, &ref))
+	})
+
+	s.Test("Create refuses a reference to an absent entity", func(t *testcase.T) {
+		ref := c.MakeReferrer(t)
+		assert.NoError(t, relationship.Associate(absent.Get(t), &ref))
+		assert.ErrorIs(t, subject.Create(ctx.Get(t), &ref), relationship.ErrMissingReference)
+	})
+
+	s.Test("Update refuses a reference to an absent entity", func(t *testcase.T) {
+		ref := createReferrer[ENT, REF, REFID](t, c, subject, ctx.Get(t), ent.Get(t))
+		update := *ref
+		assert.NoError(t, relationship.Associate(absent.Get(t), &update))
+		assert.ErrorIs(t, subject.Update(ctx.Get(t), &update), relationship.ErrMissingReference)
+
+		got := crudtest.IsPresent(t, subject, ctx.Get(t), idOf[REFID](t, ref))
+		assert.True(t, relationship.Related(*ent.Get(t), *got))
+	})
+
+	return s.AsSuite("relationship.References")
+}
+
+func create[ENT, ID any](t *testcase.T, subject Subject[ENT, ID], ctx context.Context, ent ENT) *ENT {
+	assert.NoError(t, subject.Create(ctx, &ent))
+	id := idOf[ID](t, &ent)
+	t.Defer(func() { _ = subject.DeleteByID(ctx, id) })
+	return &ent
+}
+
+// createReferrer creates a REF, which refers to the ENT.
+func createReferrer[ENT, REF, REFID any](t *testcase.T, c Config[ENT, REF], subject Subject[REF, REFID], ctx context.Context, ent *ENT) *REF {
+	ref := c.MakeReferrer(t)
+	assert.NoError(t, relationship.Associate(ent, &ref))
+	return create[REF, REFID](t, subject, ctx, ref)
+}
+
+func idOf[ID, ENT any](tb testing.TB, ptr *ENT) ID {
+	id, ok := extid.Lookup[ID](*ptr)
+	assert.True(tb, ok, "ID was expected to be present on the entity")
+	return id
+}
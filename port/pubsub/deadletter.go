@@ -0,0 +1,21 @@
+package pubsub
+
+import (
+	"context"
+	"iter"
+)
+
+// Inspector is an optional interface for a queue.
+// It lists the messages of the queue without consuming them,
+// which is useful to look into a dead-letter queue.
+type Inspector[Data any] interface {
+	Inspect(context.Context) iter.Seq2[Data, error]
+}
+
+// Requeuer is an optional interface for a queue.
+// It moves every message of the queue to the Publisher,
+// which is useful to redrive the messages of a dead-letter queue back to their source queue,
+// once the cause of their failure is fixed.
+type Requeuer[Data any] interface {
+	Requeue(ctx context.Context, to Publisher[Data]) error
+}
@@ -0,0 +1,34 @@
+package pubsub
+
+import (
+	"context"
+	"time"
+
+	"go.llib.dev/frameless/pkg/contextkit"
+	"go.llib.dev/testcase/clock"
+)
+
+type ctxKeyNotBefore struct{}
+
+var ctxNotBefore contextkit.ValueHandler[ctxKeyNotBefore, time.Time]
+
+// ContextWithNotBefore is a publish option, which delays the delivery of the messages published with the returned context.
+// A message is not delivered to a subscriber before the notBefore time,
+// but after it, it is delivered as any other message of the queue.
+//
+// Publishers that don't support delayed delivery ignore the option and deliver the message right away.
+func ContextWithNotBefore(ctx context.Context, notBefore time.Time) context.Context {
+	return ctxNotBefore.ContextWith(ctx, notBefore)
+}
+
+// ContextWithDelay is a publish option, which delays the delivery of the messages published with the returned context
+// by the given time.Duration, counted from the moment ContextWithDelay is called.
+// For the details, see ContextWithNotBefore.
+func ContextWithDelay(ctx context.Context, delay time.Duration) context.Context {
+	return ContextWithNotBefore(ctx, clock.Now().Add(delay))
+}
+
+// NotBefore returns the not-before time of a delayed publish, when the context has one.
+func NotBefore(ctx context.Context) (time.Time, bool) {
+	return ctxNotBefore.Lookup(ctx)
+}
@@ -0,0 +1,92 @@
+package pubsub
+
+import "strings"
+
+// Envelope is a message published to an exchange.
+// The exchange uses the RoutingKey and the Headers to decide which bound queues receive the Data.
+type Envelope[Data any] struct {
+	// RoutingKey is the topic of the message, a string of dot-separated words, such as "order.eu.created".
+	RoutingKey string
+	// Headers are the key-value pairs that a headers exchange matches against its bindings.
+	Headers map[string]string
+	// Data is the message that the matching queues receive.
+	Data Data
+}
+
+// MatchTopic reports whether a topic exchange routing key matches a binding pattern.
+//
+// Both the pattern and the routing key are dot-separated words.
+// In the pattern, "*" matches exactly one word, and "#" matches zero or more words.
+// Any other word in the pattern must be equal to the word of the routing key at the same position.
+func MatchTopic(pattern, routingKey string) bool {
+	return matchTopicWords(strings.Split(pattern, "."), strings.Split(routingKey, "."))
+}
+
+func matchTopicWords(pattern, key []string) bool {
+	for len(pattern) > 0 {
+		word := pattern[0]
+		pattern = pattern[1:]
+		switch word {
+		case "#":
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(key); i++ {
+				if matchTopicWords(pattern, key[i:]) {
+					return true
+				}
+			}
+			return false
+		case "*":
+			if len(key) == 0 {
+				return false
+			}
+		default:
+			if len(key) == 0 || key[0] != word {
+				return false
+			}
+		}
+		key = key[1:]
+	}
+	return len(key) == 0
+}
+
+// HeadersMatch is the matching rule of a HeadersBinding.
+type HeadersMatch string
+
+const (
+	// MatchAll requires every header of the binding to be present in the message with the same value.
+	MatchAll HeadersMatch = "all"
+	// MatchAny requires at least one header of the binding to be present in the message with the same value.
+	MatchAny HeadersMatch = "any"
+)
+
+// HeadersBinding is the rule that binds a queue to a headers exchange.
+type HeadersBinding struct {
+	// Match is the matching rule of the Headers.
+	//
+	// default: MatchAll
+	Match HeadersMatch
+	// Headers are the expected header values of the message.
+	Headers map[string]string
+}
+
+// Matches reports whether the headers of a message satisfy the binding.
+// A binding without Headers matches every message.
+func (b HeadersBinding) Matches(headers map[string]string) bool {
+	if len(b.Headers) == 0 {
+		return true
+	}
+	var matchAny = b.Match == MatchAny
+	for key, exp := range b.Headers {
+		got, ok := headers[key]
+		matches := ok && got == exp
+		if matchAny && matches {
+			return true
+		}
+		if !matchAny && !matches {
+			return false
+		}
+	}
+	return !matchAny
+}
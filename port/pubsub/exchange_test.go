@@ -0,0 +1,65 @@
+package pubsub_test
+
+import (
+	"testing"
+
+	"go.llib.dev/frameless/port/pubsub"
+	"go.llib.dev/testcase/assert"
+)
+
+func TestMatchTopic(t *testing.T) {
+	type TC struct {
+		Pattern    string
+		RoutingKey string
+		Match      bool
+	}
+	for _, tc := range []TC{
+		{Pattern: "order.created", RoutingKey: "order.created", Match: true},
+		{Pattern: "order.created", RoutingKey: "order.deleted", Match: false},
+		{Pattern: "order.created", RoutingKey: "order.created.eu", Match: false},
+		{Pattern: "order.*", RoutingKey: "order.created", Match: true},
+		{Pattern: "order.*", RoutingKey: "order", Match: false},
+		{Pattern: "order.*", RoutingKey: "order.created.eu", Match: false},
+		{Pattern: "*.created", RoutingKey: "order.created", Match: true},
+		{Pattern: "*.*.eu", RoutingKey: "order.created.eu", Match: true},
+		{Pattern: "order.#", RoutingKey: "order", Match: true},
+		{Pattern: "order.#", RoutingKey: "order.created", Match: true},
+		{Pattern: "order.#", RoutingKey: "order.created.eu", Match: true},
+		{Pattern: "order.#", RoutingKey: "user.created", Match: false},
+		{Pattern: "#", RoutingKey: "order.created.eu", Match: true},
+		{Pattern: "#.eu", RoutingKey: "order.created.eu", Match: true},
+		{Pattern: "#.eu", RoutingKey: "eu", Match: true},
+		{Pattern: "#.eu", RoutingKey: "order.created.us", Match: false},
+		{Pattern: "order.#.eu", RoutingKey: "order.eu", Match: true},
+		{Pattern: "order.#.eu", RoutingKey: "order.created.eu", Match: true},
+		{Pattern: "order.#.*", RoutingKey: "order", Match: false},
+		{Pattern: "order.#.*", RoutingKey: "order.created", Match: true},
+	} {
+		t.Run(tc.Pattern+" "+tc.RoutingKey, func(t *testing.T) {
+			assert.Equal(t, tc.Match, pubsub.MatchTopic(tc.Pattern, tc.RoutingKey))
+		})
+	}
+}
+
+func TestHeadersBinding_Matches(t *testing.T) {
+	headers := map[string]string{"format": "pdf", "type": "report"}
+
+	t.Run("match all", func(t *testing.T) {
+		assert.True(t, pubsub.HeadersBinding{Headers: map[string]string{"format": "pdf", "type": "report"}}.Matches(headers))
+		assert.True(t, pubsub.HeadersBinding{Match: pubsub.MatchAll, Headers: map[string]string{"format": "pdf"}}.Matches(headers))
+		assert.False(t, pubsub.HeadersBinding{Match: pubsub.MatchAll, Headers: map[string]string{"format": "pdf", "type": "log"}}.Matches(headers))
+		assert.False(t, pubsub.HeadersBinding{Match: pubsub.MatchAll, Headers: map[string]string{"format": "pdf", "lang": "en"}}.Matches(headers))
+	})
+
+	t.Run("match any", func(t *testing.T) {
+		assert.True(t, pubsub.HeadersBinding{Match: pubsub.MatchAny, Headers: map[string]string{"format": "pdf", "type": "log"}}.Matches(headers))
+		assert.True(t, pubsub.HeadersBinding{Match: pubsub.MatchAny, Headers: map[string]string{"lang": "en", "type": "report"}}.Matches(headers))
+		assert.False(t, pubsub.HeadersBinding{Match: pubsub.MatchAny, Headers: map[string]string{"format": "zip", "lang": "en"}}.Matches(headers))
+		assert.False(t, pubsub.HeadersBinding{Match: pubsub.MatchAny, Headers: map[string]string{"format": "pdf"}}.Matches(nil))
+	})
+
+	t.Run("without binding headers, every message matches", func(t *testing.T) {
+		assert.True(t, pubsub.HeadersBinding{}.Matches(headers))
+		assert.True(t, pubsub.HeadersBinding{Match: pubsub.MatchAny}.Matches(nil))
+	})
+}
@@ -0,0 +1,23 @@
+package pubsub
+
+import (
+	"context"
+	"time"
+
+	"go.llib.dev/frameless/pkg/errorkit"
+)
+
+// LeaseExtender is an optional interface for a Message.
+//
+// A queue with a visibility timeout leases the received message to its subscriber,
+// and when the lease expires without an ACK or NACK, the message is delivered again,
+// as if its subscriber had crashed.
+// A long-running consumer can extend the lease, to keep the message hidden from the other subscribers.
+type LeaseExtender interface {
+	// ExtendLease extends the lease of the message, so it stays hidden for the given time.Duration, counted from now.
+	ExtendLease(ctx context.Context, d time.Duration) error
+}
+
+// ErrLeaseExpired is returned when a message is ACK-ed, NACK-ed or its lease is extended after its visibility timeout,
+// and in the meantime the message was delivered to another subscriber.
+const ErrLeaseExpired errorkit.Error = "ErrLeaseExpired"
@@ -0,0 +1,130 @@
+package pubsub
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"go.llib.dev/frameless/pkg/contextkit"
+)
+
+// Metadata describes a delivered Message.
+type Metadata struct {
+	// ID is the unique identifier of the message, assigned when the message is published.
+	ID string
+	// Headers are the key-value pairs published along with the message,
+	// such as a correlation ID, a trace context or a content type.
+	Headers map[string]string
+	// PublishedAt is the time when the message was published.
+	PublishedAt time.Time
+	// DeliveryCount is the delivery attempt count of the message, including the current delivery.
+	// A message that is NACK-ed is delivered again, until it runs out of the queue's max delivery attempts.
+	DeliveryCount int
+}
+
+// MetadataProvider is an optional interface for a Message, which exposes the Metadata of the message.
+type MetadataProvider interface {
+	Metadata() Metadata
+}
+
+// LookupMetadata returns the Metadata of a Message, when the Message implements MetadataProvider.
+func LookupMetadata[Data any](msg Message[Data]) (Metadata, bool) {
+	mp, ok := msg.(MetadataProvider)
+	if !ok {
+		return Metadata{}, false
+	}
+	return mp.Metadata(), true
+}
+
+type ctxKeyHeaders struct{}
+
+var ctxHeaders contextkit.ValueHandler[ctxKeyHeaders, map[string]string]
+
+// ContextWithHeaders is a publish option, which adds the headers to the messages published with the returned context.
+// The headers are merged with the headers already present in the context.
+func ContextWithHeaders(ctx context.Context, headers map[string]string) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+	merged := map[string]string{}
+	if prev, ok := ctxHeaders.Lookup(ctx); ok {
+		maps.Copy(merged, prev)
+	}
+	maps.Copy(merged, headers)
+	return ctxHeaders.ContextWith(ctx, merged)
+}
+
+// MakeHeaders makes the headers of a message that is published with the context.
+// The headers include the ones from ContextWithHeaders,
+// and the context values that the registered ContextPropagator-s inject.
+//
+// MakeHeaders is meant for the Publisher implementations.
+func MakeHeaders(ctx context.Context) map[string]string {
+	headers := map[string]string{}
+	if hs, ok := ctxHeaders.Lookup(ctx); ok {
+		maps.Copy(headers, hs)
+	}
+	for _, p := range contextPropagators() {
+		p.Inject(ctx, headers)
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
+// ExtractContext restores the context values that the registered ContextPropagator-s injected into the headers.
+//
+// ExtractContext is meant for the Subscriber implementations, to make the context of a received message.
+func ExtractContext(ctx context.Context, headers map[string]string) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+	for _, p := range contextPropagators() {
+		ctx = p.Extract(ctx, headers)
+	}
+	return ctx
+}
+
+// ContextPropagator carries values from the context of the publisher to the context of the received message,
+// by encoding them into the message headers.
+// This allows context values, such as the logging details, to travel across a queue.
+type ContextPropagator interface {
+	// Inject encodes the propagated values of the context into the headers.
+	Inject(ctx context.Context, headers map[string]string)
+	// Extract decodes the propagated values from the headers into the context.
+	Extract(ctx context.Context, headers map[string]string) context.Context
+}
+
+var propagators struct {
+	m  sync.RWMutex
+	ps []*ContextPropagator
+}
+
+// RegisterContextPropagator registers a ContextPropagator, which is used by MakeHeaders and ExtractContext.
+// It returns a function that unregisters the ContextPropagator.
+func RegisterContextPropagator(p ContextPropagator) func() {
+	propagators.m.Lock()
+	defer propagators.m.Unlock()
+	ptr := &p
+	propagators.ps = append(propagators.ps, ptr)
+	return func() {
+		propagators.m.Lock()
+		defer propagators.m.Unlock()
+		propagators.ps = slices.DeleteFunc(propagators.ps, func(oth *ContextPropagator) bool {
+			return oth == ptr
+		})
+	}
+}
+
+func contextPropagators() []ContextPropagator {
+	propagators.m.RLock()
+	defer propagators.m.RUnlock()
+	var ps = make([]ContextPropagator, 0, len(propagators.ps))
+	for _, p := range propagators.ps {
+		ps = append(ps, *p)
+	}
+	return ps
+}
@@ -0,0 +1,102 @@
+package pubsub_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.llib.dev/frameless/port/pubsub"
+	"go.llib.dev/testcase/assert"
+)
+
+func TestContextWithHeaders(t *testing.T) {
+	t.Run("without headers, the message has no headers", func(t *testing.T) {
+		assert.Empty(t, pubsub.MakeHeaders(context.Background()))
+	})
+
+	t.Run("headers are added to the message", func(t *testing.T) {
+		ctx := pubsub.ContextWithHeaders(context.Background(), map[string]string{"correlation-id": "42"})
+		assert.Equal(t, map[string]string{"correlation-id": "42"}, pubsub.MakeHeaders(ctx))
+	})
+
+	t.Run("headers are merged with the headers of the parent context", func(t *testing.T) {
+		ctx := pubsub.ContextWithHeaders(context.Background(), map[string]string{"correlation-id": "42", "content-type": "text/plain"})
+		ctx = pubsub.ContextWithHeaders(ctx, map[string]string{"content-type": "application/json"})
+		assert.Equal(t, map[string]string{"correlation-id": "42", "content-type": "application/json"}, pubsub.MakeHeaders(ctx))
+	})
+
+	t.Run("the headers of the context are not affected by the changes of the passed headers", func(t *testing.T) {
+		headers := map[string]string{"correlation-id": "42"}
+		ctx := pubsub.ContextWithHeaders(context.Background(), headers)
+		headers["correlation-id"] = "24"
+		pubsub.MakeHeaders(ctx)["correlation-id"] = "24"
+		assert.Equal(t, map[string]string{"correlation-id": "42"}, pubsub.MakeHeaders(ctx))
+	})
+}
+
+type ctxKeyTraceID struct{}
+
+type traceIDPropagator struct{}
+
+func (traceIDPropagator) Inject(ctx context.Context, headers map[string]string) {
+	if id, ok := ctx.Value(ctxKeyTraceID{}).(string); ok {
+		headers["trace-id"] = id
+	}
+}
+
+func (traceIDPropagator) Extract(ctx context.Context, headers map[string]string) context.Context {
+	if id, ok := headers["trace-id"]; ok {
+		return context.WithValue(ctx, ctxKeyTraceID{}, id)
+	}
+	return ctx
+}
+
+func TestRegisterContextPropagator(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ctxKeyTraceID{}, "trace")
+	ctx = pubsub.ContextWithHeaders(ctx, map[string]string{"correlation-id": "42"})
+
+	unregister := pubsub.RegisterContextPropagator(traceIDPropagator{})
+	t.Cleanup(unregister)
+
+	headers := pubsub.MakeHeaders(ctx)
+	assert.Equal(t, map[string]string{"correlation-id": "42", "trace-id": "trace"}, headers)
+
+	got := pubsub.ExtractContext(context.Background(), headers)
+	assert.Equal[any](t, "trace", got.Value(ctxKeyTraceID{}))
+
+	unregister()
+	assert.Equal(t, map[string]string{"correlation-id": "42"}, pubsub.MakeHeaders(ctx))
+	assert.Nil(t, pubsub.ExtractContext(context.Background(), headers).Value(ctxKeyTraceID{}))
+}
+
+type metadataMessage struct {
+	pubsub.Message[string]
+	meta pubsub.Metadata
+}
+
+func (msg metadataMessage) Metadata() pubsub.Metadata {
+	return msg.meta
+}
+
+func TestLookupMetadata(t *testing.T) {
+	t.Run("message without metadata", func(t *testing.T) {
+		_, ok := pubsub.LookupMetadata(pubsub.MakeMessage(context.Background(), "foo", nil, nil))
+		assert.False(t, ok)
+	})
+
+	t.Run("message with metadata", func(t *testing.T) {
+		meta := pubsub.Metadata{
+			ID:            "42",
+			Headers:       map[string]string{"content-type": "text/plain"},
+			PublishedAt:   time.Now(),
+			DeliveryCount: 2,
+		}
+		msg := metadataMessage{
+			Message: pubsub.MakeMessage(context.Background(), "foo", nil, nil),
+			meta:    meta,
+		}
+		got, ok := pubsub.LookupMetadata[string](msg)
+		assert.True(t, ok)
+		assert.Equal(t, meta, got)
+	})
+}
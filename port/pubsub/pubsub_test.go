@@ -3,10 +3,12 @@ package pubsub_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"go.llib.dev/frameless/port/pubsub"
 	"go.llib.dev/testcase"
 	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/clock/timecop"
 	"go.llib.dev/testcase/let"
 )
 
@@ -304,3 +306,23 @@ func TestZeroMessage(t *testing.T) {
 		})
 	})
 }
+
+func TestContextWithNotBefore(t *testing.T) {
+	ctx := context.Background()
+	_, ok := pubsub.NotBefore(ctx)
+	assert.False(t, ok, "a context without the publish option has no not-before time")
+
+	notBefore := time.Now().Add(time.Hour)
+	got, ok := pubsub.NotBefore(pubsub.ContextWithNotBefore(ctx, notBefore))
+	assert.True(t, ok)
+	assert.Equal(t, notBefore, got)
+}
+
+func TestContextWithDelay(t *testing.T) {
+	now := time.Now()
+	timecop.Travel(t, now, timecop.Freeze)
+
+	got, ok := pubsub.NotBefore(pubsub.ContextWithDelay(context.Background(), time.Minute))
+	assert.True(t, ok)
+	assert.True(t, got.Equal(now.Add(time.Minute)))
+}
@@ -0,0 +1,129 @@
+package pubsubcontract
+
+import (
+	"iter"
+	"testing"
+
+	"go.llib.dev/frameless/pkg/iterkit"
+	"go.llib.dev/frameless/port/contract"
+	"go.llib.dev/frameless/port/option"
+	"go.llib.dev/frameless/port/pubsub"
+	"go.llib.dev/frameless/port/pubsub/pubsubtest"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+)
+
+// DeadLetter defines a contract for queues with a max delivery attempts limit.
+// A message that is NACK-ed on its last delivery attempt is a poison message,
+// and instead of being redelivered, it is moved to the dead-letter queue.
+//
+// When the dead-letter queue's subscriber implements pubsub.Inspector or pubsub.Requeuer,
+// these are tested as well.
+func DeadLetter[Data any](
+	publisher pubsub.Publisher[Data],
+	subscriber pubsub.Subscriber[Data],
+	// deadLetter is the subscriber of the queue that receives the poison messages of the subscriber.
+	deadLetter pubsub.Subscriber[Data],
+	// maxDeliveryAttempts is the number of delivery attempts the subscriber has configured.
+	maxDeliveryAttempts int,
+	opts ...Option[Data]) contract.Contract {
+
+	s := testcase.NewSpec(nil)
+	c := option.ToConfig[Config[Data]](opts)
+
+	s.Before(func(t *testcase.T) {
+		pubsubtest.TryCleanup(t, subscriber, c.MakeContext(t))
+		pubsubtest.TryCleanup(t, deadLetter, c.MakeContext(t))
+	})
+
+	// deliver receives the message n times from the subscriber, and NACK-s every delivery of it.
+	deliver := func(t *testcase.T, exp Data, n int, lastDelivery func(pubsub.Message[Data]) error) {
+		next, stop := iter.Pull2(iter.Seq2[pubsub.Message[Data], error](subscriber.Subscribe(c.MakeContext(t))))
+		defer stop()
+		for i := 1; i <= n; i++ {
+			msg, err, ok := next()
+			assert.True(t, ok, "expected that the message is redelivered after a NACK")
+			assert.NoError(t, err)
+			assert.Equal(t, exp, msg.Data())
+			if meta, ok := pubsub.LookupMetadata(msg); ok {
+				assert.Equal(t, i, meta.DeliveryCount, "unexpected delivery count")
+			}
+			if i == n {
+				assert.NoError(t, lastDelivery(msg))
+				break
+			}
+			assert.NoError(t, msg.NACK())
+		}
+	}
+	nack := func(msg pubsub.Message[Data]) error { return msg.NACK() }
+	ack := func(msg pubsub.Message[Data]) error { return msg.ACK() }
+
+	s.Test("a message that is NACK-ed on its last delivery attempt is moved to the dead-letter queue", func(t *testcase.T) {
+		var (
+			poison = c.MakeData(t)
+			marker = c.MakeData(t)
+		)
+		assert.NoError(t, publisher.Publish(c.MakeContext(t), poison))
+		deliver(t, poison, maxDeliveryAttempts, nack)
+
+		dlq := pubsubtest.Subscribe(t, deadLetter, c.MakeContext(t))
+		dlq.Eventually(t, func(tb testing.TB, got []Data) {
+			assert.Must(tb).Contains(got, poison)
+		})
+
+		t.Log("and the poison message is no longer delivered by the source queue")
+		assert.NoError(t, publisher.Publish(c.MakeContext(t), marker))
+		res := pubsubtest.Subscribe(t, subscriber, c.MakeContext(t))
+		res.Eventually(t, func(tb testing.TB, got []Data) {
+			assert.Must(tb).Contains(got, marker)
+		})
+		assert.NotContains(t, res.Values(), poison)
+	})
+
+	s.Test("a message that is ACK-ed within the max delivery attempts is not moved to the dead-letter queue", func(t *testcase.T) {
+		val := c.MakeData(t)
+		assert.NoError(t, publisher.Publish(c.MakeContext(t), val))
+		deliver(t, val, maxDeliveryAttempts, ack)
+
+		dlq := pubsubtest.Subscribe(t, deadLetter, c.MakeContext(t))
+		dlq.AssertEmpty(t, "the ACK-ed message was not expected in the dead-letter queue")
+	})
+
+	if _, ok := deadLetter.(pubsub.Inspector[Data]); ok {
+		s.Test("the dead-letter queue can be inspected without consuming its messages", func(t *testcase.T) {
+			inspector := deadLetter.(pubsub.Inspector[Data])
+			poison := c.MakeData(t)
+			assert.NoError(t, publisher.Publish(c.MakeContext(t), poison))
+			deliver(t, poison, maxDeliveryAttempts, nack)
+
+			t.Eventually(func(t *testcase.T) {
+				vs, err := iterkit.CollectE(inspector.Inspect(c.MakeContext(t)))
+				assert.NoError(t, err)
+				assert.Contains(t, vs, poison)
+			})
+
+			dlq := pubsubtest.Subscribe(t, deadLetter, c.MakeContext(t))
+			dlq.Eventually(t, func(tb testing.TB, got []Data) {
+				assert.Must(tb).Contains(got, poison, "inspect was not expected to consume the message")
+			})
+		})
+	}
+
+	if _, ok := deadLetter.(pubsub.Requeuer[Data]); ok {
+		s.Test("the messages of the dead-letter queue can be requeued to the source queue", func(t *testcase.T) {
+			requeuer := deadLetter.(pubsub.Requeuer[Data])
+			poison := c.MakeData(t)
+			assert.NoError(t, publisher.Publish(c.MakeContext(t), poison))
+			deliver(t, poison, maxDeliveryAttempts, nack)
+
+			assert.NoError(t, requeuer.Requeue(c.MakeContext(t), publisher))
+
+			res := pubsubtest.Subscribe(t, subscriber, c.MakeContext(t))
+			res.Eventually(t, func(tb testing.TB, got []Data) {
+				assert.Must(tb).Contains(got, poison)
+			})
+		})
+	}
+
+	return s.AsSuite("DeadLetter")
+}
@@ -0,0 +1,83 @@
+package pubsubcontract
+
+import (
+	"testing"
+	"time"
+
+	"go.llib.dev/frameless/port/contract"
+	"go.llib.dev/frameless/port/option"
+	"go.llib.dev/frameless/port/pubsub"
+	"go.llib.dev/frameless/port/pubsub/pubsubtest"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/clock"
+	"go.llib.dev/testcase/clock/timecop"
+)
+
+// Delayed defines a contract for queues that support delayed delivery with a not-before publish option.
+// A message published with pubsub.ContextWithNotBefore is not delivered before its not-before time.
+//
+// The contract travels in time with the testcase/clock/timecop package,
+// thus the implementation should tell the time with the testcase/clock package.
+func Delayed[Data any](publisher pubsub.Publisher[Data], subscriber pubsub.Subscriber[Data], opts ...Option[Data]) contract.Contract {
+	s := testcase.NewSpec(nil)
+	c := option.ToConfig[Config[Data]](opts)
+
+	s.Before(func(t *testcase.T) {
+		pubsubtest.TryCleanup(t, subscriber, c.MakeContext(t))
+	})
+
+	s.Test("a delayed message is not delivered before its not-before time", func(t *testcase.T) {
+		var (
+			delay   = t.Random.DurationBetween(time.Hour, 24*time.Hour)
+			delayed = c.MakeData(t)
+			marker  = c.MakeData(t)
+		)
+		assert.NoError(t, publisher.Publish(pubsub.ContextWithNotBefore(c.MakeContext(t), clock.Now().Add(delay)), delayed))
+		assert.NoError(t, publisher.Publish(c.MakeContext(t), marker))
+
+		res := pubsubtest.Subscribe(t, subscriber, c.MakeContext(t))
+		res.Eventually(t, func(tb testing.TB, got []Data) {
+			assert.Must(tb).Contains(got, marker)
+		})
+		assert.NotContains(t, res.Values(), delayed,
+			"the delayed message was not expected to be delivered before its not-before time")
+
+		t.Log("after the not-before time is reached, the delayed message is delivered")
+		timecop.Travel(t, delay+time.Second)
+
+		res.Eventually(t, func(tb testing.TB, got []Data) {
+			assert.Must(tb).Contains(got, delayed)
+		})
+	})
+
+	s.Test("a message published with a delay is delivered after the delay", func(t *testcase.T) {
+		var (
+			delay   = t.Random.DurationBetween(time.Minute, time.Hour)
+			delayed = c.MakeData(t)
+		)
+		assert.NoError(t, publisher.Publish(pubsub.ContextWithDelay(c.MakeContext(t), delay), delayed))
+
+		res := pubsubtest.Subscribe(t, subscriber, c.MakeContext(t))
+		res.AssertEmpty(t, "the delayed message was not expected to be delivered before its delay")
+
+		timecop.Travel(t, delay+time.Second)
+
+		res.Eventually(t, func(tb testing.TB, got []Data) {
+			assert.Must(tb).Contains(got, delayed)
+		})
+	})
+
+	s.Test("a message with a past not-before time is delivered right away", func(t *testcase.T) {
+		val := c.MakeData(t)
+		notBefore := clock.Now().Add(-1 * t.Random.DurationBetween(time.Minute, time.Hour))
+		assert.NoError(t, publisher.Publish(pubsub.ContextWithNotBefore(c.MakeContext(t), notBefore), val))
+
+		res := pubsubtest.Subscribe(t, subscriber, c.MakeContext(t))
+		res.Eventually(t, func(tb testing.TB, got []Data) {
+			assert.Must(tb).Contains(got, val)
+		})
+	})
+
+	return s.AsSuite("Delayed")
+}
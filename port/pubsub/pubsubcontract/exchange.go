@@ -2,6 +2,7 @@ package pubsubcontract
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"go.llib.dev/frameless/port/contract"
@@ -10,6 +11,7 @@ import (
 	"go.llib.dev/frameless/port/pubsub/pubsubtest"
 	"go.llib.dev/testcase"
 	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/random"
 )
 
 // Broadcast ensures that an identical data is sent to every subscriber.
@@ -84,3 +86,275 @@ func Broadcast[Data any](
 
 	return s.AsSuite("Broadcast")
 }
+
+// TopicExchange ensures that an exchange delivers messages to the queues
+// whose binding pattern matches the routing key of the published pubsub.Envelope.
+// For the pattern rules, see pubsub.MatchTopic.
+func TopicExchange[Data any](
+	// Exchange is the publisher that routes the published envelopes to the bound queues.
+	Exchange pubsub.Publisher[pubsub.Envelope[Data]],
+	// Bind creates a queue and binds it to the Exchange with the routing key pattern.
+	// Queues made with Bind suppose to be cleaned up after the test.
+	// For the cleanup purpose, use the testing.TB received as part of Bind.
+	Bind func(tb testing.TB, pattern string) pubsub.Subscriber[Data],
+	opts ...Option[Data]) contract.Contract {
+	s := testcase.NewSpec(nil)
+	c := option.ToConfig[Config[Data]](opts)
+
+	// topic isolates the routing keys of a test from the queues that the other tests bound to the exchange.
+	topic := testcase.Let(s, func(t *testcase.T) string {
+		return t.Random.StringNC(8, random.CharsetAlpha())
+	})
+	key := func(t *testcase.T, words ...string) string {
+		return strings.Join(append([]string{topic.Get(t)}, words...), ".")
+	}
+	// sampleKey makes a routing key that surely matches the pattern.
+	sampleKey := func(pattern string) string {
+		var words []string
+		for _, word := range strings.Split(pattern, ".") {
+			switch word {
+			case "#":
+			case "*":
+				words = append(words, "sample")
+			default:
+				words = append(words, word)
+			}
+		}
+		return strings.Join(words, ".")
+	}
+	thenRouting := func(s *testcase.Spec, pattern func(t *testcase.T) string, routingKey func(t *testcase.T) string, routed bool) {
+		s.Test(fmt.Sprintf("the message is routed: %t", routed), func(t *testcase.T) {
+			pattern := pattern(t)
+			assertExchangeRouting(t, c, Exchange, Bind(t, pattern),
+				pubsub.Envelope[Data]{RoutingKey: routingKey(t), Data: c.MakeData(t)},
+				pubsub.Envelope[Data]{RoutingKey: sampleKey(pattern), Data: c.MakeData(t)},
+				routed)
+		})
+	}
+
+	s.Context("exchange strategy is topic", func(s *testcase.Spec) {
+		s.When("the routing key is equal to the binding pattern", func(s *testcase.Spec) {
+			thenRouting(s,
+				func(t *testcase.T) string { return key(t, "order", "created") },
+				func(t *testcase.T) string { return key(t, "order", "created") },
+				true)
+		})
+
+		s.When("the routing key is different from the binding pattern", func(s *testcase.Spec) {
+			thenRouting(s,
+				func(t *testcase.T) string { return key(t, "order", "created") },
+				func(t *testcase.T) string { return key(t, "order", "deleted") },
+				false)
+		})
+
+		s.When("the routing key has more words than the binding pattern", func(s *testcase.Spec) {
+			thenRouting(s,
+				func(t *testcase.T) string { return key(t, "order") },
+				func(t *testcase.T) string { return key(t, "order", "created") },
+				false)
+		})
+
+		s.When(`the binding pattern uses the "*" wildcard, and the routing key has a single word in its place`, func(s *testcase.Spec) {
+			thenRouting(s,
+				func(t *testcase.T) string { return key(t, "*", "created") },
+				func(t *testcase.T) string { return key(t, "order", "created") },
+				true)
+		})
+
+		s.When(`the binding pattern uses the "*" wildcard, and the routing key has no word in its place`, func(s *testcase.Spec) {
+			thenRouting(s,
+				func(t *testcase.T) string { return key(t, "order", "*") },
+				func(t *testcase.T) string { return key(t, "order") },
+				false)
+		})
+
+		s.When(`the binding pattern uses the "*" wildcard, and the routing key has multiple words in its place`, func(s *testcase.Spec) {
+			thenRouting(s,
+				func(t *testcase.T) string { return key(t, "order", "*") },
+				func(t *testcase.T) string { return key(t, "order", "created", "eu") },
+				false)
+		})
+
+		s.When(`the binding pattern uses the "#" wildcard, and the routing key has no word in its place`, func(s *testcase.Spec) {
+			thenRouting(s,
+				func(t *testcase.T) string { return key(t, "order", "#") },
+				func(t *testcase.T) string { return key(t, "order") },
+				true)
+		})
+
+		s.When(`the binding pattern uses the "#" wildcard, and the routing key has multiple words in its place`, func(s *testcase.Spec) {
+			thenRouting(s,
+				func(t *testcase.T) string { return key(t, "order", "#") },
+				func(t *testcase.T) string { return key(t, "order", "created", "eu") },
+				true)
+		})
+
+		s.When(`the binding pattern uses the "#" wildcard in the middle`, func(s *testcase.Spec) {
+			thenRouting(s,
+				func(t *testcase.T) string { return key(t, "order", "#", "eu") },
+				func(t *testcase.T) string { return key(t, "order", "created", "eu") },
+				true)
+		})
+
+		s.When(`the binding pattern uses the "#" wildcard, but the rest of the routing key doesn't match`, func(s *testcase.Spec) {
+			thenRouting(s,
+				func(t *testcase.T) string { return key(t, "order", "#", "eu") },
+				func(t *testcase.T) string { return key(t, "order", "created", "us") },
+				false)
+		})
+
+		s.Test("every queue with a matching binding receives the message", func(t *testcase.T) {
+			var (
+				ctx  = c.MakeContext(t)
+				data = c.MakeData(t)
+				res1 = pubsubtest.Subscribe(t, Bind(t, key(t, "order", "*")), ctx)
+				res2 = pubsubtest.Subscribe(t, Bind(t, key(t, "#")), ctx)
+			)
+			assert.Must(t).NoError(Exchange.Publish(ctx, pubsub.Envelope[Data]{RoutingKey: key(t, "order", "created"), Data: data}))
+
+			for _, res := range []*pubsubtest.AsyncResults[Data]{res1, res2} {
+				res.Eventually(t, func(tb testing.TB, got []Data) {
+					assert.Must(tb).ContainsExactly([]Data{data}, got)
+				})
+			}
+		})
+
+		s.Test("a message that matches no binding is dropped without an error", func(t *testcase.T) {
+			assert.Must(t).NoError(Exchange.Publish(c.MakeContext(t), pubsub.Envelope[Data]{
+				RoutingKey: key(t, "unbound"),
+				Data:       c.MakeData(t),
+			}))
+		})
+	})
+
+	return s.AsSuite("TopicExchange")
+}
+
+// HeadersExchange ensures that an exchange delivers messages to the queues
+// whose pubsub.HeadersBinding matches the headers of the published pubsub.Envelope.
+func HeadersExchange[Data any](
+	// Exchange is the publisher that routes the published envelopes to the bound queues.
+	Exchange pubsub.Publisher[pubsub.Envelope[Data]],
+	// Bind creates a queue and binds it to the Exchange with the headers binding.
+	// Queues made with Bind suppose to be cleaned up after the test.
+	// For the cleanup purpose, use the testing.TB received as part of Bind.
+	Bind func(tb testing.TB, binding pubsub.HeadersBinding) pubsub.Subscriber[Data],
+	opts ...Option[Data]) contract.Contract {
+	s := testcase.NewSpec(nil)
+	c := option.ToConfig[Config[Data]](opts)
+
+	// the random header values isolate a test from the queues that the other tests bound to the exchange.
+	format := testcase.Let(s, func(t *testcase.T) string {
+		return t.Random.StringNC(8, random.CharsetAlpha())
+	})
+	kind := testcase.Let(s, func(t *testcase.T) string {
+		return t.Random.StringNC(8, random.CharsetAlpha())
+	})
+	thenRouting := func(s *testcase.Spec, binding func(t *testcase.T) pubsub.HeadersBinding, headers func(t *testcase.T) map[string]string, routed bool) {
+		s.Test(fmt.Sprintf("the message is routed: %t", routed), func(t *testcase.T) {
+			binding := binding(t)
+			assertExchangeRouting(t, c, Exchange, Bind(t, binding),
+				pubsub.Envelope[Data]{Headers: headers(t), Data: c.MakeData(t)},
+				pubsub.Envelope[Data]{Headers: binding.Headers, Data: c.MakeData(t)},
+				routed)
+		})
+	}
+
+	s.Context("exchange strategy is headers", func(s *testcase.Spec) {
+		s.Context("match all", func(s *testcase.Spec) {
+			binding := func(t *testcase.T) pubsub.HeadersBinding {
+				return pubsub.HeadersBinding{
+					Match:   pubsub.MatchAll,
+					Headers: map[string]string{"format": format.Get(t), "type": kind.Get(t)},
+				}
+			}
+
+			s.When("every header of the binding is present in the message", func(s *testcase.Spec) {
+				thenRouting(s, binding, func(t *testcase.T) map[string]string {
+					return map[string]string{"format": format.Get(t), "type": kind.Get(t), "lang": "en"}
+				}, true)
+			})
+
+			s.When("a header of the binding has a different value in the message", func(s *testcase.Spec) {
+				thenRouting(s, binding, func(t *testcase.T) map[string]string {
+					return map[string]string{"format": format.Get(t), "type": "other"}
+				}, false)
+			})
+
+			s.When("a header of the binding is missing from the message", func(s *testcase.Spec) {
+				thenRouting(s, binding, func(t *testcase.T) map[string]string {
+					return map[string]string{"format": format.Get(t)}
+				}, false)
+			})
+		})
+
+		s.Context("match any", func(s *testcase.Spec) {
+			binding := func(t *testcase.T) pubsub.HeadersBinding {
+				return pubsub.HeadersBinding{
+					Match:   pubsub.MatchAny,
+					Headers: map[string]string{"format": format.Get(t), "type": kind.Get(t)},
+				}
+			}
+
+			s.When("a single header of the binding is present in the message", func(s *testcase.Spec) {
+				thenRouting(s, binding, func(t *testcase.T) map[string]string {
+					return map[string]string{"type": kind.Get(t)}
+				}, true)
+			})
+
+			s.When("no header of the binding is present in the message", func(s *testcase.Spec) {
+				thenRouting(s, binding, func(t *testcase.T) map[string]string {
+					return map[string]string{"format": "other", "lang": "en"}
+				}, false)
+			})
+		})
+
+		s.Test("every queue with a matching binding receives the message", func(t *testcase.T) {
+			var (
+				ctx  = c.MakeContext(t)
+				data = c.MakeData(t)
+				res1 = pubsubtest.Subscribe(t, Bind(t, pubsub.HeadersBinding{
+					Match:   pubsub.MatchAll,
+					Headers: map[string]string{"format": format.Get(t)},
+				}), ctx)
+				res2 = pubsubtest.Subscribe(t, Bind(t, pubsub.HeadersBinding{
+					Match:   pubsub.MatchAny,
+					Headers: map[string]string{"type": kind.Get(t), "lang": "en"},
+				}), ctx)
+			)
+			assert.Must(t).NoError(Exchange.Publish(ctx, pubsub.Envelope[Data]{
+				Headers: map[string]string{"format": format.Get(t), "type": kind.Get(t)},
+				Data:    data,
+			}))
+
+			for _, res := range []*pubsubtest.AsyncResults[Data]{res1, res2} {
+				res.Eventually(t, func(tb testing.TB, got []Data) {
+					assert.Must(tb).ContainsExactly([]Data{data}, got)
+				})
+			}
+		})
+	})
+
+	return s.AsSuite("HeadersExchange")
+}
+
+// assertExchangeRouting publishes the envelope, and checks whether the subscriber receives it.
+// When the envelope is not expected to be routed, the marker envelope is published afterwards,
+// and once the marker is received, the subscriber is expected to not have the envelope's data.
+func assertExchangeRouting[Data any](t *testcase.T, c Config[Data], exchange pubsub.Publisher[pubsub.Envelope[Data]], sub pubsub.Subscriber[Data], env, marker pubsub.Envelope[Data], routed bool) {
+	t.Helper()
+	ctx := c.MakeContext(t)
+	res := pubsubtest.Subscribe(t, sub, ctx)
+	assert.Must(t).NoError(exchange.Publish(ctx, env))
+	if routed {
+		res.Eventually(t, func(tb testing.TB, got []Data) {
+			assert.Must(tb).Contains(got, env.Data)
+		})
+		return
+	}
+	assert.Must(t).NoError(exchange.Publish(ctx, marker))
+	res.Eventually(t, func(tb testing.TB, got []Data) {
+		assert.Must(tb).Contains(got, marker.Data)
+	})
+	assert.NotContains(t, res.Values(), env.Data)
+}
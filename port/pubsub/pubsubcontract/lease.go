@@ -0,0 +1,112 @@
+package pubsubcontract
+
+import (
+	"iter"
+	"testing"
+	"time"
+
+	"go.llib.dev/frameless/port/contract"
+	"go.llib.dev/frameless/port/option"
+	"go.llib.dev/frameless/port/pubsub"
+	"go.llib.dev/frameless/port/pubsub/pubsubtest"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/clock/timecop"
+)
+
+// VisibilityTimeout defines a contract for queues that lease the received messages to their subscriber.
+// A message that is neither ACK-ed nor NACK-ed within the visibility timeout is delivered again,
+// so the message of a crashed consumer is not lost.
+// When the message implements pubsub.LeaseExtender, the lease extension is tested as well.
+//
+// The contract travels in time with the testcase/clock/timecop package,
+// thus the implementation should tell the time with the testcase/clock package.
+func VisibilityTimeout[Data any](
+	publisher pubsub.Publisher[Data],
+	subscriber pubsub.Subscriber[Data],
+	// visibilityTimeout is the visibility timeout the subscriber has configured.
+	visibilityTimeout time.Duration,
+	opts ...Option[Data]) contract.Contract {
+
+	s := testcase.NewSpec(nil)
+	c := option.ToConfig[Config[Data]](opts)
+
+	s.Before(func(t *testcase.T) {
+		pubsubtest.TryCleanup(t, subscriber, c.MakeContext(t))
+	})
+
+	// receive takes a message from the subscriber, and keeps it without ACK or NACK,
+	// just like a consumer that crashed while it processed the message.
+	receive := func(t *testcase.T, exp Data) pubsub.Message[Data] {
+		next, stop := iter.Pull2(iter.Seq2[pubsub.Message[Data], error](subscriber.Subscribe(c.MakeContext(t))))
+		t.Defer(stop)
+		msg, err, ok := next()
+		assert.True(t, ok, "expected to receive a message")
+		assert.NoError(t, err)
+		assert.Equal(t, exp, msg.Data())
+		return msg
+	}
+
+	s.Test("a message that is not ACK-ed within the visibility timeout is delivered again", func(t *testcase.T) {
+		val := c.MakeData(t)
+		assert.NoError(t, publisher.Publish(c.MakeContext(t), val))
+		crashed := receive(t, val)
+
+		res := pubsubtest.Subscribe(t, subscriber, c.MakeContext(t))
+		res.AssertEmpty(t, "the message was not expected to be delivered again within its visibility timeout")
+
+		timecop.Travel(t, visibilityTimeout+time.Second)
+
+		res.Eventually(t, func(tb testing.TB, got []Data) {
+			assert.Must(tb).Contains(got, val)
+		})
+
+		t.Log("and the consumer that was thought to be crashed can't ACK the message anymore")
+		assert.ErrorIs(t, pubsub.ErrLeaseExpired, crashed.ACK())
+	})
+
+	s.Test("a message that is ACK-ed within the visibility timeout is not delivered again", func(t *testcase.T) {
+		val := c.MakeData(t)
+		assert.NoError(t, publisher.Publish(c.MakeContext(t), val))
+		msg := receive(t, val)
+		assert.NoError(t, msg.ACK())
+
+		timecop.Travel(t, visibilityTimeout+time.Second)
+
+		res := pubsubtest.Subscribe(t, subscriber, c.MakeContext(t))
+		res.AssertEmpty(t, "the ACK-ed message was not expected to be delivered again")
+	})
+
+	s.Test("a NACK-ed message is delivered again without waiting for the visibility timeout", func(t *testcase.T) {
+		val := c.MakeData(t)
+		assert.NoError(t, publisher.Publish(c.MakeContext(t), val))
+		msg := receive(t, val)
+		assert.NoError(t, msg.NACK())
+
+		res := pubsubtest.Subscribe(t, subscriber, c.MakeContext(t))
+		res.Eventually(t, func(tb testing.TB, got []Data) {
+			assert.Must(tb).Contains(got, val)
+		})
+	})
+
+	s.Test("a message with an extended lease is not delivered again until its extended lease expires", func(t *testcase.T) {
+		val := c.MakeData(t)
+		assert.NoError(t, publisher.Publish(c.MakeContext(t), val))
+		msg := receive(t, val)
+
+		extender, ok := msg.(pubsub.LeaseExtender)
+		if !ok {
+			t.Skip("the message doesn't implement pubsub.LeaseExtender")
+		}
+		assert.NoError(t, extender.ExtendLease(c.MakeContext(t), 3*visibilityTimeout))
+
+		timecop.Travel(t, visibilityTimeout+time.Second)
+
+		res := pubsubtest.Subscribe(t, subscriber, c.MakeContext(t))
+		res.AssertEmpty(t, "the message was not expected to be delivered again within its extended lease")
+
+		assert.NoError(t, msg.ACK())
+	})
+
+	return s.AsSuite("VisibilityTimeout")
+}
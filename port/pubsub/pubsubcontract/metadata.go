@@ -0,0 +1,136 @@
+package pubsubcontract
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"go.llib.dev/frameless/port/contract"
+	"go.llib.dev/frameless/port/option"
+	"go.llib.dev/frameless/port/pubsub"
+	"go.llib.dev/frameless/port/pubsub/pubsubtest"
+	"go.llib.dev/testcase"
+	"go.llib.dev/testcase/assert"
+	"go.llib.dev/testcase/clock"
+	"go.llib.dev/testcase/clock/timecop"
+)
+
+// Metadata defines a contract for queues whose messages implement pubsub.MetadataProvider.
+// The message ID is kept across the redeliveries of the message,
+// the headers of the publisher's context are delivered along with the message,
+// and the context values of the registered pubsub.ContextPropagator-s travel across the queue.
+//
+// The contract travels in time with the testcase/clock/timecop package,
+// thus the implementation should tell the time with the testcase/clock package.
+func Metadata[Data any](publisher pubsub.Publisher[Data], subscriber pubsub.Subscriber[Data], opts ...Option[Data]) contract.Contract {
+	s := testcase.NewSpec(nil)
+	c := option.ToConfig[Config[Data]](opts)
+
+	s.Before(func(t *testcase.T) {
+		pubsubtest.TryCleanup(t, subscriber, c.MakeContext(t))
+	})
+
+	receiver := func(t *testcase.T) func(exp Data) (pubsub.Message[Data], pubsub.Metadata) {
+		next, stop := iter.Pull2(iter.Seq2[pubsub.Message[Data], error](subscriber.Subscribe(c.MakeContext(t))))
+		t.Defer(stop)
+		return func(exp Data) (pubsub.Message[Data], pubsub.Metadata) {
+			msg, err, ok := next()
+			assert.True(t, ok, "expected to receive a message")
+			assert.NoError(t, err)
+			assert.Equal(t, exp, msg.Data())
+			meta, ok := pubsub.LookupMetadata(msg)
+			assert.True(t, ok, "expected that the message implements pubsub.MetadataProvider")
+			return msg, meta
+		}
+	}
+
+	s.Test("the received message has metadata", func(t *testcase.T) {
+		// the time is truncated, because the implementations may store the publish time with a lower precision.
+		publishedAt := clock.Now().Truncate(time.Second)
+		timecop.Travel(t, publishedAt, timecop.Freeze)
+
+		var (
+			val     = c.MakeData(t)
+			headers = map[string]string{"correlation-id": t.Random.UUID()}
+		)
+		assert.NoError(t, publisher.Publish(pubsub.ContextWithHeaders(c.MakeContext(t), headers), val))
+
+		msg, meta := receiver(t)(val)
+		assert.NotEmpty(t, meta.ID)
+		assert.Equal(t, headers, meta.Headers)
+		assert.True(t, publishedAt.Equal(meta.PublishedAt),
+			assert.MessageF("expected publish time %s, got %s", publishedAt, meta.PublishedAt))
+		assert.Equal(t, 1, meta.DeliveryCount)
+		assert.NoError(t, msg.ACK())
+	})
+
+	s.Test("the messages have a unique ID", func(t *testcase.T) {
+		var (
+			val1 = c.MakeData(t)
+			val2 = c.MakeData(t)
+		)
+		assert.NoError(t, publisher.Publish(c.MakeContext(t), val1))
+		assert.NoError(t, publisher.Publish(c.MakeContext(t), val2))
+
+		receive := receiver(t)
+		msg1, meta1 := receive(val1)
+		assert.NoError(t, msg1.ACK())
+		msg2, meta2 := receive(val2)
+		assert.NoError(t, msg2.ACK())
+		assert.NotEqual(t, meta1.ID, meta2.ID)
+	})
+
+	s.Test("a redelivered message keeps its ID and headers, while its delivery count increases", func(t *testcase.T) {
+		var (
+			val     = c.MakeData(t)
+			headers = map[string]string{"correlation-id": t.Random.UUID()}
+		)
+		assert.NoError(t, publisher.Publish(pubsub.ContextWithHeaders(c.MakeContext(t), headers), val))
+
+		receive := receiver(t)
+		msg, first := receive(val)
+		assert.NoError(t, msg.NACK())
+		msg, second := receive(val)
+		assert.NoError(t, msg.ACK())
+
+		assert.Equal(t, first.ID, second.ID)
+		assert.Equal(t, headers, second.Headers)
+		assert.Equal(t, first.DeliveryCount+1, second.DeliveryCount)
+	})
+
+	s.Test("the context values of the registered context propagators travel to the context of the received message", func(t *testcase.T) {
+		t.Defer(pubsub.RegisterContextPropagator(contractPropagator{}))
+
+		var (
+			val   = c.MakeData(t)
+			value = t.Random.UUID()
+		)
+		ctx := context.WithValue(c.MakeContext(t), ctxKeyContractPropagation{}, value)
+		assert.NoError(t, publisher.Publish(ctx, val))
+
+		msg, _ := receiver(t)(val)
+		assert.Equal[any](t, value, msg.Context().Value(ctxKeyContractPropagation{}))
+		assert.NoError(t, msg.ACK())
+	})
+
+	return s.AsSuite("Metadata")
+}
+
+type ctxKeyContractPropagation struct{}
+
+const contractPropagationHeaderKey = "pubsubcontract-propagation"
+
+type contractPropagator struct{}
+
+func (contractPropagator) Inject(ctx context.Context, headers map[string]string) {
+	if v, ok := ctx.Value(ctxKeyContractPropagation{}).(string); ok {
+		headers[contractPropagationHeaderKey] = v
+	}
+}
+
+func (contractPropagator) Extract(ctx context.Context, headers map[string]string) context.Context {
+	if v, ok := headers[contractPropagationHeaderKey]; ok {
+		return context.WithValue(ctx, ctxKeyContractPropagation{}, v)
+	}
+	return ctx
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"sort"
 	"testing"
+	"time"
 
 	"go.llib.dev/frameless/adapter/memory"
 	"go.llib.dev/frameless/port/pubsub"
@@ -140,6 +141,54 @@ func TestFanOutExchange(t *testing.T) {
 	)
 }
 
+func TestTopicExchange(t *testing.T) {
+	exchange := &memory.TopicExchange[Foo]{}
+
+	var Bind = func(tb testing.TB, pattern string) pubsub.Subscriber[Foo] {
+		return exchange.MakeQueue(pattern)
+	}
+
+	pubsubcontract.TopicExchange[Foo](exchange, Bind).Test(t)
+}
+
+func TestHeadersExchange(t *testing.T) {
+	exchange := &memory.HeadersExchange[Foo]{}
+
+	var Bind = func(tb testing.TB, binding pubsub.HeadersBinding) pubsub.Subscriber[Foo] {
+		return exchange.MakeQueue(binding)
+	}
+
+	pubsubcontract.HeadersExchange[Foo](exchange, Bind).Test(t)
+}
+
+func TestDeadLetter(t *testing.T) {
+	dlq := &memory.Queue[Foo]{}
+	q := &memory.Queue[Foo]{
+		MaxDeliveryAttempts: 2,
+		DeadLetterQueue:     dlq,
+	}
+
+	pubsubcontract.DeadLetter[Foo](q, q, dlq, q.MaxDeliveryAttempts).Test(t)
+}
+
+func TestDelayed(t *testing.T) {
+	q := &memory.Queue[Foo]{}
+
+	pubsubcontract.Delayed[Foo](q, q).Test(t)
+}
+
+func TestVisibilityTimeout(t *testing.T) {
+	q := &memory.Queue[Foo]{VisibilityTimeout: time.Minute}
+
+	pubsubcontract.VisibilityTimeout[Foo](q, q, q.VisibilityTimeout).Test(t)
+}
+
+func TestMetadata(t *testing.T) {
+	q := &memory.Queue[Foo]{}
+
+	pubsubcontract.Metadata[Foo](q, q).Test(t)
+}
+
 func TestTransactionalMessageContext(t *testing.T) {
 	pubsubConfig := pubsubcontract.Config[TestEntity]{
 		MakeContext: func(t testing.TB) context.Context {
@@ -247,3 +247,43 @@ func (FooerT1) GetFoo() string { return "foo" }
 type FooerT2 struct{ V int }
 
 func (FooerT2) GetFoo() string { return "foo" }
+
+// Doc is a versioned entity, that opts into optimistic concurrency control with its Version field.
+type Doc struct {
+	ID      DocID `ext:"id"`
+	Title   string
+	Body    string
+	Version int64 `ext:"version"`
+}
+
+type DocID string
+
+func (id DocID) String() string { return string(id) }
+
+func MakeDoc(tb testing.TB) Doc {
+	t := testcase.ToT(&tb)
+	return Doc{
+		Title: t.Random.String(),
+		Body:  t.Random.String(),
+	}
+}
+
+// Composite is an entity with a composite ID, that is made of multiple `ext:"id"` tagged fields.
+type Composite struct {
+	TenantID string `ext:"id"`
+	LocalID  int    `ext:"id"`
+	Value    string
+}
+
+// CompositeID is the ID of Composite, with a field for each of the Composite's ID fields.
+type CompositeID struct {
+	TenantID string
+	LocalID  int
+}
+
+func MakeComposite(tb testing.TB) Composite {
+	t := testcase.ToT(&tb)
+	return Composite{
+		Value: t.Random.String(),
+	}
+}